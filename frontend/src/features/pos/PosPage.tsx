@@ -1,10 +1,20 @@
 import {useEffect, useMemo, useState} from "react";
 import type {ProductView} from "@/features/products/api";
 import {fetchProducts} from "@/features/products/api";
-import {buildCreateSaleRequest, createSale} from "@/features/pos/api";
-import type {Sale} from "@/features/pos/api";
+import {
+  buildCreateSaleRequest,
+  createCustomer,
+  createSale,
+  discardCart,
+  fetchHeldCarts,
+  fetchPricesIncludeTax,
+  parkCart,
+  resumeCart,
+  searchCustomers,
+} from "@/features/pos/api";
+import type {Customer, HeldCart, Sale} from "@/features/pos/api";
 import {InvoiceDialog} from "@/features/pos/components/InvoiceDialog";
-import {calculateTotals, parseMoney, type TotalsInputLine} from "@/features/pos/utils";
+import {calculateLineTax, calculateTotals, parseMoney, type TotalsInputLine} from "@/features/pos/utils";
 import {useCurrencyFormatter} from "@/features/settings/ShopProfileContext";
 
 type CartLine = {
@@ -15,12 +25,7 @@ type CartLine = {
 
 // TODO(#POS-42): Support applying customer-specific pricing tiers during cart calculations.
 
-const paymentOptions = ["Cash", "Card", "Wallet/UPI"] as const;
-
-function generateSaleNumber(): string {
-  const now = new Date();
-  return `INV-${now.getFullYear()}${String(now.getMonth() + 1).padStart(2, "0")}${String(now.getDate()).padStart(2, "0")}-${now.getHours()}${now.getMinutes()}${now.getSeconds()}`;
-}
+const paymentOptions = ["Cash", "Card", "Wallet/UPI", "Points", "Gift Card", "Store Credit"] as const;
 
 type PosPageProps = {
   onInventoryChanged?: () => Promise<void> | void;
@@ -32,12 +37,20 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
   const [search, setSearch] = useState("");
   const [cart, setCart] = useState<CartLine[]>([]);
   const [customerName, setCustomerName] = useState("");
+  const [customerId, setCustomerId] = useState<number | null>(null);
+  const [customerMatches, setCustomerMatches] = useState<Customer[]>([]);
   const [paymentMethod, setPaymentMethod] = useState<(typeof paymentOptions)[number]>("Cash");
+  const [paymentReference, setPaymentReference] = useState("");
   const [orderDiscount, setOrderDiscount] = useState("0.00");
+  const [couponCode, setCouponCode] = useState("");
   const [isLoading, setIsLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
   const [isSubmitting, setIsSubmitting] = useState(false);
   const [invoice, setInvoice] = useState<Sale | null>(null);
+  const [heldCarts, setHeldCarts] = useState<HeldCart[]>([]);
+  const [parkedBy, setParkedBy] = useState("");
+  const [reserveStock, setReserveStock] = useState(false);
+  const [pricesIncludeTax, setPricesIncludeTax] = useState(false);
 
   useEffect(() => {
     fetchProducts()
@@ -49,8 +62,18 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
       })
       .catch(() => setError("Unable to load products."))
       .finally(() => setIsLoading(false));
+    refreshHeldCarts();
+    fetchPricesIncludeTax()
+      .then(setPricesIncludeTax)
+      .catch(() => setPricesIncludeTax(false));
   }, []);
 
+  function refreshHeldCarts() {
+    fetchHeldCarts()
+      .then(setHeldCarts)
+      .catch(() => setHeldCarts([]));
+  }
+
   const filteredProducts = useMemo(() => {
     if (!search.trim()) {
       return products.slice(0, 20);
@@ -61,6 +84,45 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
     ).slice(0, 20);
   }, [products, search]);
 
+  useEffect(() => {
+    const query = customerName.trim();
+    if (customerId !== null || query.length < 2) {
+      setCustomerMatches([]);
+      return;
+    }
+    const timer = window.setTimeout(() => {
+      searchCustomers(query)
+        .then(setCustomerMatches)
+        .catch(() => setCustomerMatches([]));
+    }, 250);
+    return () => window.clearTimeout(timer);
+  }, [customerName, customerId]);
+
+  function handleCustomerNameChange(value: string) {
+    setCustomerName(value);
+    const match = customerMatches.find(item => item.name === value);
+    setCustomerId(match ? match.id : null);
+  }
+
+  function clearCustomer() {
+    setCustomerName("");
+    setCustomerId(null);
+    if (paymentMethod === "Points") {
+      setPaymentMethod("Cash");
+    }
+  }
+
+  async function handleSaveCustomer() {
+    setError(null);
+    try {
+      const created = await createCustomer(customerName.trim());
+      setCustomerName(created.name);
+      setCustomerId(created.id);
+    } catch (err) {
+      setError(describeError(err));
+    }
+  }
+
   const totals = useMemo(() => {
     const lines: TotalsInputLine[] = cart.map(line => ({
       unitPriceCents: line.product.unitPriceCents,
@@ -68,8 +130,10 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
       lineDiscountCents: Math.min(parseMoney(line.lineDiscount), line.product.unitPriceCents * line.quantity),
       taxRatePercent: line.product.taxRate,
     }));
-    return calculateTotals(lines, parseMoney(orderDiscount));
-  }, [cart, orderDiscount]);
+    return calculateTotals(lines, parseMoney(orderDiscount), pricesIncludeTax);
+  }, [cart, orderDiscount, pricesIncludeTax]);
+
+  const isStoredValue = paymentMethod === "Gift Card" || paymentMethod === "Store Credit";
 
   function handleAddToCart(product: ProductView) {
     setCart(prev => {
@@ -106,8 +170,7 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
 
     setIsSubmitting(true);
     try {
-      const saleNumber = generateSaleNumber();
-      const orderDiscountCents = Math.min(parseMoney(orderDiscount), totals.subtotal);
+      const orderDiscountCents = totals.orderDiscount;
 
       const lines = cart
         .filter(line => line.quantity > 0)
@@ -127,10 +190,12 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
       }
 
       const request = buildCreateSaleRequest({
-        saleNumber,
+        customerId: customerId ?? 0,
         customerName: customerName.trim(),
         paymentMethod,
+        paymentReference: isStoredValue ? paymentReference.trim() : "",
         discountCents: orderDiscountCents,
+        couponCodes: couponCode.trim() ? [couponCode.trim()] : [],
         note: "",
         lines,
       });
@@ -140,7 +205,9 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
       setInvoice(sale);
       setCart([]);
       setOrderDiscount("0.00");
-      setCustomerName("");
+      setCouponCode("");
+      setPaymentReference("");
+      clearCustomer();
       setError(null);
 
       const updated = await fetchProducts();
@@ -155,6 +222,90 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
     }
   }
 
+  async function handlePark() {
+    setError(null);
+    const lines = cart
+      .filter(line => line.quantity > 0)
+      .map(line => ({
+        productId: line.product.id,
+        quantity: line.quantity,
+        discountCents: Math.min(parseMoney(line.lineDiscount), line.product.unitPriceCents * line.quantity),
+        serialNumbers: [],
+      }));
+    if (lines.length === 0) {
+      setError("Add at least one product before parking the cart.");
+      return;
+    }
+    if (!parkedBy.trim()) {
+      setError("Enter who is parking the cart.");
+      return;
+    }
+
+    setIsSubmitting(true);
+    try {
+      await parkCart({
+        customerName: customerName.trim(),
+        discountCents: totals.orderDiscount,
+        note: "",
+        parkedBy: parkedBy.trim(),
+        locationId: 0,
+        reserveStock,
+        lines,
+      });
+      setCart([]);
+      setOrderDiscount("0.00");
+      clearCustomer();
+      refreshHeldCarts();
+    } catch (err) {
+      setError(describeError(err));
+    } finally {
+      setIsSubmitting(false);
+    }
+  }
+
+  async function handleResume(id: number) {
+    setError(null);
+    if (cart.length > 0) {
+      setError("Charge or park the current cart before resuming another.");
+      return;
+    }
+    try {
+      const held = await resumeCart(id);
+      const missing: string[] = [];
+      const lines: CartLine[] = [];
+      for (const line of held.lines ?? []) {
+        const product = products.find(item => item.id === line.productId);
+        if (!product) {
+          missing.push(line.sku || String(line.productId));
+          continue;
+        }
+        lines.push({product, quantity: line.quantity, lineDiscount: (line.discountCents / 100).toFixed(2)});
+      }
+      setCart(lines);
+      setCustomerName(held.customerName ?? "");
+      setCustomerId(null);
+      setOrderDiscount((held.discountCents / 100).toFixed(2));
+      if (missing.length > 0) {
+        setError(`Some items are no longer for sale: ${missing.join(", ")}`);
+      }
+    } catch (err) {
+      setError(describeError(err));
+    } finally {
+      refreshHeldCarts();
+    }
+  }
+
+  async function handleDiscard(id: number) {
+    setError(null);
+    try {
+      await discardCart(id);
+    } catch (err) {
+      setError(describeError(err));
+    } finally {
+      refreshHeldCarts();
+    }
+  }
+
   function closeInvoice() {
     setInvoice(null);
   }
@@ -241,8 +392,8 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
                   const subtotal = line.product.unitPriceCents * line.quantity;
                   const discountCents = Math.min(parseMoney(line.lineDiscount), subtotal);
                   const taxableBase = subtotal - discountCents;
-                  const taxCents = Math.round(taxableBase * (line.product.taxRate / 100));
-                  const totalCents = taxableBase + taxCents;
+                  const taxCents = calculateLineTax(taxableBase, line.product.taxRate, pricesIncludeTax);
+                  const totalCents = pricesIncludeTax ? taxableBase : taxableBase + taxCents;
 
                   return (
                     <tr key={line.product.id} className="rounded-xl bg-slate-50/80 align-top shadow-sm dark:bg-slate-800/60">
@@ -293,8 +444,27 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
 
         <div className="grid gap-4 md:grid-cols-3">
           <label className="flex flex-col gap-1 text-sm font-semibold text-slate-600 dark:text-slate-300">
-            <span>Customer Name (optional)</span>
-            <input value={customerName} onChange={event => setCustomerName(event.target.value)} placeholder="Walk-in"/>
+            <span>Customer (optional)</span>
+            <input
+              value={customerName}
+              onChange={event => handleCustomerNameChange(event.target.value)}
+              placeholder="Walk-in"
+              list="pos-customer-matches"
+            />
+            <datalist id="pos-customer-matches">
+              {customerMatches.map(item => (
+                <option key={item.id} value={item.name}>{[item.phone, item.email].filter(Boolean).join(" · ")}</option>
+              ))}
+            </datalist>
+            {customerId !== null ? (
+              <span className="text-xs font-normal text-emerald-600 dark:text-emerald-300">
+                Customer record linked · <button type="button" className="underline" onClick={clearCustomer}>clear</button>
+              </span>
+            ) : customerName.trim() && (
+              <button type="button" className="self-start text-xs font-normal underline" onClick={handleSaveCustomer}>
+                Save as new customer
+              </button>
+            )}
           </label>
 
           <label className="flex flex-col gap-1 text-sm font-semibold text-slate-600 dark:text-slate-300">
@@ -305,11 +475,23 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
               onChange={event => setPaymentMethod(event.target.value as (typeof paymentOptions)[number])}
             >
               {paymentOptions.map(method => (
-                <option key={method} value={method}>{method}</option>
+                <option key={method} value={method} disabled={method === "Points" && customerId === null}>{method}</option>
               ))}
             </select>
           </label>
 
+          {isStoredValue && (
+            <label className="flex flex-col gap-1 text-sm font-semibold text-slate-600 dark:text-slate-300">
+              <span>{paymentMethod} Code</span>
+              <input
+                type="text"
+                value={paymentReference}
+                onChange={event => setPaymentReference(event.target.value)}
+                placeholder="GC-XXXX-XXXX-XXXX"
+              />
+            </label>
+          )}
+
           <label className="flex flex-col gap-1 text-sm font-semibold text-slate-600 dark:text-slate-300">
             <span>Order Discount</span>
             <input
@@ -320,6 +502,16 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
               onChange={event => setOrderDiscount(event.target.value)}
             />
           </label>
+
+          <label className="flex flex-col gap-1 text-sm font-semibold text-slate-600 dark:text-slate-300">
+            <span>Coupon Code</span>
+            <input
+              type="text"
+              value={couponCode}
+              onChange={event => setCouponCode(event.target.value)}
+              placeholder="Optional"
+            />
+          </label>
         </div>
 
         <dl className="grid gap-3 rounded-2xl border border-slate-200 bg-slate-50/70 p-4 text-sm font-semibold text-slate-700 dark:border-slate-700 dark:bg-slate-800/60 dark:text-slate-200 sm:grid-cols-2">
@@ -329,7 +521,7 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
           </div>
           <div className="flex items-center justify-between">
             <dt>Discount</dt>
-            <dd>{formatCurrency(totals.orderDiscount)}</dd>
+            <dd>{formatCurrency(totals.lineDiscount + totals.orderDiscount)}</dd>
           </div>
           <div className="flex items-center justify-between">
             <dt>Tax</dt>
@@ -355,6 +547,61 @@ export function PosPage({onInventoryChanged}: PosPageProps) {
         >
           {isSubmitting ? "Processing…" : "Charge & Print"}
         </button>
+
+        <div className="flex flex-col gap-3 rounded-2xl border border-slate-200 bg-slate-50/70 p-4 text-sm dark:border-slate-700 dark:bg-slate-800/60">
+          <div className="flex flex-wrap items-end gap-3">
+            <label className="flex flex-col gap-1 font-semibold text-slate-600 dark:text-slate-300">
+              <span>Parked by</span>
+              <input value={parkedBy} onChange={event => setParkedBy(event.target.value)} placeholder="Cashier"/>
+            </label>
+            <label className="flex items-center gap-2 font-semibold text-slate-600 dark:text-slate-300">
+              <input type="checkbox" checked={reserveStock} onChange={event => setReserveStock(event.target.checked)}/>
+              <span>Reserve stock</span>
+            </label>
+            <button
+              type="button"
+              className="rounded-2xl border border-slate-200 bg-white px-4 py-2 font-semibold text-slate-700 transition hover:bg-slate-50 focus:outline-none focus:ring-2 focus:ring-brand-primary/40 disabled:cursor-not-allowed disabled:opacity-60 dark:border-slate-700 dark:bg-slate-900 dark:text-slate-100"
+              disabled={isSubmitting || cart.length === 0}
+              onClick={handlePark}
+            >
+              Park Cart
+            </button>
+          </div>
+
+          {heldCarts.length > 0 && (
+            <ul className="flex flex-col gap-2">
+              {heldCarts.map(held => (
+                <li key={held.id} className="flex items-center justify-between gap-3 rounded-xl bg-white px-3 py-2 shadow-sm dark:bg-slate-900">
+                  <div className="flex flex-col">
+                    <span className="font-semibold text-slate-900 dark:text-white">
+                      {held.customerName || "Walk-in"} · {(held.lines ?? []).length} items
+                    </span>
+                    <span className="text-xs text-slate-500 dark:text-slate-400">
+                      Parked by {held.parkedBy} · expires {new Date(held.expiresAt).toLocaleString()}
+                      {held.reserveStock ? " · stock reserved" : ""}
+                    </span>
+                  </div>
+                  <div className="flex gap-2">
+                    <button
+                      type="button"
+                      onClick={() => handleResume(held.id)}
+                      className="rounded-full border border-blue-200 bg-blue-50 px-3 py-1 text-xs font-semibold text-brand-primary transition hover:bg-blue-100 dark:border-blue-800 dark:bg-blue-900/40 dark:text-blue-100"
+                    >
+                      Resume
+                    </button>
+                    <button
+                      type="button"
+                      onClick={() => handleDiscard(held.id)}
+                      className="rounded-full border border-rose-200 bg-rose-50 px-3 py-1 text-xs font-semibold text-rose-600 transition hover:bg-rose-100 dark:border-rose-700 dark:bg-rose-900/40 dark:text-rose-200"
+                    >
+                      Discard
+                    </button>
+                  </div>
+                </li>
+              ))}
+            </ul>
+          )}
+        </div>
       </section>
 
       {invoice && <InvoiceDialog sale={invoice} onClose={closeInvoice}/>}
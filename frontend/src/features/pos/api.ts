@@ -1,5 +1,8 @@
 import {CreateSale, GetSale, ListSales, RefundSale, VoidSale} from "../../../wailsjs/go/sale/API";
-import {sale} from "../../../wailsjs/go/models";
+import {Discard as DiscardCart, List as ListHeldCarts, Park as ParkCart, Resume as ResumeCart} from "../../../wailsjs/go/cart/API";
+import {Create as CreateCustomer, Search as SearchCustomers} from "../../../wailsjs/go/customer/API";
+import {Policy as TaxPolicy} from "../../../wailsjs/go/tax/API";
+import {cart, customer, sale} from "../../../wailsjs/go/models";
 import {unwrap, unwrapVoid} from "@/services/wailsResponse";
 
 type CreateSaleRequestLine = sale.CreateSaleRequestLine;
@@ -35,10 +38,12 @@ export async function voidSale(id: number, note: string): Promise<void> {
 }
 
 export function buildCreateSaleRequest(input: {
-  saleNumber: string;
+  customerId?: number;
   customerName?: string;
   paymentMethod: string;
+  paymentReference?: string;
   discountCents: number;
+  couponCodes?: string[];
   note?: string;
   lines: Array<Omit<CreateSaleRequestLine, "__ignore" | "createFrom" | "constructor">>;
 }): CreateSaleRequest {
@@ -48,3 +53,43 @@ export function buildCreateSaleRequest(input: {
     lines: requestLines,
   });
 }
+
+export type HeldCart = cart.Held;
+export type ParkCartRequest = cart.ParkCartRequest;
+
+export async function parkCart(request: ParkCartRequest): Promise<HeldCart> {
+  const envelope = await ParkCart(cart.ParkCartRequest.createFrom(request));
+  return cart.Held.createFrom(unwrap(envelope));
+}
+
+export async function fetchHeldCarts(): Promise<HeldCart[]> {
+  const envelope = await ListHeldCarts();
+  return unwrap(envelope).map(cart.Held.createFrom);
+}
+
+export async function resumeCart(id: number): Promise<HeldCart> {
+  const envelope = await ResumeCart(id);
+  return cart.Held.createFrom(unwrap(envelope));
+}
+
+export async function discardCart(id: number): Promise<void> {
+  const envelope = await DiscardCart(id);
+  unwrapVoid(envelope);
+}
+
+export type Customer = customer.Customer;
+
+export async function searchCustomers(query: string): Promise<Customer[]> {
+  const envelope = await SearchCustomers(query);
+  return unwrap(envelope).map(customer.Customer.createFrom);
+}
+
+export async function createCustomer(name: string): Promise<Customer> {
+  const envelope = await CreateCustomer(customer.CustomerInput.createFrom({name, phone: "", email: "", address: "", taxId: "", notes: ""}));
+  return customer.Customer.createFrom(unwrap(envelope));
+}
+
+export async function fetchPricesIncludeTax(): Promise<boolean> {
+  const envelope = await TaxPolicy();
+  return unwrap(envelope).pricesIncludeTax;
+}
@@ -78,13 +78,31 @@ export function InvoiceDialog({sale, onClose}: InvoiceDialogProps) {
               <dd>{formatCurrency(sale.discountCents)}</dd>
             </div>
             <div className="flex items-center justify-between rounded-xl bg-slate-100 px-3 py-2 dark:bg-slate-800">
-              <dt>Tax</dt>
+              <dt>{sale.taxExempt ? "Tax exempt" : sale.taxIncluded ? "Tax included" : "Tax"}</dt>
               <dd>{formatCurrency(sale.taxCents)}</dd>
             </div>
+            {(sale.taxes ?? []).map(tax => (
+              <div key={`${tax.name}-${tax.rateBasisPoints}`} className="flex items-center justify-between rounded-xl bg-slate-100 px-3 py-2 text-xs dark:bg-slate-800">
+                <dt>{tax.name} {(tax.rateBasisPoints / 100).toFixed(2)}%</dt>
+                <dd>{formatCurrency(tax.taxCents)}</dd>
+              </div>
+            ))}
             <div className="flex items-center justify-between rounded-xl bg-gradient-to-r from-brand-primary to-brand-accent px-3 py-2 text-white shadow-lg">
               <dt>Total</dt>
               <dd>{formatCurrency(sale.totalCents)}</dd>
             </div>
+            {(sale.payments ?? []).map((payment, index) => (
+              <div key={`${payment.method}-${index}`} className="flex items-center justify-between rounded-xl bg-slate-100 px-3 py-2 dark:bg-slate-800">
+                <dt>Paid by {payment.method}</dt>
+                <dd>{formatCurrency(payment.tenderedCents)}</dd>
+              </div>
+            ))}
+            {sale.changeCents > 0 && (
+              <div className="flex items-center justify-between rounded-xl bg-slate-100 px-3 py-2 dark:bg-slate-800">
+                <dt>Change</dt>
+                <dd>{formatCurrency(sale.changeCents)}</dd>
+              </div>
+            )}
           </dl>
 
           <div className="flex flex-wrap items-center gap-3">
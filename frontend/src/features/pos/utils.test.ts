@@ -14,8 +14,17 @@ describe("pos utils", () => {
     ];
     const totals = calculateTotals(lines, 200);
     expect(totals.subtotal).toBe(2500);
+    expect(totals.lineDiscount).toBe(100);
     expect(totals.orderDiscount).toBe(200);
-    expect(totals.tax).toBe(100);
-    expect(totals.total).toBe(2400);
+    // The mug line carries 167 of the order discount, leaving 1833 to tax at 5%.
+    expect(totals.tax).toBe(92);
+    expect(totals.total).toBe(2292);
+  });
+
+  it("works tax out of tax-inclusive prices", () => {
+    const lines: TotalsInputLine[] = [{unitPriceCents: 1100, quantity: 2, lineDiscountCents: 0, taxRatePercent: 10}];
+    const totals = calculateTotals(lines, 200, true);
+    expect(totals.tax).toBe(182);
+    expect(totals.total).toBe(2000);
   });
 });
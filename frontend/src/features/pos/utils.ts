@@ -15,32 +15,64 @@ export type TotalsInputLine = {
 
 export type Totals = {
   subtotal: number;
+  lineDiscount: number;
   orderDiscount: number;
   tax: number;
   total: number;
 };
 
-export function calculateTotals(lines: TotalsInputLine[], orderDiscountCents: number): Totals {
+// Line discounts and the order discount come off the lines before tax; the order discount
+// is spread over the lines in proportion to what is left of them, leftover cents going to
+// the earliest lines. With pricesIncludeTax the shelf prices already contain the tax, which
+// is worked out of each line instead of added to the total.
+export function calculateTotals(lines: TotalsInputLine[], orderDiscountCents: number, pricesIncludeTax = false): Totals {
   let subtotal = 0;
-  let tax = 0;
+  let lineDiscounts = 0;
+  const amounts: number[] = [];
 
   for (const line of lines) {
-    if (line.quantity <= 0) continue;
+    if (line.quantity <= 0) {
+      amounts.push(0);
+      continue;
+    }
     const lineSubtotal = line.unitPriceCents * line.quantity;
     const lineDiscount = Math.min(line.lineDiscountCents, lineSubtotal);
-    const taxable = lineSubtotal - lineDiscount;
-    const lineTax = Math.round(taxable * (line.taxRatePercent / 100));
     subtotal += lineSubtotal;
-    tax += lineTax;
+    lineDiscounts += lineDiscount;
+    amounts.push(lineSubtotal - lineDiscount);
+  }
+
+  const discounted = subtotal - lineDiscounts;
+  const effectiveOrderDiscount = Math.min(Math.max(orderDiscountCents, 0), discounted);
+  const shares = amounts.map(amount => (discounted > 0 ? Math.floor(effectiveOrderDiscount * amount / discounted) : 0));
+  let allocated = shares.reduce((sum, share) => sum + share, 0);
+  for (let i = 0; allocated < effectiveOrderDiscount; i = (i + 1) % amounts.length) {
+    if (amounts[i] > 0) {
+      shares[i]++;
+      allocated++;
+    }
   }
 
-  const effectiveOrderDiscount = Math.min(orderDiscountCents, subtotal);
-  const total = subtotal - effectiveOrderDiscount + tax;
+  let tax = 0;
+  lines.forEach((line, i) => {
+    if (line.quantity <= 0) return;
+    tax += calculateLineTax(amounts[i] - shares[i], line.taxRatePercent, pricesIncludeTax);
+  });
+
+  const total = discounted - effectiveOrderDiscount + (pricesIncludeTax ? 0 : tax);
 
   return {
     subtotal,
+    lineDiscount: lineDiscounts,
     orderDiscount: effectiveOrderDiscount,
     tax,
     total,
   };
 }
+
+export function calculateLineTax(amountCents: number, taxRatePercent: number, pricesIncludeTax = false): number {
+  if (pricesIncludeTax) {
+    return amountCents - Math.round(amountCents / (1 + taxRatePercent / 100));
+  }
+  return Math.round(amountCents * (taxRatePercent / 100));
+}
@@ -25,8 +25,9 @@ export async function createProduct(input: ProductInput): Promise<ProductView> {
   return product.ProductView.createFrom(unwrap(envelope));
 }
 
-export async function updateProduct(id: number, form: ProductInput): Promise<ProductView> {
-  const envelope = await UpdateProduct(product.UpdateProductRequest.createFrom({id, form}));
+/** Saves an edit made against `version`; rejects with a ConflictError if the product changed since. */
+export async function updateProduct(id: number, version: number, form: ProductInput): Promise<ProductView> {
+  const envelope = await UpdateProduct(product.UpdateProductRequest.createFrom({id, version, form}));
   return product.ProductView.createFrom(unwrap(envelope));
 }
 
@@ -12,6 +12,7 @@ type FormState = {
   stockQuantity: string;
   reorderLevel: string;
   notes: string;
+  changedBy: string;
 };
 
 type ProductFormProps = {
@@ -29,6 +30,7 @@ const initialState: FormState = {
   stockQuantity: "0",
   reorderLevel: "0",
   notes: "",
+  changedBy: "",
 };
 
 function parseMoney(value: string): number {
@@ -59,10 +61,12 @@ export function ProductForm({onCreate, isSubmitting, error}: ProductFormProps) {
       stockQuantity: Number.parseInt(form.stockQuantity, 10) || 0,
       reorderLevel: Number.parseInt(form.reorderLevel, 10) || 0,
       notes: form.notes.trim(),
+      changedBy: form.changedBy.trim(),
     };
 
     await onCreate(payload);
-    setForm(initialState);
+    // The same person usually adds several products in a row.
+    setForm({...initialState, changedBy: form.changedBy});
   }
 
   return (
@@ -96,6 +100,10 @@ export function ProductForm({onCreate, isSubmitting, error}: ProductFormProps) {
           <span>Reorder Level</span>
           <input type="number" min="0" step="1" value={form.reorderLevel} onChange={updateField("reorderLevel")}/>
         </label>
+        <label className="flex flex-col gap-1 text-sm font-semibold text-slate-600 dark:text-slate-300">
+          <span>Changed By</span>
+          <input value={form.changedBy} onChange={updateField("changedBy")} placeholder="Your name"/>
+        </label>
       </div>
       <label className="flex flex-col gap-1 text-sm font-semibold text-slate-600 dark:text-slate-300">
         <span>Notes</span>
@@ -10,7 +10,7 @@ import {InvoiceDialog} from "@/features/pos/components/InvoiceDialog";
 import {useCurrencyFormatter} from "@/features/settings/ShopProfileContext";
 
 const PAYMENT_METHODS = ["Cash", "Card", "Wallet/UPI"] as const;
-const STATUSES = ["Completed", "Refunded", "Voided"] as const;
+const STATUSES = ["Completed", "PartiallyRefunded", "Refunded", "Voided"] as const;
 
 type SalesFilter = {
   from: string;
@@ -244,7 +244,7 @@ export function SalesPage() {
                         className={`inline-flex items-center rounded-full px-3 py-1 text-xs font-semibold ${
                           sale.status === "Completed"
                             ? "bg-emerald-100 text-emerald-700 dark:bg-emerald-900/40 dark:text-emerald-200"
-                            : sale.status === "Refunded"
+                            : sale.status === "Refunded" || sale.status === "PartiallyRefunded"
                               ? "bg-amber-100 text-amber-700 dark:bg-amber-900/40 dark:text-amber-200"
                               : "bg-rose-100 text-rose-700 dark:bg-rose-900/40 dark:text-rose-200"
                         }`}
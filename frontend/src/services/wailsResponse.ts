@@ -2,9 +2,21 @@ export type Envelope<T> = {
   ok: boolean;
   data?: T | null;
   error?: string | null;
+  code?: string | null;
 };
 
+/** Thrown when a record changed since it was loaded; `current` holds its latest values. */
+export class ConflictError<T> extends Error {
+  constructor(message: string, readonly current: T) {
+    super(message);
+    this.name = "ConflictError";
+  }
+}
+
 export function unwrap<T>(envelope: Envelope<T>): T {
+  if (!envelope.ok && envelope.code === "CONFLICT" && envelope.data) {
+    throw new ConflictError(envelope.error ?? "Record changed", envelope.data);
+  }
   if (!envelope.ok || envelope.data === undefined || envelope.data === null) {
     const message = envelope.error ?? "Unexpected response";
     throw new Error(message);
@@ -0,0 +1,80 @@
+// Package spreadsheet reads tabular CSV and XLSX files into rows of strings.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Supported file formats.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Table is the first row of a sheet as headers followed by the data rows.
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+// DetectFormat picks the format from the file extension, falling back to sniffing the
+// zip signature every XLSX file starts with.
+func DetectFormat(fileName string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".xlsx":
+		return FormatXLSX
+	case ".csv", ".txt":
+		return FormatCSV
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return FormatXLSX
+	}
+	return FormatCSV
+}
+
+// Read decodes data in the given format.
+func Read(format string, data []byte) (Table, error) {
+	switch format {
+	case FormatCSV:
+		return ReadCSV(bytes.NewReader(data))
+	case FormatXLSX:
+		return ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	default:
+		return Table{}, fmt.Errorf("unsupported file format %q", format)
+	}
+}
+
+// ReadCSV decodes a CSV file. Rows may have differing column counts.
+func ReadCSV(r io.Reader) (Table, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	headers, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return Table{}, errors.New("file is empty")
+		}
+		return Table{}, fmt.Errorf("read header: %w", err)
+	}
+	headers[0] = strings.TrimPrefix(headers[0], "\ufeff")
+
+	table := Table{Headers: headers}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Table{}, fmt.Errorf("read row %d: %w", len(table.Rows)+2, err)
+		}
+		table.Rows = append(table.Rows, record)
+	}
+	return table, nil
+}
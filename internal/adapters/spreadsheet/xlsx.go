@@ -0,0 +1,311 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ReadXLSX decodes the first worksheet of an Office Open XML workbook. Only cell values are
+// read; formulas contribute their cached result. Styling is ignored except that numbers in
+// a percent format read as they display, so a stored 0.2 becomes "20%".
+func ReadXLSX(r io.ReaderAt, size int64) (Table, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return Table{}, fmt.Errorf("open xlsx: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	shared, err := readSharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return Table{}, err
+	}
+
+	percent, err := readPercentStyles(files["xl/styles.xml"])
+	if err != nil {
+		return Table{}, err
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return Table{}, err
+	}
+	sheet, ok := files[sheetPath]
+	if !ok {
+		return Table{}, fmt.Errorf("xlsx worksheet %s missing", sheetPath)
+	}
+
+	rows, err := readSheet(sheet, shared, percent)
+	if err != nil {
+		return Table{}, err
+	}
+	if len(rows) == 0 {
+		return Table{}, errors.New("file is empty")
+	}
+	return Table{Headers: rows[0], Rows: rows[1:]}, nil
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+// xlsxRichText is either a plain <t> or a run of <r><t> fragments.
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.Text
+	}
+	var sb strings.Builder
+	sb.WriteString(t.Text)
+	for _, run := range t.Runs {
+		sb.WriteString(run.Text)
+	}
+	return sb.String()
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Index int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Style  int          `xml:"s,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	var doc xlsxSharedStrings
+	if err := decodeZipXML(f, &doc); err != nil {
+		return nil, fmt.Errorf("read shared strings: %w", err)
+	}
+	shared := make([]string, len(doc.Items))
+	for i, item := range doc.Items {
+		shared[i] = item.String()
+	}
+	return shared, nil
+}
+
+type xlsxStyles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+// readPercentStyles reports, by cell style index, which styles format numbers as percents:
+// the built-in 0% and 0.00% formats and custom formats with an unquoted percent sign.
+func readPercentStyles(f *zip.File) ([]bool, error) {
+	if f == nil {
+		return nil, nil
+	}
+	var doc xlsxStyles
+	if err := decodeZipXML(f, &doc); err != nil {
+		return nil, fmt.Errorf("read styles: %w", err)
+	}
+	formats := map[int]bool{9: true, 10: true}
+	for _, format := range doc.NumFmts {
+		formats[format.ID] = isPercentFormat(format.Code)
+	}
+	percent := make([]bool, len(doc.CellXfs))
+	for i, xf := range doc.CellXfs {
+		percent[i] = formats[xf.NumFmtID]
+	}
+	return percent, nil
+}
+
+func isPercentFormat(code string) bool {
+	quoted, escaped := false, false
+	for _, r := range code {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == '%' && !quoted:
+			return true
+		}
+	}
+	return false
+}
+
+// percentValue renders a stored fraction such as 0.2 as the percent it displays, "20%".
+func percentValue(value string) (string, bool) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(value))
+	if !ok {
+		return "", false
+	}
+	f, _ := r.Mul(r, big.NewRat(100, 1)).Float64()
+	return strconv.FormatFloat(f, 'f', -1, 64) + "%", true
+}
+
+// firstSheetPath resolves the first sheet listed in the workbook through its relationship,
+// falling back to the conventional sheet1 location.
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wb, rels := files["xl/workbook.xml"], files["xl/_rels/workbook.xml.rels"]
+	if wb == nil || rels == nil {
+		return fallback, nil
+	}
+
+	var workbook xlsxWorkbook
+	if err := decodeZipXML(wb, &workbook); err != nil {
+		return "", fmt.Errorf("read workbook: %w", err)
+	}
+	var relationships xlsxRelationships
+	if err := decodeZipXML(rels, &relationships); err != nil {
+		return "", fmt.Errorf("read workbook relationships: %w", err)
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("workbook has no sheets")
+	}
+
+	for _, rel := range relationships.Items {
+		if rel.ID != workbook.Sheets[0].RelID {
+			continue
+		}
+		target := rel.Target
+		if strings.HasPrefix(target, "/") {
+			return strings.TrimPrefix(target, "/"), nil
+		}
+		return path.Join("xl", target), nil
+	}
+	return fallback, nil
+}
+
+func readSheet(f *zip.File, shared []string, percent []bool) ([][]string, error) {
+	var sheet xlsxSheet
+	if err := decodeZipXML(f, &sheet); err != nil {
+		return nil, fmt.Errorf("read worksheet: %w", err)
+	}
+
+	var rows [][]string
+	for i, row := range sheet.Rows {
+		// Rows may be sparse; keep blank rows so row numbers match the sheet.
+		index := row.Index
+		if index <= 0 {
+			index = i + 1
+		}
+		for len(rows) < index-1 {
+			rows = append(rows, nil)
+		}
+
+		var values []string
+		for j, cell := range row.Cells {
+			col := j
+			if cell.Ref != "" {
+				parsed, err := columnIndex(cell.Ref)
+				if err != nil {
+					return nil, err
+				}
+				col = parsed
+			}
+			for len(values) <= col {
+				values = append(values, "")
+			}
+
+			switch cell.Type {
+			case "s":
+				idx, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+				if err != nil || idx < 0 || idx >= len(shared) {
+					return nil, fmt.Errorf("cell %s: invalid shared string index %q", cell.Ref, cell.Value)
+				}
+				values[col] = shared[idx]
+			case "inlineStr":
+				values[col] = cell.Inline.String()
+			case "", "n":
+				values[col] = cell.Value
+				if cell.Style >= 0 && cell.Style < len(percent) && percent[cell.Style] {
+					if shown, ok := percentValue(cell.Value); ok {
+						values[col] = shown
+					}
+				}
+			default:
+				values[col] = cell.Value
+			}
+		}
+		rows = append(rows, values)
+	}
+
+	// Drop trailing blank rows.
+	for len(rows) > 0 && isBlank(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	return rows, nil
+}
+
+// columnIndex converts a cell reference such as "AB12" to a zero-based column index.
+func columnIndex(ref string) (int, error) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			col = col*26 + int(r-'A'+1)
+			n++
+			continue
+		}
+		break
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+func isBlank(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func decodeZipXML(f *zip.File, target interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(target)
+}
@@ -0,0 +1,92 @@
+package spreadsheet_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"shopmate/internal/adapters/spreadsheet"
+)
+
+func TestReadXLSX(t *testing.T) {
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+			<sheets><sheet name="Stock" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+			<Relationship Id="rId1" Type="worksheet" Target="worksheets/stock.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+			<si><t>Code</t></si><si><t>Description</t></si><si><r><t>Whole </t></r><r><t>Milk</t></r></si></sst>`,
+		"xl/worksheets/stock.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="inlineStr"><is><t>Price</t></is></c></row>
+			<row r="2"><c r="A2"><v>1001</v></c><c r="B2" t="s"><v>2</v></c><c r="C2"><v>1.25</v></c></row>
+			<row r="4"><c r="A4" t="inlineStr"><is><t>1002</t></is></c><c r="C4"><v>3</v></c></row>
+		</sheetData></worksheet>`,
+	}
+
+	data := buildXLSX(t, files)
+	if format := spreadsheet.DetectFormat("upload", data); format != spreadsheet.FormatXLSX {
+		t.Fatalf("expected xlsx detected from signature, got %s", format)
+	}
+
+	table, err := spreadsheet.Read(spreadsheet.FormatXLSX, data)
+	if err != nil {
+		t.Fatalf("read xlsx: %v", err)
+	}
+	if len(table.Headers) != 3 || table.Headers[1] != "Description" || table.Headers[2] != "Price" {
+		t.Fatalf("unexpected headers %v", table.Headers)
+	}
+	if len(table.Rows) != 3 {
+		t.Fatalf("expected sparse rows preserved, got %v", table.Rows)
+	}
+	if table.Rows[0][1] != "Whole Milk" || table.Rows[0][2] != "1.25" {
+		t.Fatalf("unexpected first row %v", table.Rows[0])
+	}
+	if len(table.Rows[1]) != 0 || table.Rows[2][0] != "1002" || table.Rows[2][1] != "" || table.Rows[2][2] != "3" {
+		t.Fatalf("unexpected sparse rows %v", table.Rows[1:])
+	}
+}
+
+func TestReadXLSXPercentCells(t *testing.T) {
+	// Style 1 is the built-in 0% format, style 2 a custom 0.0% and style 3 a format whose
+	// percent sign is quoted text.
+	data := buildXLSX(t, map[string]string{
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+			<numFmts count="2"><numFmt numFmtId="164" formatCode="0.0%"/><numFmt numFmtId="165" formatCode="0&quot;%&quot;"/></numFmts>
+			<cellXfs count="4"><xf numFmtId="0"/><xf numFmtId="9"/><xf numFmtId="164"/><xf numFmtId="165"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+			<row r="1"><c r="A1" t="inlineStr"><is><t>Plain</t></is></c><c r="B1" t="inlineStr"><is><t>Percent</t></is></c><c r="C1" t="inlineStr"><is><t>Custom</t></is></c><c r="D1" t="inlineStr"><is><t>Quoted</t></is></c></row>
+			<row r="2"><c r="A2"><v>0.2</v></c><c r="B2" s="1"><v>0.2</v></c><c r="C2" s="2" t="n"><v>7.0000000000000007E-2</v></c><c r="D2" s="3"><v>20</v></c></row>
+			<row r="3"><c r="B3" s="1" t="inlineStr"><is><t>20%</t></is></c><c r="C3" s="2"/></row>
+		</sheetData></worksheet>`,
+	})
+
+	table, err := spreadsheet.Read(spreadsheet.FormatXLSX, data)
+	if err != nil {
+		t.Fatalf("read xlsx: %v", err)
+	}
+	if got := table.Rows[0]; got[0] != "0.2" || got[1] != "20%" || got[2] != "7.000000000000001%" || got[3] != "20" {
+		t.Fatalf("unexpected percent cells %v", got)
+	}
+	if got := table.Rows[1]; got[1] != "20%" || got[2] != "" {
+		t.Fatalf("expected text and empty cells left alone, got %v", got)
+	}
+}
+
+func buildXLSX(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
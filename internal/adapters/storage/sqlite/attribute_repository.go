@@ -0,0 +1,356 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/product"
+)
+
+// ListAttributes returns the custom attribute definitions in display order.
+func (r *ProductRepository) ListAttributes(ctx context.Context) ([]product.Attribute, error) {
+	return listAttributes(ctx, r.db)
+}
+
+// CreateAttribute adds a custom attribute definition.
+func (r *ProductRepository) CreateAttribute(ctx context.Context, input product.AttributeInput) (*product.Attribute, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	options, err := encodeAttributeOptions(input.Options)
+	if err != nil {
+		return nil, err
+	}
+	var id int64
+	if err := r.db.QueryRowContext(ctx, `
+		INSERT INTO product_attributes (code, name, type, options, sort_order, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		input.Code, input.Name, input.Type, options, input.SortOrder, time.Now().UnixMilli(),
+	).Scan(&id); err != nil {
+		if isAttributeConflict(err) {
+			return nil, fmt.Errorf("an attribute with code %q already exists", input.Code)
+		}
+		return nil, fmt.Errorf("insert attribute: %w", err)
+	}
+	return getAttribute(ctx, r.db, id)
+}
+
+// UpdateAttribute changes an attribute definition. Existing values are re-checked against the
+// new type and options, and stored in their new normalised form.
+func (r *ProductRepository) UpdateAttribute(ctx context.Context, id int64, input product.AttributeInput) (*product.Attribute, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	options, err := encodeAttributeOptions(input.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin attribute tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = getAttribute(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE product_attributes SET code = ?, name = ?, type = ?, options = ?, sort_order = ?
+		WHERE id = ?`,
+		input.Code, input.Name, input.Type, options, input.SortOrder, id,
+	); err != nil {
+		if isAttributeConflict(err) {
+			err = fmt.Errorf("an attribute with code %q already exists", input.Code)
+			return nil, err
+		}
+		return nil, fmt.Errorf("update attribute: %w", err)
+	}
+
+	var updated *product.Attribute
+	if updated, err = getAttribute(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	if err = renormaliseAttributeValues(ctx, tx, *updated); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit attribute: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteAttribute removes an attribute definition together with its values.
+func (r *ProductRepository) DeleteAttribute(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM product_attributes WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete attribute: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("attribute not found: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+// Search lists products matching a filter, ordered by name.
+func (r *ProductRepository) Search(ctx context.Context, filter product.Filter) ([]product.Product, error) {
+	var (
+		sb   strings.Builder
+		args = []interface{}{filter.IncludeArchived}
+	)
+	sb.WriteString(`SELECT ` + productColumns + ` FROM products WHERE (? OR archived_at IS NULL)`)
+
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		sb.WriteString(` AND (LOWER(name) LIKE ? OR LOWER(sku) LIKE ? OR LOWER(category) LIKE ? OR LOWER(COALESCE(notes, '')) LIKE ?
+			OR EXISTS (SELECT 1 FROM product_attribute_values v WHERE v.product_id = products.id AND LOWER(v.value) LIKE ?))`)
+		query := "%" + strings.ToLower(q) + "%"
+		args = append(args, query, query, query, query, query)
+	}
+	for code, value := range filter.Attributes {
+		sb.WriteString(` AND EXISTS (
+			SELECT 1 FROM product_attribute_values v
+			INNER JOIN product_attributes a ON a.id = v.attribute_id
+			WHERE v.product_id = products.id AND a.code = ? AND v.value = ? COLLATE NOCASE)`)
+		args = append(args, code, value)
+	}
+	sb.WriteString(` ORDER BY name ASC`)
+
+	rows, err := r.db.QueryContext(ctx, sb.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]product.Product, 0)
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := attachAttributes(ctx, r.db, products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// setAttributeValues validates and stores attribute values for a product. Blank values clear
+// the attribute; with replace set, attributes missing from values are cleared as well.
+func setAttributeValues(ctx context.Context, tx *sql.Tx, productID int64, values map[string]string, replace bool) error {
+	attributes, err := listAttributes(ctx, tx)
+	if err != nil {
+		return err
+	}
+	normalised, err := product.NormaliseAttributeValues(attributes, values)
+	if err != nil {
+		return err
+	}
+	if replace {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM product_attribute_values WHERE product_id = ?`, productID); err != nil {
+			return fmt.Errorf("clear attribute values: %w", err)
+		}
+	}
+	for _, a := range attributes {
+		value, ok := normalised[a.Code]
+		if !ok {
+			continue
+		}
+		if value == "" {
+			if _, err := tx.ExecContext(ctx, `
+				DELETE FROM product_attribute_values WHERE product_id = ? AND attribute_id = ?`,
+				productID, a.ID,
+			); err != nil {
+				return fmt.Errorf("clear attribute value: %w", err)
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO product_attribute_values (product_id, attribute_id, value) VALUES (?, ?, ?)
+			ON CONFLICT(product_id, attribute_id) DO UPDATE SET value = excluded.value`,
+			productID, a.ID, value,
+		); err != nil {
+			return fmt.Errorf("set attribute value: %w", err)
+		}
+	}
+	return nil
+}
+
+// renormaliseAttributeValues rewrites every stored value of an attribute in its current form,
+// failing on the first product whose value no longer fits.
+func renormaliseAttributeValues(ctx context.Context, tx *sql.Tx, a product.Attribute) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT v.product_id, p.sku, v.value
+		FROM product_attribute_values v
+		INNER JOIN products p ON p.id = v.product_id
+		WHERE v.attribute_id = ?`, a.ID)
+	if err != nil {
+		return fmt.Errorf("query attribute values: %w", err)
+	}
+	type stored struct {
+		productID  int64
+		sku, value string
+	}
+	var values []stored
+	for rows.Next() {
+		var v stored
+		if err := rows.Scan(&v.productID, &v.sku, &v.value); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan attribute value: %w", err)
+		}
+		values = append(values, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, v := range values {
+		value, err := a.NormaliseValue(v.value)
+		if err != nil {
+			return fmt.Errorf("product %s: %w", v.sku, err)
+		}
+		if value == v.value {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE product_attribute_values SET value = ? WHERE product_id = ? AND attribute_id = ?`,
+			value, v.productID, a.ID,
+		); err != nil {
+			return fmt.Errorf("update attribute value: %w", err)
+		}
+	}
+	return nil
+}
+
+// productAttributes returns one product's attribute values keyed by code.
+func productAttributes(ctx context.Context, q dbtx, productID int64) (map[string]string, error) {
+	products := []product.Product{{ID: productID}}
+	if err := attachAttributes(ctx, q, products); err != nil {
+		return nil, err
+	}
+	return products[0].Attributes, nil
+}
+
+// attachAttributes fills the Attributes map of each product.
+func attachAttributes(ctx context.Context, q dbtx, products []product.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	index := make(map[int64]int, len(products))
+	for i := range products {
+		products[i].Attributes = make(map[string]string)
+		index[products[i].ID] = i
+	}
+
+	query := `
+		SELECT v.product_id, a.code, v.value
+		FROM product_attribute_values v
+		INNER JOIN product_attributes a ON a.id = v.attribute_id`
+	var args []interface{}
+	if len(products) == 1 {
+		query += ` WHERE v.product_id = ?`
+		args = append(args, products[0].ID)
+	}
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("query attribute values: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			productID   int64
+			code, value string
+		)
+		if err := rows.Scan(&productID, &code, &value); err != nil {
+			return fmt.Errorf("scan attribute value: %w", err)
+		}
+		if i, ok := index[productID]; ok {
+			products[i].Attributes[code] = value
+		}
+	}
+	return rows.Err()
+}
+
+const attributeColumns = `id, code, name, type, options, sort_order, created_at`
+
+func listAttributes(ctx context.Context, q dbtx) ([]product.Attribute, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT `+attributeColumns+`
+		FROM product_attributes
+		ORDER BY sort_order ASC, name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("query attributes: %w", err)
+	}
+	defer rows.Close()
+
+	attributes := make([]product.Attribute, 0)
+	for rows.Next() {
+		a, err := scanAttribute(rows)
+		if err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, *a)
+	}
+	return attributes, rows.Err()
+}
+
+func getAttribute(ctx context.Context, q dbtx, id int64) (*product.Attribute, error) {
+	return scanAttribute(q.QueryRowContext(ctx, `
+		SELECT `+attributeColumns+`
+		FROM product_attributes WHERE id = ?`, id))
+}
+
+func scanAttribute(row rowScanner) (*product.Attribute, error) {
+	var (
+		a       product.Attribute
+		options sql.NullString
+		created int64
+	)
+	if err := row.Scan(&a.ID, &a.Code, &a.Name, &a.Type, &options, &a.SortOrder, &created); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("attribute not found: %w", err)
+		}
+		return nil, fmt.Errorf("scan attribute: %w", err)
+	}
+	a.CreatedAt = time.UnixMilli(created).UTC()
+	a.Options = make([]string, 0)
+	if options.Valid {
+		if err := json.Unmarshal([]byte(options.String), &a.Options); err != nil {
+			return nil, fmt.Errorf("decode attribute options: %w", err)
+		}
+	}
+	return &a, nil
+}
+
+func encodeAttributeOptions(options []string) (interface{}, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(options)
+	if err != nil {
+		return nil, fmt.Errorf("encode attribute options: %w", err)
+	}
+	return string(data), nil
+}
+
+func isAttributeConflict(err error) bool {
+	return stringsContainsIgnoreCase(err.Error(), "unique constraint failed: product_attributes.code")
+}
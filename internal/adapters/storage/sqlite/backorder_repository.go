@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/domain/sale"
+)
+
+// Backorders lists backorders of sales not voided, oldest first. Fulfilled and cancelled
+// ones are only included on request.
+func (r *SaleRepository) Backorders(ctx context.Context, includeFulfilled bool) ([]sale.Backorder, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT b.id, b.sale_id, s.sale_no, b.product_id, p.sku, p.name, COALESCE(b.location_id, 0), b.qty, b.created_at,
+			b.fulfilled_at, b.cancelled_at
+		FROM backorders b
+		INNER JOIN sales s ON s.id = b.sale_id
+		INNER JOIN products p ON p.id = b.product_id
+		WHERE s.status <> 'Voided' AND (? OR (b.fulfilled_at IS NULL AND b.cancelled_at IS NULL))
+		ORDER BY b.created_at, b.id`, includeFulfilled)
+	if err != nil {
+		return nil, fmt.Errorf("query backorders: %w", err)
+	}
+	defer rows.Close()
+
+	backorders := make([]sale.Backorder, 0)
+	for rows.Next() {
+		var (
+			b         sale.Backorder
+			created   int64
+			fulfilled sql.NullInt64
+			cancelled sql.NullInt64
+		)
+		if err := rows.Scan(&b.ID, &b.SaleID, &b.SaleNumber, &b.ProductID, &b.SKU, &b.ProductName, &b.LocationID,
+			&b.Qty, &created, &fulfilled, &cancelled); err != nil {
+			return nil, fmt.Errorf("scan backorder: %w", err)
+		}
+		b.CreatedAt = time.UnixMilli(created).UTC()
+		b.FulfilledAt = nullableTime(fulfilled)
+		b.CancelledAt = nullableTime(cancelled)
+		backorders = append(backorders, b)
+	}
+	return backorders, rows.Err()
+}
+
+// FulfilBackorder marks an open backorder as handed over to the customer.
+func (r *SaleRepository) FulfilBackorder(ctx context.Context, id int64, now time.Time) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE backorders SET fulfilled_at = ? WHERE id = ? AND fulfilled_at IS NULL AND cancelled_at IS NULL`, now.UnixMilli(), id)
+	if err != nil {
+		return fmt.Errorf("fulfil backorder: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errors.New("backorder not found or no longer open")
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/domain/cart"
+	"shopmate/internal/domain/product"
+)
+
+// CartRepository stores carts parked at the till and the stock reserved for them.
+type CartRepository struct {
+	db *sql.DB
+}
+
+// NewCartRepository constructs a repository.
+func NewCartRepository(db *sql.DB) *CartRepository {
+	return &CartRepository{db: db}
+}
+
+// Park stores a cart until expiresAt. Carts that reserve stock need it available at their
+// location now; the reservation then keeps it from other sales while the cart is held.
+func (r *CartRepository) Park(ctx context.Context, draft cart.Draft, parkedAt, expiresAt time.Time) (*cart.Held, error) {
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin park tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var locationID int64
+	if locationID, err = resolveLocationID(ctx, tx, draft.LocationID); err != nil {
+		return nil, err
+	}
+
+	var cartID int64
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO held_carts (customer_name, discount_cents, note, parked_by, location_id, reserve_stock, status, parked_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		nullIfEmpty(draft.CustomerName), draft.DiscountCents, nullIfEmpty(draft.Note), draft.ParkedBy,
+		locationID, draft.ReserveStock, cart.StatusHeld, parkedAt.UnixMilli(), expiresAt.UnixMilli(),
+	).Scan(&cartID); err != nil {
+		return nil, fmt.Errorf("insert held cart: %w", err)
+	}
+
+	reserve := make(map[int64]int64)
+	for _, line := range draft.Lines {
+		var serials interface{}
+		if len(line.SerialNumbers) > 0 {
+			var encoded []byte
+			if encoded, err = json.Marshal(line.SerialNumbers); err != nil {
+				return nil, fmt.Errorf("encode serial numbers: %w", err)
+			}
+			serials = string(encoded)
+		}
+		if _, err = tx.ExecContext(ctx, `
+			INSERT INTO held_cart_items (cart_id, product_id, qty, discount_cents, serial_numbers)
+			VALUES (?, ?, ?, ?, ?)`,
+			cartID, line.ProductID, line.Quantity, line.DiscountCents, serials,
+		); err != nil {
+			return nil, fmt.Errorf("insert held cart line: %w", err)
+		}
+
+		if !draft.ReserveStock {
+			continue
+		}
+		var kit bool
+		if kit, err = isKit(ctx, tx, line.ProductID); err != nil {
+			return nil, err
+		}
+		if !kit {
+			reserve[line.ProductID] += line.Quantity
+			continue
+		}
+		var components []product.KitComponent
+		if components, err = kitComponents(ctx, tx, line.ProductID); err != nil {
+			return nil, err
+		}
+		for _, c := range components {
+			reserve[c.ComponentID] += c.Quantity * line.Quantity
+		}
+	}
+
+	for productID, qty := range reserve {
+		if err = reserveStock(ctx, tx, cartID, productID, locationID, qty); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit held cart: %w", err)
+	}
+	return r.Get(ctx, cartID)
+}
+
+// reserveStock holds qty of a product at a location for a cart, provided it is available.
+func reserveStock(ctx context.Context, tx *sql.Tx, cartID, productID, locationID, qty int64) error {
+	available, err := availableQty(ctx, tx, productID, locationID)
+	if err != nil {
+		return err
+	}
+	if available < qty {
+		var sku, name string
+		if err := tx.QueryRowContext(ctx, `SELECT sku, name FROM products WHERE id = ?`, productID).Scan(&sku, &name); err != nil {
+			return fmt.Errorf("load product: %w", err)
+		}
+		return &product.StockShortageError{ProductID: productID, SKU: sku, Name: name, OnHand: available, Requested: qty}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO stock_reservations (cart_id, product_id, location_id, qty) VALUES (?, ?, ?, ?)`,
+		cartID, productID, locationID, qty,
+	); err != nil {
+		return fmt.Errorf("reserve stock: %w", err)
+	}
+	return nil
+}
+
+// Get retrieves a cart with its lines.
+func (r *CartRepository) Get(ctx context.Context, id int64) (*cart.Held, error) {
+	carts, err := r.query(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(carts) == 0 {
+		return nil, fmt.Errorf("held cart %d not found", id)
+	}
+	return &carts[0], nil
+}
+
+// Held lists carts still held, most recently parked first.
+func (r *CartRepository) Held(ctx context.Context) ([]cart.Held, error) {
+	return r.query(ctx, `WHERE status = ? ORDER BY parked_at DESC, id DESC`, cart.StatusHeld)
+}
+
+func (r *CartRepository) query(ctx context.Context, where string, args ...interface{}) ([]cart.Held, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, COALESCE(customer_name, ''), discount_cents, COALESCE(note, ''), parked_by, location_id,
+			reserve_stock, status, parked_at, expires_at, closed_at
+		FROM held_carts `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query held carts: %w", err)
+	}
+	defer rows.Close()
+
+	carts := make([]cart.Held, 0)
+	for rows.Next() {
+		var (
+			h                   cart.Held
+			parkedAt, expiresAt int64
+			closedAt            sql.NullInt64
+		)
+		if err := rows.Scan(&h.ID, &h.CustomerName, &h.DiscountCents, &h.Note, &h.ParkedBy, &h.LocationID,
+			&h.ReserveStock, &h.Status, &parkedAt, &expiresAt, &closedAt); err != nil {
+			return nil, fmt.Errorf("scan held cart: %w", err)
+		}
+		h.ParkedAt = time.UnixMilli(parkedAt).UTC()
+		h.ExpiresAt = time.UnixMilli(expiresAt).UTC()
+		if closedAt.Valid {
+			t := time.UnixMilli(closedAt.Int64).UTC()
+			h.ClosedAt = &t
+		}
+		carts = append(carts, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range carts {
+		if carts[i].Lines, err = r.lines(ctx, carts[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return carts, nil
+}
+
+func (r *CartRepository) lines(ctx context.Context, cartID int64) ([]cart.Line, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT i.product_id, COALESCE(p.name, ''), COALESCE(p.sku, ''), i.qty, i.discount_cents, COALESCE(i.serial_numbers, '')
+		FROM held_cart_items i
+		LEFT JOIN products p ON p.id = i.product_id
+		WHERE i.cart_id = ?
+		ORDER BY i.id`, cartID)
+	if err != nil {
+		return nil, fmt.Errorf("query held cart lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []cart.Line
+	for rows.Next() {
+		var (
+			line    cart.Line
+			serials string
+		)
+		if err := rows.Scan(&line.ProductID, &line.ProductName, &line.SKU, &line.Quantity, &line.DiscountCents, &serials); err != nil {
+			return nil, fmt.Errorf("scan held cart line: %w", err)
+		}
+		if serials != "" {
+			if err := json.Unmarshal([]byte(serials), &line.SerialNumbers); err != nil {
+				return nil, fmt.Errorf("decode serial numbers: %w", err)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// Resume takes a held cart back to the till, releasing its reservation. Only one window
+// can resume a cart; an expired cart is marked so and cannot be resumed.
+func (r *CartRepository) Resume(ctx context.Context, id int64, now time.Time) (*cart.Held, error) {
+	return r.close(ctx, id, cart.StatusResumed, now)
+}
+
+// Discard abandons a held cart, releasing its reservation.
+func (r *CartRepository) Discard(ctx context.Context, id int64, now time.Time) error {
+	_, err := r.close(ctx, id, cart.StatusDiscarded, now)
+	return err
+}
+
+func (r *CartRepository) close(ctx context.Context, id int64, status string, now time.Time) (*cart.Held, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin cart tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var (
+		current   string
+		expiresAt int64
+	)
+	if err = tx.QueryRowContext(ctx, `SELECT status, expires_at FROM held_carts WHERE id = ?`, id).Scan(&current, &expiresAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("held cart %d not found", id)
+			return nil, err
+		}
+		return nil, fmt.Errorf("load held cart: %w", err)
+	}
+	if current != cart.StatusHeld {
+		err = fmt.Errorf("cart %d is %s and no longer held", id, current)
+		return nil, err
+	}
+
+	expired := status == cart.StatusResumed && now.UnixMilli() >= expiresAt
+	if expired {
+		status = cart.StatusExpired
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE held_carts SET status = ?, closed_at = ? WHERE id = ?`, status, now.UnixMilli(), id); err != nil {
+		return nil, fmt.Errorf("close held cart: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM stock_reservations WHERE cart_id = ?`, id); err != nil {
+		return nil, fmt.Errorf("release reserved stock: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit held cart: %w", err)
+	}
+	if expired {
+		return nil, fmt.Errorf("cart %d expired and can no longer be resumed", id)
+	}
+	return r.Get(ctx, id)
+}
+
+// ExpireDue expires carts held past their expiry and releases their reservations. It
+// returns the number of carts expired.
+func (r *CartRepository) ExpireDue(ctx context.Context, now time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin expiry tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `
+		DELETE FROM stock_reservations
+		WHERE cart_id IN (SELECT id FROM held_carts WHERE status = ? AND expires_at <= ?)`,
+		cart.StatusHeld, now.UnixMilli(),
+	); err != nil {
+		return 0, fmt.Errorf("release expired reservations: %w", err)
+	}
+	var res sql.Result
+	if res, err = tx.ExecContext(ctx, `
+		UPDATE held_carts SET status = ?, closed_at = ? WHERE status = ? AND expires_at <= ?`,
+		cart.StatusExpired, now.UnixMilli(), cart.StatusHeld, now.UnixMilli(),
+	); err != nil {
+		return 0, fmt.Errorf("expire held carts: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit expiry: %w", err)
+	}
+	expired, _ := res.RowsAffected()
+	return int(expired), nil
+}
@@ -0,0 +1,446 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"shopmate/internal/domain/category"
+)
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// categoryTreeCTE resolves every category to its display path.
+const categoryTreeCTE = `
+	WITH RECURSIVE category_tree(id, path) AS (
+		SELECT id, name FROM categories WHERE parent_id IS NULL
+		UNION ALL
+		SELECT c.id, category_tree.path || ' > ' || c.name
+		FROM categories c
+		INNER JOIN category_tree ON c.parent_id = category_tree.id
+	)`
+
+// CategoryRepository persists the product category hierarchy.
+type CategoryRepository struct {
+	db *sql.DB
+}
+
+// NewCategoryRepository constructs a category repository.
+func NewCategoryRepository(db *sql.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// List returns all categories ordered by path with direct product counts.
+func (r *CategoryRepository) List(ctx context.Context) ([]category.Category, error) {
+	rows, err := r.db.QueryContext(ctx, categoryTreeCTE+`
+		SELECT c.id, c.name, COALESCE(c.parent_id, 0), t.path, c.default_tax_rate_bp, c.default_reorder_level,
+			(SELECT COUNT(*) FROM products p WHERE p.category_id = c.id)
+		FROM categories c
+		INNER JOIN category_tree t ON t.id = c.id
+		ORDER BY t.path COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("query categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]category.Category, 0)
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *c)
+	}
+	return categories, rows.Err()
+}
+
+// GetByID fetches a category with its path.
+func (r *CategoryRepository) GetByID(ctx context.Context, id int64) (*category.Category, error) {
+	return getCategory(ctx, r.db, id)
+}
+
+// Create adds a category under an optional parent.
+func (r *CategoryRepository) Create(ctx context.Context, input category.Input) (*category.Category, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if input.ParentID > 0 {
+		if _, err := getCategory(ctx, r.db, input.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO categories (name, parent_id, default_tax_rate_bp, default_reorder_level)
+		VALUES (?, ?, ?, ?)`,
+		normaliseCategoryName(input.Name),
+		nullIfZero(input.ParentID),
+		nullableInt(input.DefaultTaxRateBasisPoints),
+		nullableInt(input.DefaultReorderLevel),
+	)
+	if err != nil {
+		if isCategoryConflict(err) {
+			return nil, errors.New("a category with that name already exists under the parent")
+		}
+		return nil, fmt.Errorf("insert category: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("category last insert id: %w", err)
+	}
+	return getCategory(ctx, r.db, id)
+}
+
+// Update renames, moves or changes defaults of a category, cascading the new path to products.
+func (r *CategoryRepository) Update(ctx context.Context, id int64, input category.Input) (*category.Category, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin category tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if input.ParentID > 0 {
+		var subtree map[int64]bool
+		if subtree, err = categorySubtree(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		if subtree[input.ParentID] {
+			err = errors.New("a category cannot be moved beneath itself")
+			return nil, err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE categories
+		SET name = ?, parent_id = ?, default_tax_rate_bp = ?, default_reorder_level = ?
+		WHERE id = ?`,
+		normaliseCategoryName(input.Name),
+		nullIfZero(input.ParentID),
+		nullableInt(input.DefaultTaxRateBasisPoints),
+		nullableInt(input.DefaultReorderLevel),
+		id,
+	); err != nil {
+		if isCategoryConflict(err) {
+			err = errors.New("a category with that name already exists under the parent")
+		} else {
+			err = fmt.Errorf("update category: %w", err)
+		}
+		return nil, err
+	}
+
+	if err = refreshProductCategoryPaths(ctx, tx); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit category: %w", err)
+	}
+	return getCategory(ctx, r.db, id)
+}
+
+// Merge moves products and child categories from source into target and removes source.
+// Children whose names collide with an existing child of target are merged recursively.
+func (r *CategoryRepository) Merge(ctx context.Context, sourceID, targetID int64) (*category.Category, error) {
+	if sourceID == targetID {
+		return nil, errors.New("cannot merge a category into itself")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin merge tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var subtree map[int64]bool
+	if subtree, err = categorySubtree(ctx, tx, sourceID); err != nil {
+		return nil, err
+	}
+	if subtree[targetID] {
+		err = errors.New("cannot merge a category into its own descendant")
+		return nil, err
+	}
+	if _, err = getCategory(ctx, tx, targetID); err != nil {
+		return nil, err
+	}
+
+	if err = mergeCategory(ctx, tx, sourceID, targetID); err != nil {
+		return nil, err
+	}
+	if err = refreshProductCategoryPaths(ctx, tx); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit merge: %w", err)
+	}
+	return getCategory(ctx, r.db, targetID)
+}
+
+// Delete removes an empty category.
+func (r *CategoryRepository) Delete(ctx context.Context, id int64) error {
+	var products, children int64
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM products WHERE category_id = ?),
+			(SELECT COUNT(*) FROM categories WHERE parent_id = ?)`,
+		id, id,
+	).Scan(&products, &children); err != nil {
+		return fmt.Errorf("count category usage: %w", err)
+	}
+	if products > 0 || children > 0 {
+		return errors.New("category still has products or subcategories; merge it instead")
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete category: %w", err)
+	}
+	return nil
+}
+
+// SubtreeIDs returns the category and all of its descendants.
+func (r *CategoryRepository) SubtreeIDs(ctx context.Context, id int64) ([]int64, error) {
+	subtree, err := categorySubtree(ctx, r.db, id)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(subtree))
+	for id := range subtree {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// categoryDefaults returns the nearest tax rate and reorder level set on the category or its ancestors.
+func categoryDefaults(ctx context.Context, q dbtx, id int64) (taxRate, reorder *int64, err error) {
+	for id > 0 && (taxRate == nil || reorder == nil) {
+		var (
+			parent          sql.NullInt64
+			tax, reorderLvl sql.NullInt64
+		)
+		if err := q.QueryRowContext(ctx, `
+			SELECT parent_id, default_tax_rate_bp, default_reorder_level FROM categories WHERE id = ?`, id,
+		).Scan(&parent, &tax, &reorderLvl); err != nil {
+			return nil, nil, fmt.Errorf("load category defaults: %w", err)
+		}
+		if taxRate == nil && tax.Valid {
+			v := tax.Int64
+			taxRate = &v
+		}
+		if reorder == nil && reorderLvl.Valid {
+			v := reorderLvl.Int64
+			reorder = &v
+		}
+		id = parent.Int64
+	}
+	return taxRate, reorder, nil
+}
+
+// categoryAncestry returns id followed by its ancestors, nearest first.
+func categoryAncestry(ctx context.Context, q dbtx, id int64) ([]int64, error) {
+	var ids []int64
+	for id > 0 {
+		ids = append(ids, id)
+		var parent sql.NullInt64
+		if err := q.QueryRowContext(ctx, `SELECT parent_id FROM categories WHERE id = ?`, id).Scan(&parent); err != nil {
+			return nil, fmt.Errorf("load category parent: %w", err)
+		}
+		id = parent.Int64
+	}
+	return ids, nil
+}
+
+// resolveCategoryPath finds or creates each level of a "Parent > Child" path and returns the leaf
+// id with its canonical path. Blank paths resolve to no category.
+func resolveCategoryPath(ctx context.Context, q dbtx, path string) (int64, string, error) {
+	names := category.SplitPath(path)
+	if len(names) == 0 {
+		return 0, "", nil
+	}
+
+	var (
+		parentID  int64
+		canonical = make([]string, 0, len(names))
+	)
+	for _, name := range names {
+		var (
+			id       int64
+			existing string
+		)
+		err := q.QueryRowContext(ctx, `
+			SELECT id, name FROM categories
+			WHERE COALESCE(parent_id, 0) = ? AND name = ? COLLATE NOCASE`,
+			parentID, name,
+		).Scan(&id, &existing)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			res, insertErr := q.ExecContext(ctx, `INSERT INTO categories (name, parent_id) VALUES (?, ?)`, name, nullIfZero(parentID))
+			if insertErr != nil {
+				return 0, "", fmt.Errorf("create category %q: %w", name, insertErr)
+			}
+			if id, err = res.LastInsertId(); err != nil {
+				return 0, "", fmt.Errorf("category last insert id: %w", err)
+			}
+			existing = name
+		case err != nil:
+			return 0, "", fmt.Errorf("resolve category %q: %w", name, err)
+		}
+		canonical = append(canonical, existing)
+		parentID = id
+	}
+	return parentID, category.JoinPath(canonical), nil
+}
+
+func mergeCategory(ctx context.Context, tx *sql.Tx, sourceID, targetID int64) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE products SET category_id = ? WHERE category_id = ?`, targetID, sourceID); err != nil {
+		return fmt.Errorf("move products: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT src.id, COALESCE(dst.id, 0)
+		FROM categories src
+		LEFT JOIN categories dst ON dst.parent_id = ? AND dst.name = src.name COLLATE NOCASE
+		WHERE src.parent_id = ?`, targetID, sourceID)
+	if err != nil {
+		return fmt.Errorf("load child categories: %w", err)
+	}
+	type child struct{ id, collision int64 }
+	var children []child
+	for rows.Next() {
+		var c child
+		if err := rows.Scan(&c.id, &c.collision); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan child category: %w", err)
+		}
+		children = append(children, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, c := range children {
+		if c.collision > 0 {
+			if err := mergeCategory(ctx, tx, c.id, c.collision); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE categories SET parent_id = ? WHERE id = ?`, targetID, c.id); err != nil {
+			return fmt.Errorf("reparent category: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = ?`, sourceID); err != nil {
+		return fmt.Errorf("delete merged category: %w", err)
+	}
+	return nil
+}
+
+// refreshProductCategoryPaths rewrites products.category for rows whose path changed.
+func refreshProductCategoryPaths(ctx context.Context, q dbtx) error {
+	if _, err := q.ExecContext(ctx, categoryTreeCTE+`
+		UPDATE products
+		SET category = (SELECT path FROM category_tree WHERE category_tree.id = products.category_id)
+		WHERE category_id IS NOT NULL
+			AND category IS NOT (SELECT path FROM category_tree WHERE category_tree.id = products.category_id)`,
+	); err != nil {
+		return fmt.Errorf("refresh product categories: %w", err)
+	}
+	return nil
+}
+
+func categorySubtree(ctx context.Context, q dbtx, id int64) (map[int64]bool, error) {
+	rows, err := q.QueryContext(ctx, `
+		WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM categories WHERE id = ?
+			UNION ALL
+			SELECT c.id FROM categories c INNER JOIN subtree ON c.parent_id = subtree.id
+		)
+		SELECT id FROM subtree`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query category subtree: %w", err)
+	}
+	defer rows.Close()
+
+	subtree := make(map[int64]bool)
+	for rows.Next() {
+		var child int64
+		if err := rows.Scan(&child); err != nil {
+			return nil, fmt.Errorf("scan category subtree: %w", err)
+		}
+		subtree[child] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(subtree) == 0 {
+		return nil, fmt.Errorf("category %d not found", id)
+	}
+	return subtree, nil
+}
+
+func getCategory(ctx context.Context, q dbtx, id int64) (*category.Category, error) {
+	return scanCategory(q.QueryRowContext(ctx, categoryTreeCTE+`
+		SELECT c.id, c.name, COALESCE(c.parent_id, 0), t.path, c.default_tax_rate_bp, c.default_reorder_level,
+			(SELECT COUNT(*) FROM products p WHERE p.category_id = c.id)
+		FROM categories c
+		INNER JOIN category_tree t ON t.id = c.id
+		WHERE c.id = ?`, id))
+}
+
+func scanCategory(row rowScanner) (*category.Category, error) {
+	var (
+		c               category.Category
+		tax, reorderLvl sql.NullInt64
+	)
+	if err := row.Scan(&c.ID, &c.Name, &c.ParentID, &c.Path, &tax, &reorderLvl, &c.ProductCount); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("category not found: %w", err)
+		}
+		return nil, fmt.Errorf("scan category: %w", err)
+	}
+	if tax.Valid {
+		c.DefaultTaxRateBasisPoints = &tax.Int64
+	}
+	if reorderLvl.Valid {
+		c.DefaultReorderLevel = &reorderLvl.Int64
+	}
+	return &c, nil
+}
+
+func normaliseCategoryName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+func isCategoryConflict(err error) bool {
+	return stringsContainsIgnoreCase(err.Error(), "unique constraint failed: index 'idx_categories_parent_name'")
+}
+
+func nullIfZero(value int64) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
+
+func nullableInt(value *int64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
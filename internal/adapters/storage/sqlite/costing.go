@@ -0,0 +1,163 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"shopmate/internal/domain/product"
+	"shopmate/internal/domain/settings"
+)
+
+// CostLayers lists the stock a product still holds from each receipt, oldest first.
+func (r *ProductRepository) CostLayers(ctx context.Context, productID int64) ([]product.CostLayer, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, received_at, qty, remaining_qty, unit_cost_cents
+		FROM cost_layers
+		WHERE product_id = ? AND remaining_qty > 0
+		ORDER BY received_at, id`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("query cost layers: %w", err)
+	}
+	defer rows.Close()
+
+	layers := make([]product.CostLayer, 0)
+	for rows.Next() {
+		var (
+			l        product.CostLayer
+			received int64
+		)
+		if err := rows.Scan(&l.ID, &received, &l.Qty, &l.RemainingQty, &l.UnitCostCents); err != nil {
+			return nil, fmt.Errorf("scan cost layer: %w", err)
+		}
+		l.ReceivedAt = time.UnixMilli(received).UTC()
+		layers = append(layers, l)
+	}
+	return layers, rows.Err()
+}
+
+// valueMovement prices a stock movement, returning its signed value. Inbound stock is valued
+// at its total value when given, else at its unit cost or the product's. Outbound stock always consumes cost layers oldest
+// first; it is valued at the consumed layers' cost under FIFO and at the running average
+// cost under weighted average. Stock beyond the recorded layers is valued at the product's
+// cost.
+func valueMovement(ctx context.Context, tx *sql.Tx, m stockMovement) (int64, error) {
+	if m.delta > 0 && m.valueCents > 0 {
+		return m.valueCents, nil
+	}
+	var productCost int64
+	if err := tx.QueryRowContext(ctx, `SELECT cost_cents FROM products WHERE id = ?`, m.productID).Scan(&productCost); err != nil {
+		return 0, fmt.Errorf("load product cost: %w", err)
+	}
+
+	if m.delta > 0 {
+		unitCost := m.unitCostCents
+		if unitCost <= 0 {
+			unitCost = productCost
+		}
+		return m.delta * unitCost, nil
+	}
+
+	costing, err := loadCosting(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	qty := -m.delta
+
+	var onHandQty, onHandValue int64
+	if costing.Method == settings.CostingWeightedAverage {
+		if err := tx.QueryRowContext(ctx, `
+			SELECT
+				(SELECT COALESCE(SUM(remaining_qty), 0) FROM cost_layers WHERE product_id = ?),
+				(SELECT COALESCE(SUM(value_cents), 0) FROM stock_movements WHERE product_id = ?)`,
+			m.productID, m.productID,
+		).Scan(&onHandQty, &onHandValue); err != nil {
+			return 0, fmt.Errorf("load stock value: %w", err)
+		}
+	}
+
+	layerCost, covered, err := consumeCostLayers(ctx, tx, m.productID, qty)
+	if err != nil {
+		return 0, err
+	}
+
+	cost := layerCost + (qty-covered)*productCost
+	if costing.Method == settings.CostingWeightedAverage {
+		cost = averageCost(onHandQty, onHandValue, qty, productCost)
+	}
+	return -cost, nil
+}
+
+// averageCost values qty units at the average cost of stock on hand, falling back to the
+// product cost for units beyond it.
+func averageCost(onHandQty, onHandValue, qty, productCost int64) int64 {
+	if onHandQty <= 0 {
+		return qty * productCost
+	}
+	taken := min(qty, onHandQty)
+	return max(onHandValue, 0)*taken/onHandQty + (qty-taken)*productCost
+}
+
+// consumeCostLayers draws qty units from a product's open layers, oldest first, returning
+// their cost and how many units the layers covered.
+func consumeCostLayers(ctx context.Context, tx *sql.Tx, productID, qty int64) (int64, int64, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, remaining_qty, unit_cost_cents
+		FROM cost_layers
+		WHERE product_id = ? AND remaining_qty > 0
+		ORDER BY received_at, id`, productID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("query cost layers: %w", err)
+	}
+	type layer struct {
+		id, remaining, unitCost int64
+	}
+	var layers []layer
+	for rows.Next() {
+		var l layer
+		if err := rows.Scan(&l.id, &l.remaining, &l.unitCost); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("scan cost layer: %w", err)
+		}
+		layers = append(layers, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	var cost, covered int64
+	for _, l := range layers {
+		if covered == qty {
+			break
+		}
+		taken := min(l.remaining, qty-covered)
+		if _, err := tx.ExecContext(ctx, `UPDATE cost_layers SET remaining_qty = remaining_qty - ? WHERE id = ?`, taken, l.id); err != nil {
+			return 0, 0, fmt.Errorf("consume cost layer: %w", err)
+		}
+		cost += taken * l.unitCost
+		covered += taken
+	}
+	return cost, covered, nil
+}
+
+// addCostLayers opens the layers for qty units received at a total of value. A value that
+// does not split into whole cents per unit puts the odd cents one each on some units, so the
+// layers hold exactly the value received.
+func addCostLayers(ctx context.Context, tx *sql.Tx, productID, movementID, tsMillis, qty, value int64) error {
+	unitCost, dearer := value/qty, value%qty
+	for _, layer := range []struct{ qty, unitCost int64 }{{qty - dearer, unitCost}, {dearer, unitCost + 1}} {
+		if layer.qty == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO cost_layers (product_id, movement_id, received_at, qty, remaining_qty, unit_cost_cents)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			productID, movementID, tsMillis, layer.qty, layer.qty, layer.unitCost,
+		); err != nil {
+			return fmt.Errorf("insert cost layer: %w", err)
+		}
+	}
+	return nil
+}
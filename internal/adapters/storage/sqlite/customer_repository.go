@@ -0,0 +1,260 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/customer"
+)
+
+// CustomerRepository stores customer records and reads their purchase history.
+type CustomerRepository struct {
+	db *sql.DB
+}
+
+// NewCustomerRepository constructs a repository.
+func NewCustomerRepository(db *sql.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+const customerColumns = `id, name, COALESCE(phone, ''), COALESCE(email, ''), COALESCE(address, ''), COALESCE(tax_id, ''),
+	COALESCE(notes, ''), tax_exempt, COALESCE(tax_exemption_number, ''), created_at, updated_at`
+
+// Create saves a new customer.
+func (r *CustomerRepository) Create(ctx context.Context, input customer.Input, now time.Time) (*customer.Customer, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	id, err := insertCustomer(ctx, r.db, input, now)
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
+
+func insertCustomer(ctx context.Context, q dbtx, input customer.Input, now time.Time) (int64, error) {
+	var id int64
+	if err := q.QueryRowContext(ctx, `
+		INSERT INTO customers (name, phone, email, address, tax_id, notes, tax_exempt, tax_exemption_number, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		input.Name, nullIfEmpty(input.Phone), nullIfEmpty(input.Email), nullIfEmpty(input.Address),
+		nullIfEmpty(input.TaxID), nullIfEmpty(input.Notes), input.TaxExempt, nullIfEmpty(input.TaxExemptionNumber),
+		now.UnixMilli(), now.UnixMilli(),
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("insert customer: %w", err)
+	}
+	return id, nil
+}
+
+// Update replaces a customer's details. Sales already recorded keep the name they were
+// sold under.
+func (r *CustomerRepository) Update(ctx context.Context, id int64, input customer.Input, now time.Time) (*customer.Customer, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if err := updateCustomer(ctx, r.db, id, input, now); err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id)
+}
+
+func updateCustomer(ctx context.Context, q dbtx, id int64, input customer.Input, now time.Time) error {
+	res, err := q.ExecContext(ctx, `
+		UPDATE customers SET name = ?, phone = ?, email = ?, address = ?, tax_id = ?, notes = ?,
+			tax_exempt = ?, tax_exemption_number = ?, updated_at = ?
+		WHERE id = ?`,
+		input.Name, nullIfEmpty(input.Phone), nullIfEmpty(input.Email), nullIfEmpty(input.Address),
+		nullIfEmpty(input.TaxID), nullIfEmpty(input.Notes), input.TaxExempt, nullIfEmpty(input.TaxExemptionNumber),
+		now.UnixMilli(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("update customer: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("customer %d not found", id)
+	}
+	return nil
+}
+
+// Get retrieves a customer by id.
+func (r *CustomerRepository) Get(ctx context.Context, id int64) (*customer.Customer, error) {
+	customers, err := r.query(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(customers) == 0 {
+		return nil, fmt.Errorf("customer %d not found", id)
+	}
+	return &customers[0], nil
+}
+
+// Search finds customers whose name, phone or email contains query, by name. An empty
+// query lists every customer.
+func (r *CustomerRepository) Search(ctx context.Context, query string, limit int) ([]customer.Customer, error) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	if q == "" {
+		return r.query(ctx, `ORDER BY name COLLATE NOCASE, id LIMIT ?`, limit)
+	}
+	like := "%" + q + "%"
+	return r.query(ctx, `
+		WHERE LOWER(name) LIKE ? OR COALESCE(phone, '') LIKE ? OR COALESCE(email, '') LIKE ?
+		ORDER BY name COLLATE NOCASE, id LIMIT ?`, like, like, like, limit)
+}
+
+// All lists every customer by name.
+func (r *CustomerRepository) All(ctx context.Context) ([]customer.Customer, error) {
+	return r.query(ctx, `ORDER BY name COLLATE NOCASE, id`)
+}
+
+func (r *CustomerRepository) query(ctx context.Context, where string, args ...interface{}) ([]customer.Customer, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+customerColumns+` FROM customers `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query customers: %w", err)
+	}
+	defer rows.Close()
+
+	customers := make([]customer.Customer, 0)
+	for rows.Next() {
+		var (
+			c                    customer.Customer
+			createdAt, updatedAt int64
+		)
+		if err := rows.Scan(&c.ID, &c.Name, &c.Phone, &c.Email, &c.Address, &c.TaxID, &c.Notes, &c.TaxExempt, &c.TaxExemptionNumber,
+			&createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan customer: %w", err)
+		}
+		c.CreatedAt = time.UnixMilli(createdAt).UTC()
+		c.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+		customers = append(customers, c)
+	}
+	return customers, rows.Err()
+}
+
+// Detail returns a customer with their lifetime spend and up to limit of their most
+// recent purchases.
+func (r *CustomerRepository) Detail(ctx context.Context, id int64, limit int) (*customer.Detail, error) {
+	c, err := r.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	detail := customer.Detail{Customer: *c, Purchases: make([]customer.Purchase, 0)}
+
+	var lastPurchase sql.NullInt64
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COALESCE(SUM(s.total_cents - COALESCE((SELECT SUM(rf.total_cents) FROM refunds rf WHERE rf.sale_id = s.id), 0)), 0),
+			MAX(s.ts)
+		FROM sales s
+		WHERE s.customer_id = ? AND s.status != 'Voided'`, id,
+	).Scan(&detail.SaleCount, &detail.LifetimeSpendCents, &lastPurchase); err != nil {
+		return nil, fmt.Errorf("summarise customer sales: %w", err)
+	}
+	if lastPurchase.Valid {
+		t := time.UnixMilli(lastPurchase.Int64).UTC()
+		detail.LastPurchaseAt = &t
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.id, s.sale_no, s.ts, s.status, s.total_cents,
+			COALESCE((SELECT SUM(rf.total_cents) FROM refunds rf WHERE rf.sale_id = s.id), 0)
+		FROM sales s
+		WHERE s.customer_id = ?
+		ORDER BY s.ts DESC, s.id DESC
+		LIMIT ?`, id, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query customer purchases: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			p        customer.Purchase
+			tsMillis int64
+		)
+		if err := rows.Scan(&p.SaleID, &p.SaleNumber, &tsMillis, &p.Status, &p.TotalCents, &p.RefundedCents); err != nil {
+			return nil, fmt.Errorf("scan customer purchase: %w", err)
+		}
+		p.Timestamp = time.UnixMilli(tsMillis).UTC()
+		detail.Purchases = append(detail.Purchases, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &detail, nil
+}
+
+// Import creates or updates customers in one transaction. A row updates the customer with
+// the same email, or failing that the same phone number; otherwise it creates one.
+func (r *CustomerRepository) Import(ctx context.Context, inputs []customer.Input, now time.Time) (created, updated int, err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("begin customer import tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, input := range inputs {
+		input.Normalize()
+		if err = input.Validate(); err != nil {
+			return 0, 0, err
+		}
+		var existing int64
+		if existing, err = matchCustomer(ctx, tx, input); err != nil {
+			return 0, 0, err
+		}
+		if existing == 0 {
+			if _, err = insertCustomer(ctx, tx, input, now); err != nil {
+				return 0, 0, err
+			}
+			created++
+			continue
+		}
+		if err = updateCustomer(ctx, tx, existing, input, now); err != nil {
+			return 0, 0, err
+		}
+		updated++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("commit customer import: %w", err)
+	}
+	return created, updated, nil
+}
+
+func matchCustomer(ctx context.Context, tx *sql.Tx, input customer.Input) (int64, error) {
+	for _, match := range []struct{ column, value string }{{"email", input.Email}, {"phone", input.Phone}} {
+		if match.value == "" {
+			continue
+		}
+		var id int64
+		err := tx.QueryRowContext(ctx, `SELECT id FROM customers WHERE `+match.column+` = ? ORDER BY id LIMIT 1`, match.value).Scan(&id)
+		if err == nil {
+			return id, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("match customer: %w", err)
+		}
+	}
+	return 0, nil
+}
+
+// customerName returns the name of a customer, for documents that record it.
+func customerName(ctx context.Context, q queryRower, id int64) (string, error) {
+	var name string
+	if err := q.QueryRowContext(ctx, `SELECT name FROM customers WHERE id = ?`, id).Scan(&name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("customer %d not found", id)
+		}
+		return "", fmt.Errorf("load customer: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,106 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/domain/sale"
+)
+
+// CreateExchange records the return of the draft's lines and the sale of their
+// replacements in one transaction, so neither side is kept without the other. The return
+// is credited before the replacements are sold, letting returned stock be sold again.
+func (r *SaleRepository) CreateExchange(ctx context.Context, draft sale.ExchangeDraft) (*sale.Exchange, error) {
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+
+	ts := draft.Sale.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	draft.Sale.Timestamp = ts
+	draft.Return.Timestamp = ts
+	draft.Return.PaymentMethod = draft.Sale.PaymentMethod
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin exchange tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var refund *sale.Refund
+	if refund, err = insertRefund(ctx, tx, draft.Return); err != nil {
+		return nil, err
+	}
+	if err = insertSale(ctx, tx, &draft.Sale); err != nil {
+		return nil, err
+	}
+
+	exchange := sale.Exchange{
+		Timestamp:     time.UnixMilli(ts.UnixMilli()).UTC(),
+		PaymentMethod: draft.Sale.PaymentMethod,
+		Return:        *refund,
+		Sale:          draft.Sale,
+	}
+	exchange.NetCents = exchange.NetTotal()
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO exchanges (ts, refund_id, sale_id, payment_method, net_cents)
+		VALUES (?, ?, ?, ?, ?)
+		RETURNING id`,
+		ts.UnixMilli(), refund.ID, draft.Sale.ID, exchange.PaymentMethod, exchange.NetCents,
+	).Scan(&exchange.ID); err != nil {
+		return nil, fmt.Errorf("insert exchange: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit exchange: %w", err)
+	}
+	return &exchange, nil
+}
+
+// GetExchange retrieves an exchange with its credit note and replacement sale.
+func (r *SaleRepository) GetExchange(ctx context.Context, id int64) (*sale.Exchange, error) {
+	var (
+		exchange       sale.Exchange
+		tsMillis       int64
+		refundID       int64
+		originalSaleID int64
+	)
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT e.id, e.ts, e.payment_method, e.net_cents, e.refund_id, rf.sale_id, e.sale_id
+		FROM exchanges e
+		INNER JOIN refunds rf ON rf.id = e.refund_id
+		WHERE e.id = ?`, id,
+	).Scan(&exchange.ID, &tsMillis, &exchange.PaymentMethod, &exchange.NetCents, &refundID, &originalSaleID, &exchange.Sale.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("exchange %d not found", id)
+		}
+		return nil, fmt.Errorf("load exchange: %w", err)
+	}
+	exchange.Timestamp = time.UnixMilli(tsMillis).UTC()
+
+	refunds, err := r.Refunds(ctx, originalSaleID)
+	if err != nil {
+		return nil, err
+	}
+	for _, rf := range refunds {
+		if rf.ID == refundID {
+			exchange.Return = rf
+		}
+	}
+
+	replacement, err := r.GetByID(ctx, exchange.Sale.ID)
+	if err != nil {
+		return nil, err
+	}
+	exchange.Sale = *replacement
+	return &exchange, nil
+}
@@ -0,0 +1,440 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/domain/product"
+)
+
+// ImportProducts applies a batch of import rows as one import job, creating or updating
+// products by SKU. Each row is applied under its own savepoint so a failing row leaves no
+// trace; in all-or-nothing mode any failure rolls back the whole job and returns
+// product.ErrImportAborted alongside the row errors. Stock differences are recorded as
+// stock movements referencing the job number, and the before-image of every touched
+// product is kept so the job can be rolled back.
+func (r *ProductRepository) ImportProducts(ctx context.Context, batch product.ImportBatch) (*product.ImportJob, []product.ImportRowError, error) {
+	mode, err := product.NormaliseImportMode(batch.Mode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin import tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	tsMillis := time.Now().UnixMilli()
+	var jobID int64
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO import_jobs (job_no, file_name, mode, created_at)
+		VALUES ('', ?, ?, ?)
+		RETURNING id`,
+		nullIfEmpty(batch.FileName), mode, tsMillis,
+	).Scan(&jobID); err != nil {
+		return nil, nil, fmt.Errorf("insert import job: %w", err)
+	}
+	jobNo := fmt.Sprintf("IMP-%06d", jobID)
+
+	var (
+		created, updated int
+		rowErrs          []product.ImportRowError
+	)
+	for _, row := range batch.Rows {
+		if _, err = tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+			return nil, nil, fmt.Errorf("begin import row: %w", err)
+		}
+
+		action, rowErr := importRow(ctx, tx, jobID, jobNo, tsMillis, row)
+		if rowErr != nil {
+			rowErrs = append(rowErrs, product.ImportRowError{Line: row.OriginalLine, SKU: row.SKU, Error: rowErr.Error()})
+			if _, err = tx.ExecContext(ctx, `ROLLBACK TO import_row`); err != nil {
+				return nil, nil, fmt.Errorf("undo import row: %w", err)
+			}
+		} else if action == product.ImportActionCreate {
+			created++
+		} else {
+			updated++
+		}
+		if _, err = tx.ExecContext(ctx, `RELEASE import_row`); err != nil {
+			return nil, nil, fmt.Errorf("release import row: %w", err)
+		}
+	}
+
+	if (mode == product.ImportModeAllOrNothing && len(rowErrs) > 0) || created+updated == 0 {
+		err = product.ErrImportAborted
+		return nil, rowErrs, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE import_jobs SET job_no = ?, created_count = ?, updated_count = ?, failed_count = ?
+		WHERE id = ?`,
+		jobNo, created, updated, len(rowErrs), jobID,
+	); err != nil {
+		return nil, nil, fmt.Errorf("update import job: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("commit import: %w", err)
+	}
+
+	job, err := r.ImportJob(ctx, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, rowErrs, nil
+}
+
+// ImportJobs lists recent imports, newest first, without their items.
+func (r *ProductRepository) ImportJobs(ctx context.Context, limit int) ([]product.ImportJob, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+importJobColumns+`
+		FROM import_jobs
+		ORDER BY id DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query import jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := make([]product.ImportJob, 0)
+	for rows.Next() {
+		job, err := scanImportJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+// ImportJob loads an import with the products it touched.
+func (r *ProductRepository) ImportJob(ctx context.Context, id int64) (*product.ImportJob, error) {
+	job, err := scanImportJob(r.db.QueryRowContext(ctx, `
+		SELECT `+importJobColumns+`
+		FROM import_jobs WHERE id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+	if job.Items, err = importJobItems(ctx, r.db, id); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// RollbackImport undoes an import. Updated products get their pre-import details back,
+// created products are archived, and the stock each import row moved is reversed with a
+// compensating movement so sales made since the import are preserved. An import cannot be
+// rolled back while a later import that touched the same products is still in place.
+func (r *ProductRepository) RollbackImport(ctx context.Context, id int64) (*product.ImportJob, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin rollback tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var (
+		jobNo      string
+		rolledBack sql.NullInt64
+	)
+	if err = tx.QueryRowContext(ctx, `SELECT job_no, rolled_back_at FROM import_jobs WHERE id = ?`, id).
+		Scan(&jobNo, &rolledBack); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("import not found: %w", err)
+		}
+		return nil, fmt.Errorf("load import job: %w", err)
+	}
+	if rolledBack.Valid {
+		err = product.ErrImportRolledBack
+		return nil, err
+	}
+
+	var laterJob string
+	err = tx.QueryRowContext(ctx, `
+		SELECT j.job_no
+		FROM import_job_items i
+		INNER JOIN import_jobs j ON j.id = i.job_id
+		WHERE j.id > ? AND j.rolled_back_at IS NULL
+		  AND i.product_id IN (SELECT product_id FROM import_job_items WHERE job_id = ?)
+		ORDER BY j.id DESC
+		LIMIT 1`, id, id).Scan(&laterJob)
+	switch {
+	case err == nil:
+		err = fmt.Errorf("import %s changed the same products later; roll it back first", laterJob)
+		return nil, err
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("check later imports: %w", err)
+	}
+
+	var items []product.ImportJobItem
+	if items, err = importJobItems(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	nowMillis := time.Now().UnixMilli()
+	var locationID int64
+	if locationID, err = defaultLocationID(ctx, tx); err != nil {
+		return nil, err
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if item.QtyDelta != 0 {
+			if err = adjustLocationStock(ctx, tx, item.ProductID, locationID, -item.QtyDelta, true); err != nil {
+				return nil, err
+			}
+			if _, err = insertStockMovement(ctx, tx, stockMovement{
+				productID:  item.ProductID,
+				locationID: locationID,
+				tsMillis:   nowMillis,
+				delta:      -item.QtyDelta,
+				reason:     "Import rollback",
+				ref:        jobNo,
+			}); err != nil {
+				return nil, err
+			}
+		}
+
+		if item.Before == nil {
+			if _, err = tx.ExecContext(ctx, `
+				UPDATE products SET archived_at = ? WHERE id = ? AND archived_at IS NULL`,
+				nowMillis, item.ProductID,
+			); err != nil {
+				return nil, fmt.Errorf("archive imported product: %w", err)
+			}
+			continue
+		}
+		if err = restoreProduct(ctx, tx, *item.Before); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE import_jobs SET rolled_back_at = ? WHERE id = ?`, nowMillis, id); err != nil {
+		return nil, fmt.Errorf("mark import rolled back: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit rollback: %w", err)
+	}
+	return r.ImportJob(ctx, id)
+}
+
+// importRow creates or updates the product for one import row and records it against the job.
+func importRow(ctx context.Context, tx *sql.Tx, jobID int64, jobNo string, tsMillis int64, row product.ImportRow) (string, error) {
+	input := row.ToCreateInput()
+	if err := input.Validate(); err != nil {
+		return "", err
+	}
+
+	existing, err := scanProduct(tx.QueryRowContext(ctx, `
+		SELECT `+productColumns+`
+		FROM products WHERE sku = ?`, input.SKU))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("look up sku: %w", err)
+	}
+
+	var (
+		action    string
+		productID int64
+		delta     int64
+		before    interface{}
+	)
+	if existing == nil {
+		action = product.ImportActionCreate
+		if productID, err = insertProduct(ctx, tx, input); err != nil {
+			return "", err
+		}
+		delta = input.CurrentQty
+	} else {
+		action = product.ImportActionUpdate
+		productID = existing.ID
+		if existing.Attributes, err = productAttributes(ctx, tx, existing.ID); err != nil {
+			return "", err
+		}
+		image, err := json.Marshal(existing)
+		if err != nil {
+			return "", fmt.Errorf("encode before-image: %w", err)
+		}
+		before = string(image)
+
+		if err := updateImportedProduct(ctx, tx, *existing, input); err != nil {
+			return "", err
+		}
+		// The imported quantity is a total; the difference lands on the default location.
+		// Serialised stock only changes through serial-numbered receipts, and kits hold none.
+		if !existing.Serialised && !existing.IsKit {
+			delta = input.CurrentQty - existing.CurrentQty
+		}
+	}
+
+	if delta != 0 {
+		locationID, err := defaultLocationID(ctx, tx)
+		if err != nil {
+			return "", err
+		}
+		if err := adjustLocationStock(ctx, tx, productID, locationID, delta, true); err != nil {
+			return "", err
+		}
+		if _, err := insertStockMovement(ctx, tx, stockMovement{
+			productID:  productID,
+			locationID: locationID,
+			tsMillis:   tsMillis,
+			delta:      delta,
+			reason:     "Import",
+			ref:        jobNo,
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO import_job_items (job_id, product_id, line, action, qty_delta, before_json)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		jobID, productID, row.OriginalLine, action, delta, before,
+	); err != nil {
+		return "", fmt.Errorf("record import item: %w", err)
+	}
+	return action, nil
+}
+
+// updateImportedProduct overwrites a product's details with an import row, logging any
+// price change in the price history.
+func updateImportedProduct(ctx context.Context, tx *sql.Tx, existing product.Product, input product.CreateInput) error {
+	categoryID, categoryPath, err := resolveCategoryPath(ctx, tx, input.Category)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE products
+		SET name = ?, category = ?, category_id = ?, unit_price_cents = ?, tax_rate_bp = ?, reorder_level = ?, notes = ?
+		WHERE id = ?`,
+		input.Name,
+		categoryPath,
+		nullIfZero(categoryID),
+		input.UnitPriceCents,
+		input.TaxRateBasisPoints,
+		input.ReorderLevel,
+		input.Notes,
+		existing.ID,
+	); err != nil {
+		return fmt.Errorf("update imported product: %w", err)
+	}
+	if input.Attributes != nil {
+		if err := setAttributeValues(ctx, tx, existing.ID, input.Attributes, false); err != nil {
+			return err
+		}
+	}
+	if input.UnitPriceCents != existing.UnitPriceCents {
+		return recordAppliedPrice(ctx, tx, existing.ID, input.UnitPriceCents, input.ChangedBy, "Import")
+	}
+	return nil
+}
+
+// restoreProduct puts back the details captured in an import before-image.
+func restoreProduct(ctx context.Context, tx *sql.Tx, before product.Product) error {
+	var current int64
+	if err := tx.QueryRowContext(ctx, `SELECT unit_price_cents FROM products WHERE id = ?`, before.ID).Scan(&current); err != nil {
+		return fmt.Errorf("load imported product: %w", err)
+	}
+	// The category may have been renamed or removed since; resolving the path recreates it.
+	categoryID, categoryPath, err := resolveCategoryPath(ctx, tx, before.Category)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE products
+		SET name = ?, category = ?, category_id = ?, unit_price_cents = ?, tax_rate_bp = ?, reorder_level = ?, notes = ?
+		WHERE id = ?`,
+		before.Name,
+		categoryPath,
+		nullIfZero(categoryID),
+		before.UnitPriceCents,
+		before.TaxRateBasisPoints,
+		before.ReorderLevel,
+		before.Notes,
+		before.ID,
+	); err != nil {
+		return fmt.Errorf("restore product: %w", err)
+	}
+	// Before-images taken before attributes existed carry none and leave values alone.
+	if before.Attributes != nil {
+		if err := setAttributeValues(ctx, tx, before.ID, before.Attributes, true); err != nil {
+			return err
+		}
+	}
+	if current != before.UnitPriceCents {
+		return recordAppliedPrice(ctx, tx, before.ID, before.UnitPriceCents, "", "Import rollback")
+	}
+	return nil
+}
+
+const importJobColumns = `id, job_no, file_name, mode, created_count, updated_count, failed_count, created_at, rolled_back_at`
+
+func scanImportJob(row rowScanner) (*product.ImportJob, error) {
+	var (
+		job        product.ImportJob
+		fileName   sql.NullString
+		created    int64
+		rolledBack sql.NullInt64
+	)
+	if err := row.Scan(&job.ID, &job.JobNumber, &fileName, &job.Mode, &job.Created, &job.Updated, &job.Failed, &created, &rolledBack); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("import not found: %w", err)
+		}
+		return nil, fmt.Errorf("scan import job: %w", err)
+	}
+	job.FileName = fileName.String
+	job.CreatedAt = time.UnixMilli(created).UTC()
+	if rolledBack.Valid {
+		at := time.UnixMilli(rolledBack.Int64).UTC()
+		job.RolledBackAt = &at
+	}
+	return &job, nil
+}
+
+func importJobItems(ctx context.Context, q dbtx, jobID int64) ([]product.ImportJobItem, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT i.product_id, p.sku, i.line, i.action, i.qty_delta, i.before_json
+		FROM import_job_items i
+		INNER JOIN products p ON p.id = i.product_id
+		WHERE i.job_id = ?
+		ORDER BY i.id`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("query import items: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]product.ImportJobItem, 0)
+	for rows.Next() {
+		var (
+			item   product.ImportJobItem
+			before sql.NullString
+		)
+		if err := rows.Scan(&item.ProductID, &item.SKU, &item.Line, &item.Action, &item.QtyDelta, &before); err != nil {
+			return nil, fmt.Errorf("scan import item: %w", err)
+		}
+		if before.Valid {
+			item.Before = &product.Product{}
+			if err := json.Unmarshal([]byte(before.String), item.Before); err != nil {
+				return nil, fmt.Errorf("decode before-image: %w", err)
+			}
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"shopmate/internal/domain/product"
+	"shopmate/internal/domain/sale"
+	"shopmate/internal/domain/settings"
+)
+
+// errKitStock reports an attempt to hold stock against a kit rather than its components.
+var errKitStock = errors.New("kits do not hold stock; adjust their component products instead")
+
+// KitComponents lists a kit's components with their total stock on hand.
+func (r *ProductRepository) KitComponents(ctx context.Context, kitID int64) ([]product.KitComponent, error) {
+	return kitComponents(ctx, r.db, kitID)
+}
+
+// SetKitComponents replaces the component list of a product, turning it into a kit.
+// An empty list turns the kit back into an ordinary product. Kits must hold no stock of
+// their own and components must be ordinary, unserialised products.
+func (r *ProductRepository) SetKitComponents(ctx context.Context, kitID int64, components []product.KitComponent) ([]product.KitComponent, error) {
+	if err := product.ValidateKitComponents(kitID, components); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin kit tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var (
+		qty        int64
+		serialised bool
+	)
+	if err = tx.QueryRowContext(ctx, `SELECT current_qty, serialised FROM products WHERE id = ?`, kitID).Scan(&qty, &serialised); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("load kit: %w", err)
+	}
+	if len(components) > 0 && (qty != 0 || serialised) {
+		err = errors.New("only unserialised products with zero stock can become kits")
+		return nil, err
+	}
+
+	var usedIn int64
+	if err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM kit_components WHERE component_id = ?`, kitID).Scan(&usedIn); err != nil {
+		return nil, fmt.Errorf("check kit usage: %w", err)
+	}
+	if len(components) > 0 && usedIn > 0 {
+		err = errors.New("a product used as a kit component cannot itself be a kit")
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM kit_components WHERE kit_id = ?`, kitID); err != nil {
+		return nil, fmt.Errorf("clear kit components: %w", err)
+	}
+	for _, c := range components {
+		var isKit, componentSerialised bool
+		if err = tx.QueryRowContext(ctx, `SELECT is_kit, serialised FROM products WHERE id = ?`, c.ComponentID).
+			Scan(&isKit, &componentSerialised); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err = fmt.Errorf("component product %d not found", c.ComponentID)
+				return nil, err
+			}
+			return nil, fmt.Errorf("load component: %w", err)
+		}
+		if isKit || componentSerialised {
+			err = fmt.Errorf("component product %d must be an unserialised, non-kit product", c.ComponentID)
+			return nil, err
+		}
+		if _, err = tx.ExecContext(ctx, `
+			INSERT INTO kit_components (kit_id, component_id, qty) VALUES (?, ?, ?)`,
+			kitID, c.ComponentID, c.Quantity,
+		); err != nil {
+			return nil, fmt.Errorf("insert kit component: %w", err)
+		}
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE products SET is_kit = ? WHERE id = ?`, len(components) > 0, kitID); err != nil {
+		return nil, fmt.Errorf("mark kit: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit kit: %w", err)
+	}
+	return r.KitComponents(ctx, kitID)
+}
+
+// sellKitComponents decrements each component of a kit sale line at the sale location under
+// the components' negative-stock policies, recording stock movements, the consumed quantities
+// and costs for refunds, and backorders for components short. It returns the cost of the
+// components consumed and any shortages.
+func sellKitComponents(ctx context.Context, tx *sql.Tx, kitID, saleID, saleItemID, locationID, qty, tsMillis int64, saleNo string, policy settings.StockPolicy) (int64, []sale.Shortage, error) {
+	components, err := kitComponents(ctx, tx, kitID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(components) == 0 {
+		return 0, nil, fmt.Errorf("kit %d has no components", kitID)
+	}
+
+	var (
+		total     int64
+		shortages []sale.Shortage
+	)
+	for _, c := range components {
+		consumed := c.Quantity * qty
+		shortage, err := takeStock(ctx, tx, c.ComponentID, locationID, consumed, policy)
+		if err != nil {
+			return 0, nil, err
+		}
+		if shortage != nil {
+			if err := recordBackorder(ctx, tx, saleID, saleItemID, locationID, tsMillis, shortage); err != nil {
+				return 0, nil, err
+			}
+			shortages = append(shortages, *shortage)
+		}
+		value, err := insertStockMovement(ctx, tx, stockMovement{
+			productID:  c.ComponentID,
+			locationID: locationID,
+			tsMillis:   tsMillis,
+			delta:      -consumed,
+			reason:     "Sale",
+			ref:        saleNo,
+		})
+		if err != nil {
+			return 0, nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO sale_item_components (sale_item_id, product_id, qty, cost_cents) VALUES (?, ?, ?, ?)`,
+			saleItemID, c.ComponentID, consumed, -value,
+		); err != nil {
+			return 0, nil, fmt.Errorf("record kit component: %w", err)
+		}
+		total -= value
+	}
+	return total, shortages, nil
+}
+
+// isKit reports whether a product is a kit.
+func isKit(ctx context.Context, q queryRower, productID int64) (bool, error) {
+	var kit bool
+	if err := q.QueryRowContext(ctx, `SELECT is_kit FROM products WHERE id = ?`, productID).Scan(&kit); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, fmt.Errorf("product %d not found", productID)
+		}
+		return false, fmt.Errorf("load product: %w", err)
+	}
+	return kit, nil
+}
+
+func kitComponents(ctx context.Context, q dbtx, kitID int64) ([]product.KitComponent, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT kc.component_id, p.name, p.sku, kc.qty, p.current_qty
+		FROM kit_components kc
+		INNER JOIN products p ON p.id = kc.component_id
+		WHERE kc.kit_id = ?
+		ORDER BY p.name`, kitID)
+	if err != nil {
+		return nil, fmt.Errorf("query kit components: %w", err)
+	}
+	defer rows.Close()
+
+	components := make([]product.KitComponent, 0)
+	for rows.Next() {
+		var c product.KitComponent
+		if err := rows.Scan(&c.ComponentID, &c.Name, &c.SKU, &c.Quantity, &c.OnHand); err != nil {
+			return nil, fmt.Errorf("scan kit component: %w", err)
+		}
+		components = append(components, c)
+	}
+	return components, rows.Err()
+}
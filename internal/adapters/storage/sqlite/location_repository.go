@@ -0,0 +1,360 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/cart"
+	"shopmate/internal/domain/location"
+)
+
+// LocationRepository persists stock locations, per-location levels and transfers.
+type LocationRepository struct {
+	db *sql.DB
+}
+
+// NewLocationRepository constructs a location repository.
+func NewLocationRepository(db *sql.DB) *LocationRepository {
+	return &LocationRepository{db: db}
+}
+
+// List returns all locations, default first.
+func (r *LocationRepository) List(ctx context.Context) ([]location.Location, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, is_default, active, created_at
+		FROM locations
+		ORDER BY is_default DESC, name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("query locations: %w", err)
+	}
+	defer rows.Close()
+
+	locations := make([]location.Location, 0)
+	for rows.Next() {
+		loc, err := scanLocation(rows)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, *loc)
+	}
+	return locations, rows.Err()
+}
+
+// GetByID fetches a location.
+func (r *LocationRepository) GetByID(ctx context.Context, id int64) (*location.Location, error) {
+	return scanLocation(r.db.QueryRowContext(ctx, `
+		SELECT id, name, is_default, active, created_at
+		FROM locations WHERE id = ?`, id))
+}
+
+// Create adds a location.
+func (r *LocationRepository) Create(ctx context.Context, input location.Input) (*location.Location, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	res, err := r.db.ExecContext(ctx, `INSERT INTO locations (name, active) VALUES (?, ?)`,
+		strings.TrimSpace(input.Name), input.Active)
+	if err != nil {
+		return nil, fmt.Errorf("insert location: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("location last insert id: %w", err)
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Update renames or (de)activates a location. The default location cannot be deactivated,
+// nor can one that still holds stock or has a parked cart waiting to be resumed there.
+func (r *LocationRepository) Update(ctx context.Context, id int64, input location.Input) (*location.Location, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	existing, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if existing.IsDefault && !input.Active {
+		return nil, errors.New("the default location cannot be deactivated")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin location tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if existing.Active && !input.Active {
+		if err = ensureLocationClear(ctx, tx, id, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE locations SET name = ?, active = ? WHERE id = ?`,
+		strings.TrimSpace(input.Name), input.Active, id); err != nil {
+		return nil, fmt.Errorf("update location: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit location: %w", err)
+	}
+	return r.GetByID(ctx, id)
+}
+
+// ensureLocationClear refuses to retire a location while stock is counted there, including
+// negative levels still to be corrected, or while a held cart is parked or reserves stock there.
+func ensureLocationClear(ctx context.Context, q dbtx, id int64, now time.Time) error {
+	var stocked, held, reserved int64
+	if err := q.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM product_stock WHERE location_id = ? AND qty <> 0),
+			(SELECT COUNT(*) FROM held_carts WHERE location_id = ? AND status = ? AND expires_at > ?),
+			(SELECT COUNT(DISTINCT cart_id) FROM stock_reservations WHERE location_id = ?)`,
+		id, id, cart.StatusHeld, now.UnixMilli(), id,
+	).Scan(&stocked, &held, &reserved); err != nil {
+		return fmt.Errorf("check location in use: %w", err)
+	}
+	switch {
+	case stocked > 0:
+		return fmt.Errorf("location still holds stock of %d products; transfer or adjust it to zero first", stocked)
+	case held > 0:
+		return fmt.Errorf("location has %d held carts; resume or discard them first", held)
+	case reserved > 0:
+		return fmt.Errorf("location has stock reserved for %d held carts; resume or discard them first", reserved)
+	}
+	return nil
+}
+
+// SetDefault marks the location used when no other is chosen.
+func (r *LocationRepository) SetDefault(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin default location tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = resolveLocationID(ctx, tx, id); err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE locations SET is_default = (id = ?)`, id); err != nil {
+		return fmt.Errorf("set default location: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit default location: %w", err)
+	}
+	return nil
+}
+
+// StockLevels returns per-location quantities, filtered by product or location when non-zero.
+func (r *LocationRepository) StockLevels(ctx context.Context, productID, locationID int64) ([]location.StockLevel, error) {
+	query := `
+		SELECT ps.product_id, p.name, p.sku, ps.location_id, l.name, ps.qty
+		FROM product_stock ps
+		INNER JOIN products p ON p.id = ps.product_id
+		INNER JOIN locations l ON l.id = ps.location_id
+		WHERE 1 = 1`
+	var args []interface{}
+	if productID > 0 {
+		query += ` AND ps.product_id = ?`
+		args = append(args, productID)
+	}
+	if locationID > 0 {
+		query += ` AND ps.location_id = ?`
+		args = append(args, locationID)
+	}
+	query += ` ORDER BY p.name ASC, l.name ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query stock levels: %w", err)
+	}
+	defer rows.Close()
+
+	levels := make([]location.StockLevel, 0)
+	for rows.Next() {
+		var level location.StockLevel
+		if err := rows.Scan(&level.ProductID, &level.ProductName, &level.SKU, &level.LocationID, &level.LocationName, &level.Qty); err != nil {
+			return nil, fmt.Errorf("scan stock level: %w", err)
+		}
+		levels = append(levels, level)
+	}
+	return levels, rows.Err()
+}
+
+// CreateTransfer moves stock between locations, writing paired stock movements.
+func (r *LocationRepository) CreateTransfer(ctx context.Context, draft location.TransferDraft) (*location.Transfer, error) {
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin transfer tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = resolveLocationID(ctx, tx, draft.FromLocationID); err != nil {
+		return nil, err
+	}
+	if _, err = resolveLocationID(ctx, tx, draft.ToLocationID); err != nil {
+		return nil, err
+	}
+
+	tsMillis := time.Now().UnixMilli()
+	var transferID int64
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO stock_transfers (transfer_no, from_location_id, to_location_id, ts, note)
+		VALUES ('', ?, ?, ?, ?)
+		RETURNING id`,
+		draft.FromLocationID, draft.ToLocationID, tsMillis, nullIfEmpty(draft.Note),
+	).Scan(&transferID); err != nil {
+		return nil, fmt.Errorf("insert transfer: %w", err)
+	}
+
+	transferNo := fmt.Sprintf("TRF-%06d", transferID)
+	if _, err = tx.ExecContext(ctx, `UPDATE stock_transfers SET transfer_no = ? WHERE id = ?`, transferNo, transferID); err != nil {
+		return nil, fmt.Errorf("number transfer: %w", err)
+	}
+
+	for _, line := range draft.Lines {
+		var kit bool
+		if kit, err = isKit(ctx, tx, line.ProductID); err != nil {
+			return nil, err
+		}
+		if kit {
+			err = errKitStock
+			return nil, err
+		}
+
+		if _, err = tx.ExecContext(ctx, `
+			INSERT INTO stock_transfer_lines (transfer_id, product_id, qty) VALUES (?, ?, ?)`,
+			transferID, line.ProductID, line.Quantity,
+		); err != nil {
+			return nil, fmt.Errorf("insert transfer line: %w", err)
+		}
+
+		if err = adjustLocationStock(ctx, tx, line.ProductID, draft.FromLocationID, -line.Quantity, false); err != nil {
+			if errors.Is(err, errInsufficientStock) {
+				err = fmt.Errorf("insufficient stock for product %d at source location", line.ProductID)
+			}
+			return nil, err
+		}
+		if err = adjustLocationStock(ctx, tx, line.ProductID, draft.ToLocationID, line.Quantity, false); err != nil {
+			return nil, err
+		}
+
+		for _, m := range []stockMovement{
+			{productID: line.ProductID, locationID: draft.FromLocationID, tsMillis: tsMillis, delta: -line.Quantity, reason: "Transfer", ref: transferNo, locationOnly: true},
+			{productID: line.ProductID, locationID: draft.ToLocationID, tsMillis: tsMillis, delta: line.Quantity, reason: "Transfer", ref: transferNo, locationOnly: true},
+		} {
+			if _, err = insertStockMovement(ctx, tx, m); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit transfer: %w", err)
+	}
+
+	return r.GetTransfer(ctx, transferID)
+}
+
+// GetTransfer loads a transfer with its lines.
+func (r *LocationRepository) GetTransfer(ctx context.Context, id int64) (*location.Transfer, error) {
+	transfer, err := scanTransfer(r.db.QueryRowContext(ctx, `
+		SELECT id, transfer_no, from_location_id, to_location_id, ts, note
+		FROM stock_transfers WHERE id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT tl.product_id, COALESCE(p.name, ''), COALESCE(p.sku, ''), tl.qty
+		FROM stock_transfer_lines tl
+		LEFT JOIN products p ON p.id = tl.product_id
+		WHERE tl.transfer_id = ?
+		ORDER BY tl.id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query transfer lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line location.TransferLine
+		if err := rows.Scan(&line.ProductID, &line.ProductName, &line.SKU, &line.Quantity); err != nil {
+			return nil, fmt.Errorf("scan transfer line: %w", err)
+		}
+		transfer.Lines = append(transfer.Lines, line)
+	}
+	return transfer, rows.Err()
+}
+
+// ListTransfers returns the most recent transfers without lines.
+func (r *LocationRepository) ListTransfers(ctx context.Context, limit int) ([]location.Transfer, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, transfer_no, from_location_id, to_location_id, ts, note
+		FROM stock_transfers
+		ORDER BY ts DESC, id DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query transfers: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := make([]location.Transfer, 0)
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, *transfer)
+	}
+	return transfers, rows.Err()
+}
+
+func scanLocation(row rowScanner) (*location.Location, error) {
+	var (
+		loc     location.Location
+		created int64
+	)
+	if err := row.Scan(&loc.ID, &loc.Name, &loc.IsDefault, &loc.Active, &created); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("location not found: %w", err)
+		}
+		return nil, fmt.Errorf("scan location: %w", err)
+	}
+	loc.CreatedAt = time.UnixMilli(created).UTC()
+	return &loc, nil
+}
+
+func scanTransfer(row rowScanner) (*location.Transfer, error) {
+	var (
+		transfer location.Transfer
+		tsMillis int64
+		note     sql.NullString
+	)
+	if err := row.Scan(&transfer.ID, &transfer.TransferNumber, &transfer.FromLocationID, &transfer.ToLocationID, &tsMillis, &note); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("transfer not found: %w", err)
+		}
+		return nil, fmt.Errorf("scan transfer: %w", err)
+	}
+	transfer.Timestamp = time.UnixMilli(tsMillis).UTC()
+	transfer.Note = note.String
+	return &transfer, nil
+}
@@ -0,0 +1,231 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/lowstock"
+)
+
+// LowStockRepository reads stock positions and stores low-stock alerts.
+type LowStockRepository struct {
+	db *sql.DB
+}
+
+// NewLowStockRepository constructs a repository.
+func NewLowStockRepository(db *sql.DB) *LowStockRepository {
+	return &LowStockRepository{db: db}
+}
+
+// Levels returns stock and units sold since the given time for active, stocked products.
+// Units sold inside kits count towards their components. When productIDs is non-empty only
+// those products are returned, with kits replaced by their components.
+func (r *LowStockRepository) Levels(ctx context.Context, since time.Time, productIDs []int64) ([]lowstock.Stock, error) {
+	query := `
+		SELECT
+			p.id,
+			p.name,
+			p.sku,
+			p.current_qty,
+			p.reorder_level,
+			COALESCE((
+				SELECT SUM(si.qty - si.refunded_qty)
+				FROM sale_items si
+				INNER JOIN sales sa ON sa.id = si.sale_id
+				WHERE si.product_id = p.id AND sa.status IN ('Completed', 'PartiallyRefunded') AND sa.ts >= ?
+			), 0) + COALESCE((
+				SELECT SUM(c.qty * (si.qty - si.refunded_qty) / si.qty)
+				FROM sale_item_components c
+				INNER JOIN sale_items si ON si.id = c.sale_item_id
+				INNER JOIN sales sa ON sa.id = si.sale_id
+				WHERE c.product_id = p.id AND sa.status IN ('Completed', 'PartiallyRefunded') AND sa.ts >= ?
+			), 0)
+		FROM products p
+		WHERE p.archived_at IS NULL AND p.is_kit = 0`
+	args := []interface{}{since.UnixMilli(), since.UnixMilli()}
+	if len(productIDs) > 0 {
+		in, ids := placeholders(len(productIDs)), int64Args(productIDs)
+		query += ` AND (p.id IN (` + in + `) OR p.id IN (SELECT component_id FROM kit_components WHERE kit_id IN (` + in + `)))`
+		args = append(args, ids...)
+		args = append(args, ids...)
+	}
+	query += ` ORDER BY p.name`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query stock levels: %w", err)
+	}
+	defer rows.Close()
+
+	var levels []lowstock.Stock
+	for rows.Next() {
+		var s lowstock.Stock
+		if err := rows.Scan(&s.ProductID, &s.ProductName, &s.SKU, &s.CurrentQty, &s.ReorderLevel, &s.UnitsSold); err != nil {
+			return nil, fmt.Errorf("scan stock level: %w", err)
+		}
+		levels = append(levels, s)
+	}
+	return levels, rows.Err()
+}
+
+// SyncAlerts reconciles open alerts for the evaluated products with the rules now firing:
+// alerts whose rule stopped firing are resolved and rules without an open alert raise a new
+// one. It returns only the newly raised alerts.
+func (r *LowStockRepository) SyncAlerts(ctx context.Context, productIDs []int64, items []lowstock.Item, now time.Time) ([]lowstock.Alert, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin alert tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	firing := make(map[string]bool)
+	for _, item := range items {
+		for _, rule := range item.Rules {
+			firing[alertKey(item.ProductID, rule)] = true
+		}
+	}
+
+	var open []lowstock.Alert
+	if open, err = queryAlerts(ctx, tx, `a.resolved_at IS NULL AND a.product_id IN (`+placeholders(len(productIDs))+`)`, int64Args(productIDs)...); err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(open))
+	for _, alert := range open {
+		key := alertKey(alert.ProductID, alert.Rule)
+		if firing[key] {
+			existing[key] = true
+			continue
+		}
+		if _, err = tx.ExecContext(ctx, `UPDATE stock_alerts SET resolved_at = ? WHERE id = ?`, now.UnixMilli(), alert.ID); err != nil {
+			return nil, fmt.Errorf("resolve alert: %w", err)
+		}
+	}
+
+	var raisedIDs []int64
+	for _, item := range items {
+		for _, rule := range item.Rules {
+			if existing[alertKey(item.ProductID, rule)] {
+				continue
+			}
+			var id int64
+			if err = tx.QueryRowContext(ctx, `
+				INSERT INTO stock_alerts (product_id, rule, current_qty, shortfall, raised_at)
+				VALUES (?, ?, ?, ?, ?)
+				RETURNING id`,
+				item.ProductID, rule, item.CurrentQty, item.Shortfall, now.UnixMilli(),
+			).Scan(&id); err != nil {
+				return nil, fmt.Errorf("raise alert: %w", err)
+			}
+			raisedIDs = append(raisedIDs, id)
+		}
+	}
+
+	raised := make([]lowstock.Alert, 0, len(raisedIDs))
+	if len(raisedIDs) > 0 {
+		if raised, err = queryAlerts(ctx, tx, `a.id IN (`+placeholders(len(raisedIDs))+`)`, int64Args(raisedIDs)...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit alerts: %w", err)
+	}
+	return raised, nil
+}
+
+// OpenAlerts lists unresolved alerts, newest first.
+func (r *LowStockRepository) OpenAlerts(ctx context.Context) ([]lowstock.Alert, error) {
+	return queryAlerts(ctx, r.db, `a.resolved_at IS NULL`)
+}
+
+// Acknowledge marks an open alert as seen. It stays open until stock recovers, so the same
+// crossing does not alert again.
+func (r *LowStockRepository) Acknowledge(ctx context.Context, id int64, now time.Time) error {
+	return r.updateOpenAlert(ctx, `UPDATE stock_alerts SET acknowledged_at = ? WHERE id = ? AND resolved_at IS NULL`, now.UnixMilli(), id)
+}
+
+// Snooze hides an open alert until the given time.
+func (r *LowStockRepository) Snooze(ctx context.Context, id int64, until time.Time) error {
+	return r.updateOpenAlert(ctx, `UPDATE stock_alerts SET snoozed_until = ? WHERE id = ? AND resolved_at IS NULL`, until.UnixMilli(), id)
+}
+
+func (r *LowStockRepository) updateOpenAlert(ctx context.Context, query string, value, id int64) error {
+	res, err := r.db.ExecContext(ctx, query, value, id)
+	if err != nil {
+		return fmt.Errorf("update alert: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errors.New("alert not found or already resolved")
+	}
+	return nil
+}
+
+func queryAlerts(ctx context.Context, q dbtx, where string, args ...interface{}) ([]lowstock.Alert, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT a.id, a.product_id, p.name, p.sku, a.rule, a.current_qty, a.shortfall,
+			a.raised_at, a.acknowledged_at, a.snoozed_until, a.resolved_at
+		FROM stock_alerts a
+		INNER JOIN products p ON p.id = a.product_id
+		WHERE `+where+`
+		ORDER BY a.raised_at DESC, a.id DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := make([]lowstock.Alert, 0)
+	for rows.Next() {
+		var (
+			a                               lowstock.Alert
+			raised                          int64
+			acknowledged, snoozed, resolved sql.NullInt64
+		)
+		if err := rows.Scan(&a.ID, &a.ProductID, &a.ProductName, &a.SKU, &a.Rule, &a.CurrentQty, &a.Shortfall,
+			&raised, &acknowledged, &snoozed, &resolved); err != nil {
+			return nil, fmt.Errorf("scan alert: %w", err)
+		}
+		a.RaisedAt = time.UnixMilli(raised).UTC()
+		a.AcknowledgedAt = nullableTime(acknowledged)
+		a.SnoozedUntil = nullableTime(snoozed)
+		a.ResolvedAt = nullableTime(resolved)
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+func alertKey(productID int64, rule string) string {
+	return fmt.Sprintf("%d/%s", productID, rule)
+}
+
+func nullableTime(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.UnixMilli(v.Int64).UTC()
+	return &t
+}
+
+// placeholders returns n comma-separated query parameters.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func int64Args(ids []int64) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
@@ -0,0 +1,503 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/loyalty"
+	"shopmate/internal/domain/sale"
+)
+
+// LoyaltyRepository reads and adjusts customers' loyalty points. Sales, refunds and voids
+// post their points inside their own transactions.
+type LoyaltyRepository struct {
+	db *sql.DB
+}
+
+// NewLoyaltyRepository constructs a repository.
+func NewLoyaltyRepository(db *sql.DB) *LoyaltyRepository {
+	return &LoyaltyRepository{db: db}
+}
+
+// Account returns a customer's points balance valued under policy, with the points due to
+// expire soonest.
+func (r *LoyaltyRepository) Account(ctx context.Context, customerID int64, policy loyalty.Policy) (*loyalty.Account, error) {
+	if _, err := customerName(ctx, r.db, customerID); err != nil {
+		return nil, err
+	}
+	account := loyalty.Account{CustomerID: customerID}
+	var err error
+	if account.Points, err = pointsBalance(ctx, r.db, customerID); err != nil {
+		return nil, err
+	}
+	account.ValueCents = policy.Value(max(account.Points, 0))
+
+	var next sql.NullInt64
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT MIN(expires_at) FROM loyalty_ledger WHERE customer_id = ? AND remaining > 0`, customerID,
+	).Scan(&next); err != nil {
+		return nil, fmt.Errorf("load next points expiry: %w", err)
+	}
+	if next.Valid {
+		at := time.UnixMilli(next.Int64).UTC()
+		account.NextExpiryAt = &at
+		if err := r.db.QueryRowContext(ctx, `
+			SELECT COALESCE(SUM(remaining), 0) FROM loyalty_ledger WHERE customer_id = ? AND remaining > 0 AND expires_at = ?`,
+			customerID, next.Int64,
+		).Scan(&account.ExpiringPoints); err != nil {
+			return nil, fmt.Errorf("sum expiring points: %w", err)
+		}
+	}
+	return &account, nil
+}
+
+// Ledger lists a customer's most recent points movements, newest first.
+func (r *LoyaltyRepository) Ledger(ctx context.Context, customerID int64, limit int) ([]loyalty.Entry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.id, l.customer_id, l.ts, l.kind, l.points, l.expires_at, COALESCE(l.sale_id, 0), COALESCE(s.sale_no, ''),
+			COALESCE(l.refund_id, 0), COALESCE(l.note, '')
+		FROM loyalty_ledger l
+		LEFT JOIN sales s ON s.id = l.sale_id
+		WHERE l.customer_id = ?
+		ORDER BY l.ts DESC, l.id DESC
+		LIMIT ?`, customerID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query points ledger: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]loyalty.Entry, 0)
+	for rows.Next() {
+		var (
+			e         loyalty.Entry
+			tsMillis  int64
+			expiresAt sql.NullInt64
+		)
+		if err := rows.Scan(&e.ID, &e.CustomerID, &tsMillis, &e.Kind, &e.Points, &expiresAt, &e.SaleID, &e.SaleNumber,
+			&e.RefundID, &e.Note); err != nil {
+			return nil, fmt.Errorf("scan points entry: %w", err)
+		}
+		e.Timestamp = time.UnixMilli(tsMillis).UTC()
+		if expiresAt.Valid {
+			at := time.UnixMilli(expiresAt.Int64).UTC()
+			e.ExpiresAt = &at
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Adjust credits or debits a customer's points by hand. A debit cannot take the balance
+// below zero.
+func (r *LoyaltyRepository) Adjust(ctx context.Context, customerID, points int64, note string, now time.Time) error {
+	if points == 0 {
+		return errors.New("points adjustment must not be zero")
+	}
+	if strings.TrimSpace(note) == "" {
+		return errors.New("a note explaining the adjustment is required")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin points tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = customerName(ctx, tx, customerID); err != nil {
+		return err
+	}
+	var policy loyalty.Policy
+	if policy, err = loadLoyaltyPolicy(ctx, tx); err != nil {
+		return err
+	}
+	entry := pointsEntry{customerID: customerID, kind: loyalty.KindAdjust, points: points, note: strings.TrimSpace(note), tsMillis: now.UnixMilli()}
+	if points > 0 {
+		err = creditPoints(ctx, tx, entry, policy.Expiry(now))
+	} else {
+		err = spendPoints(ctx, tx, entry)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit points adjustment: %w", err)
+	}
+	return nil
+}
+
+// ExpireDue expires every customer's points past their expiry at now and returns how many
+// points expired.
+func (r *LoyaltyRepository) ExpireDue(ctx context.Context, now time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin points expiry tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var expired int64
+	if expired, err = expireDuePoints(ctx, tx, 0, now.UnixMilli()); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit points expiry: %w", err)
+	}
+	return expired, nil
+}
+
+// pointsEntry is a ledger entry to post. points is the size of the movement; debits are
+// stored negated.
+type pointsEntry struct {
+	customerID int64
+	kind       string
+	points     int64
+	saleID     int64
+	refundID   int64
+	note       string
+	tsMillis   int64
+}
+
+// pointsBalance sums a customer's ledger.
+func pointsBalance(ctx context.Context, q queryRower, customerID int64) (int64, error) {
+	var balance int64
+	if err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE customer_id = ?`, customerID,
+	).Scan(&balance); err != nil {
+		return 0, fmt.Errorf("load points balance: %w", err)
+	}
+	return balance, nil
+}
+
+// creditPoints posts a credit that opens a lot expiring at expiresAt. Points a reversal
+// left owing are settled out of the new lot first.
+func creditPoints(ctx context.Context, q dbtx, entry pointsEntry, expiresAt *time.Time) error {
+	balance, err := pointsBalance(ctx, q, entry.customerID)
+	if err != nil {
+		return err
+	}
+	remaining := entry.points + min(balance, 0)
+	var expires interface{}
+	if expiresAt != nil {
+		expires = expiresAt.UnixMilli()
+	}
+	if _, err := q.ExecContext(ctx, `
+		INSERT INTO loyalty_ledger (customer_id, ts, kind, points, remaining, expires_at, sale_id, refund_id, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.customerID, entry.tsMillis, entry.kind, entry.points, max(remaining, 0), expires,
+		nullIfZero(entry.saleID), nullIfZero(entry.refundID), nullIfEmpty(entry.note),
+	); err != nil {
+		return fmt.Errorf("insert points credit: %w", err)
+	}
+	return nil
+}
+
+// spendPoints posts a debit the customer must be able to cover, as when redeeming.
+func spendPoints(ctx context.Context, q dbtx, entry pointsEntry) error {
+	if _, err := expireDuePoints(ctx, q, entry.customerID, entry.tsMillis); err != nil {
+		return err
+	}
+	balance, err := pointsBalance(ctx, q, entry.customerID)
+	if err != nil {
+		return err
+	}
+	if balance < entry.points {
+		return fmt.Errorf("customer has %d points; %d needed", max(balance, 0), entry.points)
+	}
+	return debitPoints(ctx, q, entry)
+}
+
+// debitPoints posts a debit and consumes it from the customer's open lots, soonest to
+// expire first. A reversal may take the balance below zero when the points it takes back
+// were already spent.
+func debitPoints(ctx context.Context, q dbtx, entry pointsEntry) error {
+	if _, err := q.ExecContext(ctx, `
+		INSERT INTO loyalty_ledger (customer_id, ts, kind, points, sale_id, refund_id, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		entry.customerID, entry.tsMillis, entry.kind, -entry.points,
+		nullIfZero(entry.saleID), nullIfZero(entry.refundID), nullIfEmpty(entry.note),
+	); err != nil {
+		return fmt.Errorf("insert points debit: %w", err)
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, remaining FROM loyalty_ledger
+		WHERE customer_id = ? AND remaining > 0
+		ORDER BY expires_at IS NULL, expires_at, id`, entry.customerID)
+	if err != nil {
+		return fmt.Errorf("query open points: %w", err)
+	}
+	type lot struct{ id, remaining int64 }
+	var lots []lot
+	for rows.Next() {
+		var l lot
+		if err := rows.Scan(&l.id, &l.remaining); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan open points: %w", err)
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	owed := entry.points
+	for _, l := range lots {
+		if owed == 0 {
+			break
+		}
+		take := min(owed, l.remaining)
+		if _, err := q.ExecContext(ctx, `UPDATE loyalty_ledger SET remaining = remaining - ? WHERE id = ?`, take, l.id); err != nil {
+			return fmt.Errorf("consume points: %w", err)
+		}
+		owed -= take
+	}
+	return nil
+}
+
+// expireDuePoints expires what is left of lots past their expiry at tsMillis, for one
+// customer or, with a zero customerID, everyone. It returns the points expired.
+func expireDuePoints(ctx context.Context, q dbtx, customerID, tsMillis int64) (int64, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, customer_id, remaining FROM loyalty_ledger
+		WHERE remaining > 0 AND expires_at <= ? AND (? = 0 OR customer_id = ?)
+		ORDER BY id`, tsMillis, customerID, customerID)
+	if err != nil {
+		return 0, fmt.Errorf("query expired points: %w", err)
+	}
+	type lot struct{ id, customerID, remaining int64 }
+	var lots []lot
+	for rows.Next() {
+		var l lot
+		if err := rows.Scan(&l.id, &l.customerID, &l.remaining); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan expired points: %w", err)
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var expired int64
+	for _, l := range lots {
+		if _, err := q.ExecContext(ctx, `UPDATE loyalty_ledger SET remaining = 0 WHERE id = ?`, l.id); err != nil {
+			return 0, fmt.Errorf("close expired points: %w", err)
+		}
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO loyalty_ledger (customer_id, ts, kind, points, note) VALUES (?, ?, ?, ?, ?)`,
+			l.customerID, tsMillis, loyalty.KindExpire, -l.remaining, fmt.Sprintf("Lot %d expired", l.id),
+		); err != nil {
+			return 0, fmt.Errorf("insert points expiry: %w", err)
+		}
+		expired += l.remaining
+	}
+	return expired, nil
+}
+
+// postSalePoints redeems the points draft was paid with and credits the points it earns,
+// inside the transaction saving sale saleID.
+func postSalePoints(ctx context.Context, tx *sql.Tx, draft *sale.Sale, saleID, tsMillis int64) error {
+	if draft.CustomerID <= 0 {
+		if draft.PointsRedeemed > 0 {
+			return errors.New("points can only be redeemed on a sale to a customer")
+		}
+		return nil
+	}
+	policy, err := loadLoyaltyPolicy(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if draft.PointsRedeemed > 0 {
+		if !policy.Enabled {
+			return errors.New("loyalty points are not enabled")
+		}
+		if err := spendPoints(ctx, tx, pointsEntry{
+			customerID: draft.CustomerID,
+			kind:       loyalty.KindRedeem,
+			points:     draft.PointsRedeemed,
+			saleID:     saleID,
+			note:       draft.SaleNumber,
+			tsMillis:   tsMillis,
+		}); err != nil {
+			return err
+		}
+	}
+
+	shares := sale.AllocateOrderDiscount(draft.DiscountCents, draft.Lines)
+	lines := make([]loyalty.EarnLine, 0, len(draft.Lines))
+	for i, line := range draft.Lines {
+		var categoryID sql.NullInt64
+		if err := tx.QueryRowContext(ctx, `SELECT category_id FROM products WHERE id = ?`, line.ProductID).Scan(&categoryID); err != nil {
+			return fmt.Errorf("load product category: %w", err)
+		}
+		categories, err := categoryAncestry(ctx, tx, categoryID.Int64)
+		if err != nil {
+			return err
+		}
+		amount := line.LineSubtotalCents - line.LineDiscountCents - shares[i]
+		if draft.TaxIncluded {
+			// Points are earned on what the line sold for before tax.
+			amount -= line.LineTaxCents
+		}
+		lines = append(lines, loyalty.EarnLine{
+			ProductID:   line.ProductID,
+			CategoryIDs: categories,
+			AmountCents: amount,
+		})
+	}
+	draft.PointsEarned = policy.Earn(lines, draft.TotalCents, policy.Value(draft.PointsRedeemed))
+	if draft.PointsEarned > 0 {
+		ts := time.UnixMilli(tsMillis)
+		if err := creditPoints(ctx, tx, pointsEntry{
+			customerID: draft.CustomerID,
+			kind:       loyalty.KindEarn,
+			points:     draft.PointsEarned,
+			saleID:     saleID,
+			note:       draft.SaleNumber,
+			tsMillis:   tsMillis,
+		}, policy.Expiry(ts)); err != nil {
+			return err
+		}
+	}
+
+	// The balance is kept for the invoice while the sale is in the programme.
+	if policy.Enabled || draft.PointsEarned > 0 || draft.PointsRedeemed > 0 {
+		if _, err := expireDuePoints(ctx, tx, draft.CustomerID, tsMillis); err != nil {
+			return err
+		}
+		balance, err := pointsBalance(ctx, tx, draft.CustomerID)
+		if err != nil {
+			return err
+		}
+		draft.PointsBalance = &balance
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE sales SET points_earned = ?, points_redeemed = ?, points_balance = ? WHERE id = ?`,
+		draft.PointsEarned, draft.PointsRedeemed, draft.PointsBalance, saleID,
+	); err != nil {
+		return fmt.Errorf("record sale points: %w", err)
+	}
+	return nil
+}
+
+// returnRefundPoints takes back the points earned on the share of the sale refunded so far,
+// and credits the refund as points when it is refunded to the Points method.
+func returnRefundPoints(ctx context.Context, tx *sql.Tx, refund *sale.Refund, tsMillis int64) error {
+	var (
+		customerID sql.NullInt64
+		earned     int64
+		total      int64
+	)
+	if err := tx.QueryRowContext(ctx, `
+		SELECT customer_id, points_earned, total_cents FROM sales WHERE id = ?`, refund.SaleID,
+	).Scan(&customerID, &earned, &total); err != nil {
+		return fmt.Errorf("load sale points: %w", err)
+	}
+	if !customerID.Valid {
+		if sale.IsPoints(refund.PaymentMethod) {
+			return errors.New("only sales to a customer can be refunded as points")
+		}
+		return nil
+	}
+
+	if earned > 0 {
+		var refunded, reversed int64
+		if err := tx.QueryRowContext(ctx, `
+			SELECT
+				(SELECT COALESCE(SUM(total_cents), 0) FROM refunds WHERE sale_id = ?),
+				(SELECT COALESCE(-SUM(points), 0) FROM loyalty_ledger WHERE sale_id = ? AND kind = ?)`,
+			refund.SaleID, refund.SaleID, loyalty.KindReverse,
+		).Scan(&refunded, &reversed); err != nil {
+			return fmt.Errorf("load reversed points: %w", err)
+		}
+		due := earned
+		if total > 0 && refunded < total {
+			due = earned * refunded / total
+		}
+		if due > reversed {
+			if err := debitPoints(ctx, tx, pointsEntry{
+				customerID: customerID.Int64,
+				kind:       loyalty.KindReverse,
+				points:     due - reversed,
+				saleID:     refund.SaleID,
+				refundID:   refund.ID,
+				note:       refund.RefundNumber,
+				tsMillis:   tsMillis,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !sale.IsPoints(refund.PaymentMethod) {
+		return nil
+	}
+	policy, err := loadLoyaltyPolicy(ctx, tx)
+	if err != nil {
+		return err
+	}
+	// Points are worth whole steps of the point value; a part point is not credited.
+	if points := refund.TotalCents / policy.PointValueCents; points > 0 {
+		return creditPoints(ctx, tx, pointsEntry{
+			customerID: customerID.Int64,
+			kind:       loyalty.KindRefund,
+			points:     points,
+			saleID:     refund.SaleID,
+			refundID:   refund.ID,
+			note:       refund.RefundNumber,
+			tsMillis:   tsMillis,
+		}, policy.Expiry(time.UnixMilli(tsMillis)))
+	}
+	return nil
+}
+
+// voidSalePoints takes back the points a voided sale earned and gives back those it was
+// paid with.
+func voidSalePoints(ctx context.Context, tx *sql.Tx, saleID int64, saleNo string, tsMillis int64) error {
+	var (
+		customerID       sql.NullInt64
+		earned, redeemed int64
+	)
+	if err := tx.QueryRowContext(ctx, `
+		SELECT customer_id, points_earned, points_redeemed FROM sales WHERE id = ?`, saleID,
+	).Scan(&customerID, &earned, &redeemed); err != nil {
+		return fmt.Errorf("load sale points: %w", err)
+	}
+	if !customerID.Valid {
+		return nil
+	}
+	entry := pointsEntry{customerID: customerID.Int64, saleID: saleID, note: saleNo, tsMillis: tsMillis}
+	if earned > 0 {
+		entry.kind, entry.points = loyalty.KindReverse, earned
+		if err := debitPoints(ctx, tx, entry); err != nil {
+			return err
+		}
+	}
+	if redeemed > 0 {
+		policy, err := loadLoyaltyPolicy(ctx, tx)
+		if err != nil {
+			return err
+		}
+		entry.kind, entry.points = loyalty.KindRefund, redeemed
+		if err := creditPoints(ctx, tx, entry, policy.Expiry(time.UnixMilli(tsMillis))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/product"
+)
+
+// PriceHistory returns every recorded, scheduled and cancelled price for a product, newest first.
+func (r *ProductRepository) PriceHistory(ctx context.Context, productID int64) ([]product.PriceChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, product_id, price_cents, effective_from, changed_by, note, created_at, applied_at, cancelled_at
+		FROM product_prices
+		WHERE product_id = ?
+		ORDER BY effective_from DESC, id DESC`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("query price history: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]product.PriceChange, 0)
+	for rows.Next() {
+		change, err := scanPriceChange(rows)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, *change)
+	}
+	return history, rows.Err()
+}
+
+// SchedulePriceChange records a price change. Changes effective now or earlier apply immediately;
+// later ones wait for ApplyDuePriceChanges.
+func (r *ProductRepository) SchedulePriceChange(ctx context.Context, input product.PriceChangeInput) (*product.PriceChange, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	effective := input.EffectiveFrom
+	if effective.IsZero() {
+		effective = now
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin price tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var exists int
+	if err = tx.QueryRowContext(ctx, `SELECT 1 FROM products WHERE id = ?`, input.ProductID).Scan(&exists); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("product not found: %w", err)
+		}
+		return nil, fmt.Errorf("load product: %w", err)
+	}
+
+	due := !effective.After(now)
+	var id int64
+	if id, err = insertPrice(ctx, tx, priceRecord{
+		productID:  input.ProductID,
+		priceCents: input.PriceCents,
+		effective:  effective.UnixMilli(),
+		changedBy:  input.ChangedBy,
+		note:       input.Note,
+		nowMillis:  now.UnixMilli(),
+		applied:    due,
+	}); err != nil {
+		return nil, err
+	}
+	if due {
+		if _, err = tx.ExecContext(ctx, `UPDATE products SET unit_price_cents = ? WHERE id = ?`, input.PriceCents, input.ProductID); err != nil {
+			return nil, fmt.Errorf("apply price: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit price change: %w", err)
+	}
+	return r.getPriceChange(ctx, id)
+}
+
+// CancelPriceChange withdraws a scheduled price change that has not applied yet.
+func (r *ProductRepository) CancelPriceChange(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE product_prices SET cancelled_at = ?
+		WHERE id = ? AND applied_at IS NULL AND cancelled_at IS NULL`,
+		time.Now().UnixMilli(), id)
+	if err != nil {
+		return fmt.Errorf("cancel price change: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return product.ErrPriceChangeNotPending
+	}
+	return nil
+}
+
+// ApplyDuePriceChanges copies every scheduled price effective at or before now onto its product,
+// oldest first, and returns how many changes applied. A scheduled price that falls due after a
+// newer price was already set, such as an edit made before its first check, is superseded
+// and cancelled rather than overwriting the newer price.
+func (r *ProductRepository) ApplyDuePriceChanges(ctx context.Context, now time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin price tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE product_prices SET cancelled_at = ?
+		WHERE applied_at IS NULL AND cancelled_at IS NULL AND effective_from <= ?
+			AND effective_from < (
+				SELECT MAX(applied.effective_from) FROM product_prices applied
+				WHERE applied.product_id = product_prices.product_id AND applied.applied_at IS NOT NULL
+			)`,
+		now.UnixMilli(), now.UnixMilli(),
+	); err != nil {
+		return 0, fmt.Errorf("cancel superseded prices: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, product_id, price_cents
+		FROM product_prices
+		WHERE applied_at IS NULL AND cancelled_at IS NULL AND effective_from <= ?
+		ORDER BY effective_from ASC, id ASC`, now.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("query due prices: %w", err)
+	}
+	type due struct{ id, productID, priceCents int64 }
+	var changes []due
+	for rows.Next() {
+		var d due
+		if err = rows.Scan(&d.id, &d.productID, &d.priceCents); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan due price: %w", err)
+		}
+		changes = append(changes, d)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, d := range changes {
+		if _, err = tx.ExecContext(ctx, `UPDATE products SET unit_price_cents = ? WHERE id = ?`, d.priceCents, d.productID); err != nil {
+			return 0, fmt.Errorf("apply price: %w", err)
+		}
+		if _, err = tx.ExecContext(ctx, `UPDATE product_prices SET applied_at = ? WHERE id = ?`, now.UnixMilli(), d.id); err != nil {
+			return 0, fmt.Errorf("mark price applied: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit due prices: %w", err)
+	}
+	return len(changes), nil
+}
+
+// priceRecord is one row of the price history.
+type priceRecord struct {
+	productID  int64
+	priceCents int64
+	effective  int64
+	changedBy  string
+	note       string
+	nowMillis  int64
+	applied    bool
+}
+
+func insertPrice(ctx context.Context, tx *sql.Tx, rec priceRecord) (int64, error) {
+	var appliedAt interface{}
+	if rec.applied {
+		appliedAt = rec.nowMillis
+	}
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO product_prices (product_id, price_cents, effective_from, changed_by, note, created_at, applied_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		rec.productID,
+		rec.priceCents,
+		rec.effective,
+		strings.TrimSpace(rec.changedBy),
+		sqlNullIfEmpty(rec.note),
+		rec.nowMillis,
+		appliedAt,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("insert price history: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// recordAppliedPrice logs a price that takes effect immediately, as done by product create and edit.
+func recordAppliedPrice(ctx context.Context, tx *sql.Tx, productID, priceCents int64, changedBy, note string) error {
+	nowMillis := time.Now().UnixMilli()
+	_, err := insertPrice(ctx, tx, priceRecord{
+		productID:  productID,
+		priceCents: priceCents,
+		effective:  nowMillis,
+		changedBy:  changedBy,
+		note:       note,
+		nowMillis:  nowMillis,
+		applied:    true,
+	})
+	return err
+}
+
+func (r *ProductRepository) getPriceChange(ctx context.Context, id int64) (*product.PriceChange, error) {
+	return scanPriceChange(r.db.QueryRowContext(ctx, `
+		SELECT id, product_id, price_cents, effective_from, changed_by, note, created_at, applied_at, cancelled_at
+		FROM product_prices WHERE id = ?`, id))
+}
+
+func scanPriceChange(row rowScanner) (*product.PriceChange, error) {
+	var (
+		change               product.PriceChange
+		effective, created   int64
+		note                 sql.NullString
+		applied, cancelledAt sql.NullInt64
+	)
+	if err := row.Scan(&change.ID, &change.ProductID, &change.PriceCents, &effective, &change.ChangedBy, &note, &created, &applied, &cancelledAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("price change not found: %w", err)
+		}
+		return nil, fmt.Errorf("scan price change: %w", err)
+	}
+	change.EffectiveFrom = time.UnixMilli(effective).UTC()
+	change.CreatedAt = time.UnixMilli(created).UTC()
+	change.Note = note.String
+	change.Status = product.PriceStatusScheduled
+	if applied.Valid {
+		appliedAt := time.UnixMilli(applied.Int64).UTC()
+		change.AppliedAt = &appliedAt
+		change.Status = product.PriceStatusApplied
+	}
+	if cancelledAt.Valid {
+		cancelled := time.UnixMilli(cancelledAt.Int64).UTC()
+		change.CancelledAt = &cancelled
+		change.Status = product.PriceStatusCancelled
+	}
+	return &change, nil
+}
@@ -3,10 +3,26 @@ package sqlite
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"time"
 
 	"shopmate/internal/domain/product"
 )
 
+const productColumns = `id, sku, name, category, unit_price_cents, tax_rate_bp, current_qty, reorder_level, notes, serialised,
+	COALESCE(category_id, 0), archived_at, is_kit,
+	CASE WHEN is_kit THEN COALESCE((
+		SELECT MIN(MAX(c.current_qty, 0) / kc.qty)
+		FROM kit_components kc
+		INNER JOIN products c ON c.id = kc.component_id
+		WHERE kc.kit_id = products.id
+	), 0) ELSE current_qty END, cost_cents, version, COALESCE(negative_stock_policy, ''), COALESCE(tax_group_id, 0)`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 // ProductRepository persists product records in SQLite.
 type ProductRepository struct {
 	db *sql.DB
@@ -17,31 +33,109 @@ func NewProductRepository(db *sql.DB) *ProductRepository {
 	return &ProductRepository{db: db}
 }
 
-// Create stores a new product and returns the persisted record.
+// Create stores a new product and returns the persisted record. The category path is
+// mapped onto the category tree, creating missing levels, and a zero tax rate or reorder
+// level inherits the category default. Opening stock is placed at the default location.
 func (r *ProductRepository) Create(ctx context.Context, input product.CreateInput) (*product.Product, error) {
-	res, err := r.db.ExecContext(ctx,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin product tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var id int64
+	if id, err = insertProduct(ctx, tx, input); err != nil {
+		return nil, err
+	}
+
+	if input.CurrentQty != 0 {
+		var locationID int64
+		if locationID, err = defaultLocationID(ctx, tx); err != nil {
+			return nil, err
+		}
+		if err = adjustLocationStock(ctx, tx, id, locationID, input.CurrentQty, false); err != nil {
+			return nil, err
+		}
+		if _, err = insertStockMovement(ctx, tx, stockMovement{
+			productID:  id,
+			locationID: locationID,
+			tsMillis:   time.Now().UnixMilli(),
+			delta:      input.CurrentQty,
+			reason:     "Opening stock",
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit product: %w", err)
+	}
+
+	return r.getByID(ctx, id)
+}
+
+// insertProduct adds a product row within tx, mapping its category path onto the category
+// tree and recording the opening price and attribute values. Opening stock is left for the
+// caller to place.
+func insertProduct(ctx context.Context, tx *sql.Tx, input product.CreateInput) (int64, error) {
+	categoryID, categoryPath, err := resolveCategoryPath(ctx, tx, input.Category)
+	if err != nil {
+		return 0, err
+	}
+	if categoryID > 0 && (input.TaxRateBasisPoints == 0 || input.ReorderLevel == 0) {
+		var taxRate, reorder *int64
+		if taxRate, reorder, err = categoryDefaults(ctx, tx, categoryID); err != nil {
+			return 0, err
+		}
+		if input.TaxRateBasisPoints == 0 && taxRate != nil {
+			input.TaxRateBasisPoints = *taxRate
+		}
+		if input.ReorderLevel == 0 && reorder != nil {
+			input.ReorderLevel = *reorder
+		}
+	}
+
+	res, err := tx.ExecContext(ctx,
 		`INSERT INTO products
-			(sku, name, category, unit_price_cents, tax_rate_bp, current_qty, reorder_level, notes)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			(sku, name, category, category_id, unit_price_cents, cost_cents, tax_rate_bp, current_qty, reorder_level, notes, serialised, negative_stock_policy, tax_group_id)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		input.SKU,
 		input.Name,
-		input.Category,
+		categoryPath,
+		nullIfZero(categoryID),
 		input.UnitPriceCents,
+		input.CostCents,
 		input.TaxRateBasisPoints,
 		input.CurrentQty,
 		input.ReorderLevel,
 		input.Notes,
+		input.Serialised,
+		nullIfEmpty(input.NegativeStockPolicy),
+		nullIfZero(input.TaxGroupID),
 	)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
 	id, err := res.LastInsertId()
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	return r.getByID(ctx, id)
+	if err = recordAppliedPrice(ctx, tx, id, input.UnitPriceCents, input.ChangedBy, "Opening price"); err != nil {
+		return 0, err
+	}
+	if len(input.Attributes) > 0 {
+		if err = setAttributeValues(ctx, tx, id, input.Attributes, false); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, nil
 }
 
 // GetByID fetches a product by primary key.
@@ -49,42 +143,57 @@ func (r *ProductRepository) GetByID(ctx context.Context, id int64) (*product.Pro
 	return r.getByID(ctx, id)
 }
 
-// List returns all products sorted by name ascending.
-func (r *ProductRepository) List(ctx context.Context) ([]product.Product, error) {
-	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, sku, name, category, unit_price_cents, tax_rate_bp, current_qty, reorder_level, notes
-		 FROM products
-		 ORDER BY name ASC`)
+// List returns products sorted by name ascending. Archived products are only included on request.
+func (r *ProductRepository) List(ctx context.Context, includeArchived bool) ([]product.Product, error) {
+	return r.Search(ctx, product.Filter{IncludeArchived: includeArchived})
+}
+
+func (r *ProductRepository) getByID(ctx context.Context, id int64) (*product.Product, error) {
+	p, err := scanProduct(r.db.QueryRowContext(ctx,
+		`SELECT `+productColumns+`
+		 FROM products WHERE id = ?`, id,
+	))
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	products := make([]product.Product, 0)
-
-	for rows.Next() {
-		var p product.Product
-		if err := rows.Scan(&p.ID, &p.SKU, &p.Name, &p.Category, &p.UnitPriceCents, &p.TaxRateBasisPoints, &p.CurrentQty, &p.ReorderLevel, &p.Notes); err != nil {
-			return nil, err
-		}
-		products = append(products, p)
-	}
-
-	if err := rows.Err(); err != nil {
+	if p.Attributes, err = productAttributes(ctx, r.db, p.ID); err != nil {
 		return nil, err
 	}
-
-	return products, nil
+	return p, nil
 }
 
-func (r *ProductRepository) getByID(ctx context.Context, id int64) (*product.Product, error) {
-	var p product.Product
-	err := r.db.QueryRowContext(ctx,
-		`SELECT id, sku, name, category, unit_price_cents, tax_rate_bp, current_qty, reorder_level, notes
-		 FROM products WHERE id = ?`, id,
-	).Scan(&p.ID, &p.SKU, &p.Name, &p.Category, &p.UnitPriceCents, &p.TaxRateBasisPoints, &p.CurrentQty, &p.ReorderLevel, &p.Notes)
-	if err != nil {
+func scanProduct(row rowScanner) (*product.Product, error) {
+	var (
+		p        product.Product
+		notes    sql.NullString
+		archived sql.NullInt64
+	)
+	if err := row.Scan(
+		&p.ID,
+		&p.SKU,
+		&p.Name,
+		&p.Category,
+		&p.UnitPriceCents,
+		&p.TaxRateBasisPoints,
+		&p.CurrentQty,
+		&p.ReorderLevel,
+		&notes,
+		&p.Serialised,
+		&p.CategoryID,
+		&archived,
+		&p.IsKit,
+		&p.AvailableQty,
+		&p.CostCents,
+		&p.Version,
+		&p.NegativeStockPolicy,
+		&p.TaxGroupID,
+	); err != nil {
 		return nil, err
 	}
+	p.Notes = notes.String
+	if archived.Valid {
+		archivedAt := time.UnixMilli(archived.Int64).UTC()
+		p.ArchivedAt = &archivedAt
+	}
 	return &p, nil
 }
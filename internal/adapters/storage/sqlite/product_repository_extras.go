@@ -6,11 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"shopmate/internal/domain/product"
 )
 
-// Update mutates an existing product by id.
+// Update mutates an existing product by id. The edit only applies if the product is still
+// at input.Version; otherwise a *product.ConflictError carries the current values.
 func (r *ProductRepository) Update(ctx context.Context, id int64, input product.UpdateInput) (*product.Product, error) {
 	if id <= 0 {
 		return nil, errors.New("id must be > 0")
@@ -19,33 +21,106 @@ func (r *ProductRepository) Update(ctx context.Context, id int64, input product.
 		return nil, err
 	}
 
-	_, err := r.db.ExecContext(ctx, `
+	existing, err := r.getByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("load product: %w", err)
+	}
+	if existing.Version != input.Version {
+		return nil, &product.ConflictError{Current: existing}
+	}
+	if input.Serialised && !existing.Serialised && existing.CurrentQty != 0 {
+		return nil, errors.New("serial tracking can only be enabled while stock is zero")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin product tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	categoryID, categoryPath, err := resolveCategoryPath(ctx, tx, input.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	var res sql.Result
+	res, err = tx.ExecContext(ctx, `
 		UPDATE products
-		SET name = ?, category = ?, unit_price_cents = ?, tax_rate_bp = ?, reorder_level = ?, notes = ?
-		WHERE id = ?`,
+		SET name = ?, category = ?, category_id = ?, unit_price_cents = ?, cost_cents = ?, tax_rate_bp = ?, reorder_level = ?, notes = ?, serialised = ?, negative_stock_policy = ?,
+			tax_group_id = ?
+		WHERE id = ? AND version = ?`,
 		input.Name,
-		input.Category,
+		categoryPath,
+		nullIfZero(categoryID),
 		input.UnitPriceCents,
+		input.CostCents,
 		input.TaxRateBasisPoints,
 		input.ReorderLevel,
 		input.Notes,
+		input.Serialised,
+		nullIfEmpty(input.NegativeStockPolicy),
+		nullIfZero(input.TaxGroupID),
 		id,
+		input.Version,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("update product: %w", err)
 	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		// Changed between the check above and this write; release the transaction before
+		// reading the current values.
+		_ = tx.Rollback()
+		current, loadErr := r.getByID(ctx, id)
+		if loadErr != nil {
+			err = loadErr
+			return nil, err
+		}
+		err = &product.ConflictError{Current: current}
+		return nil, err
+	}
+
+	if input.UnitPriceCents != existing.UnitPriceCents {
+		if err = recordAppliedPrice(ctx, tx, id, input.UnitPriceCents, input.ChangedBy, ""); err != nil {
+			return nil, err
+		}
+	}
+	if input.Attributes != nil {
+		if err = setAttributeValues(ctx, tx, id, input.Attributes, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit product: %w", err)
+	}
 
 	return r.getByID(ctx, id)
 }
 
-// Delete removes a product by id.
-func (r *ProductRepository) Delete(ctx context.Context, id int64) error {
+// Archive withdraws a product from sale while keeping it for history and reports.
+func (r *ProductRepository) Archive(ctx context.Context, id int64) error {
+	return r.setArchived(ctx, id, sql.NullInt64{Int64: time.Now().UnixMilli(), Valid: true})
+}
+
+// Unarchive returns an archived product to sale.
+func (r *ProductRepository) Unarchive(ctx context.Context, id int64) error {
+	return r.setArchived(ctx, id, sql.NullInt64{})
+}
+
+func (r *ProductRepository) setArchived(ctx context.Context, id int64, archivedAt sql.NullInt64) error {
 	if id <= 0 {
 		return errors.New("id must be > 0")
 	}
-	_, err := r.db.ExecContext(ctx, `DELETE FROM products WHERE id = ?`, id)
+	res, err := r.db.ExecContext(ctx, `UPDATE products SET archived_at = ? WHERE id = ?`, archivedAt, id)
 	if err != nil {
-		return fmt.Errorf("delete product: %w", err)
+		return fmt.Errorf("archive product: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("product %d not found", id)
 	}
 	return nil
 }
@@ -66,85 +141,79 @@ func (r *ProductRepository) AdjustStock(ctx context.Context, input product.Adjus
 		}
 	}()
 
-	var (
-		currentQty int64
-		sku        string
-	)
-	if err = tx.QueryRowContext(ctx, `SELECT current_qty, sku FROM products WHERE id = ?`, input.ProductID).
-		Scan(&currentQty, &sku); err != nil {
+	var serialised, kit bool
+	if err = tx.QueryRowContext(ctx, `SELECT serialised, is_kit FROM products WHERE id = ?`, input.ProductID).
+		Scan(&serialised, &kit); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("product not found: %w", err)
 		}
-		return nil, fmt.Errorf("load product qty: %w", err)
+		return nil, fmt.Errorf("load product: %w", err)
 	}
-
-	newQty := currentQty + input.Delta
-	if newQty < 0 {
-		return nil, fmt.Errorf("insufficient stock for adjustment; current=%d delta=%d", currentQty, input.Delta)
+	if kit {
+		err = errKitStock
+		return nil, err
 	}
 
-	if _, err = tx.ExecContext(ctx, `
-		UPDATE products
-		SET current_qty = ?, updated_at = (CAST(strftime('%s','now') AS INTEGER) * 1000)
-		WHERE id = ?`,
-		newQty,
-		input.ProductID,
-	); err != nil {
-		return nil, fmt.Errorf("update product qty: %w", err)
+	var locationID int64
+	if locationID, err = resolveLocationID(ctx, tx, input.LocationID); err != nil {
+		return nil, err
 	}
 
-	if _, err = tx.ExecContext(ctx, `
-		INSERT INTO stock_movements (product_id, ts, delta, reason, ref)
-		VALUES (?, (CAST(strftime('%s','now') AS INTEGER) * 1000), ?, ?, ?)`,
-		input.ProductID,
-		input.Delta,
-		input.Reason,
-		sqlNullIfEmpty(input.Ref),
-	); err != nil {
-		return nil, fmt.Errorf("insert stock movement: %w", err)
+	if err = adjustLocationStock(ctx, tx, input.ProductID, locationID, input.Delta, false); err != nil {
+		if errors.Is(err, errInsufficientStock) {
+			currentQty, qtyErr := locationQty(ctx, tx, input.ProductID, locationID)
+			if qtyErr != nil {
+				err = qtyErr
+				return nil, err
+			}
+			err = fmt.Errorf("insufficient stock for adjustment; current=%d delta=%d", currentQty, input.Delta)
+		}
+		return nil, err
 	}
 
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("commit adjustment: %w", err)
+	nowMillis := time.Now().UnixMilli()
+	if serialised {
+		var serials []string
+		if serials, err = product.NormalizeSerials(input.SerialNumbers); err != nil {
+			return nil, err
+		}
+		if err = product.CheckSerialCount(serials, input.Delta); err != nil {
+			return nil, err
+		}
+		if input.Delta > 0 {
+			err = receiveSerials(ctx, tx, input.ProductID, serials, nowMillis, input.Ref)
+		} else {
+			err = removeSerials(ctx, tx, input.ProductID, serials)
+		}
+		if err != nil {
+			return nil, err
+		}
+	} else if len(input.SerialNumbers) > 0 {
+		return nil, errors.New("product is not serialised")
 	}
 
-	return r.getByID(ctx, input.ProductID)
-}
-
-// Upsert creates or updates a product by SKU, returning whether a new record was created.
-func (r *ProductRepository) Upsert(ctx context.Context, input product.CreateInput) (*product.Product, bool, error) {
-	if err := input.Validate(); err != nil {
-		return nil, false, err
+	if _, err = insertStockMovement(ctx, tx, stockMovement{
+		productID:     input.ProductID,
+		locationID:    locationID,
+		tsMillis:      nowMillis,
+		delta:         input.Delta,
+		reason:        input.Reason,
+		ref:           input.Ref,
+		unitCostCents: input.UnitCostCents,
+	}); err != nil {
+		return nil, err
 	}
-
-	if product, err := r.Create(ctx, input); err == nil {
-		return product, true, nil
-	} else if !isUniqueConstraint(err) {
-		return nil, false, err
+	if input.UnitCostCents > 0 {
+		if _, err = tx.ExecContext(ctx, `UPDATE products SET cost_cents = ? WHERE id = ?`, input.UnitCostCents, input.ProductID); err != nil {
+			return nil, fmt.Errorf("update product cost: %w", err)
+		}
 	}
 
-	_, err := r.db.ExecContext(ctx, `
-		UPDATE products
-		SET name = ?, category = ?, unit_price_cents = ?, tax_rate_bp = ?, current_qty = ?, reorder_level = ?, notes = ?
-		WHERE sku = ?`,
-		input.Name,
-		input.Category,
-		input.UnitPriceCents,
-		input.TaxRateBasisPoints,
-		input.CurrentQty,
-		input.ReorderLevel,
-		input.Notes,
-		input.SKU,
-	)
-	if err != nil {
-		return nil, false, fmt.Errorf("upsert product: %w", err)
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit adjustment: %w", err)
 	}
 
-	product, err := r.getBySKU(ctx, input.SKU)
-	if err != nil {
-		return nil, false, fmt.Errorf("load upserted product: %w", err)
-	}
-	return product, false, nil
+	return r.getByID(ctx, input.ProductID)
 }
 
 // CountLowStock returns number of products below or at reorder level.
@@ -152,40 +221,30 @@ func (r *ProductRepository) CountLowStock(ctx context.Context) (int, error) {
 	var count int
 	err := r.db.QueryRowContext(ctx, `
 		SELECT COUNT(*) FROM products
-		WHERE reorder_level > 0 AND current_qty <= reorder_level`).Scan(&count)
+		WHERE archived_at IS NULL AND is_kit = 0 AND reorder_level > 0 AND current_qty <= reorder_level`).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("count low stock: %w", err)
 	}
 	return count, nil
 }
 
+// GetBySKU fetches a product by its SKU.
+func (r *ProductRepository) GetBySKU(ctx context.Context, sku string) (*product.Product, error) {
+	return r.getBySKU(ctx, sku)
+}
+
 func (r *ProductRepository) getBySKU(ctx context.Context, sku string) (*product.Product, error) {
-	var p product.Product
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, sku, name, category, unit_price_cents, tax_rate_bp, current_qty, reorder_level, notes
+	p, err := scanProduct(r.db.QueryRowContext(ctx, `
+		SELECT `+productColumns+`
 		FROM products
-		WHERE sku = ?`, sku).Scan(
-		&p.ID,
-		&p.SKU,
-		&p.Name,
-		&p.Category,
-		&p.UnitPriceCents,
-		&p.TaxRateBasisPoints,
-		&p.CurrentQty,
-		&p.ReorderLevel,
-		&p.Notes,
-	)
+		WHERE sku = ?`, sku))
 	if err != nil {
 		return nil, err
 	}
-	return &p, nil
-}
-
-func isUniqueConstraint(err error) bool {
-	if err == nil {
-		return false
+	if p.Attributes, err = productAttributes(ctx, r.db, p.ID); err != nil {
+		return nil, err
 	}
-	return stringsContainsIgnoreCase(err.Error(), "unique constraint failed: products.sku")
+	return p, nil
 }
 
 func stringsContainsIgnoreCase(str, substr string) bool {
@@ -0,0 +1,400 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/domain/promotion"
+	"shopmate/internal/domain/sale"
+)
+
+// PromotionRepository stores promotions and their targets. Sales record the promotions
+// applied to their lines and count coupon uses inside their own transactions.
+type PromotionRepository struct {
+	db *sql.DB
+}
+
+// NewPromotionRepository constructs a repository.
+func NewPromotionRepository(db *sql.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+const promotionColumns = `id, name, kind, active, percent_bp, amount_cents, buy_qty, get_qty, min_spend_cents,
+	starts_at, ends_at, days_mask, start_minute, end_minute, COALESCE(coupon_code, ''), usage_limit, per_customer_limit,
+	used_count, priority, created_at, updated_at`
+
+// Create saves a new promotion.
+func (r *PromotionRepository) Create(ctx context.Context, input promotion.Input, now time.Time) (*promotion.Promotion, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin promotion tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = ensureCouponCodeFree(ctx, tx, input.CouponCode, 0); err != nil {
+		return nil, err
+	}
+
+	var id int64
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO promotions (name, kind, active, percent_bp, amount_cents, buy_qty, get_qty, min_spend_cents,
+			starts_at, ends_at, days_mask, start_minute, end_minute, coupon_code, usage_limit, per_customer_limit,
+			priority, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		input.Name, input.Kind, input.Active, input.PercentBasisPoints, input.AmountCents, input.BuyQty, input.GetQty,
+		input.MinSpendCents, nullableMillis(input.StartsAt), nullableMillis(input.EndsAt), daysMask(input.Days),
+		input.StartMinute, input.EndMinute, nullIfEmpty(input.CouponCode), input.UsageLimit, input.PerCustomerLimit,
+		input.Priority, now.UnixMilli(), now.UnixMilli(),
+	).Scan(&id); err != nil {
+		return nil, fmt.Errorf("save promotion: %w", err)
+	}
+	if err = writePromotionTargets(ctx, tx, id, input); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit promotion: %w", err)
+	}
+	return r.Get(ctx, id)
+}
+
+// Update replaces a promotion's rule. Sales already recorded keep the discounts they were
+// given, and a coupon keeps its use count.
+func (r *PromotionRepository) Update(ctx context.Context, id int64, input promotion.Input, now time.Time) (*promotion.Promotion, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin promotion tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = ensureCouponCodeFree(ctx, tx, input.CouponCode, id); err != nil {
+		return nil, err
+	}
+
+	var res sql.Result
+	if res, err = tx.ExecContext(ctx, `
+		UPDATE promotions SET name = ?, kind = ?, active = ?, percent_bp = ?, amount_cents = ?, buy_qty = ?, get_qty = ?,
+			min_spend_cents = ?, starts_at = ?, ends_at = ?, days_mask = ?, start_minute = ?, end_minute = ?,
+			coupon_code = ?, usage_limit = ?, per_customer_limit = ?, priority = ?, updated_at = ?
+		WHERE id = ?`,
+		input.Name, input.Kind, input.Active, input.PercentBasisPoints, input.AmountCents, input.BuyQty, input.GetQty,
+		input.MinSpendCents, nullableMillis(input.StartsAt), nullableMillis(input.EndsAt), daysMask(input.Days),
+		input.StartMinute, input.EndMinute, nullIfEmpty(input.CouponCode), input.UsageLimit, input.PerCustomerLimit,
+		input.Priority, now.UnixMilli(), id,
+	); err != nil {
+		return nil, fmt.Errorf("save promotion: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		err = fmt.Errorf("promotion %d not found", id)
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM promotion_targets WHERE promotion_id = ?`, id); err != nil {
+		return nil, fmt.Errorf("clear promotion targets: %w", err)
+	}
+	if err = writePromotionTargets(ctx, tx, id, input); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit promotion: %w", err)
+	}
+	return r.Get(ctx, id)
+}
+
+func writePromotionTargets(ctx context.Context, tx *sql.Tx, id int64, input promotion.Input) error {
+	for _, productID := range input.ProductIDs {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO promotion_targets (promotion_id, product_id) VALUES (?, ?)`, id, productID); err != nil {
+			return fmt.Errorf("insert promotion product %d: %w", productID, err)
+		}
+	}
+	for _, categoryID := range input.CategoryIDs {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO promotion_targets (promotion_id, category_id) VALUES (?, ?)`, id, categoryID); err != nil {
+			return fmt.Errorf("insert promotion category %d: %w", categoryID, err)
+		}
+	}
+	return nil
+}
+
+// ensureCouponCodeFree refuses a coupon code another promotion than id already uses.
+func ensureCouponCodeFree(ctx context.Context, tx *sql.Tx, code string, id int64) error {
+	if code == "" {
+		return nil
+	}
+	var taken bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM promotions WHERE coupon_code = ? AND id <> ?)`, code, id).Scan(&taken); err != nil {
+		return fmt.Errorf("check coupon code: %w", err)
+	}
+	if taken {
+		return fmt.Errorf("coupon code %s is already in use", code)
+	}
+	return nil
+}
+
+// Delete removes a promotion. Sale lines it discounted keep its name and discount.
+func (r *PromotionRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM promotions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete promotion: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("promotion %d not found", id)
+	}
+	return nil
+}
+
+// Get retrieves a promotion by id.
+func (r *PromotionRepository) Get(ctx context.Context, id int64) (*promotion.Promotion, error) {
+	promotions, err := r.query(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(promotions) == 0 {
+		return nil, fmt.Errorf("promotion %d not found", id)
+	}
+	return &promotions[0], nil
+}
+
+// List returns every promotion, highest priority first.
+func (r *PromotionRepository) List(ctx context.Context) ([]promotion.Promotion, error) {
+	return r.query(ctx, `ORDER BY priority DESC, id`)
+}
+
+// Active returns the promotions switched on, whatever their schedule.
+func (r *PromotionRepository) Active(ctx context.Context) ([]promotion.Promotion, error) {
+	return r.query(ctx, `WHERE active = 1 ORDER BY priority DESC, id`)
+}
+
+// Ancestry returns a category followed by its ancestors, for matching category
+// promotions.
+func (r *PromotionRepository) Ancestry(ctx context.Context, categoryID int64) ([]int64, error) {
+	return categoryAncestry(ctx, r.db, categoryID)
+}
+
+func (r *PromotionRepository) query(ctx context.Context, where string, args ...interface{}) ([]promotion.Promotion, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+promotionColumns+` FROM promotions `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := make([]promotion.Promotion, 0)
+	index := make(map[int64]int)
+	for rows.Next() {
+		var (
+			p                    promotion.Promotion
+			startsAt, endsAt     sql.NullInt64
+			mask                 int
+			createdAt, updatedAt int64
+		)
+		if err := rows.Scan(&p.ID, &p.Name, &p.Kind, &p.Active, &p.PercentBasisPoints, &p.AmountCents, &p.BuyQty, &p.GetQty,
+			&p.MinSpendCents, &startsAt, &endsAt, &mask, &p.StartMinute, &p.EndMinute, &p.CouponCode, &p.UsageLimit,
+			&p.PerCustomerLimit, &p.UsedCount, &p.Priority, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan promotion: %w", err)
+		}
+		p.StartsAt = millisPtr(startsAt)
+		p.EndsAt = millisPtr(endsAt)
+		p.Days = maskDays(mask)
+		p.ProductIDs = []int64{}
+		p.CategoryIDs = []int64{}
+		p.CreatedAt = time.UnixMilli(createdAt).UTC()
+		p.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+		index[p.ID] = len(promotions)
+		promotions = append(promotions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if len(promotions) == 0 {
+		return promotions, nil
+	}
+	targets, err := r.db.QueryContext(ctx, `
+		SELECT promotion_id, COALESCE(product_id, 0), COALESCE(category_id, 0)
+		FROM promotion_targets
+		ORDER BY promotion_id, product_id, category_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query promotion targets: %w", err)
+	}
+	defer targets.Close()
+	for targets.Next() {
+		var promotionID, productID, categoryID int64
+		if err := targets.Scan(&promotionID, &productID, &categoryID); err != nil {
+			return nil, fmt.Errorf("scan promotion target: %w", err)
+		}
+		i, ok := index[promotionID]
+		if !ok {
+			continue
+		}
+		if productID > 0 {
+			promotions[i].ProductIDs = append(promotions[i].ProductIDs, productID)
+		}
+		if categoryID > 0 {
+			promotions[i].CategoryIDs = append(promotions[i].CategoryIDs, categoryID)
+		}
+	}
+	return promotions, targets.Err()
+}
+
+func daysMask(days []int) int {
+	mask := 0
+	for _, day := range days {
+		mask |= 1 << day
+	}
+	return mask
+}
+
+func maskDays(mask int) []int {
+	days := make([]int, 0, 7)
+	for day := range 7 {
+		if mask&(1<<day) != 0 {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func nullableMillis(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UnixMilli()
+}
+
+func millisPtr(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.UnixMilli(v.Int64).UTC()
+	return &t
+}
+
+// insertLinePromotions records the promotions applied to a sale line.
+func insertLinePromotions(ctx context.Context, tx *sql.Tx, itemID int64, promotions []sale.LinePromotion) error {
+	for _, p := range promotions {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO sale_item_promotions (sale_item_id, promotion_id, name, coupon_code, discount_cents)
+			VALUES (?, ?, ?, ?, ?)`,
+			itemID, nullIfZero(p.PromotionID), p.Name, nullIfEmpty(p.CouponCode), p.DiscountCents,
+		); err != nil {
+			return fmt.Errorf("insert line promotion: %w", err)
+		}
+	}
+	return nil
+}
+
+// redeemCoupons counts one use of each coupon a sale's lines were discounted with. The
+// limits are checked here, inside the sale's transaction, so two sales cannot both take
+// a coupon's last use.
+func redeemCoupons(ctx context.Context, tx *sql.Tx, draft *sale.Sale, saleID int64) error {
+	redeemed := make(map[int64]bool)
+	for _, line := range draft.Lines {
+		for _, p := range line.Promotions {
+			if p.CouponCode == "" || p.PromotionID == 0 || redeemed[p.PromotionID] {
+				continue
+			}
+			redeemed[p.PromotionID] = true
+
+			var perCustomer int64
+			if err := tx.QueryRowContext(ctx, `SELECT per_customer_limit FROM promotions WHERE id = ?`, p.PromotionID).Scan(&perCustomer); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return fmt.Errorf("coupon %s is no longer available", p.CouponCode)
+				}
+				return fmt.Errorf("load coupon: %w", err)
+			}
+			if perCustomer > 0 {
+				if draft.CustomerID <= 0 {
+					return fmt.Errorf("coupon %s can only be used on a sale to a customer", p.CouponCode)
+				}
+				var uses int64
+				if err := tx.QueryRowContext(ctx, `
+					SELECT COUNT(DISTINCT s.id)
+					FROM sale_item_promotions sip
+					INNER JOIN sale_items si ON si.id = sip.sale_item_id
+					INNER JOIN sales s ON s.id = si.sale_id
+					WHERE sip.promotion_id = ? AND sip.coupon_code IS NOT NULL AND s.customer_id = ?
+						AND s.status != 'Voided' AND s.id != ?`,
+					p.PromotionID, draft.CustomerID, saleID,
+				).Scan(&uses); err != nil {
+					return fmt.Errorf("count coupon uses: %w", err)
+				}
+				if uses >= perCustomer {
+					return fmt.Errorf("coupon %s has already been used %d times by this customer", p.CouponCode, uses)
+				}
+			}
+
+			res, err := tx.ExecContext(ctx, `
+				UPDATE promotions SET used_count = used_count + 1
+				WHERE id = ? AND (usage_limit = 0 OR used_count < usage_limit)`, p.PromotionID)
+			if err != nil {
+				return fmt.Errorf("count coupon use: %w", err)
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				return fmt.Errorf("coupon %s has been used up", p.CouponCode)
+			}
+		}
+	}
+	return nil
+}
+
+// releaseCoupons gives back the coupon uses of a voided sale.
+func releaseCoupons(ctx context.Context, tx *sql.Tx, saleID int64) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE promotions SET used_count = MAX(used_count - 1, 0)
+		WHERE id IN (
+			SELECT DISTINCT sip.promotion_id
+			FROM sale_item_promotions sip
+			INNER JOIN sale_items si ON si.id = sip.sale_item_id
+			WHERE si.sale_id = ? AND sip.coupon_code IS NOT NULL
+		)`, saleID); err != nil {
+		return fmt.Errorf("release coupons: %w", err)
+	}
+	return nil
+}
+
+// saleLinePromotions loads the promotions applied to a sale's lines, keyed by line id.
+func saleLinePromotions(ctx context.Context, q dbtx, saleID int64) (map[int64][]sale.LinePromotion, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT sip.sale_item_id, COALESCE(sip.promotion_id, 0), sip.name, COALESCE(sip.coupon_code, ''), sip.discount_cents
+		FROM sale_item_promotions sip
+		INNER JOIN sale_items si ON si.id = sip.sale_item_id
+		WHERE si.sale_id = ?
+		ORDER BY sip.id`, saleID)
+	if err != nil {
+		return nil, fmt.Errorf("query line promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := make(map[int64][]sale.LinePromotion)
+	for rows.Next() {
+		var (
+			itemID int64
+			p      sale.LinePromotion
+		)
+		if err := rows.Scan(&itemID, &p.PromotionID, &p.Name, &p.CouponCode, &p.DiscountCents); err != nil {
+			return nil, fmt.Errorf("scan line promotion: %w", err)
+		}
+		promotions[itemID] = append(promotions[itemID], p)
+	}
+	return promotions, rows.Err()
+}
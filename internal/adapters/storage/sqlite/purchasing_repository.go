@@ -0,0 +1,356 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/purchasing"
+)
+
+// PurchasingRepository persists suppliers, purchase orders and the demand history used for replenishment.
+type PurchasingRepository struct {
+	db *sql.DB
+}
+
+// NewPurchasingRepository constructs a purchasing repository.
+func NewPurchasingRepository(db *sql.DB) *PurchasingRepository {
+	return &PurchasingRepository{db: db}
+}
+
+// ListSuppliers returns suppliers sorted by name.
+func (r *PurchasingRepository) ListSuppliers(ctx context.Context) ([]purchasing.Supplier, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, contact, phone, email, lead_time_days, notes, created_at
+		FROM suppliers
+		ORDER BY name ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("query suppliers: %w", err)
+	}
+	defer rows.Close()
+
+	suppliers := make([]purchasing.Supplier, 0)
+	for rows.Next() {
+		supplier, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, *supplier)
+	}
+	return suppliers, rows.Err()
+}
+
+// GetSupplier fetches a supplier by id.
+func (r *PurchasingRepository) GetSupplier(ctx context.Context, id int64) (*purchasing.Supplier, error) {
+	return scanSupplier(r.db.QueryRowContext(ctx, `
+		SELECT id, name, contact, phone, email, lead_time_days, notes, created_at
+		FROM suppliers WHERE id = ?`, id))
+}
+
+// CreateSupplier adds a supplier.
+func (r *PurchasingRepository) CreateSupplier(ctx context.Context, input purchasing.SupplierInput) (*purchasing.Supplier, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO suppliers (name, contact, phone, email, lead_time_days, notes)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		strings.TrimSpace(input.Name),
+		nullIfEmpty(input.Contact),
+		nullIfEmpty(input.Phone),
+		nullIfEmpty(input.Email),
+		input.LeadTimeDays,
+		nullIfEmpty(input.Notes),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert supplier: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("supplier last insert id: %w", err)
+	}
+	return r.GetSupplier(ctx, id)
+}
+
+// UpdateSupplier modifies a supplier.
+func (r *PurchasingRepository) UpdateSupplier(ctx context.Context, id int64, input purchasing.SupplierInput) (*purchasing.Supplier, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE suppliers
+		SET name = ?, contact = ?, phone = ?, email = ?, lead_time_days = ?, notes = ?
+		WHERE id = ?`,
+		strings.TrimSpace(input.Name),
+		nullIfEmpty(input.Contact),
+		nullIfEmpty(input.Phone),
+		nullIfEmpty(input.Email),
+		input.LeadTimeDays,
+		nullIfEmpty(input.Notes),
+		id,
+	); err != nil {
+		return nil, fmt.Errorf("update supplier: %w", err)
+	}
+	return r.GetSupplier(ctx, id)
+}
+
+// AssignSupplier sets the supplier and lead-time override for a product. A zero
+// supplierID clears the assignment; a zero lead time defers to the supplier.
+func (r *PurchasingRepository) AssignSupplier(ctx context.Context, productID, supplierID, leadTimeDays int64) error {
+	if leadTimeDays < 0 {
+		return fmt.Errorf("lead time must be >= 0 (got %d)", leadTimeDays)
+	}
+	var supplier interface{}
+	if supplierID > 0 {
+		supplier = supplierID
+	}
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE products SET supplier_id = ?, lead_time_days = ? WHERE id = ?`,
+		supplier, leadTimeDays, productID,
+	)
+	if err != nil {
+		return fmt.Errorf("assign supplier: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errors.New("product not found")
+	}
+	return nil
+}
+
+// Demand returns stock, lead time and units sold since the given time for every stocked product.
+// Units sold inside kits count towards their components. Lead time resolves product override, then supplier, leaving zero for the policy default.
+func (r *PurchasingRepository) Demand(ctx context.Context, since time.Time) ([]purchasing.Demand, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			p.id,
+			p.name,
+			p.sku,
+			COALESCE(p.supplier_id, 0),
+			COALESCE(s.name, ''),
+			p.current_qty,
+			p.reorder_level,
+			CASE WHEN p.lead_time_days > 0 THEN p.lead_time_days ELSE COALESCE(s.lead_time_days, 0) END,
+			COALESCE((
+				SELECT SUM(si.qty - si.refunded_qty)
+				FROM sale_items si
+				INNER JOIN sales sa ON sa.id = si.sale_id
+				WHERE si.product_id = p.id AND sa.status IN ('Completed', 'PartiallyRefunded') AND sa.ts >= ?
+			), 0) + COALESCE((
+				SELECT SUM(c.qty * (si.qty - si.refunded_qty) / si.qty)
+				FROM sale_item_components c
+				INNER JOIN sale_items si ON si.id = c.sale_item_id
+				INNER JOIN sales sa ON sa.id = si.sale_id
+				WHERE c.product_id = p.id AND sa.status IN ('Completed', 'PartiallyRefunded') AND sa.ts >= ?
+			), 0)
+		FROM products p
+		LEFT JOIN suppliers s ON s.id = p.supplier_id
+		WHERE p.archived_at IS NULL AND p.is_kit = 0
+		ORDER BY COALESCE(s.name, ''), p.name`,
+		since.UnixMilli(), since.UnixMilli(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query demand: %w", err)
+	}
+	defer rows.Close()
+
+	var demand []purchasing.Demand
+	for rows.Next() {
+		var d purchasing.Demand
+		if err := rows.Scan(
+			&d.ProductID,
+			&d.ProductName,
+			&d.SKU,
+			&d.SupplierID,
+			&d.SupplierName,
+			&d.CurrentQty,
+			&d.ReorderLevel,
+			&d.LeadTimeDays,
+			&d.UnitsSold,
+		); err != nil {
+			return nil, fmt.Errorf("scan demand: %w", err)
+		}
+		demand = append(demand, d)
+	}
+	return demand, rows.Err()
+}
+
+// CreateDraftOrders creates one draft purchase order per supplier of the requested products.
+func (r *PurchasingRepository) CreateDraftOrders(ctx context.Context, lines []purchasing.DraftLine, note string) ([]purchasing.PurchaseOrder, error) {
+	if len(lines) == 0 {
+		return nil, errors.New("at least one line item required")
+	}
+	for i, line := range lines {
+		if err := line.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i, err)
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin purchase order tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	orderBySupplier := make(map[int64]int64)
+	var orderIDs []int64
+	for _, line := range lines {
+		var supplierID int64
+		if err = tx.QueryRowContext(ctx, `SELECT COALESCE(supplier_id, 0) FROM products WHERE id = ?`, line.ProductID).
+			Scan(&supplierID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err = fmt.Errorf("product %d not found", line.ProductID)
+			}
+			return nil, err
+		}
+
+		orderID, ok := orderBySupplier[supplierID]
+		if !ok {
+			var supplier interface{}
+			if supplierID > 0 {
+				supplier = supplierID
+			}
+			if err = tx.QueryRowContext(ctx, `
+				INSERT INTO purchase_orders (po_no, supplier_id, status, note)
+				VALUES ('', ?, ?, ?)
+				RETURNING id`,
+				supplier, purchasing.StatusDraft, nullIfEmpty(note),
+			).Scan(&orderID); err != nil {
+				return nil, fmt.Errorf("insert purchase order: %w", err)
+			}
+			if _, err = tx.ExecContext(ctx, `UPDATE purchase_orders SET po_no = ? WHERE id = ?`,
+				fmt.Sprintf("PO-%06d", orderID), orderID); err != nil {
+				return nil, fmt.Errorf("number purchase order: %w", err)
+			}
+			orderBySupplier[supplierID] = orderID
+			orderIDs = append(orderIDs, orderID)
+		}
+
+		if _, err = tx.ExecContext(ctx, `
+			INSERT INTO purchase_order_lines (po_id, product_id, qty) VALUES (?, ?, ?)`,
+			orderID, line.ProductID, line.Quantity,
+		); err != nil {
+			return nil, fmt.Errorf("insert purchase order line: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit purchase orders: %w", err)
+	}
+
+	orders := make([]purchasing.PurchaseOrder, 0, len(orderIDs))
+	for _, id := range orderIDs {
+		order, err := r.GetOrder(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+	return orders, nil
+}
+
+// GetOrder loads a purchase order with its lines.
+func (r *PurchasingRepository) GetOrder(ctx context.Context, id int64) (*purchasing.PurchaseOrder, error) {
+	order, err := scanOrder(r.db.QueryRowContext(ctx, `
+		SELECT po.id, po.po_no, COALESCE(po.supplier_id, 0), COALESCE(s.name, ''), po.status, po.created_at, po.note
+		FROM purchase_orders po
+		LEFT JOIN suppliers s ON s.id = po.supplier_id
+		WHERE po.id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pol.product_id, COALESCE(p.name, ''), COALESCE(p.sku, ''), pol.qty, pol.unit_cost_cents
+		FROM purchase_order_lines pol
+		LEFT JOIN products p ON p.id = pol.product_id
+		WHERE pol.po_id = ?
+		ORDER BY pol.id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query purchase order lines: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var line purchasing.OrderLine
+		if err := rows.Scan(&line.ProductID, &line.ProductName, &line.SKU, &line.Quantity, &line.UnitCostCents); err != nil {
+			return nil, fmt.Errorf("scan purchase order line: %w", err)
+		}
+		order.Lines = append(order.Lines, line)
+	}
+	return order, rows.Err()
+}
+
+// ListOrders returns purchase orders, optionally filtered by status, newest first.
+func (r *PurchasingRepository) ListOrders(ctx context.Context, status string, limit int) ([]purchasing.PurchaseOrder, error) {
+	query := `
+		SELECT po.id, po.po_no, COALESCE(po.supplier_id, 0), COALESCE(s.name, ''), po.status, po.created_at, po.note
+		FROM purchase_orders po
+		LEFT JOIN suppliers s ON s.id = po.supplier_id`
+	var args []interface{}
+	if status != "" {
+		query += ` WHERE po.status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY po.created_at DESC, po.id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query purchase orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]purchasing.PurchaseOrder, 0)
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+	return orders, rows.Err()
+}
+
+func scanSupplier(row rowScanner) (*purchasing.Supplier, error) {
+	var (
+		supplier                     purchasing.Supplier
+		contact, phone, email, notes sql.NullString
+		created                      int64
+	)
+	if err := row.Scan(&supplier.ID, &supplier.Name, &contact, &phone, &email, &supplier.LeadTimeDays, &notes, &created); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("supplier not found: %w", err)
+		}
+		return nil, fmt.Errorf("scan supplier: %w", err)
+	}
+	supplier.Contact = contact.String
+	supplier.Phone = phone.String
+	supplier.Email = email.String
+	supplier.Notes = notes.String
+	supplier.CreatedAt = time.UnixMilli(created).UTC()
+	return &supplier, nil
+}
+
+func scanOrder(row rowScanner) (*purchasing.PurchaseOrder, error) {
+	var (
+		order   purchasing.PurchaseOrder
+		created int64
+		note    sql.NullString
+	)
+	if err := row.Scan(&order.ID, &order.OrderNumber, &order.SupplierID, &order.SupplierName, &order.Status, &created, &note); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("purchase order not found: %w", err)
+		}
+		return nil, fmt.Errorf("scan purchase order: %w", err)
+	}
+	order.CreatedAt = time.UnixMilli(created).UTC()
+	order.Note = note.String
+	return &order, nil
+}
@@ -0,0 +1,357 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/domain/quote"
+	"shopmate/internal/domain/sale"
+	"shopmate/internal/domain/sequence"
+)
+
+// QuoteRepository stores quotes and converts them into sales.
+type QuoteRepository struct {
+	db *sql.DB
+}
+
+// NewQuoteRepository constructs a repository.
+func NewQuoteRepository(db *sql.DB) *QuoteRepository {
+	return &QuoteRepository{db: db}
+}
+
+// Create saves a draft quote under the next quote number.
+func (r *QuoteRepository) Create(ctx context.Context, draft quote.Draft, createdAt time.Time) (*quote.Quote, error) {
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin quote tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if draft.CustomerID > 0 {
+		if draft.CustomerName, err = customerName(ctx, tx, draft.CustomerID); err != nil {
+			return nil, err
+		}
+	}
+
+	var number string
+	if number, err = nextNumber(ctx, tx, sequence.Quote, createdAt, func(number string) (bool, error) {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM quotes WHERE quote_no = ?)`, number).Scan(&exists); err != nil {
+			return false, fmt.Errorf("check quote number: %w", err)
+		}
+		return exists, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	var quoteID int64
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO quotes (quote_no, customer_id, customer_name, status, created_at, valid_until, subtotal_cents, discount_cents, tax_cents, total_cents, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		number, nullIfZero(draft.CustomerID), nullIfEmpty(draft.CustomerName), quote.StatusDraft, createdAt.UnixMilli(), draft.ValidUntil.UnixMilli(),
+		draft.SubtotalCents, draft.DiscountCents, draft.TaxCents, draft.TotalCents, nullIfEmpty(draft.Note),
+	).Scan(&quoteID); err != nil {
+		return nil, fmt.Errorf("insert quote: %w", err)
+	}
+	if err = insertQuoteLines(ctx, tx, quoteID, draft.Lines); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit quote: %w", err)
+	}
+	return r.Get(ctx, quoteID)
+}
+
+// Update replaces a draft quote's lines, totals and validity. Quotes that have been sent
+// are no longer edited.
+func (r *QuoteRepository) Update(ctx context.Context, id int64, draft quote.Draft) (*quote.Quote, error) {
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin quote tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var current *quote.Quote
+	if current, err = lockQuote(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	if current.Status != quote.StatusDraft {
+		err = fmt.Errorf("quote %s is %s and can no longer be edited", current.QuoteNumber, current.Status)
+		return nil, err
+	}
+	if draft.CustomerID > 0 {
+		if draft.CustomerName, err = customerName(ctx, tx, draft.CustomerID); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE quotes
+		SET customer_id = ?, customer_name = ?, valid_until = ?, subtotal_cents = ?, discount_cents = ?, tax_cents = ?, total_cents = ?, note = ?
+		WHERE id = ?`,
+		nullIfZero(draft.CustomerID), nullIfEmpty(draft.CustomerName), draft.ValidUntil.UnixMilli(), draft.SubtotalCents, draft.DiscountCents,
+		draft.TaxCents, draft.TotalCents, nullIfEmpty(draft.Note), id,
+	); err != nil {
+		return nil, fmt.Errorf("update quote: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM quote_items WHERE quote_id = ?`, id); err != nil {
+		return nil, fmt.Errorf("clear quote lines: %w", err)
+	}
+	if err = insertQuoteLines(ctx, tx, id, draft.Lines); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit quote: %w", err)
+	}
+	return r.Get(ctx, id)
+}
+
+func insertQuoteLines(ctx context.Context, tx *sql.Tx, quoteID int64, lines []quote.Line) error {
+	for _, line := range lines {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO quote_items (quote_id, product_id, product_name, sku, qty, unit_price_cents, tax_rate_bp, line_subtotal_cents, line_discount_cents, line_tax_cents, line_total_cents)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			quoteID, line.ProductID, line.ProductName, line.SKU, line.Quantity, line.UnitPriceCents, line.TaxRateBasisPoints,
+			line.LineSubtotalCents, line.LineDiscountCents, line.LineTaxCents, line.LineTotalCents,
+		); err != nil {
+			return fmt.Errorf("insert quote line: %w", err)
+		}
+	}
+	return nil
+}
+
+// lockQuote loads a quote's header inside tx.
+func lockQuote(ctx context.Context, tx *sql.Tx, id int64) (*quote.Quote, error) {
+	var (
+		q          quote.Quote
+		validUntil int64
+	)
+	if err := tx.QueryRowContext(ctx, `SELECT id, quote_no, status, valid_until FROM quotes WHERE id = ?`, id).
+		Scan(&q.ID, &q.QuoteNumber, &q.Status, &validUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("quote %d not found", id)
+		}
+		return nil, fmt.Errorf("load quote: %w", err)
+	}
+	q.ValidUntil = time.UnixMilli(validUntil).UTC()
+	return &q, nil
+}
+
+// Get retrieves a quote with its lines.
+func (r *QuoteRepository) Get(ctx context.Context, id int64) (*quote.Quote, error) {
+	quotes, err := r.query(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(quotes) == 0 {
+		return nil, fmt.Errorf("quote %d not found", id)
+	}
+	return &quotes[0], nil
+}
+
+// List returns quotes newest first, optionally only those with the given status.
+func (r *QuoteRepository) List(ctx context.Context, status string) ([]quote.Quote, error) {
+	if status == "" {
+		return r.query(ctx, `ORDER BY created_at DESC, id DESC`)
+	}
+	return r.query(ctx, `WHERE status = ? ORDER BY created_at DESC, id DESC`, status)
+}
+
+func (r *QuoteRepository) query(ctx context.Context, where string, args ...interface{}) ([]quote.Quote, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, quote_no, COALESCE(customer_id, 0), COALESCE(customer_name, ''), status, created_at, valid_until, sent_at, accepted_at,
+			COALESCE(sale_id, 0), subtotal_cents, discount_cents, tax_cents, total_cents, COALESCE(note, '')
+		FROM quotes `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query quotes: %w", err)
+	}
+	defer rows.Close()
+
+	quotes := make([]quote.Quote, 0)
+	for rows.Next() {
+		var (
+			q                     quote.Quote
+			createdAt, validUntil int64
+			sentAt, acceptedAt    sql.NullInt64
+		)
+		if err := rows.Scan(&q.ID, &q.QuoteNumber, &q.CustomerID, &q.CustomerName, &q.Status, &createdAt, &validUntil, &sentAt, &acceptedAt,
+			&q.SaleID, &q.SubtotalCents, &q.DiscountCents, &q.TaxCents, &q.TotalCents, &q.Note); err != nil {
+			return nil, fmt.Errorf("scan quote: %w", err)
+		}
+		q.CreatedAt = time.UnixMilli(createdAt).UTC()
+		q.ValidUntil = time.UnixMilli(validUntil).UTC()
+		if sentAt.Valid {
+			t := time.UnixMilli(sentAt.Int64).UTC()
+			q.SentAt = &t
+		}
+		if acceptedAt.Valid {
+			t := time.UnixMilli(acceptedAt.Int64).UTC()
+			q.AcceptedAt = &t
+		}
+		quotes = append(quotes, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range quotes {
+		if quotes[i].Lines, err = r.lines(ctx, quotes[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return quotes, nil
+}
+
+func (r *QuoteRepository) lines(ctx context.Context, quoteID int64) ([]quote.Line, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, product_id, product_name, sku, qty, unit_price_cents, tax_rate_bp, line_subtotal_cents, line_discount_cents, line_tax_cents, line_total_cents
+		FROM quote_items
+		WHERE quote_id = ?
+		ORDER BY id`, quoteID)
+	if err != nil {
+		return nil, fmt.Errorf("query quote lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []quote.Line
+	for rows.Next() {
+		var line quote.Line
+		if err := rows.Scan(&line.ID, &line.ProductID, &line.ProductName, &line.SKU, &line.Quantity, &line.UnitPriceCents,
+			&line.TaxRateBasisPoints, &line.LineSubtotalCents, &line.LineDiscountCents, &line.LineTaxCents, &line.LineTotalCents); err != nil {
+			return nil, fmt.Errorf("scan quote line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// MarkSent records that an open quote was sent to the customer. Sending it again updates
+// the date sent; a lapsed quote is marked Expired instead.
+func (r *QuoteRepository) MarkSent(ctx context.Context, id int64, now time.Time) (*quote.Quote, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin quote tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var current *quote.Quote
+	if current, err = lockQuote(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	if !current.Open() {
+		err = fmt.Errorf("quote %s is %s and can no longer be sent", current.QuoteNumber, current.Status)
+		return nil, err
+	}
+	if current.Lapsed(now) {
+		if err = expireQuote(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit quote: %w", err)
+		}
+		return nil, fmt.Errorf("quote %s expired on %s", current.QuoteNumber, current.ValidUntil.Format("2006-01-02"))
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE quotes SET status = ?, sent_at = ? WHERE id = ?`, quote.StatusSent, now.UnixMilli(), id); err != nil {
+		return nil, fmt.Errorf("mark quote sent: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit quote: %w", err)
+	}
+	return r.Get(ctx, id)
+}
+
+// Convert sells an open quote: draft is saved as a sale and the quote is marked Accepted
+// and linked to it in the same transaction, so a quote is converted at most once. A lapsed
+// quote is marked Expired instead.
+func (r *QuoteRepository) Convert(ctx context.Context, id int64, draft sale.Sale, now time.Time) (*sale.Sale, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin quote tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var current *quote.Quote
+	if current, err = lockQuote(ctx, tx, id); err != nil {
+		return nil, err
+	}
+	if !current.Open() {
+		err = fmt.Errorf("quote %s is %s and can no longer be converted", current.QuoteNumber, current.Status)
+		return nil, err
+	}
+	if current.Lapsed(now) {
+		if err = expireQuote(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		if err = tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit quote: %w", err)
+		}
+		return nil, fmt.Errorf("quote %s expired on %s", current.QuoteNumber, current.ValidUntil.Format("2006-01-02"))
+	}
+
+	if err = insertSale(ctx, tx, &draft); err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, `UPDATE quotes SET status = ?, accepted_at = ?, sale_id = ? WHERE id = ?`,
+		quote.StatusAccepted, now.UnixMilli(), draft.ID, id,
+	); err != nil {
+		return nil, fmt.Errorf("accept quote: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit quote conversion: %w", err)
+	}
+	return &draft, nil
+}
+
+func expireQuote(ctx context.Context, tx *sql.Tx, id int64) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE quotes SET status = ? WHERE id = ?`, quote.StatusExpired, id); err != nil {
+		return fmt.Errorf("expire quote: %w", err)
+	}
+	return nil
+}
+
+// ExpireDue marks open quotes past their validity date as Expired and returns how many
+// were expired.
+func (r *QuoteRepository) ExpireDue(ctx context.Context, now time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE quotes SET status = ? WHERE status IN (?, ?) AND valid_until <= ?`,
+		quote.StatusExpired, quote.StatusDraft, quote.StatusSent, now.UnixMilli(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("expire quotes: %w", err)
+	}
+	expired, _ := res.RowsAffected()
+	return int(expired), nil
+}
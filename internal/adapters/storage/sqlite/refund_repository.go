@@ -0,0 +1,412 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/product"
+	"shopmate/internal/domain/sale"
+	"shopmate/internal/domain/sequence"
+)
+
+// CreateRefund records a credit note for the chosen lines of a sale, restores their stock
+// and serialised units, and moves the sale to PartiallyRefunded or, once every unit is
+// back, Refunded. Each line's cumulative refunds are capped at the quantity sold.
+func (r *SaleRepository) CreateRefund(ctx context.Context, draft sale.RefundDraft) (*sale.Refund, error) {
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin refund tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var refund *sale.Refund
+	if refund, err = insertRefund(ctx, tx, draft); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit refund: %w", err)
+	}
+	return refund, nil
+}
+
+// insertRefund writes a credit note for draft inside tx, restocking the returned units, taking
+// back the points they earned, crediting gift cards or store credit and updating the sale's
+// status.
+func insertRefund(ctx context.Context, tx *sql.Tx, draft sale.RefundDraft) (*sale.Refund, error) {
+	ts := draft.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	tsMillis := ts.UnixMilli()
+
+	refund := sale.Refund{SaleID: draft.SaleID, Reason: draft.Reason, PaymentMethod: draft.PaymentMethod}
+	var (
+		status        string
+		orderDiscount int64
+		locationID    sql.NullInt64
+		salePayment   string
+		err           error
+	)
+	if err = tx.QueryRowContext(ctx, `
+		SELECT sale_no, status, discount_cents, payment_method, location_id, tax_included FROM sales WHERE id = ?`, draft.SaleID,
+	).Scan(&refund.SaleNumber, &status, &orderDiscount, &salePayment, &locationID, &refund.TaxIncluded); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("sale %d not found", draft.SaleID)
+		}
+		return nil, fmt.Errorf("load sale: %w", err)
+	}
+	if status != "Completed" && status != "PartiallyRefunded" {
+		return nil, fmt.Errorf("sale %s is %s and cannot be refunded", refund.SaleNumber, status)
+	}
+	if refund.PaymentMethod == "" {
+		if salePayment == sale.PaymentSplit {
+			return nil, fmt.Errorf("sale %s was paid with several methods; choose a payment method for the refund", refund.SaleNumber)
+		}
+		refund.PaymentMethod = salePayment
+	}
+	refund.LocationID = locationID.Int64
+	if !locationID.Valid {
+		if refund.LocationID, err = defaultLocationID(ctx, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	var lines []sale.Line
+	if lines, err = refundableLines(ctx, tx, draft.SaleID); err != nil {
+		return nil, err
+	}
+	shares := sale.AllocateOrderDiscount(orderDiscount, lines)
+
+	if refund.RefundNumber, err = nextNumber(ctx, tx, sequence.CreditNote, time.UnixMilli(tsMillis), func(number string) (bool, error) {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM refunds WHERE refund_no = ?)`, number).Scan(&exists); err != nil {
+			return false, fmt.Errorf("check refund number: %w", err)
+		}
+		return exists, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO refunds (refund_no, sale_id, ts, payment_method, reason, location_id)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		refund.RefundNumber, draft.SaleID, tsMillis, refund.PaymentMethod, nullIfEmpty(draft.Reason), refund.LocationID,
+	).Scan(&refund.ID); err != nil {
+		return nil, fmt.Errorf("insert refund: %w", err)
+	}
+
+	for _, ret := range draft.Lines {
+		i := slices.IndexFunc(lines, func(l sale.Line) bool { return l.ID == ret.SaleLineID })
+		if i < 0 {
+			return nil, fmt.Errorf("sale line %d is not on sale %s", ret.SaleLineID, refund.SaleNumber)
+		}
+		var line *sale.RefundLine
+		if line, err = returnLine(ctx, tx, refund, lines[i], shares[i], ret, tsMillis); err != nil {
+			return nil, err
+		}
+		refund.Lines = append(refund.Lines, *line)
+	}
+
+	refund.Totals()
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE refunds SET subtotal_cents = ?, discount_cents = ?, tax_cents = ?, total_cents = ? WHERE id = ?`,
+		refund.SubtotalCents, refund.DiscountCents, refund.TaxCents, refund.TotalCents, refund.ID,
+	); err != nil {
+		return nil, fmt.Errorf("update refund totals: %w", err)
+	}
+	if err = returnRefundPoints(ctx, tx, &refund, tsMillis); err != nil {
+		return nil, err
+	}
+	if err = refundStoredValue(ctx, tx, &refund, draft.Reference, tsMillis); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE sales
+		SET status = CASE
+			WHEN EXISTS (SELECT 1 FROM sale_items WHERE sale_id = sales.id AND refunded_qty < qty) THEN 'PartiallyRefunded'
+			ELSE 'Refunded'
+		END
+		WHERE id = ?`, draft.SaleID,
+	); err != nil {
+		return nil, fmt.Errorf("update sale status: %w", err)
+	}
+
+	refund.Timestamp = time.UnixMilli(tsMillis).UTC()
+	return &refund, nil
+}
+
+// Refunds lists a sale's refunds, oldest first.
+func (r *SaleRepository) Refunds(ctx context.Context, saleID int64) ([]sale.Refund, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rf.id, rf.refund_no, rf.sale_id, s.sale_no, rf.ts, rf.payment_method, COALESCE(rf.reason, ''),
+			rf.subtotal_cents, rf.discount_cents, rf.tax_cents, rf.total_cents, COALESCE(rf.location_id, 0),
+			COALESCE(rf.reference, ''), s.tax_included
+		FROM refunds rf
+		INNER JOIN sales s ON s.id = rf.sale_id
+		WHERE rf.sale_id = ?
+		ORDER BY rf.ts, rf.id`, saleID)
+	if err != nil {
+		return nil, fmt.Errorf("query refunds: %w", err)
+	}
+	defer rows.Close()
+
+	refunds := make([]sale.Refund, 0)
+	for rows.Next() {
+		var (
+			rf       sale.Refund
+			tsMillis int64
+		)
+		if err := rows.Scan(&rf.ID, &rf.RefundNumber, &rf.SaleID, &rf.SaleNumber, &tsMillis, &rf.PaymentMethod, &rf.Reason,
+			&rf.SubtotalCents, &rf.DiscountCents, &rf.TaxCents, &rf.TotalCents, &rf.LocationID, &rf.Reference, &rf.TaxIncluded); err != nil {
+			return nil, fmt.Errorf("scan refund: %w", err)
+		}
+		rf.Timestamp = time.UnixMilli(tsMillis).UTC()
+		refunds = append(refunds, rf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range refunds {
+		if refunds[i].Lines, err = r.refundLines(ctx, refunds[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return refunds, nil
+}
+
+func (r *SaleRepository) refundLines(ctx context.Context, refundID int64) ([]sale.RefundLine, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ri.sale_item_id, ri.product_id,
+			COALESCE(NULLIF(si.product_name, ''), p.name, ''), COALESCE(NULLIF(si.sku, ''), p.sku, ''),
+			ri.qty, ri.subtotal_cents, ri.line_discount_cents, ri.order_discount_cents, ri.tax_cents, ri.total_cents, ri.cost_cents,
+			COALESCE((
+				SELECT GROUP_CONCAT(ps.serial_no, char(31))
+				FROM refund_item_serials ris
+				INNER JOIN product_serials ps ON ps.id = ris.serial_id
+				WHERE ris.refund_item_id = ri.id
+			), '')
+		FROM refund_items ri
+		INNER JOIN sale_items si ON si.id = ri.sale_item_id
+		LEFT JOIN products p ON p.id = ri.product_id
+		WHERE ri.refund_id = ?
+		ORDER BY ri.id`, refundID)
+	if err != nil {
+		return nil, fmt.Errorf("query refund lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []sale.RefundLine
+	for rows.Next() {
+		var (
+			line    sale.RefundLine
+			serials string
+		)
+		if err := rows.Scan(&line.SaleLineID, &line.ProductID, &line.ProductName, &line.SKU,
+			&line.Quantity, &line.SubtotalCents, &line.LineDiscountCents, &line.OrderDiscountCents,
+			&line.TaxCents, &line.TotalCents, &line.CostCents, &serials); err != nil {
+			return nil, fmt.Errorf("scan refund line: %w", err)
+		}
+		if serials != "" {
+			line.SerialNumbers = strings.Split(serials, "\x1f")
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// refundableLines loads a sale's lines inside tx for pricing a refund.
+func refundableLines(ctx context.Context, tx *sql.Tx, saleID int64) ([]sale.Line, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT si.id, si.product_id, COALESCE(NULLIF(si.product_name, ''), p.name, ''), COALESCE(NULLIF(si.sku, ''), p.sku, ''),
+			si.qty, si.unit_price_cents, si.tax_rate_bp, si.line_subtotal_cents, si.line_discount_cents,
+			si.promotion_discount_cents, si.line_tax_cents, si.line_total_cents, si.cost_cents, si.refunded_qty
+		FROM sale_items si
+		LEFT JOIN products p ON p.id = si.product_id
+		WHERE si.sale_id = ?
+		ORDER BY si.id`, saleID)
+	if err != nil {
+		return nil, fmt.Errorf("query sale lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []sale.Line
+	for rows.Next() {
+		var line sale.Line
+		if err := rows.Scan(&line.ID, &line.ProductID, &line.ProductName, &line.SKU, &line.Quantity,
+			&line.UnitPriceCents, &line.TaxRateBasisPoints, &line.LineSubtotalCents, &line.LineDiscountCents,
+			&line.PromotionDiscountCents, &line.LineTaxCents, &line.LineTotalCents, &line.CostCents, &line.RefundedQty); err != nil {
+			return nil, fmt.Errorf("scan sale line: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// returnLine records the return of ret against a sale line: it prices the units, restores
+// their stock (or their kit components') at the cost they left with, takes them off any
+// backorder still owed, returns serialised units to stock and writes the refund item.
+func returnLine(ctx context.Context, tx *sql.Tx, refund sale.Refund, line sale.Line, orderDiscount int64, ret sale.ReturnLine, tsMillis int64) (*sale.RefundLine, error) {
+	remaining := line.Quantity - line.RefundedQty
+	if ret.Quantity > remaining {
+		return nil, fmt.Errorf("cannot refund %d of %s; %d of %d left to refund", ret.Quantity, line.SKU, remaining, line.Quantity)
+	}
+	res, err := tx.ExecContext(ctx, `
+		UPDATE sale_items SET refunded_qty = refunded_qty + ? WHERE id = ? AND refunded_qty + ? <= qty`,
+		ret.Quantity, line.ID, ret.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("record refunded quantity: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return nil, fmt.Errorf("cannot refund %d of %s; only %d left to refund", ret.Quantity, line.SKU, remaining)
+	}
+
+	refundLine := sale.ProrateReturn(line, orderDiscount, line.RefundedQty, ret.Quantity)
+	refundLine.SaleLineID = line.ID
+
+	restock := func(productID, qty, cost int64) error {
+		if err := restockAtCost(ctx, tx, productID, refund.LocationID, qty, cost, tsMillis, "Refund", refund.RefundNumber); err != nil {
+			return err
+		}
+		return reduceBackorder(ctx, tx, line.ID, productID, qty, tsMillis)
+	}
+
+	components, err := tx.QueryContext(ctx, `SELECT product_id, qty, cost_cents FROM sale_item_components WHERE sale_item_id = ? ORDER BY product_id`, line.ID)
+	if err != nil {
+		return nil, fmt.Errorf("load kit components: %w", err)
+	}
+	type component struct{ productID, qty, cost int64 }
+	var consumed []component
+	for components.Next() {
+		var c component
+		if err := components.Scan(&c.productID, &c.qty, &c.cost); err != nil {
+			components.Close()
+			return nil, fmt.Errorf("scan kit component: %w", err)
+		}
+		consumed = append(consumed, c)
+	}
+	components.Close()
+	if err := components.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(consumed) > 0 {
+		// Kit lines restore their share of the components consumed at sale time.
+		refundLine.CostCents = 0
+		for _, c := range consumed {
+			qty := sale.Prorate(c.qty, line.Quantity, line.RefundedQty, ret.Quantity)
+			cost := sale.Prorate(c.cost, line.Quantity, line.RefundedQty, ret.Quantity)
+			if err := restock(c.productID, qty, cost); err != nil {
+				return nil, err
+			}
+			refundLine.CostCents += cost
+		}
+	} else if err := restock(line.ProductID, ret.Quantity, refundLine.CostCents); err != nil {
+		return nil, err
+	}
+
+	var itemID int64
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO refund_items (refund_id, sale_item_id, product_id, qty, subtotal_cents, line_discount_cents, order_discount_cents, tax_cents, total_cents, cost_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		refund.ID, line.ID, line.ProductID, refundLine.Quantity, refundLine.SubtotalCents, refundLine.LineDiscountCents,
+		refundLine.OrderDiscountCents, refundLine.TaxCents, refundLine.TotalCents, refundLine.CostCents,
+	).Scan(&itemID); err != nil {
+		return nil, fmt.Errorf("insert refund line: %w", err)
+	}
+
+	if refundLine.SerialNumbers, err = returnSerials(ctx, tx, line, itemID, ret); err != nil {
+		return nil, err
+	}
+	return &refundLine, nil
+}
+
+// returnSerials puts the serialised units named by ret back in stock and links them to the
+// refund line. When none are named, every unit still out is returned, provided that is the
+// quantity being refunded.
+func returnSerials(ctx context.Context, tx *sql.Tx, line sale.Line, refundItemID int64, ret sale.ReturnLine) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT ps.id, ps.serial_no
+		FROM sale_item_serials sis
+		INNER JOIN product_serials ps ON ps.id = sis.serial_id
+		WHERE sis.sale_item_id = ?
+			AND sis.serial_id NOT IN (
+				SELECT ris.serial_id
+				FROM refund_item_serials ris
+				INNER JOIN refund_items ri ON ri.id = ris.refund_item_id
+				WHERE ri.sale_item_id = ?
+			)
+		ORDER BY ps.serial_no`, line.ID, line.ID)
+	if err != nil {
+		return nil, fmt.Errorf("query sold serials: %w", err)
+	}
+	outstanding := make(map[string]int64)
+	var all []string
+	for rows.Next() {
+		var (
+			id     int64
+			serial string
+		)
+		if err := rows.Scan(&id, &serial); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan sold serial: %w", err)
+		}
+		outstanding[serial] = id
+		all = append(all, serial)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(all) == 0 {
+		if len(ret.SerialNumbers) > 0 {
+			return nil, fmt.Errorf("%s was not sold with serial numbers", line.SKU)
+		}
+		return nil, nil
+	}
+
+	returned := all
+	if len(ret.SerialNumbers) > 0 || int64(len(all)) != ret.Quantity {
+		if returned, err = product.NormalizeSerials(ret.SerialNumbers); err != nil {
+			return nil, fmt.Errorf("%s: %w", line.SKU, err)
+		}
+		if err := product.CheckSerialCount(returned, ret.Quantity); err != nil {
+			return nil, fmt.Errorf("%s: %w", line.SKU, err)
+		}
+	}
+
+	for _, serial := range returned {
+		id, ok := outstanding[serial]
+		if !ok {
+			return nil, fmt.Errorf("serial %s was not sold on this line or is already returned", serial)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE product_serials SET status = ? WHERE id = ?`, product.SerialStatusInStock, id); err != nil {
+			return nil, fmt.Errorf("restock serial %s: %w", serial, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO refund_item_serials (refund_item_id, serial_id) VALUES (?, ?)`, refundItemID, id,
+		); err != nil {
+			return nil, fmt.Errorf("link returned serial %s: %w", serial, err)
+		}
+	}
+	return returned, nil
+}
@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"time"
 
+	"shopmate/internal/domain/product"
 	"shopmate/internal/domain/report"
 )
 
+// refundedLineTotal is the part of a sale line's total returned by refunds.
+const refundedLineTotal = `COALESCE((SELECT SUM(ri.total_cents) FROM refund_items ri WHERE ri.sale_item_id = sale_items.id), 0)`
+
 // ReportRepository exposes aggregate reporting queries.
 type ReportRepository struct {
 	db *sql.DB
@@ -29,8 +33,12 @@ func (r *ReportRepository) DailySummary(ctx context.Context, date time.Time) (*r
 	var summary report.DailySummary
 	summary.Date = start
 
-	row := r.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(total_cents),0), COUNT(id), COALESCE(SUM(tax_cents),0)
-		FROM sales WHERE status = 'Completed' AND ts >= ? AND ts < ?`, startMillis, endMillis)
+	// Partially refunded sales count net of their refunds.
+	row := r.db.QueryRowContext(ctx, `SELECT
+			COALESCE(SUM(total_cents - COALESCE((SELECT SUM(rf.total_cents) FROM refunds rf WHERE rf.sale_id = sales.id), 0)), 0),
+			COUNT(id),
+			COALESCE(SUM(tax_cents - COALESCE((SELECT SUM(rf.tax_cents) FROM refunds rf WHERE rf.sale_id = sales.id), 0)), 0)
+		FROM sales WHERE status IN ('Completed', 'PartiallyRefunded') AND ts >= ? AND ts < ?`, startMillis, endMillis)
 
 	var total, count, tax int64
 	if err := row.Scan(&total, &count, &tax); err != nil {
@@ -51,13 +59,14 @@ func (r *ReportRepository) TopProducts(ctx context.Context, from, to time.Time,
 	fromMillis := from.UnixMilli()
 	toMillis := to.UnixMilli()
 
-	rows, err := r.db.QueryContext(ctx, `SELECT sale_items.product_id, COALESCE(MAX(products.name), ''), SUM(sale_items.qty), SUM(sale_items.line_total_cents)
+	rows, err := r.db.QueryContext(ctx, `SELECT sale_items.product_id, COALESCE(NULLIF(MAX(sale_items.product_name), ''), MAX(products.name), ''),
+			SUM(sale_items.qty - sale_items.refunded_qty), SUM(sale_items.line_total_cents - `+refundedLineTotal+`) AS revenue
 		FROM sale_items
 		INNER JOIN sales ON sales.id = sale_items.sale_id
 		LEFT JOIN products ON products.id = sale_items.product_id
-		WHERE sales.status = 'Completed' AND sales.ts >= ? AND sales.ts < ?
+		WHERE sales.status IN ('Completed', 'PartiallyRefunded') AND sales.ts >= ? AND sales.ts < ?
 		GROUP BY sale_items.product_id
-		ORDER BY SUM(sale_items.line_total_cents) DESC
+		ORDER BY revenue DESC
 		LIMIT ?`, fromMillis, toMillis, limit)
 	if err != nil {
 		return nil, fmt.Errorf("query top products: %w", err)
@@ -75,3 +84,209 @@ func (r *ReportRepository) TopProducts(ctx context.Context, from, to time.Time,
 
 	return tops, rows.Err()
 }
+
+// CategorySales totals completed sales for each direct child of parentID (top-level categories
+// when zero), rolling up every descendant category. At the top level, products without a
+// category are reported under id 0.
+func (r *ReportRepository) CategorySales(ctx context.Context, from, to time.Time, parentID int64) ([]report.CategorySales, error) {
+	rows, err := r.db.QueryContext(ctx, categoryTreeCTE+`,
+		rollup(id, root_id) AS (
+			SELECT id, id FROM categories WHERE COALESCE(parent_id, 0) = ?
+			UNION ALL
+			SELECT c.id, rollup.root_id FROM categories c INNER JOIN rollup ON c.parent_id = rollup.id
+		),
+		totals(root_id, qty, revenue) AS (
+			SELECT COALESCE(rollup.root_id, 0), SUM(sale_items.qty - sale_items.refunded_qty), SUM(sale_items.line_total_cents - `+refundedLineTotal+`)
+			FROM sale_items
+			INNER JOIN sales ON sales.id = sale_items.sale_id
+			LEFT JOIN products ON products.id = sale_items.product_id
+			LEFT JOIN rollup ON rollup.id = products.category_id
+			WHERE sales.status IN ('Completed', 'PartiallyRefunded') AND sales.ts >= ? AND sales.ts < ?
+				AND (rollup.root_id IS NOT NULL OR (? = 0 AND products.category_id IS NULL))
+			GROUP BY COALESCE(rollup.root_id, 0)
+		)
+		SELECT totals.root_id, COALESCE(category_tree.path, 'Uncategorised'), totals.qty, totals.revenue
+		FROM totals
+		LEFT JOIN category_tree ON category_tree.id = totals.root_id
+		ORDER BY totals.revenue DESC`,
+		parentID, from.UnixMilli(), to.UnixMilli(), parentID)
+	if err != nil {
+		return nil, fmt.Errorf("query category sales: %w", err)
+	}
+	defer rows.Close()
+
+	var results []report.CategorySales
+	for rows.Next() {
+		var cs report.CategorySales
+		if err := rows.Scan(&cs.CategoryID, &cs.CategoryPath, &cs.QuantitySold, &cs.RevenueCents); err != nil {
+			return nil, fmt.Errorf("scan category sales: %w", err)
+		}
+		results = append(results, cs)
+	}
+
+	return results, rows.Err()
+}
+
+// Valuation values stock as of the given time from the stock ledger: quantities are worked
+// back from current stock and values are the sum of movement values up to that time.
+func (r *ReportRepository) Valuation(ctx context.Context, asOf time.Time) (*report.Valuation, error) {
+	costing, err := loadCosting(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	asOfMillis := asOf.UnixMilli()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, sku, name, category, qty, value
+		FROM (
+			SELECT
+				p.id,
+				p.sku,
+				p.name,
+				p.category,
+				p.current_qty - COALESCE((SELECT SUM(m.delta) FROM stock_movements m WHERE m.product_id = p.id AND m.ts > ?), 0) AS qty,
+				COALESCE((SELECT SUM(m.value_cents) FROM stock_movements m WHERE m.product_id = p.id AND m.ts <= ?), 0) AS value
+			FROM products p
+			WHERE p.is_kit = 0
+		)
+		WHERE qty <> 0 OR value <> 0
+		ORDER BY name`, asOfMillis, asOfMillis)
+	if err != nil {
+		return nil, fmt.Errorf("query valuation: %w", err)
+	}
+	defer rows.Close()
+
+	valuation := report.Valuation{AsOf: asOf, Method: costing.Method, Lines: make([]report.ValuationLine, 0)}
+	for rows.Next() {
+		var line report.ValuationLine
+		if err := rows.Scan(&line.ProductID, &line.SKU, &line.ProductName, &line.Category, &line.Qty, &line.ValueCents); err != nil {
+			return nil, fmt.Errorf("scan valuation: %w", err)
+		}
+		if line.Qty > 0 {
+			line.UnitCostCents = line.ValueCents / line.Qty
+		}
+		valuation.TotalQty += line.Qty
+		valuation.TotalValueCents += line.ValueCents
+		valuation.Lines = append(valuation.Lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &valuation, nil
+}
+
+// NegativeStock lists stock positions below zero, most negative first, with each product's
+// effective negative-stock policy and open backorders at that location.
+func (r *ReportRepository) NegativeStock(ctx context.Context) ([]report.NegativeStockLine, error) {
+	policy, err := loadStockPolicy(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			p.id,
+			p.sku,
+			p.name,
+			l.id,
+			l.name,
+			ps.qty,
+			COALESCE(p.negative_stock_policy, ''),
+			COALESCE((
+				SELECT SUM(b.qty) FROM backorders b
+				INNER JOIN sales s ON s.id = b.sale_id
+				WHERE b.product_id = p.id AND b.location_id = l.id AND b.fulfilled_at IS NULL
+					AND b.cancelled_at IS NULL AND s.status <> 'Voided'
+			), 0)
+		FROM product_stock ps
+		INNER JOIN products p ON p.id = ps.product_id
+		INNER JOIN locations l ON l.id = ps.location_id
+		WHERE ps.qty < 0
+		ORDER BY ps.qty, p.name`)
+	if err != nil {
+		return nil, fmt.Errorf("query negative stock: %w", err)
+	}
+	defer rows.Close()
+
+	lines := make([]report.NegativeStockLine, 0)
+	for rows.Next() {
+		var line report.NegativeStockLine
+		if err := rows.Scan(&line.ProductID, &line.SKU, &line.ProductName, &line.LocationID, &line.LocationName,
+			&line.Qty, &line.Policy, &line.BackorderedQty); err != nil {
+			return nil, fmt.Errorf("scan negative stock: %w", err)
+		}
+		line.Policy = product.EffectiveNegativeStockPolicy(line.Policy, policy.NegativeStock)
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// PaymentBreakdown totals tenders by payment method for sales made in the range, less the
+// refunds paid out in the range. Voided sales took no payment.
+func (r *ReportRepository) PaymentBreakdown(ctx context.Context, from, to time.Time) ([]report.PaymentTotal, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH taken(method, sales, tendered, applied) AS (
+			SELECT sp.method, COUNT(DISTINCT sp.sale_id), SUM(sp.tendered_cents), SUM(sp.applied_cents)
+			FROM sale_payments sp
+			INNER JOIN sales s ON s.id = sp.sale_id
+			WHERE s.status != 'Voided' AND s.ts >= ? AND s.ts < ?
+			GROUP BY sp.method
+		),
+		refunded(method, total) AS (
+			SELECT payment_method, SUM(total_cents) FROM refunds WHERE ts >= ? AND ts < ? GROUP BY payment_method
+		),
+		methods(method) AS (
+			SELECT method FROM taken UNION SELECT method FROM refunded
+		)
+		SELECT methods.method, COALESCE(taken.sales, 0), COALESCE(taken.tendered, 0), COALESCE(taken.applied, 0), COALESCE(refunded.total, 0)
+		FROM methods
+		LEFT JOIN taken ON taken.method = methods.method
+		LEFT JOIN refunded ON refunded.method = methods.method
+		ORDER BY methods.method`,
+		from.UnixMilli(), to.UnixMilli(), from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("query payment breakdown: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make([]report.PaymentTotal, 0)
+	for rows.Next() {
+		var pt report.PaymentTotal
+		if err := rows.Scan(&pt.Method, &pt.SaleCount, &pt.TenderedCents, &pt.ReceivedCents, &pt.RefundedCents); err != nil {
+			return nil, fmt.Errorf("scan payment total: %w", err)
+		}
+		pt.ChangeCents = pt.TenderedCents - pt.ReceivedCents
+		pt.NetCents = pt.ReceivedCents - pt.RefundedCents
+		totals = append(totals, pt)
+	}
+	return totals, rows.Err()
+}
+
+// Promotions totals the discounts each promotion gave sales made in the range, largest
+// first. Voided sales gave nothing away.
+func (r *ReportRepository) Promotions(ctx context.Context, from, to time.Time) ([]report.PromotionTotal, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(sip.promotion_id, 0), sip.name, COALESCE(sip.coupon_code, ''),
+			COUNT(DISTINCT s.id), COUNT(DISTINCT si.id), SUM(si.qty), SUM(sip.discount_cents)
+		FROM sale_item_promotions sip
+		INNER JOIN sale_items si ON si.id = sip.sale_item_id
+		INNER JOIN sales s ON s.id = si.sale_id
+		WHERE s.status != 'Voided' AND s.ts >= ? AND s.ts < ?
+		GROUP BY COALESCE(sip.promotion_id, 0), sip.name, COALESCE(sip.coupon_code, '')
+		ORDER BY SUM(sip.discount_cents) DESC, sip.name`,
+		from.UnixMilli(), to.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("query promotion totals: %w", err)
+	}
+	defer rows.Close()
+
+	totals := make([]report.PromotionTotal, 0)
+	for rows.Next() {
+		var pt report.PromotionTotal
+		if err := rows.Scan(&pt.PromotionID, &pt.Name, &pt.CouponCode, &pt.SaleCount, &pt.LineCount, &pt.Units, &pt.DiscountCents); err != nil {
+			return nil, fmt.Errorf("scan promotion total: %w", err)
+		}
+		totals = append(totals, pt)
+	}
+	return totals, rows.Err()
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"shopmate/internal/domain/sale"
+	"shopmate/internal/domain/sequence"
 )
 
 // SaleRepository handles persistence of sales and stock movements.
@@ -21,7 +22,7 @@ func NewSaleRepository(db *sql.DB) *SaleRepository {
 	return &SaleRepository{db: db}
 }
 
-// Create persists a sale and decrements stock atomically.
+// Create persists a sale under the next sale number and decrements stock atomically.
 func (r *SaleRepository) Create(ctx context.Context, draft sale.Sale) (*sale.Sale, error) {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -33,17 +34,54 @@ func (r *SaleRepository) Create(ctx context.Context, draft sale.Sale) (*sale.Sal
 		}
 	}()
 
+	if err = insertSale(ctx, tx, &draft); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit sale: %w", err)
+	}
+	return &draft, nil
+}
+
+// insertSale writes draft inside tx under the next sale number, takes its stock and posts its
+// loyalty points, filling in the ids, costs, shortages and points recorded.
+func insertSale(ctx context.Context, tx *sql.Tx, draft *sale.Sale) error {
 	ts := draft.Timestamp
 	if ts.IsZero() {
 		ts = time.Now()
 	}
 	tsMillis := ts.UnixMilli()
 
+	locationID, err := resolveLocationID(ctx, tx, draft.LocationID)
+	if err != nil {
+		return err
+	}
+
+	if draft.CustomerID > 0 {
+		// The sale keeps the customer's name as it was when sold.
+		if draft.CustomerName, err = customerName(ctx, tx, draft.CustomerID); err != nil {
+			return err
+		}
+	}
+
+	if draft.SaleNumber, err = nextNumber(ctx, tx, sequence.Sale, time.UnixMilli(tsMillis), func(number string) (bool, error) {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM sales WHERE sale_no = ?)`, number).Scan(&exists); err != nil {
+			return false, fmt.Errorf("check sale number: %w", err)
+		}
+		return exists, nil
+	}); err != nil {
+		return err
+	}
+
 	res, err := tx.ExecContext(ctx, `
-		INSERT INTO sales (sale_no, ts, customer_name, payment_method, subtotal_cents, discount_cents, tax_cents, total_cents, status, note)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		INSERT INTO sales (sale_no, ts, customer_id, customer_name, payment_method, subtotal_cents, discount_cents, tax_cents, total_cents, status, note, location_id,
+			tax_included, tax_exempt, tax_exemption_number)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		draft.SaleNumber,
 		tsMillis,
+		nullIfZero(draft.CustomerID),
 		nullIfEmpty(draft.CustomerName),
 		draft.PaymentMethod,
 		draft.SubtotalCents,
@@ -52,75 +90,139 @@ func (r *SaleRepository) Create(ctx context.Context, draft sale.Sale) (*sale.Sal
 		draft.TotalCents,
 		draft.Status,
 		nullIfEmpty(draft.Note),
+		locationID,
+		draft.TaxIncluded,
+		draft.TaxExempt,
+		nullIfEmpty(draft.TaxExemptionNumber),
 	)
 	if err != nil {
-		return nil, fmt.Errorf("insert sale: %w", err)
+		return fmt.Errorf("insert sale: %w", err)
 	}
 
 	saleID, err := res.LastInsertId()
 	if err != nil {
-		return nil, fmt.Errorf("sale last insert id: %w", err)
+		return fmt.Errorf("sale last insert id: %w", err)
+	}
+	if err = insertSaleTaxes(ctx, tx, saleID, draft.Taxes); err != nil {
+		return err
 	}
 
-	for _, line := range draft.Lines {
+	for i, payment := range draft.Payments {
+		if sale.IsStoredValue(payment.Method) {
+			if payment.Reference, err = redeemStoredValue(ctx, tx, payment, saleID, draft.SaleNumber, tsMillis); err != nil {
+				return err
+			}
+			draft.Payments[i].Reference = payment.Reference
+		}
 		if _, err = tx.ExecContext(ctx, `
-			INSERT INTO sale_items (sale_id, product_id, qty, unit_price_cents, tax_rate_bp, line_subtotal_cents, line_discount_cents, line_tax_cents, line_total_cents)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			INSERT INTO sale_payments (sale_id, method, tendered_cents, applied_cents, reference) VALUES (?, ?, ?, ?, ?)`,
+			saleID, payment.Method, payment.TenderedCents, payment.AppliedCents, nullIfEmpty(payment.Reference),
+		); err != nil {
+			return fmt.Errorf("insert sale payment: %w", err)
+		}
+	}
+
+	policy, err := loadStockPolicy(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for i, line := range draft.Lines {
+		var itemRes sql.Result
+		if itemRes, err = tx.ExecContext(ctx, `
+			INSERT INTO sale_items (sale_id, product_id, product_name, sku, qty, unit_price_cents, tax_rate_bp, line_subtotal_cents, line_discount_cents, promotion_discount_cents, line_tax_cents, line_total_cents)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			saleID,
 			line.ProductID,
+			line.ProductName,
+			line.SKU,
 			line.Quantity,
 			line.UnitPriceCents,
 			line.TaxRateBasisPoints,
 			line.LineSubtotalCents,
 			line.LineDiscountCents,
+			line.PromotionDiscountCents,
 			line.LineTaxCents,
 			line.LineTotalCents,
 		); err != nil {
-			return nil, fmt.Errorf("insert sale line: %w", err)
+			return fmt.Errorf("insert sale line: %w", err)
 		}
 
-		result, errUpdate := tx.ExecContext(ctx, `
-			UPDATE products
-			SET current_qty = current_qty - ?
-			WHERE id = ? AND current_qty >= ?`,
-			line.Quantity,
-			line.ProductID,
-			line.Quantity,
-		)
-		if errUpdate != nil {
-			return nil, fmt.Errorf("update stock: %w", errUpdate)
+		var itemID int64
+		if itemID, err = itemRes.LastInsertId(); err != nil {
+			return fmt.Errorf("sale line last insert id: %w", err)
+		}
+		if err = insertLinePromotions(ctx, tx, itemID, line.Promotions); err != nil {
+			return err
 		}
-		affected, _ := result.RowsAffected()
-		if affected == 0 {
-			return nil, errors.New("insufficient stock")
+		if len(line.SerialNumbers) > 0 {
+			if err = sellSerials(ctx, tx, line.ProductID, itemID, line.SerialNumbers); err != nil {
+				return err
+			}
 		}
 
-		if _, err = tx.ExecContext(ctx, `
-			INSERT INTO stock_movements (product_id, ts, delta, reason, ref)
-			VALUES (?, ?, ?, ?, ?)`,
-			line.ProductID,
-			tsMillis,
-			-line.Quantity,
-			"Sale",
-			draft.SaleNumber,
-		); err != nil {
-			return nil, fmt.Errorf("insert stock movement: %w", err)
+		var kit bool
+		if kit, err = isKit(ctx, tx, line.ProductID); err != nil {
+			return err
+		}
+		var cost int64
+		if kit {
+			var shortages []sale.Shortage
+			if cost, shortages, err = sellKitComponents(ctx, tx, line.ProductID, saleID, itemID, locationID, line.Quantity, tsMillis, draft.SaleNumber, policy); err != nil {
+				return err
+			}
+			draft.Shortages = append(draft.Shortages, shortages...)
+		} else {
+			var shortage *sale.Shortage
+			if shortage, err = takeStock(ctx, tx, line.ProductID, locationID, line.Quantity, policy); err != nil {
+				return err
+			}
+			if shortage != nil {
+				if err = recordBackorder(ctx, tx, saleID, itemID, locationID, tsMillis, shortage); err != nil {
+					return err
+				}
+				draft.Shortages = append(draft.Shortages, *shortage)
+			}
+			var value int64
+			if value, err = insertStockMovement(ctx, tx, stockMovement{
+				productID:  line.ProductID,
+				locationID: locationID,
+				tsMillis:   tsMillis,
+				delta:      -line.Quantity,
+				reason:     "Sale",
+				ref:        draft.SaleNumber,
+			}); err != nil {
+				return err
+			}
+			cost = -value
 		}
+
+		if _, err = tx.ExecContext(ctx, `UPDATE sale_items SET cost_cents = ? WHERE id = ?`, cost, itemID); err != nil {
+			return fmt.Errorf("record line cost: %w", err)
+		}
+		draft.Lines[i].ID = itemID
+		draft.Lines[i].CostCents = cost
 	}
 
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("commit sale: %w", err)
+	if err = redeemCoupons(ctx, tx, draft, saleID); err != nil {
+		return err
+	}
+
+	if err = postSalePoints(ctx, tx, draft, saleID, tsMillis); err != nil {
+		return err
 	}
 
 	draft.ID = saleID
+	draft.LocationID = locationID
 	draft.Timestamp = time.UnixMilli(tsMillis).UTC()
-	return &draft, nil
+	return nil
 }
 
 // GetByID retrieves a sale with its lines.
 func (r *SaleRepository) GetByID(ctx context.Context, saleID int64) (*sale.Sale, error) {
 	row := r.db.QueryRowContext(ctx, `
-		SELECT id, sale_no, ts, customer_name, payment_method, subtotal_cents, discount_cents, tax_cents, total_cents, status, note
+		SELECT id, sale_no, ts, COALESCE(customer_id, 0), customer_name, payment_method, subtotal_cents, discount_cents, tax_cents, total_cents, points_earned, points_redeemed, points_balance, status, note, COALESCE(location_id, 0),
+			tax_included, tax_exempt, COALESCE(tax_exemption_number, '')
 		FROM sales
 		WHERE id = ?`, saleID,
 	)
@@ -130,23 +232,35 @@ func (r *SaleRepository) GetByID(ctx context.Context, saleID int64) (*sale.Sale,
 		tsMillis int64
 		note     sql.NullString
 		customer sql.NullString
+		balance  sql.NullInt64
 	)
 	if err := row.Scan(
 		&rec.ID,
 		&rec.SaleNumber,
 		&tsMillis,
+		&rec.CustomerID,
 		&customer,
 		&rec.PaymentMethod,
 		&rec.SubtotalCents,
 		&rec.DiscountCents,
 		&rec.TaxCents,
 		&rec.TotalCents,
+		&rec.PointsEarned,
+		&rec.PointsRedeemed,
+		&balance,
 		&rec.Status,
 		&note,
+		&rec.LocationID,
+		&rec.TaxIncluded,
+		&rec.TaxExempt,
+		&rec.TaxExemptionNumber,
 	); err != nil {
 		return nil, fmt.Errorf("load sale: %w", err)
 	}
 	rec.Timestamp = time.UnixMilli(tsMillis).UTC()
+	if balance.Valid {
+		rec.PointsBalance = &balance.Int64
+	}
 	if customer.Valid {
 		rec.CustomerName = customer.String
 	}
@@ -159,14 +273,15 @@ func (r *SaleRepository) GetByID(ctx context.Context, saleID int64) (*sale.Sale,
 		return nil, err
 	}
 	rec.Lines = lines
+	if rec.Taxes, err = saleTaxes(ctx, r.db, rec.ID); err != nil {
+		return nil, err
+	}
+	if err := r.loadPayments(ctx, &rec); err != nil {
+		return nil, err
+	}
 	return &rec, nil
 }
 
-// Refund marks a sale as refunded and restores stock levels.
-func (r *SaleRepository) Refund(ctx context.Context, saleID int64) error {
-	return r.reverseSale(ctx, saleID, "Refunded", "Refund")
-}
-
 // Void marks a sale as voided and restores stock.
 func (r *SaleRepository) Void(ctx context.Context, saleID int64, note string) error {
 	if err := r.reverseSale(ctx, saleID, "Voided", "Void"); err != nil {
@@ -200,52 +315,111 @@ func (r *SaleRepository) List(ctx context.Context, filter sale.Filter) ([]sale.S
 			tsMillis int64
 			note     sql.NullString
 			customer sql.NullString
+			balance  sql.NullInt64
 		)
 		if err := rows.Scan(
 			&rec.ID,
 			&rec.SaleNumber,
 			&tsMillis,
+			&rec.CustomerID,
 			&customer,
 			&rec.PaymentMethod,
 			&rec.SubtotalCents,
 			&rec.DiscountCents,
 			&rec.TaxCents,
 			&rec.TotalCents,
+			&rec.PointsEarned,
+			&rec.PointsRedeemed,
+			&balance,
 			&rec.Status,
 			&note,
+			&rec.LocationID,
+			&rec.TaxIncluded,
+			&rec.TaxExempt,
+			&rec.TaxExemptionNumber,
 		); err != nil {
 			return nil, fmt.Errorf("scan sale: %w", err)
 		}
 		rec.Timestamp = time.UnixMilli(tsMillis).UTC()
+		if balance.Valid {
+			rec.PointsBalance = &balance.Int64
+		}
 		if customer.Valid {
 			rec.CustomerName = customer.String
 		}
 		if note.Valid {
 			rec.Note = note.String
 		}
-		rec.Lines, err = r.loadLines(ctx, rec.ID)
-		if err != nil {
+		salesResults = append(salesResults, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	// The store has a single connection, so details load once the sales are read.
+	rows.Close()
+
+	for i := range salesResults {
+		if salesResults[i].Lines, err = r.loadLines(ctx, salesResults[i].ID); err != nil {
+			return nil, err
+		}
+		if salesResults[i].Taxes, err = saleTaxes(ctx, r.db, salesResults[i].ID); err != nil {
+			return nil, err
+		}
+		if err := r.loadPayments(ctx, &salesResults[i]); err != nil {
 			return nil, err
 		}
-		salesResults = append(salesResults, rec)
 	}
+	return salesResults, nil
+}
+
+// loadPayments fills in a sale's payments and the change given.
+func (r *SaleRepository) loadPayments(ctx context.Context, rec *sale.Sale) error {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT method, tendered_cents, applied_cents, COALESCE(reference, '') FROM sale_payments WHERE sale_id = ? ORDER BY id`, rec.ID)
+	if err != nil {
+		return fmt.Errorf("query sale payments: %w", err)
+	}
+	defer rows.Close()
 
-	return salesResults, rows.Err()
+	rec.Payments = make([]sale.Payment, 0, 1)
+	rec.ChangeCents = 0
+	for rows.Next() {
+		var p sale.Payment
+		if err := rows.Scan(&p.Method, &p.TenderedCents, &p.AppliedCents, &p.Reference); err != nil {
+			return fmt.Errorf("scan sale payment: %w", err)
+		}
+		rec.Payments = append(rec.Payments, p)
+		rec.ChangeCents += p.ChangeCents()
+	}
+	return rows.Err()
 }
 
 func (r *SaleRepository) loadLines(ctx context.Context, saleID int64) ([]sale.Line, error) {
+	serials, err := saleLineSerials(ctx, r.db, saleID)
+	if err != nil {
+		return nil, err
+	}
+	promotions, err := saleLinePromotions(ctx, r.db, saleID)
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT
+			si.id,
 			si.product_id,
-			COALESCE(p.name, ''),
-			COALESCE(p.sku, ''),
+			COALESCE(NULLIF(si.product_name, ''), p.name, ''),
+			COALESCE(NULLIF(si.sku, ''), p.sku, ''),
 			si.qty,
 			si.unit_price_cents,
 			si.tax_rate_bp,
 			si.line_subtotal_cents,
 			si.line_discount_cents,
+			si.promotion_discount_cents,
 			si.line_tax_cents,
-			si.line_total_cents
+			si.line_total_cents,
+			si.cost_cents,
+			si.refunded_qty
 		FROM sale_items si
 		LEFT JOIN products p ON p.id = si.product_id
 		WHERE si.sale_id = ?
@@ -259,8 +433,12 @@ func (r *SaleRepository) loadLines(ctx context.Context, saleID int64) ([]sale.Li
 
 	var lines []sale.Line
 	for rows.Next() {
-		var line sale.Line
+		var (
+			line   sale.Line
+			itemID int64
+		)
 		if err := rows.Scan(
+			&itemID,
 			&line.ProductID,
 			&line.ProductName,
 			&line.SKU,
@@ -269,11 +447,17 @@ func (r *SaleRepository) loadLines(ctx context.Context, saleID int64) ([]sale.Li
 			&line.TaxRateBasisPoints,
 			&line.LineSubtotalCents,
 			&line.LineDiscountCents,
+			&line.PromotionDiscountCents,
 			&line.LineTaxCents,
 			&line.LineTotalCents,
+			&line.CostCents,
+			&line.RefundedQty,
 		); err != nil {
 			return nil, fmt.Errorf("scan sale line: %w", err)
 		}
+		line.ID = itemID
+		line.SerialNumbers = serials[itemID]
+		line.Promotions = promotions[itemID]
 		lines = append(lines, line)
 	}
 	return lines, rows.Err()
@@ -302,11 +486,12 @@ func (r *SaleRepository) reverseSale(ctx context.Context, saleID int64, targetSt
 	}()
 
 	var (
-		saleNo string
-		status string
+		saleNo     string
+		status     string
+		locationID sql.NullInt64
 	)
-	if err = tx.QueryRowContext(ctx, `SELECT sale_no, status FROM sales WHERE id = ?`, saleID).
-		Scan(&saleNo, &status); err != nil {
+	if err = tx.QueryRowContext(ctx, `SELECT sale_no, status, location_id FROM sales WHERE id = ?`, saleID).
+		Scan(&saleNo, &status, &locationID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return fmt.Errorf("sale not found: %w", err)
 		}
@@ -316,8 +501,22 @@ func (r *SaleRepository) reverseSale(ctx context.Context, saleID int64, targetSt
 	if status == targetStatus {
 		return nil
 	}
+	if status != "Completed" {
+		err = fmt.Errorf("sale %s is %s and cannot be reversed", saleNo, status)
+		return err
+	}
 
-	rows, err := tx.QueryContext(ctx, `SELECT product_id, qty FROM sale_items WHERE sale_id = ?`, saleID)
+	// Kit lines restore the components recorded at sale time rather than the kit itself.
+	rows, err := tx.QueryContext(ctx, `
+		SELECT si.product_id, si.qty, si.cost_cents
+		FROM sale_items si
+		WHERE si.sale_id = ?
+			AND NOT EXISTS (SELECT 1 FROM sale_item_components c WHERE c.sale_item_id = si.id)
+		UNION ALL
+		SELECT c.product_id, c.qty, c.cost_cents
+		FROM sale_item_components c
+		INNER JOIN sale_items si ON si.id = c.sale_item_id
+		WHERE si.sale_id = ?`, saleID, saleID)
 	if err != nil {
 		return fmt.Errorf("load sale items: %w", err)
 	}
@@ -326,12 +525,13 @@ func (r *SaleRepository) reverseSale(ctx context.Context, saleID int64, targetSt
 	type movement struct {
 		productID int64
 		qty       int64
+		cost      int64
 	}
 
 	var movements []movement
 	for rows.Next() {
 		var m movement
-		if err := rows.Scan(&m.productID, &m.qty); err != nil {
+		if err := rows.Scan(&m.productID, &m.qty, &m.cost); err != nil {
 			return fmt.Errorf("scan sale item: %w", err)
 		}
 		movements = append(movements, m)
@@ -344,27 +544,35 @@ func (r *SaleRepository) reverseSale(ctx context.Context, saleID int64, targetSt
 		return fmt.Errorf("update sale status: %w", err)
 	}
 
+	if err = restockSaleSerials(ctx, tx, saleID); err != nil {
+		return err
+	}
+
+	restoreLocation := locationID.Int64
+	if !locationID.Valid {
+		if restoreLocation, err = defaultLocationID(ctx, tx); err != nil {
+			return err
+		}
+	}
+
 	nowMillis := time.Now().UnixMilli()
 
+	if err = voidSalePoints(ctx, tx, saleID, saleNo, nowMillis); err != nil {
+		return err
+	}
+	if err = voidSaleStoredValue(ctx, tx, saleID, saleNo, nowMillis); err != nil {
+		return err
+	}
+	if err = releaseCoupons(ctx, tx, saleID); err != nil {
+		return err
+	}
+	if err = cancelSaleBackorders(ctx, tx, saleID, nowMillis); err != nil {
+		return err
+	}
+
 	for _, m := range movements {
-		if _, err = tx.ExecContext(ctx, `
-			UPDATE products
-			SET current_qty = current_qty + ?
-			WHERE id = ?`,
-			m.qty, m.productID,
-		); err != nil {
-			return fmt.Errorf("restore stock: %w", err)
-		}
-		if _, err = tx.ExecContext(ctx, `
-			INSERT INTO stock_movements (product_id, ts, delta, reason, ref)
-			VALUES (?, ?, ?, ?, ?)`,
-			m.productID,
-			nowMillis,
-			m.qty,
-			reason,
-			saleNo,
-		); err != nil {
-			return fmt.Errorf("insert stock reversal: %w", err)
+		if err = restockAtCost(ctx, tx, m.productID, restoreLocation, m.qty, m.cost, nowMillis, reason, saleNo); err != nil {
+			return err
 		}
 	}
 
@@ -381,13 +589,15 @@ func buildSalesQuery(filter sale.Filter) (string, []interface{}) {
 	)
 
 	sb.WriteString(`
-		SELECT id, sale_no, ts, customer_name, payment_method, subtotal_cents, discount_cents, tax_cents, total_cents, status, note
+		SELECT id, sale_no, ts, COALESCE(customer_id, 0), customer_name, payment_method, subtotal_cents, discount_cents, tax_cents, total_cents, points_earned, points_redeemed, points_balance, status, note, COALESCE(location_id, 0),
+			tax_included, tax_exempt, COALESCE(tax_exemption_number, '')
 		FROM sales
 		WHERE ts BETWEEN ? AND ?`)
 	args = append(args, filter.From.UnixMilli(), filter.To.UnixMilli())
 
 	if len(filter.PaymentMethods) > 0 {
-		sb.WriteString(" AND payment_method IN (")
+		// A sale matches when any of its tenders used one of the methods.
+		sb.WriteString(" AND id IN (SELECT sale_id FROM sale_payments WHERE method IN (")
 		for i, method := range filter.PaymentMethods {
 			if i > 0 {
 				sb.WriteString(",")
@@ -395,7 +605,7 @@ func buildSalesQuery(filter sale.Filter) (string, []interface{}) {
 			sb.WriteString("?")
 			args = append(args, method)
 		}
-		sb.WriteString(")")
+		sb.WriteString("))")
 	}
 
 	if len(filter.Status) > 0 {
@@ -410,6 +620,11 @@ func buildSalesQuery(filter sale.Filter) (string, []interface{}) {
 		sb.WriteString(")")
 	}
 
+	if filter.CustomerID > 0 {
+		sb.WriteString(" AND customer_id = ?")
+		args = append(args, filter.CustomerID)
+	}
+
 	if q := strings.TrimSpace(filter.CustomerQuery); q != "" {
 		sb.WriteString(" AND (LOWER(COALESCE(customer_name,'')) LIKE ? OR LOWER(sale_no) LIKE ?)")
 		query := "%" + strings.ToLower(q) + "%"
@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/domain/sequence"
+)
+
+// SequenceRepository stores number formats and their counters.
+type SequenceRepository struct {
+	db *sql.DB
+}
+
+// NewSequenceRepository constructs a repository.
+func NewSequenceRepository(db *sql.DB) *SequenceRepository {
+	return &SequenceRepository{db: db}
+}
+
+// Format returns the configured format for a sequence, or its default.
+func (r *SequenceRepository) Format(ctx context.Context, name string) (sequence.Format, error) {
+	return loadSequenceFormat(ctx, r.db, name)
+}
+
+// SaveFormat stores a sequence's format. Counters carry on from their last value.
+func (r *SequenceRepository) SaveFormat(ctx context.Context, format sequence.Format) error {
+	if err := format.Validate(); err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO number_sequences (name, pattern, reset_yearly) VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET pattern = excluded.pattern, reset_yearly = excluded.reset_yearly`,
+		format.Name, format.Pattern, format.ResetYearly,
+	); err != nil {
+		return fmt.Errorf("save sequence format: %w", err)
+	}
+	return nil
+}
+
+// Peek renders the number the sequence would issue next at the given time without using it.
+func (r *SequenceRepository) Peek(ctx context.Context, name string, at time.Time) (string, error) {
+	format, err := loadSequenceFormat(ctx, r.db, name)
+	if err != nil {
+		return "", err
+	}
+	var last int64
+	err = r.db.QueryRowContext(ctx, `
+		SELECT last_value FROM number_sequence_counters WHERE name = ? AND period = ?`,
+		name, format.Period(at),
+	).Scan(&last)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("load sequence counter: %w", err)
+	}
+	return format.Render(at, last+1), nil
+}
+
+func loadSequenceFormat(ctx context.Context, q queryRower, name string) (sequence.Format, error) {
+	format := sequence.Format{Name: name}
+	err := q.QueryRowContext(ctx, `SELECT pattern, reset_yearly FROM number_sequences WHERE name = ?`, name).
+		Scan(&format.Pattern, &format.ResetYearly)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sequence.DefaultFormat(name), nil
+	}
+	if err != nil {
+		return sequence.Format{}, fmt.Errorf("load sequence format: %w", err)
+	}
+	return format, nil
+}
+
+// nextNumber allocates the next number of a sequence inside tx, so it is only consumed if
+// the transaction commits. taken reports numbers already in use, such as ones issued before
+// the sequence existed; they are skipped.
+func nextNumber(ctx context.Context, tx *sql.Tx, name string, at time.Time, taken func(string) (bool, error)) (string, error) {
+	format, err := loadSequenceFormat(ctx, tx, name)
+	if err != nil {
+		return "", err
+	}
+	period := format.Period(at)
+	for {
+		var value int64
+		if err := tx.QueryRowContext(ctx, `
+			INSERT INTO number_sequence_counters (name, period, last_value) VALUES (?, ?, 1)
+			ON CONFLICT(name, period) DO UPDATE SET last_value = last_value + 1
+			RETURNING last_value`,
+			name, period,
+		).Scan(&value); err != nil {
+			return "", fmt.Errorf("allocate %s number: %w", name, err)
+		}
+		number := format.Render(at, value)
+		used, err := taken(number)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return number, nil
+		}
+	}
+}
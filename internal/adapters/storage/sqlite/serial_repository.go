@@ -0,0 +1,248 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/domain/product"
+)
+
+// ListSerials returns tracked units for a product, optionally filtered by status.
+func (r *ProductRepository) ListSerials(ctx context.Context, productID int64, status string) ([]product.Serial, error) {
+	query := `
+		SELECT id, product_id, serial_no, status, received_at, ref
+		FROM product_serials
+		WHERE product_id = ?`
+	args := []interface{}{productID}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY serial_no`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query serials: %w", err)
+	}
+	defer rows.Close()
+
+	serials := make([]product.Serial, 0)
+	for rows.Next() {
+		serial, err := scanSerial(rows)
+		if err != nil {
+			return nil, err
+		}
+		serials = append(serials, *serial)
+	}
+	return serials, rows.Err()
+}
+
+// LookupSerial returns every unit matching the serial number with its sales history, newest first.
+func (r *ProductRepository) LookupSerial(ctx context.Context, serialNo string) ([]product.SerialLookup, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT ps.id, ps.product_id, ps.serial_no, ps.status, ps.received_at, ps.ref, p.name, p.sku
+		FROM product_serials ps
+		INNER JOIN products p ON p.id = ps.product_id
+		WHERE ps.serial_no = ?
+		ORDER BY ps.id`, serialNo)
+	if err != nil {
+		return nil, fmt.Errorf("query serial: %w", err)
+	}
+	defer rows.Close()
+
+	var results []product.SerialLookup
+	for rows.Next() {
+		var (
+			lookup     product.SerialLookup
+			receivedAt int64
+			ref        sql.NullString
+		)
+		if err := rows.Scan(
+			&lookup.Serial.ID,
+			&lookup.Serial.ProductID,
+			&lookup.Serial.SerialNumber,
+			&lookup.Serial.Status,
+			&receivedAt,
+			&ref,
+			&lookup.ProductName,
+			&lookup.SKU,
+		); err != nil {
+			return nil, fmt.Errorf("scan serial: %w", err)
+		}
+		lookup.Serial.ReceivedAt = time.UnixMilli(receivedAt).UTC()
+		lookup.Serial.Ref = ref.String
+		results = append(results, lookup)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, product.ErrSerialNotFound
+	}
+
+	for i := range results {
+		sales, err := r.serialSales(ctx, results[i].Serial.ID)
+		if err != nil {
+			return nil, err
+		}
+		results[i].Sales = sales
+	}
+	return results, nil
+}
+
+func (r *ProductRepository) serialSales(ctx context.Context, serialID int64) ([]product.SerialSale, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.id, s.sale_no, s.ts, COALESCE(s.customer_name, ''), s.status
+		FROM sale_item_serials sis
+		INNER JOIN sale_items si ON si.id = sis.sale_item_id
+		INNER JOIN sales s ON s.id = si.sale_id
+		WHERE sis.serial_id = ?
+		ORDER BY s.ts DESC, s.id DESC`, serialID)
+	if err != nil {
+		return nil, fmt.Errorf("query serial sales: %w", err)
+	}
+	defer rows.Close()
+
+	sales := make([]product.SerialSale, 0)
+	for rows.Next() {
+		var (
+			rec      product.SerialSale
+			tsMillis int64
+		)
+		if err := rows.Scan(&rec.SaleID, &rec.SaleNumber, &tsMillis, &rec.CustomerName, &rec.Status); err != nil {
+			return nil, fmt.Errorf("scan serial sale: %w", err)
+		}
+		rec.Timestamp = time.UnixMilli(tsMillis).UTC()
+		sales = append(sales, rec)
+	}
+	return sales, rows.Err()
+}
+
+func scanSerial(row rowScanner) (*product.Serial, error) {
+	var (
+		serial     product.Serial
+		receivedAt int64
+		ref        sql.NullString
+	)
+	if err := row.Scan(&serial.ID, &serial.ProductID, &serial.SerialNumber, &serial.Status, &receivedAt, &ref); err != nil {
+		return nil, fmt.Errorf("scan serial: %w", err)
+	}
+	serial.ReceivedAt = time.UnixMilli(receivedAt).UTC()
+	serial.Ref = ref.String
+	return &serial, nil
+}
+
+// receiveSerials registers new units as in stock. A unit previously removed may be received again.
+func receiveSerials(ctx context.Context, tx *sql.Tx, productID int64, serials []string, tsMillis int64, ref string) error {
+	for _, serial := range serials {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO product_serials (product_id, serial_no, status, received_at, ref)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(product_id, serial_no) DO UPDATE SET
+				status = excluded.status,
+				received_at = excluded.received_at,
+				ref = excluded.ref
+			WHERE product_serials.status = ?`,
+			productID, serial, product.SerialStatusInStock, tsMillis, sqlNullIfEmpty(ref), product.SerialStatusRemoved,
+		)
+		if err != nil {
+			return fmt.Errorf("receive serial %s: %w", serial, err)
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return fmt.Errorf("serial %s is already tracked", serial)
+		}
+	}
+	return nil
+}
+
+// removeSerials takes in-stock units out of inventory for negative adjustments.
+func removeSerials(ctx context.Context, tx *sql.Tx, productID int64, serials []string) error {
+	for _, serial := range serials {
+		res, err := tx.ExecContext(ctx, `
+			UPDATE product_serials SET status = ?
+			WHERE product_id = ? AND serial_no = ? AND status = ?`,
+			product.SerialStatusRemoved, productID, serial, product.SerialStatusInStock,
+		)
+		if err != nil {
+			return fmt.Errorf("remove serial %s: %w", serial, err)
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return fmt.Errorf("serial %s is not in stock", serial)
+		}
+	}
+	return nil
+}
+
+// sellSerials marks units sold and links them to the sale line.
+func sellSerials(ctx context.Context, tx *sql.Tx, productID, saleItemID int64, serials []string) error {
+	for _, serial := range serials {
+		var serialID int64
+		err := tx.QueryRowContext(ctx, `
+			UPDATE product_serials SET status = ?
+			WHERE product_id = ? AND serial_no = ? AND status = ?
+			RETURNING id`,
+			product.SerialStatusSold, productID, serial, product.SerialStatusInStock,
+		).Scan(&serialID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("serial %s is not in stock", serial)
+			}
+			return fmt.Errorf("sell serial %s: %w", serial, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO sale_item_serials (sale_item_id, serial_id) VALUES (?, ?)`,
+			saleItemID, serialID,
+		); err != nil {
+			return fmt.Errorf("link serial %s: %w", serial, err)
+		}
+	}
+	return nil
+}
+
+// restockSaleSerials returns every unit sold on the sale to stock.
+func restockSaleSerials(ctx context.Context, tx *sql.Tx, saleID int64) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE product_serials SET status = ?
+		WHERE status = ? AND id IN (
+			SELECT sis.serial_id
+			FROM sale_item_serials sis
+			INNER JOIN sale_items si ON si.id = sis.sale_item_id
+			WHERE si.sale_id = ?
+		)`,
+		product.SerialStatusInStock, product.SerialStatusSold, saleID,
+	); err != nil {
+		return fmt.Errorf("restock serials: %w", err)
+	}
+	return nil
+}
+
+// saleLineSerials groups serial numbers by sale line id.
+func saleLineSerials(ctx context.Context, db *sql.DB, saleID int64) (map[int64][]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT sis.sale_item_id, ps.serial_no
+		FROM sale_item_serials sis
+		INNER JOIN sale_items si ON si.id = sis.sale_item_id
+		INNER JOIN product_serials ps ON ps.id = sis.serial_id
+		WHERE si.sale_id = ?
+		ORDER BY ps.serial_no`, saleID)
+	if err != nil {
+		return nil, fmt.Errorf("query sale serials: %w", err)
+	}
+	defer rows.Close()
+
+	serials := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			itemID int64
+			serial string
+		)
+		if err := rows.Scan(&itemID, &serial); err != nil {
+			return nil, fmt.Errorf("scan sale serial: %w", err)
+		}
+		serials[itemID] = append(serials[itemID], serial)
+	}
+	return serials, rows.Err()
+}
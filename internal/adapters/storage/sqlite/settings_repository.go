@@ -8,13 +8,28 @@ import (
 	"fmt"
 	"time"
 
+	"shopmate/internal/domain/cart"
+	"shopmate/internal/domain/lowstock"
+	"shopmate/internal/domain/loyalty"
+	"shopmate/internal/domain/product"
+	"shopmate/internal/domain/purchasing"
 	"shopmate/internal/domain/settings"
+	"shopmate/internal/domain/tax"
 )
 
 const (
 	settingsKeyProfile     = "profile"
 	settingsKeyOwnerPIN    = "owner_pin"
 	settingsKeyPreferences = "preferences"
+	settingsKeyTill        = "till"
+	settingsKeyReplenish   = "replenishment_policy"
+	settingsKeyImportMap   = "product_import_mapping"
+	settingsKeyLowStock    = "low_stock_policy"
+	settingsKeyCosting     = "costing"
+	settingsKeyStockPolicy = "stock_policy"
+	settingsKeyCartPolicy  = "cart_policy"
+	settingsKeyLoyalty     = "loyalty_policy"
+	settingsKeyTax         = "tax_policy"
 )
 
 // SettingsRepository persists key-value application settings.
@@ -70,6 +85,202 @@ func (r *SettingsRepository) LoadPreferences(ctx context.Context) (settings.Pref
 	return prefs, nil
 }
 
+// SaveTill stores the register configuration.
+func (r *SettingsRepository) SaveTill(ctx context.Context, till settings.Till) error {
+	if err := till.Validate(); err != nil {
+		return err
+	}
+	return r.saveJSON(ctx, settingsKeyTill, till)
+}
+
+// LoadTill fetches the register configuration or defaults.
+func (r *SettingsRepository) LoadTill(ctx context.Context) (settings.Till, error) {
+	var till settings.Till
+	if err := r.loadJSON(ctx, settingsKeyTill, &till); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return settings.Till{}, nil
+		}
+		return settings.Till{}, err
+	}
+	return till, nil
+}
+
+// SaveReplenishmentPolicy stores the reorder suggestion policy.
+func (r *SettingsRepository) SaveReplenishmentPolicy(ctx context.Context, policy purchasing.Policy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	return r.saveJSON(ctx, settingsKeyReplenish, policy)
+}
+
+// LoadReplenishmentPolicy fetches the reorder suggestion policy or defaults.
+func (r *SettingsRepository) LoadReplenishmentPolicy(ctx context.Context) (purchasing.Policy, error) {
+	var policy purchasing.Policy
+	if err := r.loadJSON(ctx, settingsKeyReplenish, &policy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return purchasing.DefaultPolicy(), nil
+		}
+		return purchasing.Policy{}, err
+	}
+	policy.ApplyDefaults()
+	return policy, nil
+}
+
+// SaveImportMapping stores the last product import column mapping.
+func (r *SettingsRepository) SaveImportMapping(ctx context.Context, mapping product.ColumnMapping) error {
+	if err := mapping.Validate(); err != nil {
+		return err
+	}
+	return r.saveJSON(ctx, settingsKeyImportMap, mapping)
+}
+
+// LoadImportMapping fetches the saved product import column mapping, if any.
+func (r *SettingsRepository) LoadImportMapping(ctx context.Context) (product.ColumnMapping, error) {
+	mapping := product.ColumnMapping{}
+	if err := r.loadJSON(ctx, settingsKeyImportMap, &mapping); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// SaveLowStockPolicy stores the low-stock alert policy.
+func (r *SettingsRepository) SaveLowStockPolicy(ctx context.Context, policy lowstock.Policy) error {
+	policy.ApplyDefaults()
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	return r.saveJSON(ctx, settingsKeyLowStock, policy)
+}
+
+// LoadLowStockPolicy fetches the low-stock alert policy or defaults.
+func (r *SettingsRepository) LoadLowStockPolicy(ctx context.Context) (lowstock.Policy, error) {
+	var policy lowstock.Policy
+	if err := r.loadJSON(ctx, settingsKeyLowStock, &policy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return lowstock.DefaultPolicy(), nil
+		}
+		return lowstock.Policy{}, err
+	}
+	policy.ApplyDefaults()
+	return policy, nil
+}
+
+// SaveCartPolicy stores how long parked carts are held.
+func (r *SettingsRepository) SaveCartPolicy(ctx context.Context, policy cart.Policy) error {
+	policy.ApplyDefaults()
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	return r.saveJSON(ctx, settingsKeyCartPolicy, policy)
+}
+
+// LoadCartPolicy fetches the parked cart policy or defaults.
+func (r *SettingsRepository) LoadCartPolicy(ctx context.Context) (cart.Policy, error) {
+	var policy cart.Policy
+	if err := r.loadJSON(ctx, settingsKeyCartPolicy, &policy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return cart.DefaultPolicy(), nil
+		}
+		return cart.Policy{}, err
+	}
+	policy.ApplyDefaults()
+	return policy, nil
+}
+
+// SaveCosting stores the inventory costing method.
+func (r *SettingsRepository) SaveCosting(ctx context.Context, costing settings.Costing) error {
+	costing.ApplyDefaults()
+	if err := costing.Validate(); err != nil {
+		return err
+	}
+	return r.saveJSON(ctx, settingsKeyCosting, costing)
+}
+
+// LoadCosting fetches the inventory costing method or the FIFO default.
+func (r *SettingsRepository) LoadCosting(ctx context.Context) (settings.Costing, error) {
+	return loadCosting(ctx, r.db)
+}
+
+// loadCosting reads the costing method through q so stock movements can be valued inside
+// their transaction.
+func loadCosting(ctx context.Context, q queryRower) (settings.Costing, error) {
+	var costing settings.Costing
+	if err := loadSetting(ctx, q, settingsKeyCosting, &costing); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return settings.Costing{}, err
+	}
+	costing.ApplyDefaults()
+	return costing, nil
+}
+
+// SaveStockPolicy stores the shop-wide stock rules.
+func (r *SettingsRepository) SaveStockPolicy(ctx context.Context, policy settings.StockPolicy) error {
+	policy.ApplyDefaults()
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	return r.saveJSON(ctx, settingsKeyStockPolicy, policy)
+}
+
+// LoadStockPolicy fetches the shop-wide stock rules or the blocking default.
+func (r *SettingsRepository) LoadStockPolicy(ctx context.Context) (settings.StockPolicy, error) {
+	return loadStockPolicy(ctx, r.db)
+}
+
+// loadStockPolicy reads the stock rules through q so sales can apply them inside their
+// transaction.
+func loadStockPolicy(ctx context.Context, q queryRower) (settings.StockPolicy, error) {
+	var policy settings.StockPolicy
+	if err := loadSetting(ctx, q, settingsKeyStockPolicy, &policy); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return settings.StockPolicy{}, err
+	}
+	policy.ApplyDefaults()
+	return policy, nil
+}
+
+// SaveLoyaltyPolicy stores the loyalty points earn and redemption rules.
+func (r *SettingsRepository) SaveLoyaltyPolicy(ctx context.Context, policy loyalty.Policy) error {
+	policy.ApplyDefaults()
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	return r.saveJSON(ctx, settingsKeyLoyalty, policy)
+}
+
+// LoadLoyaltyPolicy fetches the loyalty rules or the disabled default.
+func (r *SettingsRepository) LoadLoyaltyPolicy(ctx context.Context) (loyalty.Policy, error) {
+	return loadLoyaltyPolicy(ctx, r.db)
+}
+
+// loadLoyaltyPolicy reads the loyalty rules through q so sales and refunds can post points
+// inside their transaction.
+func loadLoyaltyPolicy(ctx context.Context, q queryRower) (loyalty.Policy, error) {
+	var policy loyalty.Policy
+	if err := loadSetting(ctx, q, settingsKeyLoyalty, &policy); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return loyalty.Policy{}, err
+	}
+	policy.ApplyDefaults()
+	return policy, nil
+}
+
+// SaveTaxPolicy stores whether prices include tax and how tax is rounded.
+func (r *SettingsRepository) SaveTaxPolicy(ctx context.Context, policy tax.Policy) error {
+	policy.ApplyDefaults()
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	return r.saveJSON(ctx, settingsKeyTax, policy)
+}
+
+// LoadTaxPolicy fetches the tax policy or the default of adding tax rounded per line.
+func (r *SettingsRepository) LoadTaxPolicy(ctx context.Context) (tax.Policy, error) {
+	var policy tax.Policy
+	if err := r.loadJSON(ctx, settingsKeyTax, &policy); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return tax.Policy{}, err
+	}
+	policy.ApplyDefaults()
+	return policy, nil
+}
+
 // SaveOwnerPIN stores the hashed owner PIN payload.
 func (r *SettingsRepository) SaveOwnerPIN(ctx context.Context, hash string) error {
 	payload := map[string]interface{}{
@@ -121,8 +332,12 @@ func (r *SettingsRepository) saveJSON(ctx context.Context, key string, value int
 }
 
 func (r *SettingsRepository) loadJSON(ctx context.Context, key string, target interface{}) error {
+	return loadSetting(ctx, r.db, key, target)
+}
+
+func loadSetting(ctx context.Context, q queryRower, key string, target interface{}) error {
 	var value string
-	if err := r.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = ?`, key).Scan(&value); err != nil {
+	if err := q.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = ?`, key).Scan(&value); err != nil {
 		return err
 	}
 	if err := json.Unmarshal([]byte(value), target); err != nil {
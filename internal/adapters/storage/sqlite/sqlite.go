@@ -111,7 +111,25 @@ func applyMigrations(ctx context.Context, db *sql.DB) error {
 		}
 	}()
 
+	if _, err = tx.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			name TEXT PRIMARY KEY,
+			applied_at INTEGER NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000)
+		)`); err != nil {
+		err = fmt.Errorf("create schema_migrations: %w", err)
+		return err
+	}
+
+	applied, err := appliedMigrations(ctx, tx)
+	if err != nil {
+		return err
+	}
+
 	for _, name := range names {
+		if applied[name] {
+			continue
+		}
+
 		content, readErr := migrations.Files.ReadFile(name)
 		if readErr != nil {
 			err = fmt.Errorf("read migration %s: %w", name, readErr)
@@ -122,6 +140,11 @@ func applyMigrations(ctx context.Context, db *sql.DB) error {
 			err = fmt.Errorf("run migration %s: %w", name, execErr)
 			return err
 		}
+
+		if _, execErr := tx.ExecContext(ctx, `INSERT INTO schema_migrations (name) VALUES (?)`, name); execErr != nil {
+			err = fmt.Errorf("record migration %s: %w", name, execErr)
+			return err
+		}
 	}
 
 	if commitErr := tx.Commit(); commitErr != nil {
@@ -130,3 +153,23 @@ func applyMigrations(ctx context.Context, db *sql.DB) error {
 
 	return nil
 }
+
+// appliedMigrations returns the migration files already recorded. Databases created
+// before tracking existed report none, which is safe because 0001-0003 are idempotent.
+func appliedMigrations(ctx context.Context, tx *sql.Tx) (map[string]bool, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT name FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("load applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+		applied[name] = true
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,263 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"shopmate/internal/domain/product"
+	"shopmate/internal/domain/sale"
+	"shopmate/internal/domain/settings"
+)
+
+// errInsufficientStock reports that a location cannot cover a decrement.
+var errInsufficientStock = errors.New("insufficient stock")
+
+// queryRower is satisfied by *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// stockMovement is one row of the stock ledger. Inbound stock is costed at unitCostCents,
+// or the product's cost when zero. Transfers set locationOnly since they move stock
+// without changing its value.
+type stockMovement struct {
+	productID     int64
+	locationID    int64
+	tsMillis      int64
+	delta         int64
+	reason        string
+	ref           string
+	unitCostCents int64
+	valueCents    int64
+	locationOnly  bool
+}
+
+// defaultLocationID returns the location used when callers do not pick one.
+func defaultLocationID(ctx context.Context, q queryRower) (int64, error) {
+	var id int64
+	err := q.QueryRowContext(ctx, `SELECT id FROM locations WHERE is_default = 1 ORDER BY id LIMIT 1`).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errors.New("no default stock location configured")
+		}
+		return 0, fmt.Errorf("load default location: %w", err)
+	}
+	return id, nil
+}
+
+// resolveLocationID falls back to the default location for zero ids and rejects inactive ones.
+func resolveLocationID(ctx context.Context, q queryRower, locationID int64) (int64, error) {
+	if locationID <= 0 {
+		return defaultLocationID(ctx, q)
+	}
+	var active bool
+	if err := q.QueryRowContext(ctx, `SELECT active FROM locations WHERE id = ?`, locationID).Scan(&active); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("location %d not found", locationID)
+		}
+		return 0, fmt.Errorf("load location: %w", err)
+	}
+	if !active {
+		return 0, fmt.Errorf("location %d is inactive", locationID)
+	}
+	return locationID, nil
+}
+
+// adjustLocationStock applies delta to a product's quantity at one location. Decrements that
+// would take the location below zero fail with errInsufficientStock unless allowNegative is set.
+// products.current_qty is kept in sync by the product_stock triggers.
+func adjustLocationStock(ctx context.Context, tx *sql.Tx, productID, locationID, delta int64, allowNegative bool) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO product_stock (product_id, location_id, qty)
+		VALUES (?, ?, 0)
+		ON CONFLICT(product_id, location_id) DO NOTHING`,
+		productID, locationID,
+	); err != nil {
+		return fmt.Errorf("ensure stock row: %w", err)
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE product_stock
+		SET qty = qty + ?
+		WHERE product_id = ? AND location_id = ? AND (? OR qty + ? >= 0)`,
+		delta, productID, locationID, allowNegative, delta,
+	)
+	if err != nil {
+		return fmt.Errorf("update stock: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return errInsufficientStock
+	}
+	return nil
+}
+
+// locationQty returns the quantity on hand for a product at a location.
+func locationQty(ctx context.Context, q queryRower, productID, locationID int64) (int64, error) {
+	var qty int64
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(qty), 0) FROM product_stock
+		WHERE product_id = ? AND location_id = ?`,
+		productID, locationID,
+	).Scan(&qty)
+	if err != nil {
+		return 0, fmt.Errorf("load location stock: %w", err)
+	}
+	return qty, nil
+}
+
+// availableQty returns the quantity on hand at a location less stock reserved for held carts.
+func availableQty(ctx context.Context, q queryRower, productID, locationID int64) (int64, error) {
+	onHand, err := locationQty(ctx, q, productID, locationID)
+	if err != nil {
+		return 0, err
+	}
+	var reserved int64
+	if err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(qty), 0) FROM stock_reservations
+		WHERE product_id = ? AND location_id = ?`,
+		productID, locationID,
+	).Scan(&reserved); err != nil {
+		return 0, fmt.Errorf("load reserved stock: %w", err)
+	}
+	return onHand - reserved, nil
+}
+
+// insertStockMovement records a movement, values it under the shop's costing method and
+// returns the signed change in inventory value.
+func insertStockMovement(ctx context.Context, tx *sql.Tx, m stockMovement) (int64, error) {
+	var (
+		value int64
+		err   error
+	)
+	if !m.locationOnly && m.delta != 0 {
+		if value, err = valueMovement(ctx, tx, m); err != nil {
+			return 0, err
+		}
+	}
+
+	var movementID int64
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO stock_movements (product_id, location_id, ts, delta, reason, ref, value_cents)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		m.productID,
+		m.locationID,
+		m.tsMillis,
+		m.delta,
+		m.reason,
+		sqlNullIfEmpty(m.ref),
+		value,
+	).Scan(&movementID); err != nil {
+		return 0, fmt.Errorf("insert stock movement: %w", err)
+	}
+
+	if !m.locationOnly && m.delta > 0 {
+		if err := addCostLayers(ctx, tx, m.productID, movementID, m.tsMillis, m.delta, value); err != nil {
+			return 0, err
+		}
+	}
+	return value, nil
+}
+
+// restockAtCost returns qty units that left stock at a total of cost to a location.
+// Returned stock goes back at the cost it left with; sales recorded before costing carry
+// no cost and come back at the product's cost.
+func restockAtCost(ctx context.Context, tx *sql.Tx, productID, locationID, qty, cost, tsMillis int64, reason, ref string) error {
+	if err := adjustLocationStock(ctx, tx, productID, locationID, qty, true); err != nil {
+		return fmt.Errorf("restore stock: %w", err)
+	}
+	_, err := insertStockMovement(ctx, tx, stockMovement{
+		productID:  productID,
+		locationID: locationID,
+		tsMillis:   tsMillis,
+		delta:      qty,
+		reason:     reason,
+		ref:        ref,
+		valueCents: cost,
+	})
+	return err
+}
+
+// takeStock decrements stock sold at a location under the product's negative-stock policy.
+// Stock reserved for parked carts is not available to the sale. A shortfall fails with
+// *product.StockShortageError when the policy blocks it; otherwise stock goes negative and
+// the shortage is returned for the caller to report or backorder.
+func takeStock(ctx context.Context, tx *sql.Tx, productID, locationID, qty int64, shop settings.StockPolicy) (*sale.Shortage, error) {
+	var (
+		sku, name string
+		policy    sql.NullString
+	)
+	if err := tx.QueryRowContext(ctx, `SELECT sku, name, negative_stock_policy FROM products WHERE id = ?`, productID).Scan(&sku, &name, &policy); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("product %d not found", productID)
+		}
+		return nil, fmt.Errorf("load product: %w", err)
+	}
+	onHand, err := availableQty(ctx, tx, productID, locationID)
+	if err != nil {
+		return nil, err
+	}
+	if onHand >= qty {
+		return nil, adjustLocationStock(ctx, tx, productID, locationID, -qty, false)
+	}
+
+	effective := product.EffectiveNegativeStockPolicy(policy.String, shop.NegativeStock)
+	if effective == product.NegativeStockBlock {
+		return nil, &product.StockShortageError{ProductID: productID, SKU: sku, Name: name, OnHand: onHand, Requested: qty}
+	}
+	if err := adjustLocationStock(ctx, tx, productID, locationID, -qty, true); err != nil {
+		return nil, err
+	}
+	shortage := &sale.Shortage{ProductID: productID, SKU: sku, ProductName: name, OnHand: onHand, Requested: qty, Policy: effective}
+	if effective == product.NegativeStockBackorder {
+		shortage.BackorderedQty = qty - max(onHand, 0)
+	}
+	return shortage, nil
+}
+
+// recordBackorder stores the backordered part of a shortage against a sale line.
+func recordBackorder(ctx context.Context, tx *sql.Tx, saleID, saleItemID, locationID, tsMillis int64, shortage *sale.Shortage) error {
+	if shortage == nil || shortage.BackorderedQty <= 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO backorders (sale_id, sale_item_id, product_id, location_id, qty, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		saleID, saleItemID, shortage.ProductID, locationID, shortage.BackorderedQty, tsMillis,
+	); err != nil {
+		return fmt.Errorf("record backorder: %w", err)
+	}
+	return nil
+}
+
+// cancelSaleBackorders cancels every backorder still open on a voided sale.
+func cancelSaleBackorders(ctx context.Context, tx *sql.Tx, saleID, tsMillis int64) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE backorders SET cancelled_at = ?
+		WHERE sale_id = ? AND fulfilled_at IS NULL AND cancelled_at IS NULL`,
+		tsMillis, saleID,
+	); err != nil {
+		return fmt.Errorf("cancel backorders: %w", err)
+	}
+	return nil
+}
+
+// reduceBackorder takes refunded units of a product off the open backorder of a sale line:
+// units still owed are the first to go back, and a backorder left with none is cancelled.
+func reduceBackorder(ctx context.Context, tx *sql.Tx, saleItemID, productID, qty, tsMillis int64) error {
+	if qty <= 0 {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE backorders
+		SET cancelled_at = CASE WHEN qty <= ? THEN ? END,
+			qty = CASE WHEN qty <= ? THEN qty ELSE qty - ? END
+		WHERE sale_item_id = ? AND product_id = ? AND fulfilled_at IS NULL AND cancelled_at IS NULL`,
+		qty, tsMillis, qty, qty, saleItemID, productID,
+	); err != nil {
+		return fmt.Errorf("reduce backorder: %w", err)
+	}
+	return nil
+}
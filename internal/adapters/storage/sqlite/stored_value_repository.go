@@ -0,0 +1,517 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/sale"
+	"shopmate/internal/domain/storedvalue"
+)
+
+// StoredValueRepository stores gift card and store credit accounts and their ledger.
+// Sales, refunds and voids move balances inside their own transactions.
+type StoredValueRepository struct {
+	db *sql.DB
+}
+
+// NewStoredValueRepository constructs a repository.
+func NewStoredValueRepository(db *sql.DB) *StoredValueRepository {
+	return &StoredValueRepository{db: db}
+}
+
+const storedValueColumns = `a.id, a.code, a.kind, COALESCE(a.customer_id, 0), COALESCE(c.name, ''), a.balance_cents, a.status,
+	a.issued_at, a.voided_at, COALESCE(a.note, '')`
+
+// newCodeAttempts bounds the retries when a generated code is already taken.
+const newCodeAttempts = 5
+
+// Issue opens an account loaded with the issue's amount.
+func (r *StoredValueRepository) Issue(ctx context.Context, issue storedvalue.Issue, now time.Time) (*storedvalue.Account, error) {
+	issue.Normalize()
+	if err := issue.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin stored value tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var code string
+	if code, err = issueStoredValue(ctx, tx, issue, 0, now.UnixMilli()); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit stored value issue: %w", err)
+	}
+	return r.Get(ctx, code)
+}
+
+// TopUp loads more value onto an active account.
+func (r *StoredValueRepository) TopUp(ctx context.Context, code string, amountCents int64, paymentMethod, note string, now time.Time) (*storedvalue.Account, error) {
+	if err := storedvalue.ValidateAmount(amountCents); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(paymentMethod) == "" {
+		return nil, errors.New("payment method required")
+	}
+	err := r.update(ctx, code, func(tx *sql.Tx, account *storedvalue.Account) error {
+		if account.Status != storedvalue.StatusActive {
+			return fmt.Errorf("%s is %s and cannot be topped up", account.Code, strings.ToLower(account.Status))
+		}
+		return postStoredValue(ctx, tx, account.ID, storedValueEntry{
+			kind:          storedvalue.EntryTopUp,
+			amountCents:   amountCents,
+			paymentMethod: strings.TrimSpace(paymentMethod),
+			note:          strings.TrimSpace(note),
+			tsMillis:      now.UnixMilli(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, code)
+}
+
+// Void closes an account, forfeiting its balance.
+func (r *StoredValueRepository) Void(ctx context.Context, code, note string, now time.Time) (*storedvalue.Account, error) {
+	if strings.TrimSpace(note) == "" {
+		return nil, errors.New("a note explaining the void is required")
+	}
+	err := r.update(ctx, code, func(tx *sql.Tx, account *storedvalue.Account) error {
+		if account.Status == storedvalue.StatusVoided {
+			return fmt.Errorf("%s is already void", account.Code)
+		}
+		if err := postStoredValue(ctx, tx, account.ID, storedValueEntry{
+			kind:        storedvalue.EntryVoid,
+			amountCents: -account.BalanceCents,
+			note:        strings.TrimSpace(note),
+			tsMillis:    now.UnixMilli(),
+		}); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE stored_value_accounts SET status = ?, voided_at = ? WHERE id = ?`,
+			storedvalue.StatusVoided, now.UnixMilli(), account.ID,
+		); err != nil {
+			return fmt.Errorf("void stored value account: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, code)
+}
+
+// update runs fn on the account with code inside a transaction.
+func (r *StoredValueRepository) update(ctx context.Context, code string, fn func(tx *sql.Tx, account *storedvalue.Account) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin stored value tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var account *storedvalue.Account
+	if account, err = getStoredValue(ctx, tx, code); err != nil {
+		return err
+	}
+	if err = fn(tx, account); err != nil {
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit stored value: %w", err)
+	}
+	return nil
+}
+
+// Get looks an account up by its code.
+func (r *StoredValueRepository) Get(ctx context.Context, code string) (*storedvalue.Account, error) {
+	return getStoredValue(ctx, r.db, code)
+}
+
+// List returns accounts newest first, only those of a customer when customerID is set.
+func (r *StoredValueRepository) List(ctx context.Context, customerID int64) ([]storedvalue.Account, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+storedValueColumns+`
+		FROM stored_value_accounts a
+		LEFT JOIN customers c ON c.id = a.customer_id
+		WHERE ? = 0 OR a.customer_id = ?
+		ORDER BY a.issued_at DESC, a.id DESC`, customerID, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("query stored value accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := make([]storedvalue.Account, 0)
+	for rows.Next() {
+		account, err := scanStoredValue(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, *account)
+	}
+	return accounts, rows.Err()
+}
+
+// Ledger lists an account's movements, newest first.
+func (r *StoredValueRepository) Ledger(ctx context.Context, code string) ([]storedvalue.Entry, error) {
+	account, err := r.Get(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.id, l.account_id, l.ts, l.kind, l.amount_cents, l.balance_after_cents, COALESCE(l.payment_method, ''),
+			COALESCE(l.sale_id, 0), COALESCE(s.sale_no, ''), COALESCE(l.refund_id, 0), COALESCE(l.note, '')
+		FROM stored_value_ledger l
+		LEFT JOIN sales s ON s.id = l.sale_id
+		WHERE l.account_id = ?
+		ORDER BY l.ts DESC, l.id DESC`, account.ID)
+	if err != nil {
+		return nil, fmt.Errorf("query stored value ledger: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]storedvalue.Entry, 0)
+	for rows.Next() {
+		var (
+			e        storedvalue.Entry
+			tsMillis int64
+		)
+		if err := rows.Scan(&e.ID, &e.AccountID, &tsMillis, &e.Kind, &e.AmountCents, &e.BalanceAfterCents, &e.PaymentMethod,
+			&e.SaleID, &e.SaleNumber, &e.RefundID, &e.Note); err != nil {
+			return nil, fmt.Errorf("scan stored value entry: %w", err)
+		}
+		e.Timestamp = time.UnixMilli(tsMillis).UTC()
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func getStoredValue(ctx context.Context, q queryRower, code string) (*storedvalue.Account, error) {
+	code = storedvalue.NormalizeCode(code)
+	if code == "" {
+		return nil, errors.New("code required")
+	}
+	account, err := scanStoredValue(q.QueryRowContext(ctx, `
+		SELECT `+storedValueColumns+`
+		FROM stored_value_accounts a
+		LEFT JOIN customers c ON c.id = a.customer_id
+		WHERE a.code = ?`, code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("no gift card or store credit with code %s", code)
+	}
+	return account, err
+}
+
+func scanStoredValue(row interface{ Scan(...any) error }) (*storedvalue.Account, error) {
+	var (
+		a        storedvalue.Account
+		issuedAt int64
+		voidedAt sql.NullInt64
+	)
+	if err := row.Scan(&a.ID, &a.Code, &a.Kind, &a.CustomerID, &a.CustomerName, &a.BalanceCents, &a.Status,
+		&issuedAt, &voidedAt, &a.Note); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan stored value account: %w", err)
+	}
+	a.IssuedAt = time.UnixMilli(issuedAt).UTC()
+	if voidedAt.Valid {
+		at := time.UnixMilli(voidedAt.Int64).UTC()
+		a.VoidedAt = &at
+	}
+	return &a, nil
+}
+
+// storedValueEntry is a balance movement to post. amountCents is negative when value is
+// spent or forfeited.
+type storedValueEntry struct {
+	kind          string
+	amountCents   int64
+	paymentMethod string
+	saleID        int64
+	refundID      int64
+	note          string
+	tsMillis      int64
+}
+
+// issueStoredValue opens an account for a normalised, valid issue inside q and returns its
+// code. refundID links store credit to the refund that issued it.
+func issueStoredValue(ctx context.Context, q dbtx, issue storedvalue.Issue, refundID, tsMillis int64) (string, error) {
+	if issue.CustomerID > 0 {
+		if _, err := customerName(ctx, q, issue.CustomerID); err != nil {
+			return "", err
+		}
+	}
+
+	code := issue.Code
+	if code != "" {
+		taken, err := storedValueCodeTaken(ctx, q, code)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return "", fmt.Errorf("code %s is already in use", code)
+		}
+	}
+	for attempt := 0; code == "" && attempt < newCodeAttempts; attempt++ {
+		candidate, err := storedvalue.NewCode(issue.Kind)
+		if err != nil {
+			return "", err
+		}
+		taken, err := storedValueCodeTaken(ctx, q, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			code = candidate
+		}
+	}
+	if code == "" {
+		return "", errors.New("could not generate an unused code")
+	}
+
+	var id int64
+	if err := q.QueryRowContext(ctx, `
+		INSERT INTO stored_value_accounts (code, kind, customer_id, status, issued_at, note)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id`,
+		code, issue.Kind, nullIfZero(issue.CustomerID), storedvalue.StatusActive, tsMillis, nullIfEmpty(issue.Note),
+	).Scan(&id); err != nil {
+		return "", fmt.Errorf("insert stored value account: %w", err)
+	}
+	if err := postStoredValue(ctx, q, id, storedValueEntry{
+		kind:          storedvalue.EntryIssue,
+		amountCents:   issue.AmountCents,
+		paymentMethod: issue.PaymentMethod,
+		refundID:      refundID,
+		note:          issue.Note,
+		tsMillis:      tsMillis,
+	}); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// storedValueCodeTaken reports whether an account already uses code.
+func storedValueCodeTaken(ctx context.Context, q dbtx, code string) (bool, error) {
+	var taken bool
+	if err := q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM stored_value_accounts WHERE code = ?)`, code).Scan(&taken); err != nil {
+		return false, fmt.Errorf("check stored value code: %w", err)
+	}
+	return taken, nil
+}
+
+// postStoredValue moves an account's balance by the entry's amount and posts it to the
+// ledger. The balance may not go below zero, so two sales cannot spend the same value.
+func postStoredValue(ctx context.Context, q dbtx, accountID int64, entry storedValueEntry) error {
+	var balance int64
+	err := q.QueryRowContext(ctx, `
+		UPDATE stored_value_accounts SET balance_cents = balance_cents + ?
+		WHERE id = ? AND balance_cents + ? >= 0
+		RETURNING balance_cents`,
+		entry.amountCents, accountID, entry.amountCents,
+	).Scan(&balance)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("insufficient balance")
+	}
+	if err != nil {
+		return fmt.Errorf("update stored value balance: %w", err)
+	}
+	if _, err := q.ExecContext(ctx, `
+		INSERT INTO stored_value_ledger (account_id, ts, kind, amount_cents, balance_after_cents, payment_method, sale_id, refund_id, note)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		accountID, entry.tsMillis, entry.kind, entry.amountCents, balance, nullIfEmpty(entry.paymentMethod),
+		nullIfZero(entry.saleID), nullIfZero(entry.refundID), nullIfEmpty(entry.note),
+	); err != nil {
+		return fmt.Errorf("insert stored value entry: %w", err)
+	}
+	return nil
+}
+
+// redeemStoredValue spends a sale payment from the account it names inside the sale's
+// transaction and returns the account's code as stored.
+func redeemStoredValue(ctx context.Context, tx *sql.Tx, payment sale.Payment, saleID int64, saleNo string, tsMillis int64) (string, error) {
+	account, err := getStoredValue(ctx, tx, payment.Reference)
+	if err != nil {
+		return "", err
+	}
+	if account.Kind != storedvalue.KindForMethod(payment.Method) {
+		return "", fmt.Errorf("%s is not a %s; take it as %s", account.Code, strings.ToLower(payment.Method), storedvalue.MethodFor(account.Kind))
+	}
+	if account.Status != storedvalue.StatusActive {
+		return "", fmt.Errorf("%s is %s and cannot be spent", account.Code, strings.ToLower(account.Status))
+	}
+	if account.BalanceCents < payment.AppliedCents {
+		return "", fmt.Errorf("%s has %d left; %d needed", account.Code, account.BalanceCents, payment.AppliedCents)
+	}
+	if err := postStoredValue(ctx, tx, account.ID, storedValueEntry{
+		kind:        storedvalue.EntryRedeem,
+		amountCents: -payment.AppliedCents,
+		saleID:      saleID,
+		note:        saleNo,
+		tsMillis:    tsMillis,
+	}); err != nil {
+		return "", fmt.Errorf("%s: %w", account.Code, err)
+	}
+	return account.Code, nil
+}
+
+// refundStoredValue pays a refund to a stored-value method into the account reference
+// names, or else the account of that kind the sale was paid from. Store credit with
+// neither is issued as a new account for the sale's customer.
+func refundStoredValue(ctx context.Context, tx *sql.Tx, refund *sale.Refund, reference string, tsMillis int64) error {
+	if !sale.IsStoredValue(refund.PaymentMethod) {
+		return nil
+	}
+	kind := storedvalue.KindForMethod(refund.PaymentMethod)
+	code := storedvalue.NormalizeCode(reference)
+	if code == "" {
+		rows, err := tx.QueryContext(ctx, `
+			SELECT DISTINCT reference FROM sale_payments
+			WHERE sale_id = ? AND method = ? COLLATE NOCASE AND reference IS NOT NULL`,
+			refund.SaleID, refund.PaymentMethod)
+		if err != nil {
+			return fmt.Errorf("query stored value payments: %w", err)
+		}
+		var codes []string
+		for rows.Next() {
+			var c string
+			if err := rows.Scan(&c); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan stored value payment: %w", err)
+			}
+			codes = append(codes, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		switch {
+		case len(codes) == 1:
+			code = codes[0]
+		case len(codes) > 1:
+			return fmt.Errorf("sale %s was paid from several %s accounts; name the one to refund to", refund.SaleNumber, strings.ToLower(refund.PaymentMethod))
+		case kind == storedvalue.KindGiftCard:
+			return errors.New("name the gift card to refund to")
+		}
+	}
+
+	if refund.TotalCents <= 0 {
+		refund.Reference = code
+		return nil
+	}
+	if code == "" {
+		var customerID sql.NullInt64
+		if err := tx.QueryRowContext(ctx, `SELECT customer_id FROM sales WHERE id = ?`, refund.SaleID).Scan(&customerID); err != nil {
+			return fmt.Errorf("load sale customer: %w", err)
+		}
+		var err error
+		if code, err = issueStoredValue(ctx, tx, storedvalue.Issue{
+			Kind:        storedvalue.KindStoreCredit,
+			CustomerID:  customerID.Int64,
+			AmountCents: refund.TotalCents,
+			Note:        refund.RefundNumber,
+		}, refund.ID, tsMillis); err != nil {
+			return err
+		}
+	} else {
+		account, err := getStoredValue(ctx, tx, code)
+		if err != nil {
+			return err
+		}
+		if account.Kind != kind {
+			return fmt.Errorf("%s is not a %s", account.Code, strings.ToLower(refund.PaymentMethod))
+		}
+		if account.Status != storedvalue.StatusActive {
+			return fmt.Errorf("%s is %s and cannot be refunded to", account.Code, strings.ToLower(account.Status))
+		}
+		if err := postStoredValue(ctx, tx, account.ID, storedValueEntry{
+			kind:        storedvalue.EntryRefund,
+			amountCents: refund.TotalCents,
+			saleID:      refund.SaleID,
+			refundID:    refund.ID,
+			note:        refund.RefundNumber,
+			tsMillis:    tsMillis,
+		}); err != nil {
+			return err
+		}
+		code = account.Code
+	}
+
+	refund.Reference = code
+	if _, err := tx.ExecContext(ctx, `UPDATE refunds SET reference = ? WHERE id = ?`, code, refund.ID); err != nil {
+		return fmt.Errorf("record refund reference: %w", err)
+	}
+	return nil
+}
+
+// voidSaleStoredValue puts back the value a voided sale spent from gift cards and store
+// credit. Other tenders may carry a reference too, such as a card authorisation, and are
+// left alone.
+func voidSaleStoredValue(ctx context.Context, tx *sql.Tx, saleID int64, saleNo string, tsMillis int64) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT method, reference, applied_cents FROM sale_payments
+		WHERE sale_id = ? AND reference IS NOT NULL AND applied_cents > 0
+		ORDER BY id`, saleID)
+	if err != nil {
+		return fmt.Errorf("query stored value payments: %w", err)
+	}
+	type spend struct {
+		code   string
+		amount int64
+	}
+	var spends []spend
+	for rows.Next() {
+		var (
+			method string
+			s      spend
+		)
+		if err := rows.Scan(&method, &s.code, &s.amount); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan stored value payment: %w", err)
+		}
+		if sale.IsStoredValue(method) {
+			spends = append(spends, s)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, s := range spends {
+		account, err := getStoredValue(ctx, tx, s.code)
+		if err != nil {
+			return err
+		}
+		if account.Status != storedvalue.StatusActive {
+			return fmt.Errorf("%s is %s; its payment cannot be returned", account.Code, strings.ToLower(account.Status))
+		}
+		if err := postStoredValue(ctx, tx, account.ID, storedValueEntry{
+			kind:        storedvalue.EntryRefund,
+			amountCents: s.amount,
+			saleID:      saleID,
+			note:        saleNo,
+			tsMillis:    tsMillis,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/domain/sale"
+	"shopmate/internal/domain/tax"
+)
+
+// TaxRepository stores tax groups and their components, and reads the customer
+// exemptions sales are taxed under. Sales record their tax breakdown in their own
+// transactions.
+type TaxRepository struct {
+	db *sql.DB
+}
+
+// NewTaxRepository constructs a repository.
+func NewTaxRepository(db *sql.DB) *TaxRepository {
+	return &TaxRepository{db: db}
+}
+
+// Create saves a new tax group.
+func (r *TaxRepository) Create(ctx context.Context, input tax.Input, now time.Time) (*tax.Group, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tax group tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = ensureTaxGroupNameFree(ctx, tx, input.Name, 0); err != nil {
+		return nil, err
+	}
+
+	var id int64
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO tax_groups (name, created_at, updated_at) VALUES (?, ?, ?)
+		RETURNING id`,
+		input.Name, now.UnixMilli(), now.UnixMilli(),
+	).Scan(&id); err != nil {
+		return nil, fmt.Errorf("save tax group: %w", err)
+	}
+	if err = writeTaxComponents(ctx, tx, id, input.Components); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tax group: %w", err)
+	}
+	return r.Get(ctx, id)
+}
+
+// Update replaces a tax group's name and components. Products in the group are taxed at
+// the new components from their next sale; recorded sales keep the tax they were charged.
+func (r *TaxRepository) Update(ctx context.Context, id int64, input tax.Input, now time.Time) (*tax.Group, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin tax group tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = ensureTaxGroupNameFree(ctx, tx, input.Name, id); err != nil {
+		return nil, err
+	}
+
+	var res sql.Result
+	if res, err = tx.ExecContext(ctx, `UPDATE tax_groups SET name = ?, updated_at = ? WHERE id = ?`,
+		input.Name, now.UnixMilli(), id,
+	); err != nil {
+		return nil, fmt.Errorf("save tax group: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		err = fmt.Errorf("tax group %d not found", id)
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM tax_components WHERE group_id = ?`, id); err != nil {
+		return nil, fmt.Errorf("clear tax components: %w", err)
+	}
+	if err = writeTaxComponents(ctx, tx, id, input.Components); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit tax group: %w", err)
+	}
+	return r.Get(ctx, id)
+}
+
+func writeTaxComponents(ctx context.Context, tx *sql.Tx, groupID int64, components []tax.Component) error {
+	for i, c := range components {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO tax_components (group_id, position, name, rate_bp, compound) VALUES (?, ?, ?, ?, ?)`,
+			groupID, i, c.Name, c.RateBasisPoints, c.Compound,
+		); err != nil {
+			return fmt.Errorf("insert tax component %s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// ensureTaxGroupNameFree refuses a name another tax group than id already uses.
+func ensureTaxGroupNameFree(ctx context.Context, tx *sql.Tx, name string, id int64) error {
+	var taken bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM tax_groups WHERE name = ? AND id <> ?)`, name, id).Scan(&taken); err != nil {
+		return fmt.Errorf("check tax group name: %w", err)
+	}
+	if taken {
+		return fmt.Errorf("tax group %s already exists", name)
+	}
+	return nil
+}
+
+// Delete removes a tax group no product is assigned to.
+func (r *TaxRepository) Delete(ctx context.Context, id int64) error {
+	var products int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM products WHERE tax_group_id = ?`, id).Scan(&products); err != nil {
+		return fmt.Errorf("count tax group products: %w", err)
+	}
+	if products > 0 {
+		return fmt.Errorf("tax group %d is assigned to %d products", id, products)
+	}
+	res, err := r.db.ExecContext(ctx, `DELETE FROM tax_groups WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete tax group: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("tax group %d not found", id)
+	}
+	return nil
+}
+
+// Get retrieves a tax group by id.
+func (r *TaxRepository) Get(ctx context.Context, id int64) (*tax.Group, error) {
+	groups, err := r.query(ctx, `WHERE g.id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("tax group %d not found", id)
+	}
+	return &groups[0], nil
+}
+
+// List returns every tax group by name.
+func (r *TaxRepository) List(ctx context.Context) ([]tax.Group, error) {
+	return r.query(ctx, `ORDER BY g.name COLLATE NOCASE, g.id`)
+}
+
+func (r *TaxRepository) query(ctx context.Context, where string, args ...interface{}) ([]tax.Group, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT g.id, g.name, g.created_at, g.updated_at,
+			(SELECT COUNT(*) FROM products p WHERE p.tax_group_id = g.id)
+		FROM tax_groups g `+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query tax groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := make([]tax.Group, 0)
+	index := make(map[int64]int)
+	for rows.Next() {
+		var (
+			g                    tax.Group
+			createdAt, updatedAt int64
+		)
+		if err := rows.Scan(&g.ID, &g.Name, &createdAt, &updatedAt, &g.ProductCount); err != nil {
+			return nil, fmt.Errorf("scan tax group: %w", err)
+		}
+		g.Components = []tax.Component{}
+		g.CreatedAt = time.UnixMilli(createdAt).UTC()
+		g.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+		index[g.ID] = len(groups)
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if len(groups) == 0 {
+		return groups, nil
+	}
+	components, err := r.db.QueryContext(ctx, `
+		SELECT group_id, name, rate_bp, compound FROM tax_components ORDER BY group_id, position`)
+	if err != nil {
+		return nil, fmt.Errorf("query tax components: %w", err)
+	}
+	defer components.Close()
+	for components.Next() {
+		var (
+			groupID int64
+			c       tax.Component
+		)
+		if err := components.Scan(&groupID, &c.Name, &c.RateBasisPoints, &c.Compound); err != nil {
+			return nil, fmt.Errorf("scan tax component: %w", err)
+		}
+		if i, ok := index[groupID]; ok {
+			groups[i].Components = append(groups[i].Components, c)
+		}
+	}
+	return groups, components.Err()
+}
+
+// Components returns the components of a tax group, in the order they are charged.
+func (r *TaxRepository) Components(ctx context.Context, groupID int64) ([]tax.Component, error) {
+	group, err := r.Get(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return group.Components, nil
+}
+
+// Exemption reports whether a customer is sold to without tax, and under which
+// exemption number.
+func (r *TaxRepository) Exemption(ctx context.Context, customerID int64) (bool, string, error) {
+	var (
+		exempt bool
+		number string
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT tax_exempt, COALESCE(tax_exemption_number, '') FROM customers WHERE id = ?`, customerID,
+	).Scan(&exempt, &number)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, "", fmt.Errorf("customer %d not found", customerID)
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("load customer exemption: %w", err)
+	}
+	return exempt, number, nil
+}
+
+// insertSaleTaxes records a sale's tax breakdown.
+func insertSaleTaxes(ctx context.Context, tx *sql.Tx, saleID int64, taxes []sale.TaxLine) error {
+	for _, t := range taxes {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO sale_taxes (sale_id, name, rate_bp, compound, taxable_cents, tax_cents)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			saleID, t.Name, t.RateBasisPoints, t.Compound, t.TaxableCents, t.TaxCents,
+		); err != nil {
+			return fmt.Errorf("insert sale tax: %w", err)
+		}
+	}
+	return nil
+}
+
+// saleTaxes loads a sale's tax breakdown in the order it was charged.
+func saleTaxes(ctx context.Context, q dbtx, saleID int64) ([]sale.TaxLine, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT name, rate_bp, compound, taxable_cents, tax_cents
+		FROM sale_taxes WHERE sale_id = ? ORDER BY id`, saleID)
+	if err != nil {
+		return nil, fmt.Errorf("query sale taxes: %w", err)
+	}
+	defer rows.Close()
+
+	taxes := make([]sale.TaxLine, 0)
+	for rows.Next() {
+		var t sale.TaxLine
+		if err := rows.Scan(&t.Name, &t.RateBasisPoints, &t.Compound, &t.TaxableCents, &t.TaxCents); err != nil {
+			return nil, fmt.Errorf("scan sale tax: %w", err)
+		}
+		taxes = append(taxes, t)
+	}
+	return taxes, rows.Err()
+}
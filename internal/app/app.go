@@ -7,36 +7,79 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+
 	"shopmate/internal/adapters/storage/sqlite"
+	"shopmate/internal/domain/lowstock"
 	backupservice "shopmate/internal/services/backup"
+	cartservice "shopmate/internal/services/cart"
+	categoryservice "shopmate/internal/services/category"
+	customerservice "shopmate/internal/services/customer"
 	invoiceservice "shopmate/internal/services/invoice"
+	locationservice "shopmate/internal/services/location"
+	lowstockservice "shopmate/internal/services/lowstock"
+	loyaltyservice "shopmate/internal/services/loyalty"
 	productservice "shopmate/internal/services/product"
+	promotionservice "shopmate/internal/services/promotion"
+	quoteservice "shopmate/internal/services/quote"
+	replenishmentservice "shopmate/internal/services/replenishment"
 	reportservice "shopmate/internal/services/report"
 	saleservice "shopmate/internal/services/sale"
+	sequenceservice "shopmate/internal/services/sequence"
 	settingsservice "shopmate/internal/services/settings"
+	storedvalueservice "shopmate/internal/services/storedvalue"
+	taxservice "shopmate/internal/services/tax"
 	backupapi "shopmate/internal/wailsapi/backup"
+	cartapi "shopmate/internal/wailsapi/cart"
+	categoryapi "shopmate/internal/wailsapi/category"
+	customerapi "shopmate/internal/wailsapi/customer"
 	invoiceapi "shopmate/internal/wailsapi/invoice"
+	locationapi "shopmate/internal/wailsapi/location"
+	lowstockapi "shopmate/internal/wailsapi/lowstock"
+	loyaltyapi "shopmate/internal/wailsapi/loyalty"
 	productapi "shopmate/internal/wailsapi/product"
+	promotionapi "shopmate/internal/wailsapi/promotion"
+	quoteapi "shopmate/internal/wailsapi/quote"
+	replenishmentapi "shopmate/internal/wailsapi/replenishment"
 	reportapi "shopmate/internal/wailsapi/report"
 	"shopmate/internal/wailsapi/response"
 	saleapi "shopmate/internal/wailsapi/sale"
+	sequenceapi "shopmate/internal/wailsapi/sequence"
 	settingsapi "shopmate/internal/wailsapi/settings"
+	storedvalueapi "shopmate/internal/wailsapi/storedvalue"
+	taxapi "shopmate/internal/wailsapi/tax"
 )
 
 const defaultDBFile = "data/app.sqlite"
 
 // App coordinates backend services exposed to the Wails runtime.
 type App struct {
-	ctx      context.Context
-	logger   *slog.Logger
-	store    *sqlite.Store
-	backup   *backupservice.Service
-	products *productapi.API
-	sales    *saleapi.API
-	reports  *reportapi.API
-	backups  *backupapi.API
-	settings *settingsapi.API
-	invoices *invoiceapi.API
+	ctx        context.Context
+	logger     *slog.Logger
+	store      *sqlite.Store
+	backup     *backupservice.Service
+	catalog    *productservice.Service
+	lowStock   *lowstockservice.Service
+	cartHold   *cartservice.Service
+	points     *loyaltyservice.Service
+	products   *productapi.API
+	sales      *saleapi.API
+	reports    *reportapi.API
+	backups    *backupapi.API
+	settings   *settingsapi.API
+	invoices   *invoiceapi.API
+	locations  *locationapi.API
+	replenish  *replenishmentapi.API
+	categories *categoryapi.API
+	alerts     *lowstockapi.API
+	sequences  *sequenceapi.API
+	carts      *cartapi.API
+	quotes     *quoteapi.API
+	customers  *customerapi.API
+	loyalty    *loyaltyapi.API
+	giftCards  *storedvalueapi.API
+	promotions *promotionapi.API
+	taxes      *taxapi.API
 }
 
 // New constructs the application shell with its dependencies.
@@ -60,22 +103,55 @@ func New(logger *slog.Logger) (*App, error) {
 	reportRepo := sqlite.NewReportRepository(store.DB())
 	backupRepo := sqlite.NewBackupRepository(store.DB())
 	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	locationRepo := sqlite.NewLocationRepository(store.DB())
+	purchasingRepo := sqlite.NewPurchasingRepository(store.DB())
+	categoryRepo := sqlite.NewCategoryRepository(store.DB())
+	lowStockRepo := sqlite.NewLowStockRepository(store.DB())
+	sequenceRepo := sqlite.NewSequenceRepository(store.DB())
+	cartRepo := sqlite.NewCartRepository(store.DB())
+	quoteRepo := sqlite.NewQuoteRepository(store.DB())
+	customerRepo := sqlite.NewCustomerRepository(store.DB())
+	loyaltyRepo := sqlite.NewLoyaltyRepository(store.DB())
+	storedValueRepo := sqlite.NewStoredValueRepository(store.DB())
+	promotionRepo := sqlite.NewPromotionRepository(store.DB())
+	taxRepo := sqlite.NewTaxRepository(store.DB())
 
-	productSvc := productservice.NewService(productRepo)
-	saleSvc := saleservice.NewService(productRepo, saleRepo)
+	productSvc := productservice.NewService(productRepo, settingsRepo)
+	saleSvc := saleservice.NewService(productRepo, saleRepo, settingsRepo)
 	reportSvc := reportservice.NewService(reportRepo)
 	backupSvc := backupservice.NewService(backupRepo, store.Path())
 	settingsSvc := settingsservice.NewService(settingsRepo)
-	invoiceSvc, err := invoiceservice.NewService(saleRepo, settingsRepo)
+	locationSvc := locationservice.NewService(locationRepo)
+	replenishmentSvc := replenishmentservice.NewService(purchasingRepo, settingsRepo)
+	categorySvc := categoryservice.NewService(categoryRepo)
+	lowStockSvc := lowstockservice.NewService(lowStockRepo, settingsRepo)
+	sequenceSvc := sequenceservice.NewService(sequenceRepo)
+	cartSvc := cartservice.NewService(cartRepo, settingsRepo)
+	quoteSvc := quoteservice.NewService(quoteRepo, saleSvc)
+	customerSvc := customerservice.NewService(customerRepo)
+	loyaltySvc := loyaltyservice.NewService(loyaltyRepo, settingsRepo)
+	storedValueSvc := storedvalueservice.NewService(storedValueRepo)
+	promotionSvc := promotionservice.NewService(promotionRepo)
+	taxSvc := taxservice.NewService(taxRepo, settingsRepo)
+	invoiceSvc, err := invoiceservice.NewService(saleRepo, quoteRepo, settingsRepo)
 	if err != nil {
 		return nil, fmt.Errorf("initialise invoice service: %w", err)
 	}
 
 	app := &App{
-		logger: logger,
-		store:  store,
-		backup: backupSvc,
+		logger:   logger,
+		store:    store,
+		backup:   backupSvc,
+		catalog:  productSvc,
+		lowStock: lowStockSvc,
+		cartHold: cartSvc,
+		points:   loyaltySvc,
 	}
+	productSvc.SetStockObserver(app.notifyStockChange)
+	saleSvc.SetStockObserver(app.notifyStockChange)
+	saleSvc.SetPromotions(promotionRepo)
+	saleSvc.SetTaxes(taxRepo)
+	quoteSvc.SetStockObserver(app.notifyStockChange)
 	app.products = productapi.New(productSvc, app.runtimeContext)
 	app.sales = saleapi.New(saleSvc, app.runtimeContext)
 	app.reports = reportapi.New(reportSvc, app.runtimeContext)
@@ -84,6 +160,18 @@ func New(logger *slog.Logger) (*App, error) {
 	app.settings.WithContextSource(app.runtimeContext)
 	app.invoices = invoiceapi.New(invoiceSvc)
 	app.invoices.WithContextSource(app.runtimeContext)
+	app.locations = locationapi.New(locationSvc, app.runtimeContext)
+	app.replenish = replenishmentapi.New(replenishmentSvc, app.runtimeContext)
+	app.categories = categoryapi.New(categorySvc, app.runtimeContext)
+	app.alerts = lowstockapi.New(lowStockSvc, app.runtimeContext)
+	app.sequences = sequenceapi.New(sequenceSvc, app.runtimeContext)
+	app.carts = cartapi.New(cartSvc, app.runtimeContext)
+	app.quotes = quoteapi.New(quoteSvc, app.runtimeContext)
+	app.customers = customerapi.New(customerSvc, app.runtimeContext)
+	app.loyalty = loyaltyapi.New(loyaltySvc, app.runtimeContext)
+	app.giftCards = storedvalueapi.New(storedValueSvc, app.runtimeContext)
+	app.promotions = promotionapi.New(promotionSvc, app.runtimeContext)
+	app.taxes = taxapi.New(taxSvc, app.runtimeContext)
 
 	return app, nil
 }
@@ -93,12 +181,18 @@ func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
 	a.logger.InfoContext(ctx, "app.startup")
 	a.backup.StartScheduler(ctx)
+	a.catalog.StartPriceScheduler(ctx)
+	a.cartHold.StartExpiryScheduler(ctx)
+	a.points.StartExpiryScheduler(ctx)
 }
 
 // Shutdown releases resources when the runtime exits.
 func (a *App) Shutdown(ctx context.Context) {
 	a.logger.InfoContext(ctx, "app.shutdown")
 	a.backup.StopScheduler()
+	a.catalog.StopPriceScheduler()
+	a.cartHold.StopExpiryScheduler()
+	a.points.StopExpiryScheduler()
 	if _, err := a.backup.Create(ctx); err != nil {
 		a.logger.ErrorContext(ctx, "backup.create", slog.String("error", err.Error()))
 	}
@@ -146,6 +240,82 @@ func (a *App) Invoices() *invoiceapi.API {
 	return a.invoices
 }
 
+// Locations exposes stock locations and transfers.
+func (a *App) Locations() *locationapi.API {
+	return a.locations
+}
+
+// Replenishment exposes suppliers, reorder suggestions and purchase orders.
+func (a *App) Replenishment() *replenishmentapi.API {
+	return a.replenish
+}
+
+// Categories exposes the product category hierarchy.
+func (a *App) Categories() *categoryapi.API {
+	return a.categories
+}
+
+// Sequences exposes document number formats.
+func (a *App) Sequences() *sequenceapi.API {
+	return a.sequences
+}
+
+// LowStock exposes the low-stock list and its alerts.
+func (a *App) LowStock() *lowstockapi.API {
+	return a.alerts
+}
+
+// Carts exposes carts parked at the till.
+func (a *App) Carts() *cartapi.API {
+	return a.carts
+}
+
+// Quotes exposes quotes and their conversion into sales.
+func (a *App) Quotes() *quoteapi.API {
+	return a.quotes
+}
+
+// Customers exposes customer records and their purchase history.
+func (a *App) Customers() *customerapi.API {
+	return a.customers
+}
+
+// Loyalty exposes the loyalty programme and customers' points.
+func (a *App) Loyalty() *loyaltyapi.API {
+	return a.loyalty
+}
+
+// StoredValue exposes gift cards and store credit.
+func (a *App) StoredValue() *storedvalueapi.API {
+	return a.giftCards
+}
+
+// Promotions exposes promotions and coupons.
+func (a *App) Promotions() *promotionapi.API {
+	return a.promotions
+}
+
+// Taxes exposes the tax policy and tax groups.
+func (a *App) Taxes() *taxapi.API {
+	return a.taxes
+}
+
+// notifyStockChange re-checks products after their stock moved and pushes newly raised
+// low-stock alerts to the frontend once the runtime is up.
+func (a *App) notifyStockChange(ctx context.Context, productIDs []int64) {
+	alerts, err := a.lowStock.Check(ctx, productIDs)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "lowstock.check", slog.String("error", err.Error()))
+		return
+	}
+	if a.ctx == nil {
+		return
+	}
+	for _, alert := range alerts {
+		runtime.EventsEmit(a.ctx, lowstock.EventAlertRaised, alert)
+	}
+}
+
 func (a *App) runtimeContext() context.Context {
 	if a.ctx != nil {
 		return a.ctx
@@ -0,0 +1,118 @@
+// Package cart models carts parked at the point of sale to be resumed later.
+package cart
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Held cart statuses. Only Held carts can be resumed or discarded.
+const (
+	StatusHeld      = "Held"
+	StatusResumed   = "Resumed"
+	StatusDiscarded = "Discarded"
+	StatusExpired   = "Expired"
+)
+
+// Line is a product waiting in a held cart.
+type Line struct {
+	ProductID     int64    `json:"productId"`
+	ProductName   string   `json:"productName"`
+	SKU           string   `json:"sku"`
+	Quantity      int64    `json:"quantity"`
+	DiscountCents int64    `json:"discountCents"`
+	SerialNumbers []string `json:"serialNumbers,omitempty"`
+}
+
+// Held is a cart parked at the till. When ReserveStock is set its products are held back
+// from other sales at LocationID until the cart is resumed, discarded or expires.
+type Held struct {
+	ID            int64      `json:"id"`
+	CustomerName  string     `json:"customerName"`
+	DiscountCents int64      `json:"discountCents"`
+	Note          string     `json:"note"`
+	ParkedBy      string     `json:"parkedBy"`
+	LocationID    int64      `json:"locationId"`
+	ReserveStock  bool       `json:"reserveStock"`
+	Status        string     `json:"status"`
+	ParkedAt      time.Time  `json:"parkedAt"`
+	ExpiresAt     time.Time  `json:"expiresAt"`
+	ClosedAt      *time.Time `json:"closedAt,omitempty"`
+	Lines         []Line     `json:"lines"`
+}
+
+// Expired reports whether the cart's hold has run out at now.
+func (h Held) Expired(now time.Time) bool {
+	return !now.Before(h.ExpiresAt)
+}
+
+// Draft is the data needed to park a cart.
+type Draft struct {
+	CustomerName  string
+	DiscountCents int64
+	Note          string
+	ParkedBy      string
+	LocationID    int64
+	ReserveStock  bool
+	Lines         []Line
+}
+
+// Validate ensures the draft holds at least one product and sane amounts.
+func (d Draft) Validate() error {
+	if len(d.Lines) == 0 {
+		return errors.New("at least one line item required")
+	}
+	if d.DiscountCents < 0 {
+		return errors.New("discount must be >= 0")
+	}
+	if strings.TrimSpace(d.ParkedBy) == "" {
+		return errors.New("parked by required")
+	}
+	for i, line := range d.Lines {
+		if line.ProductID <= 0 {
+			return fmt.Errorf("line %d: product id required", i)
+		}
+		if line.Quantity <= 0 {
+			return fmt.Errorf("line %d: quantity must be > 0", i)
+		}
+		if line.DiscountCents < 0 {
+			return fmt.Errorf("line %d: discount must be >= 0", i)
+		}
+	}
+	return nil
+}
+
+// Policy controls how long carts stay held.
+type Policy struct {
+	// ExpiryHours is how long a parked cart is held before it expires.
+	ExpiryHours int64 `json:"expiryHours"`
+}
+
+// DefaultPolicy holds carts for a day.
+func DefaultPolicy() Policy {
+	p := Policy{}
+	p.ApplyDefaults()
+	return p
+}
+
+// ApplyDefaults fills an unset expiry.
+func (p *Policy) ApplyDefaults() {
+	if p.ExpiryHours <= 0 {
+		p.ExpiryHours = 24
+	}
+}
+
+// Validate rejects holds longer than a month.
+func (p Policy) Validate() error {
+	if p.ExpiryHours <= 0 || p.ExpiryHours > 720 {
+		return errors.New("cart expiry must be between 1 and 720 hours")
+	}
+	return nil
+}
+
+// Expiry returns when a cart parked at parkedAt expires.
+func (p Policy) Expiry(parkedAt time.Time) time.Time {
+	return parkedAt.Add(time.Duration(p.ExpiryHours) * time.Hour)
+}
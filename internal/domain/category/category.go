@@ -0,0 +1,70 @@
+package category
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PathSeparator joins category names from root to leaf, e.g. "Food > Dairy".
+const PathSeparator = " > "
+
+// Category is a node in the product category hierarchy. Defaults left nil are
+// inherited from the nearest ancestor that sets them.
+type Category struct {
+	ID                        int64  `json:"id"`
+	Name                      string `json:"name"`
+	ParentID                  int64  `json:"parentId"`
+	Path                      string `json:"path"`
+	DefaultTaxRateBasisPoints *int64 `json:"defaultTaxRateBasisPoints"`
+	DefaultReorderLevel       *int64 `json:"defaultReorderLevel"`
+	ProductCount              int64  `json:"productCount"`
+}
+
+// Input describes editable category fields.
+type Input struct {
+	Name                      string
+	ParentID                  int64
+	DefaultTaxRateBasisPoints *int64
+	DefaultReorderLevel       *int64
+}
+
+// Validate ensures the category input is usable.
+func (in Input) Validate() error {
+	name := strings.TrimSpace(in.Name)
+	if name == "" {
+		return errors.New("category name is required")
+	}
+	if strings.Contains(name, strings.TrimSpace(PathSeparator)) {
+		return fmt.Errorf("category name cannot contain %q", strings.TrimSpace(PathSeparator))
+	}
+	if in.ParentID < 0 {
+		return errors.New("parent id must be >= 0")
+	}
+	if in.DefaultTaxRateBasisPoints != nil && *in.DefaultTaxRateBasisPoints < 0 {
+		return errors.New("default tax rate must be >= 0")
+	}
+	if in.DefaultReorderLevel != nil && *in.DefaultReorderLevel < 0 {
+		return errors.New("default reorder level must be >= 0")
+	}
+	return nil
+}
+
+// SplitPath normalises a free-text category path into trimmed, non-empty names.
+// Whitespace inside names is collapsed so "Dairy  Products" matches "Dairy Products".
+func SplitPath(path string) []string {
+	parts := strings.Split(path, strings.TrimSpace(PathSeparator))
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		name := strings.Join(strings.Fields(part), " ")
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// JoinPath renders names as a display path.
+func JoinPath(names []string) string {
+	return strings.Join(names, PathSeparator)
+}
@@ -0,0 +1,24 @@
+package category
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", []string{}},
+		{"Dairy", []string{"Dairy"}},
+		{" dairy  ", []string{"dairy"}},
+		{"Food > Dairy", []string{"Food", "Dairy"}},
+		{"Food>  Dairy  Products >", []string{"Food", "Dairy Products"}},
+	}
+	for _, tc := range tests {
+		if got := SplitPath(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("SplitPath(%q) = %q want %q", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package customer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var csvHeaders = []string{"name", "phone", "email", "address", "tax_id", "notes", "tax_exempt", "tax_exemption_number"}
+
+// ImportRow is a customer read from an import file.
+type ImportRow struct {
+	Line  int
+	Input Input
+}
+
+// ParseCSV decodes customers whose headers use the export names. Columns may appear in any
+// order, unknown columns are ignored and only the name column is required.
+func ParseCSV(r io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	columns := make(map[string]int, len(headers))
+	for i, header := range headers {
+		columns[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("missing required column: name")
+	}
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	var (
+		rows     []ImportRow
+		lineNo   = 1 // header already consumed
+		parseErr []string
+	)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		lineNo++
+		if err != nil {
+			parseErr = append(parseErr, fmt.Sprintf("line %d: %v", lineNo, err))
+			continue
+		}
+
+		in := Input{
+			Name:               field(record, "name"),
+			Phone:              field(record, "phone"),
+			Email:              field(record, "email"),
+			Address:            field(record, "address"),
+			TaxID:              field(record, "tax_id"),
+			Notes:              field(record, "notes"),
+			TaxExemptionNumber: field(record, "tax_exemption_number"),
+		}
+		switch strings.ToLower(strings.TrimSpace(field(record, "tax_exempt"))) {
+		case "", "false", "no", "n", "0":
+		case "true", "yes", "y", "1":
+			in.TaxExempt = true
+		default:
+			parseErr = append(parseErr, fmt.Sprintf("line %d: tax_exempt must be yes or no", lineNo))
+			continue
+		}
+		in.Normalize()
+		if err := in.Validate(); err != nil {
+			parseErr = append(parseErr, fmt.Sprintf("line %d: %v", lineNo, err))
+			continue
+		}
+		rows = append(rows, ImportRow{Line: lineNo, Input: in})
+	}
+
+	if len(parseErr) > 0 {
+		return rows, fmt.Errorf("csv validation: %s", strings.Join(parseErr, "; "))
+	}
+	return rows, nil
+}
+
+// WriteCSV renders customers to CSV following the import contract.
+func WriteCSV(w io.Writer, customers []Customer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeaders); err != nil {
+		return fmt.Errorf("write headers: %w", err)
+	}
+	for _, c := range customers {
+		exempt := "no"
+		if c.TaxExempt {
+			exempt = "yes"
+		}
+		if err := writer.Write([]string{c.Name, c.Phone, c.Email, c.Address, c.TaxID, c.Notes, exempt, c.TaxExemptionNumber}); err != nil {
+			return fmt.Errorf("write record: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("flush csv: %w", err)
+	}
+	return nil
+}
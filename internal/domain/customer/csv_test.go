@@ -0,0 +1,44 @@
+package customer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	customers := []Customer{
+		{Name: "John Smith", Phone: "555-0100", Email: "john@example.com", Address: "1 High St, Springfield", TaxID: "GB123", Notes: "Trade", TaxExempt: true, TaxExemptionNumber: "EX-42"},
+		{Name: "Walk-in Wendy"},
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, customers); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	rows, err := ParseCSV(&buf)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Input.Address != "1 High St, Springfield" || rows[1].Line != 3 {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+	if !rows[0].Input.TaxExempt || rows[0].Input.TaxExemptionNumber != "EX-42" || rows[1].Input.TaxExempt {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+}
+
+func TestParseCSVReportsBadRows(t *testing.T) {
+	data := "Email,Name,Loyalty,Tax_Exempt\n JOHN@Example.com ,John,gold,\nnobody@example.com,,,\nbad,Bob,,\nann@example.com,Ann,,maybe\n"
+	rows, err := ParseCSV(strings.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), "line 3") || !strings.Contains(err.Error(), "line 4") || !strings.Contains(err.Error(), "line 5") {
+		t.Fatalf("expected lines 3 to 5 rejected, got %v", err)
+	}
+	if len(rows) != 1 || rows[0].Input.Email != "john@example.com" {
+		t.Fatalf("expected the valid row normalised, got %+v", rows)
+	}
+
+	if _, err := ParseCSV(strings.NewReader("phone\n555\n")); err == nil {
+		t.Fatal("expected a missing name column to be rejected")
+	}
+}
@@ -0,0 +1,80 @@
+// Package customer models the shop's customer records and their purchase history.
+package customer
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Customer is a person or business the shop sells to.
+type Customer struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Phone   string `json:"phone"`
+	Email   string `json:"email"`
+	Address string `json:"address"`
+	TaxID   string `json:"taxId"`
+	Notes   string `json:"notes"`
+	// TaxExempt customers are sold to without tax, under TaxExemptionNumber.
+	TaxExempt          bool      `json:"taxExempt"`
+	TaxExemptionNumber string    `json:"taxExemptionNumber"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+// Input holds the editable fields of a customer.
+type Input struct {
+	Name    string
+	Phone   string
+	Email   string
+	Address string
+	TaxID   string
+	Notes   string
+	// TaxExempt sells to the customer without tax.
+	TaxExempt          bool
+	TaxExemptionNumber string
+}
+
+// Normalize trims surrounding whitespace and lower-cases the email.
+func (in *Input) Normalize() {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Phone = strings.TrimSpace(in.Phone)
+	in.Email = strings.ToLower(strings.TrimSpace(in.Email))
+	in.Address = strings.TrimSpace(in.Address)
+	in.TaxID = strings.TrimSpace(in.TaxID)
+	in.Notes = strings.TrimSpace(in.Notes)
+	in.TaxExemptionNumber = strings.TrimSpace(in.TaxExemptionNumber)
+}
+
+// Validate requires a name and a plausible email when one is given.
+func (in Input) Validate() error {
+	if strings.TrimSpace(in.Name) == "" {
+		return errors.New("customer name is required")
+	}
+	if email := strings.TrimSpace(in.Email); email != "" && !strings.Contains(email, "@") {
+		return errors.New("customer email is not valid")
+	}
+	return nil
+}
+
+// Purchase is a sale in a customer's history, net of refunds.
+type Purchase struct {
+	SaleID        int64     `json:"saleId"`
+	SaleNumber    string    `json:"saleNumber"`
+	Timestamp     time.Time `json:"timestamp"`
+	Status        string    `json:"status"`
+	TotalCents    int64     `json:"totalCents"`
+	RefundedCents int64     `json:"refundedCents"`
+}
+
+// Detail is a customer with their lifetime spend and purchase history. Lifetime spend
+// counts sales that were not voided, net of refunds; Purchases lists the most recent
+// sales first, voided ones included.
+type Detail struct {
+	Customer           Customer   `json:"customer"`
+	SaleCount          int        `json:"saleCount"`
+	LifetimeSpendCents int64      `json:"lifetimeSpendCents"`
+	LastPurchaseAt     *time.Time `json:"lastPurchaseAt,omitempty"`
+	Purchases          []Purchase `json:"purchases"`
+}
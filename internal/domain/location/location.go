@@ -0,0 +1,95 @@
+package location
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Location is a place where stock is held, such as the shop floor or a branch.
+type Location struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	IsDefault bool      `json:"isDefault"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Input describes the editable fields of a location.
+type Input struct {
+	Name   string
+	Active bool
+}
+
+// Validate ensures the location input is usable.
+func (in Input) Validate() error {
+	if strings.TrimSpace(in.Name) == "" {
+		return errors.New("location name is required")
+	}
+	return nil
+}
+
+// StockLevel is the quantity of a product held at one location.
+type StockLevel struct {
+	ProductID    int64  `json:"productId"`
+	ProductName  string `json:"productName"`
+	SKU          string `json:"sku"`
+	LocationID   int64  `json:"locationId"`
+	LocationName string `json:"locationName"`
+	Qty          int64  `json:"qty"`
+}
+
+// Transfer moves stock from one location to another.
+type Transfer struct {
+	ID             int64          `json:"id"`
+	TransferNumber string         `json:"transferNumber"`
+	FromLocationID int64          `json:"fromLocationId"`
+	ToLocationID   int64          `json:"toLocationId"`
+	Timestamp      time.Time      `json:"timestamp"`
+	Note           string         `json:"note"`
+	Lines          []TransferLine `json:"lines"`
+}
+
+// TransferLine is one product moved by a transfer.
+type TransferLine struct {
+	ProductID   int64  `json:"productId"`
+	ProductName string `json:"productName"`
+	SKU         string `json:"sku"`
+	Quantity    int64  `json:"quantity"`
+}
+
+// TransferDraft is the data needed to record a transfer.
+type TransferDraft struct {
+	FromLocationID int64
+	ToLocationID   int64
+	Note           string
+	Lines          []TransferLine
+}
+
+// Validate ensures the transfer moves positive quantities between distinct locations.
+func (d TransferDraft) Validate() error {
+	if d.FromLocationID <= 0 || d.ToLocationID <= 0 {
+		return errors.New("source and destination locations are required")
+	}
+	if d.FromLocationID == d.ToLocationID {
+		return errors.New("source and destination must differ")
+	}
+	if len(d.Lines) == 0 {
+		return errors.New("at least one line item required")
+	}
+	seen := make(map[int64]struct{}, len(d.Lines))
+	for i, line := range d.Lines {
+		if line.ProductID <= 0 {
+			return fmt.Errorf("line %d: product id required", i)
+		}
+		if line.Quantity <= 0 {
+			return fmt.Errorf("line %d: quantity must be > 0", i)
+		}
+		if _, dup := seen[line.ProductID]; dup {
+			return fmt.Errorf("line %d: product listed more than once", i)
+		}
+		seen[line.ProductID] = struct{}{}
+	}
+	return nil
+}
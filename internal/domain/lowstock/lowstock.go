@@ -0,0 +1,149 @@
+// Package lowstock evaluates product stock against the shop's low-stock alert policy.
+package lowstock
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+// Alert rules.
+const (
+	RuleReorderLevel  = "ReorderLevel"
+	RuleZeroStock     = "ZeroStock"
+	RuleNegativeTrend = "NegativeTrend"
+)
+
+// EventAlertRaised is the runtime event emitted to the frontend for each new alert.
+const EventAlertRaised = "lowstock:alert"
+
+// Policy selects which rules raise alerts.
+type Policy struct {
+	// ReorderLevel alerts when stock is at or below a product's reorder level.
+	ReorderLevel bool `json:"reorderLevel"`
+	// ZeroStock alerts when stock runs out.
+	ZeroStock bool `json:"zeroStock"`
+	// NegativeTrend alerts when recent sales would empty the shelf within TrendCoverDays.
+	NegativeTrend  bool  `json:"negativeTrend"`
+	TrendCoverDays int64 `json:"trendCoverDays"`
+	// WindowDays is the sales history used for average daily sales and days of cover.
+	WindowDays int64 `json:"windowDays"`
+	// SnoozeHours is how long a snoozed alert stays hidden when no duration is given.
+	SnoozeHours int64 `json:"snoozeHours"`
+}
+
+// DefaultPolicy alerts on reorder level and zero stock.
+func DefaultPolicy() Policy {
+	p := Policy{ReorderLevel: true, ZeroStock: true}
+	p.ApplyDefaults()
+	return p
+}
+
+// ApplyDefaults fills unset durations.
+func (p *Policy) ApplyDefaults() {
+	if p.TrendCoverDays <= 0 {
+		p.TrendCoverDays = 7
+	}
+	if p.WindowDays <= 0 {
+		p.WindowDays = 28
+	}
+	if p.SnoozeHours <= 0 {
+		p.SnoozeHours = 24
+	}
+}
+
+// Validate rejects nonsensical policies.
+func (p Policy) Validate() error {
+	if p.WindowDays <= 0 || p.WindowDays > 365 {
+		return errors.New("sales window must be between 1 and 365 days")
+	}
+	if p.TrendCoverDays <= 0 {
+		return errors.New("trend cover days must be > 0")
+	}
+	if p.SnoozeHours <= 0 {
+		return errors.New("snooze hours must be > 0")
+	}
+	return nil
+}
+
+// Stock is a product's stock position and recent sales.
+type Stock struct {
+	ProductID    int64
+	ProductName  string
+	SKU          string
+	CurrentQty   int64
+	ReorderLevel int64
+	UnitsSold    int64
+}
+
+// Item is a product the policy flags as low on stock. DaysOfCover is nil when the product
+// has not sold during the window. Alerts lists the product's open alerts.
+type Item struct {
+	ProductID     int64    `json:"productId"`
+	ProductName   string   `json:"productName"`
+	SKU           string   `json:"sku"`
+	CurrentQty    int64    `json:"currentQty"`
+	ReorderLevel  int64    `json:"reorderLevel"`
+	Shortfall     int64    `json:"shortfall"`
+	AvgDailySales float64  `json:"avgDailySales"`
+	DaysOfCover   *float64 `json:"daysOfCover,omitempty"`
+	Rules         []string `json:"rules"`
+	Alerts        []Alert  `json:"alerts"`
+}
+
+// Evaluate applies the policy to a product's stock, reporting whether any rule fires.
+// Shortfall is how far stock sits below the reorder level (or below zero when none is set).
+func Evaluate(s Stock, policy Policy) (Item, bool) {
+	policy.ApplyDefaults()
+
+	item := Item{
+		ProductID:     s.ProductID,
+		ProductName:   s.ProductName,
+		SKU:           s.SKU,
+		CurrentQty:    s.CurrentQty,
+		ReorderLevel:  s.ReorderLevel,
+		Shortfall:     max(s.ReorderLevel, 0) - s.CurrentQty,
+		AvgDailySales: float64(s.UnitsSold) / float64(policy.WindowDays),
+		Rules:         make([]string, 0, 3),
+		Alerts:        make([]Alert, 0),
+	}
+	if item.Shortfall < 0 {
+		item.Shortfall = 0
+	}
+	if item.AvgDailySales > 0 {
+		cover := math.Max(float64(s.CurrentQty), 0) / item.AvgDailySales
+		item.DaysOfCover = &cover
+	}
+
+	if policy.ReorderLevel && s.ReorderLevel > 0 && s.CurrentQty <= s.ReorderLevel {
+		item.Rules = append(item.Rules, RuleReorderLevel)
+	}
+	if policy.ZeroStock && s.CurrentQty <= 0 {
+		item.Rules = append(item.Rules, RuleZeroStock)
+	}
+	if policy.NegativeTrend && item.DaysOfCover != nil && *item.DaysOfCover < float64(policy.TrendCoverDays) {
+		item.Rules = append(item.Rules, RuleNegativeTrend)
+	}
+	return item, len(item.Rules) > 0
+}
+
+// Alert is a de-duplicated notification that a product crossed a rule threshold. Only one
+// alert per product and rule is open at a time; it resolves once the rule stops firing.
+type Alert struct {
+	ID             int64      `json:"id"`
+	ProductID      int64      `json:"productId"`
+	ProductName    string     `json:"productName"`
+	SKU            string     `json:"sku"`
+	Rule           string     `json:"rule"`
+	CurrentQty     int64      `json:"currentQty"`
+	Shortfall      int64      `json:"shortfall"`
+	RaisedAt       time.Time  `json:"raisedAt"`
+	AcknowledgedAt *time.Time `json:"acknowledgedAt,omitempty"`
+	SnoozedUntil   *time.Time `json:"snoozedUntil,omitempty"`
+	ResolvedAt     *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// Snoozed reports whether the alert is hidden at the given time.
+func (a Alert) Snoozed(now time.Time) bool {
+	return a.SnoozedUntil != nil && a.SnoozedUntil.After(now)
+}
@@ -0,0 +1,48 @@
+package lowstock
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	policy := Policy{ReorderLevel: true, ZeroStock: true, NegativeTrend: true, TrendCoverDays: 7, WindowDays: 10}
+
+	tests := []struct {
+		name          string
+		stock         Stock
+		wantRules     []string
+		wantShortfall int64
+		wantCover     float64
+	}{
+		{"healthy", Stock{CurrentQty: 50, ReorderLevel: 5, UnitsSold: 10}, nil, 0, 50},
+		{"atReorderLevel", Stock{CurrentQty: 5, ReorderLevel: 5}, []string{RuleReorderLevel}, 0, -1},
+		{"belowReorderLevel", Stock{CurrentQty: 2, ReorderLevel: 5, UnitsSold: 100}, []string{RuleReorderLevel, RuleNegativeTrend}, 3, 0.2},
+		{"outOfStock", Stock{CurrentQty: 0, ReorderLevel: 4}, []string{RuleReorderLevel, RuleZeroStock}, 4, -1},
+		{"negativeWithoutLevel", Stock{CurrentQty: -2}, []string{RuleZeroStock}, 2, -1},
+		{"trendOnly", Stock{CurrentQty: 12, UnitsSold: 20}, []string{RuleNegativeTrend}, 0, 6},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := Evaluate(tc.stock, policy)
+			if ok != (len(tc.wantRules) > 0) || !slices.Equal(got.Rules, tc.wantRules) {
+				t.Fatalf("rules = %v (ok %v) want %v", got.Rules, ok, tc.wantRules)
+			}
+			if got.Shortfall != tc.wantShortfall {
+				t.Fatalf("shortfall = %d want %d", got.Shortfall, tc.wantShortfall)
+			}
+			if tc.wantCover < 0 {
+				if got.DaysOfCover != nil {
+					t.Fatalf("expected no days of cover, got %v", *got.DaysOfCover)
+				}
+			} else if got.DaysOfCover == nil || *got.DaysOfCover != tc.wantCover {
+				t.Fatalf("days of cover = %v want %v", got.DaysOfCover, tc.wantCover)
+			}
+		})
+	}
+
+	if _, ok := Evaluate(Stock{CurrentQty: 0, UnitsSold: 5}, Policy{}); ok {
+		t.Fatalf("an empty policy should not raise alerts")
+	}
+}
@@ -0,0 +1,190 @@
+// Package loyalty models the points customers earn on their purchases and spend at the till.
+package loyalty
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Ledger entry kinds. Earn, Refund and Adjust credits open a lot of points that later debits
+// consume, soonest to expire first; Expire removes what is left of a lot once it runs out.
+const (
+	KindEarn    = "Earn"
+	KindRedeem  = "Redeem"
+	KindReverse = "Reverse"
+	KindRefund  = "Refund"
+	KindExpire  = "Expire"
+	KindAdjust  = "Adjust"
+)
+
+// maxMultiplierPercent caps a category multiplier at ten times the base rate.
+const maxMultiplierPercent = 1000
+
+// CategoryMultiplier scales the points earned on a category and its subcategories.
+// MultiplierPercent is 100 for the base rate, 200 for double points.
+type CategoryMultiplier struct {
+	CategoryID        int64 `json:"categoryId"`
+	MultiplierPercent int64 `json:"multiplierPercent"`
+}
+
+// Policy holds the earn and redemption rules. Points are earned on the amount paid before
+// tax, less discounts and the part paid with points.
+type Policy struct {
+	Enabled bool `json:"enabled"`
+	// PointsPerUnit is the points earned per whole currency unit spent.
+	PointsPerUnit int64 `json:"pointsPerUnit"`
+	// PointValueCents is what one point is worth when redeemed.
+	PointValueCents int64                `json:"pointValueCents"`
+	Multipliers     []CategoryMultiplier `json:"multipliers"`
+	// ExcludedCategoryIDs and ExcludedProductIDs earn no points; excluding a category
+	// excludes its subcategories.
+	ExcludedCategoryIDs []int64 `json:"excludedCategoryIds"`
+	ExcludedProductIDs  []int64 `json:"excludedProductIds"`
+	// ExpiryMonths is how long points last once credited; zero keeps them forever.
+	ExpiryMonths int `json:"expiryMonths"`
+}
+
+// DefaultPolicy is a disabled programme earning a point per unit, each worth one cent.
+func DefaultPolicy() Policy {
+	p := Policy{}
+	p.ApplyDefaults()
+	return p
+}
+
+// ApplyDefaults fills unset rates.
+func (p *Policy) ApplyDefaults() {
+	if p.PointsPerUnit <= 0 {
+		p.PointsPerUnit = 1
+	}
+	if p.PointValueCents <= 0 {
+		p.PointValueCents = 1
+	}
+	if p.ExpiryMonths < 0 {
+		p.ExpiryMonths = 0
+	}
+}
+
+// Validate rejects unusable rates and multipliers.
+func (p Policy) Validate() error {
+	if p.PointsPerUnit <= 0 || p.PointsPerUnit > 1000 {
+		return errors.New("points per unit must be between 1 and 1000")
+	}
+	if p.PointValueCents <= 0 || p.PointValueCents > 10000 {
+		return errors.New("point value must be between 1 and 10000 cents")
+	}
+	if p.ExpiryMonths < 0 || p.ExpiryMonths > 120 {
+		return errors.New("points expiry must be between 0 and 120 months")
+	}
+	seen := make(map[int64]bool, len(p.Multipliers))
+	for _, m := range p.Multipliers {
+		if m.CategoryID <= 0 {
+			return errors.New("multiplier category required")
+		}
+		if seen[m.CategoryID] {
+			return fmt.Errorf("category %d has more than one multiplier", m.CategoryID)
+		}
+		seen[m.CategoryID] = true
+		if m.MultiplierPercent < 0 || m.MultiplierPercent > maxMultiplierPercent {
+			return fmt.Errorf("category %d multiplier must be between 0 and %d percent", m.CategoryID, maxMultiplierPercent)
+		}
+	}
+	return nil
+}
+
+// Expiry returns when points credited at creditedAt expire, or nil when they never do.
+func (p Policy) Expiry(creditedAt time.Time) *time.Time {
+	if p.ExpiryMonths <= 0 {
+		return nil
+	}
+	at := creditedAt.AddDate(0, p.ExpiryMonths, 0)
+	return &at
+}
+
+// PointsFor converts an amount paid with points into the points it costs. The amount must
+// be a whole number of points.
+func (p Policy) PointsFor(amountCents int64) (int64, error) {
+	if amountCents%p.PointValueCents != 0 {
+		return 0, fmt.Errorf("points pay in steps of %d cents", p.PointValueCents)
+	}
+	return amountCents / p.PointValueCents, nil
+}
+
+// Value returns what points are worth in cents.
+func (p Policy) Value(points int64) int64 {
+	return points * p.PointValueCents
+}
+
+// EarnLine is the part of a sale line that can earn points. CategoryIDs lists the
+// product's category followed by its ancestors, nearest first.
+type EarnLine struct {
+	ProductID   int64
+	CategoryIDs []int64
+	AmountCents int64
+}
+
+// Earn returns the points earned on lines of a sale totalling totalCents, of which
+// pointsPaidCents was paid with points and earns nothing.
+func (p Policy) Earn(lines []EarnLine, totalCents, pointsPaidCents int64) int64 {
+	if !p.Enabled || totalCents <= 0 {
+		return 0
+	}
+	var weighted int64
+	for _, line := range lines {
+		if line.AmountCents <= 0 {
+			continue
+		}
+		weighted += line.AmountCents * p.multiplier(line)
+	}
+	if pointsPaidCents > 0 {
+		weighted = weighted * max(totalCents-pointsPaidCents, 0) / totalCents
+	}
+	return weighted * p.PointsPerUnit / (100 * 100)
+}
+
+// multiplier returns the percentage a line earns at: zero when excluded, otherwise the
+// multiplier of its nearest category that has one, or the base rate.
+func (p Policy) multiplier(line EarnLine) int64 {
+	if slices.Contains(p.ExcludedProductIDs, line.ProductID) {
+		return 0
+	}
+	for _, id := range line.CategoryIDs {
+		if slices.Contains(p.ExcludedCategoryIDs, id) {
+			return 0
+		}
+	}
+	for _, id := range line.CategoryIDs {
+		for _, m := range p.Multipliers {
+			if m.CategoryID == id {
+				return m.MultiplierPercent
+			}
+		}
+	}
+	return 100
+}
+
+// Entry is one movement of a customer's points. Points is positive for credits and
+// negative for debits.
+type Entry struct {
+	ID         int64      `json:"id"`
+	CustomerID int64      `json:"customerId"`
+	Timestamp  time.Time  `json:"timestamp"`
+	Kind       string     `json:"kind"`
+	Points     int64      `json:"points"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	SaleID     int64      `json:"saleId,omitempty"`
+	SaleNumber string     `json:"saleNumber,omitempty"`
+	RefundID   int64      `json:"refundId,omitempty"`
+	Note       string     `json:"note,omitempty"`
+}
+
+// Account is a customer's points balance and what it is worth.
+type Account struct {
+	CustomerID int64 `json:"customerId"`
+	Points     int64 `json:"points"`
+	ValueCents int64 `json:"valueCents"`
+	// ExpiringPoints are due to expire at NextExpiryAt, the soonest expiry.
+	ExpiringPoints int64      `json:"expiringPoints"`
+	NextExpiryAt   *time.Time `json:"nextExpiryAt,omitempty"`
+}
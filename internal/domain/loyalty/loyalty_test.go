@@ -0,0 +1,63 @@
+package loyalty
+
+import "testing"
+
+func TestEarn(t *testing.T) {
+	policy := Policy{
+		Enabled:             true,
+		PointsPerUnit:       2,
+		PointValueCents:     1,
+		Multipliers:         []CategoryMultiplier{{CategoryID: 10, MultiplierPercent: 300}, {CategoryID: 11, MultiplierPercent: 100}},
+		ExcludedCategoryIDs: []int64{20},
+		ExcludedProductIDs:  []int64{99},
+	}
+
+	tests := []struct {
+		name       string
+		lines      []EarnLine
+		total      int64
+		pointsPaid int64
+		want       int64
+	}{
+		{"baseRate", []EarnLine{{ProductID: 1, AmountCents: 1050}}, 1050, 0, 21},
+		{"categoryMultiplier", []EarnLine{{ProductID: 1, CategoryIDs: []int64{10}, AmountCents: 1000}}, 1000, 0, 60},
+		{"nearestCategoryWins", []EarnLine{{ProductID: 1, CategoryIDs: []int64{11, 10}, AmountCents: 1000}}, 1000, 0, 20},
+		{"inheritedMultiplier", []EarnLine{{ProductID: 1, CategoryIDs: []int64{12, 10}, AmountCents: 1000}}, 1000, 0, 60},
+		{"excludedAncestor", []EarnLine{{ProductID: 1, CategoryIDs: []int64{21, 20}, AmountCents: 1000}}, 1000, 0, 0},
+		{"excludedProduct", []EarnLine{{ProductID: 99, CategoryIDs: []int64{10}, AmountCents: 1000}}, 1000, 0, 0},
+		{"pointsPaidEarnNothing", []EarnLine{{ProductID: 1, AmountCents: 2000}}, 2000, 500, 30},
+		{"mixedLines", []EarnLine{{ProductID: 1, AmountCents: 500}, {ProductID: 99, AmountCents: 500}}, 1000, 0, 10},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := policy.Earn(tc.lines, tc.total, tc.pointsPaid); got != tc.want {
+				t.Fatalf("earned %d points, want %d", got, tc.want)
+			}
+		})
+	}
+
+	policy.Enabled = false
+	if got := policy.Earn([]EarnLine{{ProductID: 1, AmountCents: 1000}}, 1000, 0); got != 0 {
+		t.Fatalf("a disabled programme should earn nothing, got %d", got)
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	policy := DefaultPolicy()
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("default policy should be valid: %v", err)
+	}
+	policy.Multipliers = []CategoryMultiplier{{CategoryID: 1, MultiplierPercent: 200}, {CategoryID: 1, MultiplierPercent: 300}}
+	if err := policy.Validate(); err == nil {
+		t.Fatal("expected duplicate multipliers to be rejected")
+	}
+
+	policy = Policy{PointsPerUnit: 1, PointValueCents: 5}
+	if _, err := policy.PointsFor(12); err == nil {
+		t.Fatal("expected a part point to be rejected")
+	}
+	if points, err := policy.PointsFor(250); err != nil || points != 50 {
+		t.Fatalf("expected 50 points, got %d (%v)", points, err)
+	}
+}
@@ -0,0 +1,191 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Attribute value types.
+const (
+	AttributeTypeText    = "text"
+	AttributeTypeNumber  = "number"
+	AttributeTypeEnum    = "enum"
+	AttributeTypeDate    = "date"
+	AttributeTypeBoolean = "boolean"
+)
+
+// attributeDateLayout is the stored form of date attribute values.
+const attributeDateLayout = "2006-01-02"
+
+// attributeFieldPrefix marks import mapping fields that target an attribute rather than a
+// built-in product field, e.g. "attr:brand".
+const attributeFieldPrefix = "attr:"
+
+// Attribute is an admin-defined product field. Code identifies it in the API and is the
+// column header in CSV exports.
+type Attribute struct {
+	ID        int64     `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	Options   []string  `json:"options"`
+	SortOrder int       `json:"sortOrder"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// AttributeInput describes an attribute definition to create or update.
+type AttributeInput struct {
+	Code      string
+	Name      string
+	Type      string
+	Options   []string
+	SortOrder int
+}
+
+// Normalize trims the input, lowercases the code and drops blank or repeated enum options.
+func (in *AttributeInput) Normalize() {
+	in.Code = strings.ToLower(strings.TrimSpace(in.Code))
+	in.Name = strings.TrimSpace(in.Name)
+	in.Type = strings.ToLower(strings.TrimSpace(in.Type))
+
+	options := make([]string, 0, len(in.Options))
+	seen := make(map[string]bool, len(in.Options))
+	for _, option := range in.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || seen[strings.ToLower(option)] {
+			continue
+		}
+		seen[strings.ToLower(option)] = true
+		options = append(options, option)
+	}
+	in.Options = options
+}
+
+// Validate checks a normalised attribute definition.
+func (in AttributeInput) Validate() error {
+	if in.Code == "" {
+		return errors.New("attribute code is required")
+	}
+	if len(in.Code) > 40 {
+		return errors.New("attribute code must be at most 40 characters")
+	}
+	for i, r := range in.Code {
+		letter := r >= 'a' && r <= 'z'
+		if !letter && (i == 0 || !(r >= '0' && r <= '9' || r == '_')) {
+			return errors.New("attribute code must start with a letter and use only letters, digits and underscores")
+		}
+	}
+	for _, header := range csvHeaders {
+		if in.Code == header {
+			return fmt.Errorf("attribute code %q is reserved for a built-in product field", in.Code)
+		}
+	}
+	if in.Name == "" {
+		return errors.New("attribute name is required")
+	}
+	switch in.Type {
+	case AttributeTypeText, AttributeTypeNumber, AttributeTypeDate, AttributeTypeBoolean:
+		if len(in.Options) > 0 {
+			return errors.New("only enum attributes have options")
+		}
+	case AttributeTypeEnum:
+		if len(in.Options) == 0 {
+			return errors.New("enum attributes need at least one option")
+		}
+	default:
+		return fmt.Errorf("unknown attribute type %q", in.Type)
+	}
+	return nil
+}
+
+// NormaliseValue checks a raw value against the attribute type and returns its stored form:
+// numbers without redundant digits, dates as YYYY-MM-DD, booleans as "true"/"false" and
+// enum values spelt as their option. A blank value normalises to "" and means unset.
+func (a Attribute) NormaliseValue(raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return "", nil
+	}
+	switch a.Type {
+	case AttributeTypeNumber:
+		f, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a number", a.Name)
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	case AttributeTypeDate:
+		if t, err := time.Parse(attributeDateLayout, value); err == nil {
+			return t.Format(attributeDateLayout), nil
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t.Format(attributeDateLayout), nil
+		}
+		return "", fmt.Errorf("%s must be a date (YYYY-MM-DD)", a.Name)
+	case AttributeTypeBoolean:
+		switch strings.ToLower(value) {
+		case "true", "yes", "y", "1":
+			return "true", nil
+		case "false", "no", "n", "0":
+			return "false", nil
+		}
+		return "", fmt.Errorf("%s must be yes or no", a.Name)
+	case AttributeTypeEnum:
+		for _, option := range a.Options {
+			if strings.EqualFold(option, value) {
+				return option, nil
+			}
+		}
+		return "", fmt.Errorf("%s must be one of %s", a.Name, strings.Join(a.Options, ", "))
+	default:
+		return value, nil
+	}
+}
+
+// NormaliseAttributeValues checks attribute values keyed by code against the definitions,
+// returning them keyed by canonical code. Blank values are kept to clear the attribute.
+func NormaliseAttributeValues(attributes []Attribute, values map[string]string) (map[string]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	byCode := make(map[string]Attribute, len(attributes))
+	for _, a := range attributes {
+		byCode[a.Code] = a
+	}
+	normalised := make(map[string]string, len(values))
+	for code, raw := range values {
+		a, ok := byCode[strings.ToLower(strings.TrimSpace(code))]
+		if !ok {
+			return nil, fmt.Errorf("unknown attribute %q", code)
+		}
+		value, err := a.NormaliseValue(raw)
+		if err != nil {
+			return nil, err
+		}
+		normalised[a.Code] = value
+	}
+	return normalised, nil
+}
+
+// AttributeField returns the import mapping field for an attribute code.
+func AttributeField(code string) string {
+	return attributeFieldPrefix + code
+}
+
+// attributeCode returns the attribute code of an import mapping field, if it is one.
+func attributeCode(field string) (string, bool) {
+	if !strings.HasPrefix(field, attributeFieldPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(field, attributeFieldPrefix), true
+}
+
+// Filter narrows product listings. Query matches name, SKU, category, notes and attribute
+// values; Attributes requires exact (case-insensitive) attribute values keyed by code.
+type Filter struct {
+	Query           string
+	IncludeArchived bool
+	Attributes      map[string]string
+}
@@ -0,0 +1,56 @@
+package product
+
+import "testing"
+
+func TestAttributeNormaliseValue(t *testing.T) {
+	size := Attribute{Name: "Size", Type: AttributeTypeEnum, Options: []string{"Small", "Large"}}
+	tests := []struct {
+		name    string
+		attr    Attribute
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "blank clears", attr: size, raw: "  ", want: ""},
+		{name: "enum matches option spelling", attr: size, raw: "large", want: "Large"},
+		{name: "enum rejects unknown option", attr: size, raw: "Medium", wantErr: true},
+		{name: "number drops redundant digits", attr: Attribute{Type: AttributeTypeNumber}, raw: "1,250.50", want: "1250.5"},
+		{name: "number rejects text", attr: Attribute{Type: AttributeTypeNumber}, raw: "ten", wantErr: true},
+		{name: "boolean accepts yes", attr: Attribute{Type: AttributeTypeBoolean}, raw: "Yes", want: "true"},
+		{name: "date keeps day", attr: Attribute{Type: AttributeTypeDate}, raw: "2026-03-01T10:00:00Z", want: "2026-03-01"},
+		{name: "date rejects other layouts", attr: Attribute{Type: AttributeTypeDate}, raw: "01/03/2026", wantErr: true},
+		{name: "text is trimmed", attr: Attribute{Type: AttributeTypeText}, raw: " Acme ", want: "Acme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.attr.NormaliseValue(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormaliseValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("NormaliseValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttributeInputValidate(t *testing.T) {
+	in := AttributeInput{Code: " Shelf_Location ", Name: "Shelf", Type: "TEXT"}
+	in.Normalize()
+	if err := in.Validate(); err != nil || in.Code != "shelf_location" {
+		t.Fatalf("expected valid normalised input, got %+v (%v)", in, err)
+	}
+
+	for _, bad := range []AttributeInput{
+		{Code: "sku", Name: "SKU", Type: AttributeTypeText},
+		{Code: "9lives", Name: "Lives", Type: AttributeTypeNumber},
+		{Code: "size", Name: "Size", Type: AttributeTypeEnum},
+		{Code: "brand", Name: "Brand", Type: AttributeTypeText, Options: []string{"Acme"}},
+	} {
+		bad.Normalize()
+		if err := bad.Validate(); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package product
+
+import "time"
+
+// CostLayer is stock received at one unit cost that has not yet been sold or removed.
+type CostLayer struct {
+	ID            int64     `json:"id"`
+	ReceivedAt    time.Time `json:"receivedAt"`
+	Qty           int64     `json:"qty"`
+	RemainingQty  int64     `json:"remainingQty"`
+	UnitCostCents int64     `json:"unitCostCents"`
+}
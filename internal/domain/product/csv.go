@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"strconv"
 	"strings"
 )
@@ -33,14 +34,16 @@ var csvHeaders = []string{
 
 // ImportRow represents a row in the product CSV import.
 type ImportRow struct {
-	SKU              string
-	Name             string
-	Category         string
-	UnitPriceCents   int64
-	TaxRateBasisPts  int64
-	CurrentQty       int64
-	ReorderLevel     int64
-	Notes            string
+	SKU             string
+	Name            string
+	Category        string
+	UnitPriceCents  int64
+	TaxRateBasisPts int64
+	CurrentQty      int64
+	ReorderLevel    int64
+	Notes           string
+	// Attributes holds values for mapped attribute columns keyed by attribute code.
+	Attributes       map[string]string
 	OriginalLine     int
 	OriginalContents []string
 }
@@ -56,20 +59,31 @@ func (row ImportRow) ToCreateInput() CreateInput {
 		CurrentQty:         row.CurrentQty,
 		ReorderLevel:       row.ReorderLevel,
 		Notes:              row.Notes,
+		Attributes:         row.Attributes,
 	}
 }
 
-// ParseImportCSV decodes CSV data into rows enforcing the required format.
-func ParseImportCSV(r io.Reader) ([]ImportRow, error) {
+// ParseImportCSV decodes CSV data whose headers use the export names. Columns may appear in
+// any order, columns headed with an attribute code fill that attribute and unknown columns
+// are ignored.
+func ParseImportCSV(r io.Reader, attributes ...Attribute) ([]ImportRow, error) {
 	reader := csv.NewReader(r)
 	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
 
 	headers, err := reader.Read()
 	if err != nil {
 		return nil, fmt.Errorf("read header: %w", err)
 	}
 
-	if err := validateHeaders(headers); err != nil {
+	mapping := DefaultColumnMapping()
+	for _, a := range attributes {
+		if hasHeader(headers, a.Code) {
+			mapping[AttributeField(a.Code)] = a.Code
+		}
+	}
+	columns, err := ResolveColumns(headers, mapping)
+	if err != nil {
 		return nil, err
 	}
 
@@ -90,7 +104,7 @@ func ParseImportCSV(r io.Reader) ([]ImportRow, error) {
 			continue
 		}
 
-		row, err := parseRecord(lineNo, record)
+		row, err := MapImportRow(lineNo, record, columns)
 		if err != nil {
 			parseErr = append(parseErr, err.Error())
 			continue
@@ -105,74 +119,6 @@ func ParseImportCSV(r io.Reader) ([]ImportRow, error) {
 	return rows, nil
 }
 
-func validateHeaders(headers []string) error {
-	if len(headers) != len(csvHeaders) {
-		return fmt.Errorf("expected %d headers, got %d", len(csvHeaders), len(headers))
-	}
-	for i, expected := range csvHeaders {
-		actual := strings.TrimSpace(strings.ToLower(headers[i]))
-		if actual != expected {
-			return fmt.Errorf("invalid header at position %d: expected %q got %q", i+1, expected, headers[i])
-		}
-	}
-	return nil
-}
-
-func parseRecord(line int, record []string) (ImportRow, error) {
-	if len(record) != len(csvHeaders) {
-		return ImportRow{}, fmt.Errorf("line %d: expected %d columns got %d", line, len(csvHeaders), len(record))
-	}
-
-	get := func(idx int) string {
-		if idx >= len(record) {
-			return ""
-		}
-		return strings.TrimSpace(record[idx])
-	}
-
-	row := ImportRow{
-		OriginalLine:     line,
-		OriginalContents: append([]string(nil), record...),
-		SKU:              get(0),
-		Name:             get(1),
-		Category:         get(2),
-		Notes:            get(7),
-	}
-
-	if row.SKU == "" {
-		return ImportRow{}, fmt.Errorf("line %d: sku is required", line)
-	}
-	if row.Name == "" {
-		return ImportRow{}, fmt.Errorf("line %d: name is required", line)
-	}
-
-	unitPrice, err := parseMoney(get(3))
-	if err != nil {
-		return ImportRow{}, fmt.Errorf("line %d: unit_price %w", line, err)
-	}
-	row.UnitPriceCents = unitPrice
-
-	taxRate, err := parsePercentToBasisPoints(get(4))
-	if err != nil {
-		return ImportRow{}, fmt.Errorf("line %d: tax_rate_percent %w", line, err)
-	}
-	row.TaxRateBasisPts = taxRate
-
-	currentQty, err := parseInt(get(5), false)
-	if err != nil {
-		return ImportRow{}, fmt.Errorf("line %d: current_qty %w", line, err)
-	}
-	row.CurrentQty = currentQty
-
-	reorder, err := parseInt(get(6), false)
-	if err != nil {
-		return ImportRow{}, fmt.Errorf("line %d: reorder_level %w", line, err)
-	}
-	row.ReorderLevel = reorder
-
-	return row, nil
-}
-
 func parseMoney(value string) (int64, error) {
 	if strings.TrimSpace(value) == "" {
 		return 0, nil
@@ -210,7 +156,12 @@ func parseInt(value string, allowNegative bool) (int64, error) {
 	}
 	i, err := strconv.ParseInt(value, 10, 64)
 	if err != nil {
-		return 0, fmt.Errorf("must be an integer: %w", err)
+		// Spreadsheets often store whole numbers as decimals such as "12.0".
+		f, floatErr := strconv.ParseFloat(value, 64)
+		if floatErr != nil || f != math.Trunc(f) {
+			return 0, fmt.Errorf("must be an integer: %w", err)
+		}
+		i = int64(f)
 	}
 	if !allowNegative && i < 0 {
 		return 0, errors.New("must be >= 0")
@@ -218,10 +169,15 @@ func parseInt(value string, allowNegative bool) (int64, error) {
 	return i, nil
 }
 
-// WriteExportCSV renders products to CSV bytes following the import contract.
-func WriteExportCSV(w io.Writer, products []Product) error {
+// WriteExportCSV renders products to CSV bytes following the import contract, with one extra
+// column per attribute headed by its code.
+func WriteExportCSV(w io.Writer, products []Product, attributes ...Attribute) error {
 	writer := csv.NewWriter(w)
-	if err := writer.Write(csvHeaders); err != nil {
+	headers := append([]string(nil), csvHeaders...)
+	for _, a := range attributes {
+		headers = append(headers, a.Code)
+	}
+	if err := writer.Write(headers); err != nil {
 		return fmt.Errorf("write headers: %w", err)
 	}
 
@@ -236,6 +192,9 @@ func WriteExportCSV(w io.Writer, products []Product) error {
 			strconv.FormatInt(p.ReorderLevel, 10),
 			p.Notes,
 		}
+		for _, a := range attributes {
+			record = append(record, p.Attributes[a.Code])
+		}
 		if err := writer.Write(record); err != nil {
 			return fmt.Errorf("write record: %w", err)
 		}
@@ -248,6 +207,15 @@ func WriteExportCSV(w io.Writer, products []Product) error {
 	return nil
 }
 
+func hasHeader(headers []string, name string) bool {
+	for _, header := range headers {
+		if normaliseHeader(header) == normaliseHeader(name) {
+			return true
+		}
+	}
+	return false
+}
+
 func formatMoney(cents int64) string {
 	return fmt.Sprintf("%.2f", float64(cents)/100.0)
 }
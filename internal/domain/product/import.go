@@ -0,0 +1,309 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"shopmate/internal/domain/category"
+)
+
+// Import row actions reported by a dry run.
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionError  = "error"
+)
+
+// ImportFields lists the product fields a source column can be mapped to.
+var ImportFields = csvHeaders
+
+// ImportFieldsWith lists the built-in import fields followed by one field per attribute.
+func ImportFieldsWith(attributes []Attribute) []string {
+	fields := append([]string(nil), ImportFields...)
+	for _, a := range attributes {
+		fields = append(fields, AttributeField(a.Code))
+	}
+	return fields
+}
+
+// ColumnMapping maps product import fields (the export header names) to source column headers.
+type ColumnMapping map[string]string
+
+// importFieldAliases are header spellings commonly found in supplier spreadsheets.
+var importFieldAliases = map[string][]string{
+	csvHeaderSKU:            {"sku", "code", "productcode", "itemcode", "barcode"},
+	csvHeaderName:           {"name", "productname", "itemname", "description", "item"},
+	csvHeaderCategory:       {"category", "department", "group"},
+	csvHeaderUnitPrice:      {"unitprice", "price", "sellprice", "retailprice", "rrp"},
+	csvHeaderTaxRatePercent: {"taxratepercent", "taxrate", "tax", "vat", "gst"},
+	csvHeaderCurrentQty:     {"currentqty", "qty", "quantity", "stock", "onhand", "qtyonhand", "stockonhand"},
+	csvHeaderReorderLevel:   {"reorderlevel", "reorder", "minstock", "minimum"},
+	csvHeaderNotes:          {"notes", "note", "comments"},
+}
+
+// DefaultColumnMapping maps every field to a column of the same name.
+func DefaultColumnMapping() ColumnMapping {
+	mapping := make(ColumnMapping, len(csvHeaders))
+	for _, field := range csvHeaders {
+		mapping[field] = field
+	}
+	return mapping
+}
+
+// Validate ensures the mapping only targets known fields and covers the required ones.
+func (m ColumnMapping) Validate() error {
+	known := make(map[string]struct{}, len(csvHeaders))
+	for _, field := range csvHeaders {
+		known[field] = struct{}{}
+	}
+	for field := range m {
+		if code, ok := attributeCode(field); ok && code != "" {
+			continue
+		}
+		if _, ok := known[field]; !ok {
+			return fmt.Errorf("unknown import field %q", field)
+		}
+	}
+	for _, required := range []string{csvHeaderSKU, csvHeaderName} {
+		if strings.TrimSpace(m[required]) == "" {
+			return fmt.Errorf("a column must be mapped to %s", required)
+		}
+	}
+	return nil
+}
+
+// CheckAttributes ensures every attribute field in the mapping names a defined attribute.
+func (m ColumnMapping) CheckAttributes(attributes []Attribute) error {
+	known := make(map[string]bool, len(attributes))
+	for _, a := range attributes {
+		known[a.Code] = true
+	}
+	for field := range m {
+		if code, ok := attributeCode(field); ok && !known[code] {
+			return fmt.Errorf("unknown attribute %q in column mapping", code)
+		}
+	}
+	return nil
+}
+
+// SuggestColumnMapping proposes a mapping for the given headers, preferring a saved mapping
+// where its columns are present and otherwise matching common header spellings. Attribute
+// fields match a header spelt like the attribute code or name.
+func SuggestColumnMapping(headers []string, saved ColumnMapping, attributes ...Attribute) ColumnMapping {
+	byKey := make(map[string]string, len(headers))
+	for _, header := range headers {
+		key := normaliseHeader(header)
+		if _, exists := byKey[key]; !exists && key != "" {
+			byKey[key] = header
+		}
+	}
+
+	aliases := make(map[string][]string, len(importFieldAliases)+len(attributes))
+	for field, names := range importFieldAliases {
+		aliases[field] = names
+	}
+	for _, a := range attributes {
+		aliases[AttributeField(a.Code)] = []string{normaliseHeader(a.Code), normaliseHeader(a.Name)}
+	}
+	fields := ImportFieldsWith(attributes)
+
+	mapping := make(ColumnMapping)
+	used := make(map[string]bool)
+	for _, field := range fields {
+		if source, ok := byKey[normaliseHeader(saved[field])]; ok && !used[source] {
+			mapping[field] = source
+			used[source] = true
+		}
+	}
+	for _, field := range fields {
+		if _, done := mapping[field]; done {
+			continue
+		}
+		for _, alias := range aliases[field] {
+			if source, ok := byKey[alias]; ok && !used[source] {
+				mapping[field] = source
+				used[source] = true
+				break
+			}
+		}
+	}
+	return mapping
+}
+
+// ResolveColumns converts a mapping into field to column index lookups for the given headers.
+func ResolveColumns(headers []string, mapping ColumnMapping) (map[string]int, error) {
+	if err := mapping.Validate(); err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(headers))
+	for i, header := range headers {
+		key := normaliseHeader(header)
+		if _, exists := index[key]; !exists {
+			index[key] = i
+		}
+	}
+
+	columns := make(map[string]int, len(mapping))
+	for field, source := range mapping {
+		if strings.TrimSpace(source) == "" {
+			continue
+		}
+		i, ok := index[normaliseHeader(source)]
+		if !ok {
+			return nil, fmt.Errorf("column %q mapped to %s not found in file", source, field)
+		}
+		columns[field] = i
+	}
+	return columns, nil
+}
+
+// MapImportRow parses one source record using resolved column positions. Unmapped fields
+// are left at their zero value.
+func MapImportRow(line int, record []string, columns map[string]int) (ImportRow, error) {
+	get := func(field string) string {
+		idx, ok := columns[field]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	row := ImportRow{
+		OriginalLine:     line,
+		OriginalContents: append([]string(nil), record...),
+		SKU:              get(csvHeaderSKU),
+		Name:             get(csvHeaderName),
+		Category:         get(csvHeaderCategory),
+		Notes:            get(csvHeaderNotes),
+	}
+
+	for field := range columns {
+		if code, ok := attributeCode(field); ok {
+			if row.Attributes == nil {
+				row.Attributes = make(map[string]string)
+			}
+			row.Attributes[code] = get(field)
+		}
+	}
+
+	if row.SKU == "" {
+		return ImportRow{}, fmt.Errorf("line %d: sku is required", line)
+	}
+	if row.Name == "" {
+		return ImportRow{}, fmt.Errorf("line %d: name is required", line)
+	}
+
+	var err error
+	if row.UnitPriceCents, err = parseMoney(get(csvHeaderUnitPrice)); err != nil {
+		return ImportRow{}, fmt.Errorf("line %d: unit_price %w", line, err)
+	}
+	if row.TaxRateBasisPts, err = parsePercentToBasisPoints(get(csvHeaderTaxRatePercent)); err != nil {
+		return ImportRow{}, fmt.Errorf("line %d: tax_rate_percent %w", line, err)
+	}
+	if row.CurrentQty, err = parseInt(get(csvHeaderCurrentQty), false); err != nil {
+		return ImportRow{}, fmt.Errorf("line %d: current_qty %w", line, err)
+	}
+	if row.ReorderLevel, err = parseInt(get(csvHeaderReorderLevel), false); err != nil {
+		return ImportRow{}, fmt.Errorf("line %d: reorder_level %w", line, err)
+	}
+	return row, nil
+}
+
+// FillUnmapped copies fields the import does not map from the existing product, so updating
+// from a partial spreadsheet leaves other values (including stock) untouched.
+func (row *ImportRow) FillUnmapped(existing Product, columns map[string]int) {
+	mapped := func(field string) bool {
+		_, ok := columns[field]
+		return ok
+	}
+	if !mapped(csvHeaderCategory) {
+		row.Category = existing.Category
+	}
+	if !mapped(csvHeaderUnitPrice) {
+		row.UnitPriceCents = existing.UnitPriceCents
+	}
+	if !mapped(csvHeaderTaxRatePercent) {
+		row.TaxRateBasisPts = existing.TaxRateBasisPoints
+	}
+	if !mapped(csvHeaderCurrentQty) {
+		row.CurrentQty = existing.CurrentQty
+	}
+	if !mapped(csvHeaderReorderLevel) {
+		row.ReorderLevel = existing.ReorderLevel
+	}
+	if !mapped(csvHeaderNotes) {
+		row.Notes = existing.Notes
+	}
+}
+
+// ImportPreviewRow describes what committing one source row would do.
+type ImportPreviewRow struct {
+	Line    int      `json:"line"`
+	SKU     string   `json:"sku"`
+	Name    string   `json:"name"`
+	Action  string   `json:"action"`
+	Changes []string `json:"changes,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// ImportPreview is the dry-run result of an import.
+type ImportPreview struct {
+	Rows    []ImportPreviewRow `json:"rows"`
+	Creates int                `json:"creates"`
+	Updates int                `json:"updates"`
+	Errors  int                `json:"errors"`
+}
+
+// Add records a row and updates the totals.
+func (p *ImportPreview) Add(row ImportPreviewRow) {
+	switch row.Action {
+	case ImportActionCreate:
+		p.Creates++
+	case ImportActionUpdate:
+		p.Updates++
+	default:
+		p.Errors++
+	}
+	p.Rows = append(p.Rows, row)
+}
+
+// ImportChanges lists the fields an import row would change on an existing product.
+func ImportChanges(existing Product, row ImportRow) []string {
+	var changes []string
+	diff := func(field string, changed bool) {
+		if changed {
+			changes = append(changes, field)
+		}
+	}
+	diff(csvHeaderName, existing.Name != row.Name)
+	diff(csvHeaderCategory, !strings.EqualFold(category.JoinPath(category.SplitPath(existing.Category)), category.JoinPath(category.SplitPath(row.Category))))
+	diff(csvHeaderUnitPrice, existing.UnitPriceCents != row.UnitPriceCents)
+	diff(csvHeaderTaxRatePercent, existing.TaxRateBasisPoints != row.TaxRateBasisPts)
+	diff(csvHeaderCurrentQty, existing.CurrentQty != row.CurrentQty)
+	diff(csvHeaderReorderLevel, existing.ReorderLevel != row.ReorderLevel)
+	diff(csvHeaderNotes, existing.Notes != row.Notes)
+	codes := make([]string, 0, len(row.Attributes))
+	for code := range row.Attributes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		diff(AttributeField(code), existing.Attributes[code] != row.Attributes[code])
+	}
+	return changes
+}
+
+// ErrNoImportRows reports a source file without data rows.
+var ErrNoImportRows = errors.New("file has no data rows")
+
+func normaliseHeader(header string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(header) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,81 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Import modes. All-or-nothing imports apply no rows if any row fails; partial imports
+// apply the rows that succeed and report the rest.
+const (
+	ImportModeAllOrNothing = "AllOrNothing"
+	ImportModePartial      = "Partial"
+)
+
+var (
+	// ErrImportAborted reports an all-or-nothing import that failed and changed nothing.
+	ErrImportAborted = errors.New("import aborted; no products were changed")
+	// ErrImportRolledBack reports an attempt to roll back an import twice.
+	ErrImportRolledBack = errors.New("import has already been rolled back")
+)
+
+// NormaliseImportMode maps an empty mode to all-or-nothing and rejects unknown modes.
+func NormaliseImportMode(mode string) (string, error) {
+	switch mode {
+	case "", ImportModeAllOrNothing:
+		return ImportModeAllOrNothing, nil
+	case ImportModePartial:
+		return ImportModePartial, nil
+	default:
+		return "", fmt.Errorf("unknown import mode %q", mode)
+	}
+}
+
+// ImportBatch is a set of validated rows applied as one import job.
+type ImportBatch struct {
+	FileName string
+	Mode     string
+	Rows     []ImportRow
+}
+
+// ImportRowError records why one row of an import failed.
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	SKU   string `json:"sku"`
+	Error string `json:"error"`
+}
+
+func (e ImportRowError) String() string {
+	return fmt.Sprintf("line %d (sku=%s): %s", e.Line, e.SKU, e.Error)
+}
+
+// ImportJob is the undo record of an applied import.
+type ImportJob struct {
+	ID           int64           `json:"id"`
+	JobNumber    string          `json:"jobNumber"`
+	FileName     string          `json:"fileName"`
+	Mode         string          `json:"mode"`
+	Created      int             `json:"created"`
+	Updated      int             `json:"updated"`
+	Failed       int             `json:"failed"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	RolledBackAt *time.Time      `json:"rolledBackAt,omitempty"`
+	Items        []ImportJobItem `json:"items,omitempty"`
+}
+
+// RolledBack reports whether the import has been undone.
+func (j ImportJob) RolledBack() bool {
+	return j.RolledBackAt != nil
+}
+
+// ImportJobItem is one product an import created or updated. Before is the product as it
+// was before the import and is nil for created products.
+type ImportJobItem struct {
+	ProductID int64    `json:"productId"`
+	SKU       string   `json:"sku"`
+	Line      int      `json:"line"`
+	Action    string   `json:"action"`
+	QtyDelta  int64    `json:"qtyDelta"`
+	Before    *Product `json:"before,omitempty"`
+}
@@ -0,0 +1,63 @@
+package product_test
+
+import (
+	"testing"
+
+	"shopmate/internal/domain/product"
+)
+
+func TestSuggestColumnMapping(t *testing.T) {
+	headers := []string{"Item Code", "Description", "Retail Price", "Qty On Hand", "Supplier"}
+
+	tests := []struct {
+		name  string
+		saved product.ColumnMapping
+		want  map[string]string
+	}{
+		{
+			name: "aliases",
+			want: map[string]string{"sku": "Item Code", "name": "Description", "unit_price": "Retail Price", "current_qty": "Qty On Hand", "notes": ""},
+		},
+		{
+			name:  "saved mapping wins when its column exists",
+			saved: product.ColumnMapping{"sku": "item code", "notes": "Supplier", "category": "Missing"},
+			want:  map[string]string{"sku": "Item Code", "notes": "Supplier", "category": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := product.SuggestColumnMapping(headers, tt.saved)
+			for field, want := range tt.want {
+				if got[field] != want {
+					t.Fatalf("field %s: got %q want %q (mapping %v)", field, got[field], want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestMapImportRow(t *testing.T) {
+	headers := []string{"Price", "Code", "Title", "Stock"}
+	columns, err := product.ResolveColumns(headers, product.ColumnMapping{
+		"sku": "code", "name": "Title", "unit_price": "Price", "current_qty": "Stock",
+	})
+	if err != nil {
+		t.Fatalf("resolve columns: %v", err)
+	}
+
+	row, err := product.MapImportRow(2, []string{"1,299.50", " A-1 ", "Kettle", "4.0"}, columns)
+	if err != nil {
+		t.Fatalf("map row: %v", err)
+	}
+	if row.SKU != "A-1" || row.Name != "Kettle" || row.UnitPriceCents != 129950 || row.CurrentQty != 4 {
+		t.Fatalf("unexpected row %+v", row)
+	}
+
+	if _, err := product.MapImportRow(3, []string{"1", "A-2", "Mug", "1.5"}, columns); err == nil {
+		t.Fatalf("expected fractional quantity to be rejected")
+	}
+	if _, err := product.ResolveColumns(headers, product.ColumnMapping{"sku": "Code"}); err == nil {
+		t.Fatalf("expected mapping without name to be rejected")
+	}
+}
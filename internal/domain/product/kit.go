@@ -0,0 +1,36 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+)
+
+// KitComponent is one stocked product and the quantity consumed per kit sold.
+type KitComponent struct {
+	ComponentID int64  `json:"componentId"`
+	Name        string `json:"name"`
+	SKU         string `json:"sku"`
+	Quantity    int64  `json:"quantity"`
+	OnHand      int64  `json:"onHand"`
+}
+
+// ValidateKitComponents ensures a kit lists each component once with a positive quantity.
+func ValidateKitComponents(kitID int64, components []KitComponent) error {
+	seen := make(map[int64]struct{}, len(components))
+	for i, c := range components {
+		if c.ComponentID <= 0 {
+			return fmt.Errorf("component %d: product id required", i)
+		}
+		if c.ComponentID == kitID {
+			return errors.New("a kit cannot contain itself")
+		}
+		if c.Quantity <= 0 {
+			return fmt.Errorf("component %d: quantity must be > 0", i)
+		}
+		if _, dup := seen[c.ComponentID]; dup {
+			return fmt.Errorf("component %d: product listed more than once", i)
+		}
+		seen[c.ComponentID] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Price change states derived from the history row.
+const (
+	PriceStatusScheduled = "Scheduled"
+	PriceStatusApplied   = "Applied"
+	PriceStatusCancelled = "Cancelled"
+)
+
+// PriceChange is one entry in a product's price history.
+type PriceChange struct {
+	ID            int64      `json:"id"`
+	ProductID     int64      `json:"productId"`
+	PriceCents    int64      `json:"priceCents"`
+	EffectiveFrom time.Time  `json:"effectiveFrom"`
+	ChangedBy     string     `json:"changedBy"`
+	Note          string     `json:"note"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	AppliedAt     *time.Time `json:"appliedAt,omitempty"`
+	CancelledAt   *time.Time `json:"cancelledAt,omitempty"`
+	Status        string     `json:"status"`
+}
+
+// PriceChangeInput schedules a new price. A zero EffectiveFrom applies immediately.
+type PriceChangeInput struct {
+	ProductID     int64
+	PriceCents    int64
+	EffectiveFrom time.Time
+	ChangedBy     string
+	Note          string
+}
+
+// Validate ensures the price change is usable.
+func (in PriceChangeInput) Validate() error {
+	if in.ProductID <= 0 {
+		return errors.New("product id required")
+	}
+	if in.PriceCents < 0 {
+		return fmt.Errorf("price must be >= 0 (got %d)", in.PriceCents)
+	}
+	return nil
+}
+
+// ErrPriceChangeNotPending reports an attempt to cancel a change that already applied or was cancelled.
+var ErrPriceChangeNotPending = errors.New("price change is not pending")
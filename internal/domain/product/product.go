@@ -3,31 +3,76 @@ package product
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Product represents a sellable item tracked in inventory.
 type Product struct {
-	ID                 int64  `json:"id"`
-	Name               string `json:"name"`
-	SKU                string `json:"sku"`
-	Category           string `json:"category"`
-	UnitPriceCents     int64  `json:"unitPriceCents"`
-	TaxRateBasisPoints int64  `json:"taxRateBasisPoints"`
-	CurrentQty         int64  `json:"currentQty"`
-	ReorderLevel       int64  `json:"reorderLevel"`
-	Notes              string `json:"notes"`
+	ID                 int64      `json:"id"`
+	Name               string     `json:"name"`
+	SKU                string     `json:"sku"`
+	Category           string     `json:"category"`
+	CategoryID         int64      `json:"categoryId"`
+	UnitPriceCents     int64      `json:"unitPriceCents"`
+	CostCents          int64      `json:"costCents"`
+	TaxRateBasisPoints int64      `json:"taxRateBasisPoints"`
+	CurrentQty         int64      `json:"currentQty"`
+	ReorderLevel       int64      `json:"reorderLevel"`
+	Notes              string     `json:"notes"`
+	Serialised         bool       `json:"serialised"`
+	ArchivedAt         *time.Time `json:"archivedAt,omitempty"`
+	IsKit              bool       `json:"isKit"`
+	// AvailableQty is CurrentQty for stocked products and the number of kits the
+	// component stock can make for kits.
+	AvailableQty int64 `json:"availableQty"`
+	// Attributes holds custom attribute values keyed by attribute code.
+	Attributes map[string]string `json:"attributes"`
+	// Version changes whenever the product's details or attribute values do; stock
+	// movements leave it alone.
+	Version int64 `json:"version"`
+	// NegativeStockPolicy overrides the shop's negative-stock policy; empty follows it.
+	NegativeStockPolicy string `json:"negativeStockPolicy"`
+	// TaxGroupID assigns the product a tax group, whose components replace
+	// TaxRateBasisPoints; zero taxes it at that single rate.
+	TaxGroupID int64 `json:"taxGroupId"`
+}
+
+// ConflictError reports that a product changed after the version an edit was based on.
+// Current holds the product as it is now so the edit can be merged.
+type ConflictError struct {
+	Current *Product
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("product %s was changed by someone else; review the current values and try again", e.Current.SKU)
+}
+
+// Archived reports whether the product has been withdrawn from sale.
+func (p Product) Archived() bool {
+	return p.ArchivedAt != nil
 }
 
 // CreateInput describes the fields required to add a product.
 type CreateInput struct {
-	Name               string
-	SKU                string
-	Category           string
-	UnitPriceCents     int64
+	Name           string
+	SKU            string
+	Category       string
+	UnitPriceCents int64
+	// CostCents is the unit cost of opening stock and of stock received without a cost.
+	CostCents          int64
 	TaxRateBasisPoints int64
 	CurrentQty         int64
 	ReorderLevel       int64
 	Notes              string
+	Serialised         bool
+	// ChangedBy is recorded against the opening price in the price history.
+	ChangedBy string
+	// Attributes sets custom attribute values keyed by attribute code.
+	Attributes map[string]string
+	// NegativeStockPolicy overrides the shop's negative-stock policy; empty follows it.
+	NegativeStockPolicy string
+	// TaxGroupID assigns a tax group; zero taxes the product at TaxRateBasisPoints.
+	TaxGroupID int64
 }
 
 // Validate ensures the product input satisfies basic constraints.
@@ -41,6 +86,9 @@ func (in CreateInput) Validate() error {
 	if in.UnitPriceCents < 0 {
 		return fmt.Errorf("unit price must be >= 0 (got %d)", in.UnitPriceCents)
 	}
+	if in.CostCents < 0 {
+		return fmt.Errorf("unit cost must be >= 0 (got %d)", in.CostCents)
+	}
 	if in.TaxRateBasisPoints < 0 {
 		return fmt.Errorf("tax rate must be >= 0 (got %d)", in.TaxRateBasisPoints)
 	}
@@ -50,7 +98,13 @@ func (in CreateInput) Validate() error {
 	if in.ReorderLevel < 0 {
 		return fmt.Errorf("reorder level must be >= 0 (got %d)", in.ReorderLevel)
 	}
-	return nil
+	if in.Serialised && in.CurrentQty != 0 {
+		return errors.New("serialised products start with zero stock; receive units with their serial numbers")
+	}
+	if in.TaxGroupID < 0 {
+		return fmt.Errorf("tax group id must be >= 0 (got %d)", in.TaxGroupID)
+	}
+	return ValidateNegativeStockPolicy(in.NegativeStockPolicy, true)
 }
 
 // UpdateInput mutates editable fields for an existing product.
@@ -58,9 +112,23 @@ type UpdateInput struct {
 	Name               string
 	Category           string
 	UnitPriceCents     int64
+	CostCents          int64
 	TaxRateBasisPoints int64
 	ReorderLevel       int64
 	Notes              string
+	Serialised         bool
+	// ChangedBy is recorded in the price history when the price changes.
+	ChangedBy string
+	// Attributes sets the listed attribute values; a blank value clears one. Attributes
+	// not listed keep their values and a nil map leaves them all untouched.
+	Attributes map[string]string
+	// Version is the product version the edit was based on; the update fails with a
+	// ConflictError when the product has changed since.
+	Version int64
+	// NegativeStockPolicy overrides the shop's negative-stock policy; empty follows it.
+	NegativeStockPolicy string
+	// TaxGroupID assigns a tax group; zero taxes the product at TaxRateBasisPoints.
+	TaxGroupID int64
 }
 
 // Validate ensures the update payload remains consistent.
@@ -68,24 +136,40 @@ func (in UpdateInput) Validate() error {
 	if len(in.Name) == 0 {
 		return errors.New("name is required")
 	}
+	if in.Version <= 0 {
+		return errors.New("product version is required")
+	}
 	if in.UnitPriceCents < 0 {
 		return fmt.Errorf("unit price must be >= 0 (got %d)", in.UnitPriceCents)
 	}
+	if in.CostCents < 0 {
+		return fmt.Errorf("unit cost must be >= 0 (got %d)", in.CostCents)
+	}
 	if in.TaxRateBasisPoints < 0 {
 		return fmt.Errorf("tax rate must be >= 0 (got %d)", in.TaxRateBasisPoints)
 	}
 	if in.ReorderLevel < 0 {
 		return fmt.Errorf("reorder level must be >= 0 (got %d)", in.ReorderLevel)
 	}
-	return nil
+	if in.TaxGroupID < 0 {
+		return fmt.Errorf("tax group id must be >= 0 (got %d)", in.TaxGroupID)
+	}
+	return ValidateNegativeStockPolicy(in.NegativeStockPolicy, true)
 }
 
-// AdjustmentInput captures a manual stock adjustment.
+// AdjustmentInput captures a manual stock adjustment. Serialised products must
+// list one serial number per unit received or removed. A zero LocationID applies
+// the adjustment to the default location. Stock received with a UnitCostCents opens a
+// cost layer at that cost and becomes the product's cost; without one it is costed at the
+// product's current cost.
 type AdjustmentInput struct {
-	ProductID int64
-	Delta     int64
-	Reason    string
-	Ref       string
+	ProductID     int64
+	LocationID    int64
+	Delta         int64
+	Reason        string
+	Ref           string
+	SerialNumbers []string
+	UnitCostCents int64
 }
 
 // Validate ensures adjustments are safe to apply.
@@ -99,5 +183,11 @@ func (in AdjustmentInput) Validate() error {
 	if len(in.Reason) == 0 {
 		return errors.New("reason is required")
 	}
+	if in.UnitCostCents < 0 {
+		return fmt.Errorf("unit cost must be >= 0 (got %d)", in.UnitCostCents)
+	}
+	if in.UnitCostCents > 0 && in.Delta < 0 {
+		return errors.New("unit cost only applies to stock received")
+	}
 	return nil
 }
@@ -0,0 +1,74 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Serial unit statuses.
+const (
+	SerialStatusInStock = "InStock"
+	SerialStatusSold    = "Sold"
+	SerialStatusRemoved = "Removed"
+)
+
+// Serial is a single tracked unit of a serialised product.
+type Serial struct {
+	ID           int64     `json:"id"`
+	ProductID    int64     `json:"productId"`
+	SerialNumber string    `json:"serialNumber"`
+	Status       string    `json:"status"`
+	ReceivedAt   time.Time `json:"receivedAt"`
+	Ref          string    `json:"ref"`
+}
+
+// SerialSale describes a sale that included a serial unit.
+type SerialSale struct {
+	SaleID       int64     `json:"saleId"`
+	SaleNumber   string    `json:"saleNumber"`
+	Timestamp    time.Time `json:"timestamp"`
+	CustomerName string    `json:"customerName"`
+	Status       string    `json:"status"`
+}
+
+// SerialLookup answers warranty questions for a serial number.
+type SerialLookup struct {
+	Serial      Serial       `json:"serial"`
+	ProductName string       `json:"productName"`
+	SKU         string       `json:"sku"`
+	Sales       []SerialSale `json:"sales"`
+}
+
+// NormalizeSerials trims serial numbers and rejects blanks and duplicates.
+func NormalizeSerials(serials []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(serials))
+	out := make([]string, 0, len(serials))
+	for i, raw := range serials {
+		serial := strings.TrimSpace(raw)
+		if serial == "" {
+			return nil, fmt.Errorf("serial %d: value required", i+1)
+		}
+		if _, dup := seen[serial]; dup {
+			return nil, fmt.Errorf("serial %s listed more than once", serial)
+		}
+		seen[serial] = struct{}{}
+		out = append(out, serial)
+	}
+	return out, nil
+}
+
+// CheckSerialCount ensures one serial number is supplied per unit.
+func CheckSerialCount(serials []string, qty int64) error {
+	if qty < 0 {
+		qty = -qty
+	}
+	if int64(len(serials)) != qty {
+		return fmt.Errorf("expected %d serial number(s), got %d", qty, len(serials))
+	}
+	return nil
+}
+
+// ErrSerialNotFound indicates a lookup matched no tracked unit.
+var ErrSerialNotFound = errors.New("serial number not found")
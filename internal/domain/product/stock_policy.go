@@ -0,0 +1,51 @@
+package product
+
+import "fmt"
+
+// Negative-stock policies decide what happens when a sale needs more stock than is on hand.
+const (
+	// NegativeStockBlock rejects the sale.
+	NegativeStockBlock = "Block"
+	// NegativeStockWarn lets the sale through, taking stock negative, and warns the cashier.
+	NegativeStockWarn = "Warn"
+	// NegativeStockBackorder lets the sale through and records the shortfall as a backorder.
+	NegativeStockBackorder = "Backorder"
+)
+
+// ValidateNegativeStockPolicy accepts the known policies and, when allowInherit is set, an
+// empty policy meaning "use the shop setting".
+func ValidateNegativeStockPolicy(policy string, allowInherit bool) error {
+	switch policy {
+	case NegativeStockBlock, NegativeStockWarn, NegativeStockBackorder:
+		return nil
+	case "":
+		if allowInherit {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported negative stock policy %q", policy)
+}
+
+// EffectiveNegativeStockPolicy returns the product's own policy, or the shop's when it has none.
+func EffectiveNegativeStockPolicy(productPolicy, shopPolicy string) string {
+	if productPolicy != "" {
+		return productPolicy
+	}
+	if shopPolicy != "" {
+		return shopPolicy
+	}
+	return NegativeStockBlock
+}
+
+// StockShortageError reports a sale blocked because a product lacks the stock it needs.
+type StockShortageError struct {
+	ProductID int64
+	SKU       string
+	Name      string
+	OnHand    int64
+	Requested int64
+}
+
+func (e *StockShortageError) Error() string {
+	return fmt.Sprintf("insufficient stock of %s (%s): %d on hand, %d needed", e.Name, e.SKU, e.OnHand, e.Requested)
+}
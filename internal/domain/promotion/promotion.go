@@ -0,0 +1,506 @@
+// Package promotion models discounts applied automatically when a sale is priced, and the
+// coupons that unlock them.
+package promotion
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/sale"
+)
+
+// Promotion kinds.
+//
+// PercentOff and AmountOff take a percentage or a fixed amount off every matching unit.
+// BuyXGetY discounts GetQty units for every BuyQty bought, the cheapest of each group
+// first, by PercentBasisPoints (free by default). MultiBuy sells each group of BuyQty
+// matching units for AmountCents. SpendThreshold takes a percentage or a fixed amount off
+// the matching lines once they reach MinSpendCents after other promotions.
+const (
+	KindPercentOff     = "PercentOff"
+	KindAmountOff      = "AmountOff"
+	KindBuyXGetY       = "BuyXGetY"
+	KindMultiBuy       = "MultiBuy"
+	KindSpendThreshold = "SpendThreshold"
+)
+
+// minutesPerDay bounds a happy-hour window.
+const minutesPerDay = 24 * 60
+
+// Promotion is a discount rule. It targets ProductIDs and CategoryIDs (subcategories
+// included), or every product when both are empty. StartsAt and EndsAt bound the dates it
+// runs; Days (time.Weekday values) and the StartMinute–EndMinute window, in minutes after
+// local midnight, limit it to a happy hour. A CouponCode makes it apply only when the
+// code is given, at most UsageLimit times overall and PerCustomerLimit times per customer
+// (zero for no limit).
+type Promotion struct {
+	ID                 int64      `json:"id"`
+	Name               string     `json:"name"`
+	Kind               string     `json:"kind"`
+	Active             bool       `json:"active"`
+	ProductIDs         []int64    `json:"productIds"`
+	CategoryIDs        []int64    `json:"categoryIds"`
+	PercentBasisPoints int64      `json:"percentBasisPoints"`
+	AmountCents        int64      `json:"amountCents"`
+	BuyQty             int64      `json:"buyQty"`
+	GetQty             int64      `json:"getQty"`
+	MinSpendCents      int64      `json:"minSpendCents"`
+	StartsAt           *time.Time `json:"startsAt,omitempty"`
+	EndsAt             *time.Time `json:"endsAt,omitempty"`
+	Days               []int      `json:"days"`
+	StartMinute        int        `json:"startMinute"`
+	EndMinute          int        `json:"endMinute"`
+	CouponCode         string     `json:"couponCode"`
+	UsageLimit         int64      `json:"usageLimit"`
+	PerCustomerLimit   int64      `json:"perCustomerLimit"`
+	UsedCount          int64      `json:"usedCount"`
+	// Priority orders the line promotions; a line takes the first that applies to it.
+	Priority  int64     `json:"priority"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Input holds the editable fields of a promotion.
+type Input struct {
+	Name               string
+	Kind               string
+	Active             bool
+	ProductIDs         []int64
+	CategoryIDs        []int64
+	PercentBasisPoints int64
+	AmountCents        int64
+	BuyQty             int64
+	GetQty             int64
+	MinSpendCents      int64
+	StartsAt           *time.Time
+	EndsAt             *time.Time
+	Days               []int
+	StartMinute        int
+	EndMinute          int
+	CouponCode         string
+	UsageLimit         int64
+	PerCustomerLimit   int64
+	Priority           int64
+}
+
+// Normalize trims the name, tidies the coupon code and target lists, and makes a buy X
+// get Y promotion give its units away free unless a percentage is set.
+func (in *Input) Normalize() {
+	in.Name = strings.TrimSpace(in.Name)
+	in.Kind = strings.TrimSpace(in.Kind)
+	in.CouponCode = NormalizeCode(in.CouponCode)
+	in.ProductIDs = uniqueSorted(in.ProductIDs)
+	in.CategoryIDs = uniqueSorted(in.CategoryIDs)
+	in.Days = uniqueSorted(in.Days)
+	if in.Kind == KindBuyXGetY && in.PercentBasisPoints == 0 {
+		in.PercentBasisPoints = 10000
+	}
+}
+
+// Validate checks the fields the promotion's kind needs and its schedule.
+func (in Input) Validate() error {
+	if in.Name == "" {
+		return errors.New("promotion name is required")
+	}
+	if in.PercentBasisPoints < 0 || in.PercentBasisPoints > 10000 {
+		return errors.New("percentage must be between 0 and 100")
+	}
+	if in.AmountCents < 0 || in.BuyQty < 0 || in.GetQty < 0 || in.MinSpendCents < 0 {
+		return errors.New("amounts and quantities must be >= 0")
+	}
+	switch in.Kind {
+	case KindPercentOff:
+		if in.PercentBasisPoints == 0 {
+			return errors.New("percentage off required")
+		}
+	case KindAmountOff:
+		if in.AmountCents == 0 {
+			return errors.New("amount off required")
+		}
+	case KindBuyXGetY:
+		if in.BuyQty == 0 || in.GetQty == 0 {
+			return errors.New("buy and get quantities required")
+		}
+	case KindMultiBuy:
+		if in.BuyQty < 2 || in.AmountCents == 0 {
+			return errors.New("a multi-buy needs a quantity of at least 2 and a price")
+		}
+	case KindSpendThreshold:
+		if in.MinSpendCents == 0 {
+			return errors.New("minimum spend required")
+		}
+		if (in.PercentBasisPoints == 0) == (in.AmountCents == 0) {
+			return errors.New("give either a percentage or an amount off")
+		}
+		if in.AmountCents > in.MinSpendCents {
+			return errors.New("amount off must not exceed the minimum spend")
+		}
+	default:
+		return fmt.Errorf("unknown promotion kind %q", in.Kind)
+	}
+	for _, id := range append(slices.Clone(in.ProductIDs), in.CategoryIDs...) {
+		if id <= 0 {
+			return errors.New("invalid product or category id")
+		}
+	}
+	if in.StartsAt != nil && in.EndsAt != nil && !in.EndsAt.After(*in.StartsAt) {
+		return errors.New("end must be after start")
+	}
+	for _, day := range in.Days {
+		if day < int(time.Sunday) || day > int(time.Saturday) {
+			return fmt.Errorf("invalid weekday %d", day)
+		}
+	}
+	if in.StartMinute < 0 || in.StartMinute > minutesPerDay || in.EndMinute < 0 || in.EndMinute > minutesPerDay {
+		return errors.New("happy hour times must fall within the day")
+	}
+	if in.CouponCode == "" && (in.UsageLimit != 0 || in.PerCustomerLimit != 0) {
+		return errors.New("usage limits need a coupon code")
+	}
+	if in.UsageLimit < 0 || in.PerCustomerLimit < 0 {
+		return errors.New("usage limits must be >= 0")
+	}
+	if in.CouponCode != "" && (len(in.CouponCode) < 3 || len(in.CouponCode) > 32) {
+		return errors.New("coupon code must be 3 to 32 characters")
+	}
+	return nil
+}
+
+// NormalizeCode removes whitespace and upper-cases a coupon code so it matches however it
+// was typed.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), ""))
+}
+
+// ActiveAt reports whether the promotion runs at t, read in t's location.
+func (p Promotion) ActiveAt(t time.Time) bool {
+	if !p.Active {
+		return false
+	}
+	if p.StartsAt != nil && t.Before(*p.StartsAt) {
+		return false
+	}
+	if p.EndsAt != nil && !t.Before(*p.EndsAt) {
+		return false
+	}
+	if len(p.Days) > 0 && !slices.Contains(p.Days, int(t.Weekday())) {
+		return false
+	}
+	if p.StartMinute == p.EndMinute {
+		return true
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if p.StartMinute < p.EndMinute {
+		return minute >= p.StartMinute && minute < p.EndMinute
+	}
+	// The window runs past midnight.
+	return minute >= p.StartMinute || minute < p.EndMinute
+}
+
+// Exhausted reports whether the coupon has been used as often as allowed.
+func (p Promotion) Exhausted() bool {
+	return p.UsageLimit > 0 && p.UsedCount >= p.UsageLimit
+}
+
+// Matches reports whether a product in the categories listed (its own followed by its
+// ancestors) is targeted by the promotion.
+func (p Promotion) Matches(productID int64, categoryIDs []int64) bool {
+	if len(p.ProductIDs) == 0 && len(p.CategoryIDs) == 0 {
+		return true
+	}
+	if slices.Contains(p.ProductIDs, productID) {
+		return true
+	}
+	for _, id := range categoryIDs {
+		if slices.Contains(p.CategoryIDs, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Item is a sale line offered to the promotions. CategoryIDs lists the product's category
+// followed by its ancestors. Lines that are not Eligible, such as those with a manual
+// discount or an agreed price, are left alone.
+type Item struct {
+	ProductID      int64
+	CategoryIDs    []int64
+	UnitPriceCents int64
+	Quantity       int64
+	Eligible       bool
+}
+
+// Apply works out the discounts promotions give items and returns them per item. Line
+// promotions go first, by priority: each takes the lines not yet claimed by another, and
+// the lines whose units it used are claimed. Then the spend threshold giving the largest
+// discount is shared across its lines in proportion to what they cost after the line
+// promotions.
+func Apply(promotions []Promotion, items []Item) [][]sale.LinePromotion {
+	applied := make([][]sale.LinePromotion, len(items))
+	net := make([]int64, len(items))
+	for i, item := range items {
+		net[i] = item.UnitPriceCents * item.Quantity
+	}
+	record := func(p Promotion, i int, discount int64) {
+		applied[i] = append(applied[i], sale.LinePromotion{
+			PromotionID:   p.ID,
+			Name:          p.Name,
+			CouponCode:    p.CouponCode,
+			DiscountCents: discount,
+		})
+		net[i] -= discount
+	}
+	candidates := func(p Promotion, claimed []bool) []int {
+		var matched []int
+		for i, item := range items {
+			if item.Eligible && !claimed[i] && item.Quantity > 0 && p.Matches(item.ProductID, item.CategoryIDs) {
+				matched = append(matched, i)
+			}
+		}
+		return matched
+	}
+
+	ordered := slices.Clone(promotions)
+	slices.SortStableFunc(ordered, func(a, b Promotion) int {
+		if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	claimed := make([]bool, len(items))
+	for _, p := range ordered {
+		if p.Kind == KindSpendThreshold {
+			continue
+		}
+		lines := candidates(p, claimed)
+		if len(lines) == 0 {
+			continue
+		}
+		discounts, used := p.lineDiscounts(items, lines)
+		for k, i := range lines {
+			if used[k] {
+				claimed[i] = true
+			}
+			if discounts[k] > 0 {
+				record(p, i, discounts[k])
+			}
+		}
+	}
+
+	var (
+		best         Promotion
+		bestLines    []int
+		bestDiscount int64
+	)
+	none := make([]bool, len(items))
+	for _, p := range ordered {
+		if p.Kind != KindSpendThreshold {
+			continue
+		}
+		lines := candidates(p, none)
+		var spend int64
+		for _, i := range lines {
+			spend += net[i]
+		}
+		if spend <= 0 || spend < p.MinSpendCents {
+			continue
+		}
+		discount := min(p.AmountCents, spend)
+		if p.PercentBasisPoints > 0 {
+			discount = percentOf(spend, p.PercentBasisPoints)
+		}
+		if discount > bestDiscount {
+			best, bestLines, bestDiscount = p, lines, discount
+		}
+	}
+	if bestDiscount > 0 {
+		weights := make([]int64, len(bestLines))
+		for k, i := range bestLines {
+			weights[k] = net[i]
+		}
+		for k, share := range allocate(bestDiscount, weights) {
+			if share > 0 {
+				record(best, bestLines[k], share)
+			}
+		}
+	}
+	return applied
+}
+
+// lineDiscounts prices a line promotion over the candidate lines, returning each line's
+// discount and whether any of its units went towards the promotion.
+func (p Promotion) lineDiscounts(items []Item, lines []int) ([]int64, []bool) {
+	discounts := make([]int64, len(lines))
+	used := make([]bool, len(lines))
+	switch p.Kind {
+	case KindPercentOff:
+		for k, i := range lines {
+			discounts[k] = percentOf(items[i].UnitPriceCents*items[i].Quantity, p.PercentBasisPoints)
+			used[k] = true
+		}
+	case KindAmountOff:
+		for k, i := range lines {
+			discounts[k] = min(p.AmountCents, items[i].UnitPriceCents) * items[i].Quantity
+			used[k] = discounts[k] > 0
+		}
+	case KindBuyXGetY, KindMultiBuy:
+		// Units are grouped dearest first, so each group's discounted units are its
+		// cheapest. A line's units sit together at its price, so they are counted as a run
+		// rather than one by one.
+		order := make([]int, len(lines))
+		var units int64
+		for k, i := range lines {
+			order[k] = k
+			units += items[i].Quantity
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			return cmp.Compare(items[lines[b]].UnitPriceCents, items[lines[a]].UnitPriceCents)
+		})
+
+		size := p.BuyQty
+		if p.Kind == KindBuyXGetY {
+			size += p.GetQty
+		}
+		grouped := units / size * size
+		// free counts the units among the first n grouped units that a group gives away.
+		free := func(n int64) int64 {
+			n = min(n, grouped)
+			return n/size*p.GetQty + max(n%size-p.BuyQty, 0)
+		}
+		var start int64
+		for _, k := range order {
+			item := items[lines[k]]
+			end := start + item.Quantity
+			used[k] = start < grouped
+			if p.Kind == KindBuyXGetY {
+				discounts[k] += (free(end) - free(start)) * percentOf(item.UnitPriceCents, p.PercentBasisPoints)
+			}
+			start = end
+		}
+		if p.Kind == KindMultiBuy {
+			p.multiBuyDiscounts(items, lines, order, grouped/size, discounts)
+		}
+	}
+	return discounts, used
+}
+
+// multiBuyDiscounts prices the first groups multi-buy groups of the candidate lines' units,
+// taken in order, adding each line's share of the savings to discounts. A group's saving
+// is split across its units by price.
+func (p Promotion) multiBuyDiscounts(items []Item, lines, order []int, groups int64, discounts []int64) {
+	var (
+		group []run
+		need  = p.BuyQty
+		next  int
+		left  int64
+	)
+	for g := int64(0); g < groups; {
+		if left == 0 {
+			left = items[lines[order[next]]].Quantity
+			next++
+		}
+		k := order[next-1]
+		price := items[lines[k]].UnitPriceCents
+		if len(group) == 0 && left >= p.BuyQty {
+			// Groups made up of this line alone all save the same.
+			n := min(left/p.BuyQty, groups-g)
+			if regular := price * p.BuyQty; regular > p.AmountCents {
+				discounts[k] += n * (regular - p.AmountCents)
+			}
+			g += n
+			left -= n * p.BuyQty
+			continue
+		}
+		take := min(left, need)
+		group = append(group, run{line: k, price: price, count: take})
+		left -= take
+		if need -= take; need > 0 {
+			continue
+		}
+		var regular int64
+		for _, r := range group {
+			regular += r.price * r.count
+		}
+		if regular > p.AmountCents {
+			for j, share := range allocateRuns(regular-p.AmountCents, group) {
+				discounts[group[j].line] += share
+			}
+		}
+		group, need = group[:0], p.BuyQty
+		g++
+	}
+}
+
+// run is count units of a line at price.
+type run struct {
+	line  int
+	price int64
+	count int64
+}
+
+// allocateRuns splits total across runs of units in proportion to their prices, the way
+// allocate splits it across the units one by one.
+func allocateRuns(total int64, runs []run) []int64 {
+	shares := make([]int64, len(runs))
+	var sum int64
+	for _, r := range runs {
+		sum += r.price * r.count
+	}
+	if total <= 0 || sum <= 0 {
+		return shares
+	}
+	var allocated int64
+	for i, r := range runs {
+		shares[i] = total * r.price / sum * r.count
+		allocated += shares[i]
+	}
+	for i := 0; allocated < total; i = (i + 1) % len(runs) {
+		if runs[i].price > 0 {
+			extra := min(runs[i].count, total-allocated)
+			shares[i] += extra
+			allocated += extra
+		}
+	}
+	return shares
+}
+
+// percentOf returns basisPoints of amount, rounded half up.
+func percentOf(amount, basisPoints int64) int64 {
+	return (amount*basisPoints + 5000) / 10000
+}
+
+// allocate splits total across weights in proportion, handing leftover cents to the
+// earliest positive weights.
+func allocate(total int64, weights []int64) []int64 {
+	shares := make([]int64, len(weights))
+	var sum int64
+	for _, w := range weights {
+		sum += w
+	}
+	if total <= 0 || sum <= 0 {
+		return shares
+	}
+	var allocated int64
+	for i, w := range weights {
+		shares[i] = total * w / sum
+		allocated += shares[i]
+	}
+	for i := 0; allocated < total; i = (i + 1) % len(weights) {
+		if weights[i] > 0 {
+			shares[i]++
+			allocated++
+		}
+	}
+	return shares
+}
+
+func uniqueSorted[T cmp.Ordered](values []T) []T {
+	out := slices.Clone(values)
+	slices.Sort(out)
+	return slices.Compact(out)
+}
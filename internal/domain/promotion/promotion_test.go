@@ -0,0 +1,128 @@
+package promotion
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func totals(promotions []Promotion, items []Item) []int64 {
+	out := make([]int64, len(items))
+	for i, lines := range Apply(promotions, items) {
+		for _, p := range lines {
+			out[i] += p.DiscountCents
+		}
+	}
+	return out
+}
+
+func TestApply(t *testing.T) {
+	coffee := Item{ProductID: 1, CategoryIDs: []int64{11, 10}, UnitPriceCents: 400, Quantity: 3, Eligible: true}
+	tea := Item{ProductID: 2, CategoryIDs: []int64{12, 10}, UnitPriceCents: 300, Quantity: 1, Eligible: true}
+	mug := Item{ProductID: 3, UnitPriceCents: 1000, Quantity: 1, Eligible: true}
+	bulk := Item{ProductID: 4, UnitPriceCents: 100, Quantity: 1_000_000_000, Eligible: true}
+
+	tests := []struct {
+		name       string
+		promotions []Promotion
+		items      []Item
+		want       []int64
+	}{
+		{"percentOffCategory", []Promotion{{ID: 1, Kind: KindPercentOff, CategoryIDs: []int64{10}, PercentBasisPoints: 1000}}, []Item{coffee, tea, mug}, []int64{120, 30, 0}},
+		{"amountOffProduct", []Promotion{{ID: 1, Kind: KindAmountOff, ProductIDs: []int64{1}, AmountCents: 50}}, []Item{coffee, mug}, []int64{150, 0}},
+		{"amountOffCappedAtPrice", []Promotion{{ID: 1, Kind: KindAmountOff, AmountCents: 500}}, []Item{tea}, []int64{300}},
+		{"buyTwoGetCheapestFree", []Promotion{{ID: 1, Kind: KindBuyXGetY, CategoryIDs: []int64{10}, BuyQty: 2, GetQty: 1, PercentBasisPoints: 10000}}, []Item{coffee, tea}, []int64{400, 0}},
+		{"buyOneGetOneHalfOff", []Promotion{{ID: 1, Kind: KindBuyXGetY, ProductIDs: []int64{1}, BuyQty: 1, GetQty: 1, PercentBasisPoints: 5000}}, []Item{coffee}, []int64{200}},
+		{"multiBuy", []Promotion{{ID: 1, Kind: KindMultiBuy, CategoryIDs: []int64{10}, BuyQty: 2, AmountCents: 600}}, []Item{coffee, tea}, []int64{258, 42}},
+		{"buyXGetYInBulk", []Promotion{{ID: 1, Kind: KindBuyXGetY, ProductIDs: []int64{4}, BuyQty: 2, GetQty: 1, PercentBasisPoints: 10000}}, []Item{bulk}, []int64{33_333_333_300}},
+		{"multiBuyInBulk", []Promotion{{ID: 1, Kind: KindMultiBuy, ProductIDs: []int64{1, 4}, BuyQty: 3, AmountCents: 250}}, []Item{coffee, bulk}, []int64{950, 16_666_666_650}},
+		{"multiBuyNeverRaisesPrice", []Promotion{{ID: 1, Kind: KindMultiBuy, BuyQty: 2, AmountCents: 900}}, []Item{coffee}, []int64{0}},
+		{"spendThreshold", []Promotion{{ID: 1, Kind: KindSpendThreshold, MinSpendCents: 2000, AmountCents: 250}}, []Item{coffee, tea, mug}, []int64{120, 30, 100}},
+		{"spendThresholdNotReached", []Promotion{{ID: 1, Kind: KindSpendThreshold, MinSpendCents: 5000, PercentBasisPoints: 1000}}, []Item{coffee, mug}, []int64{0, 0}},
+		{"bestThresholdWins", []Promotion{
+			{ID: 1, Kind: KindSpendThreshold, MinSpendCents: 1000, AmountCents: 100},
+			{ID: 2, Kind: KindSpendThreshold, MinSpendCents: 2000, AmountCents: 220},
+		}, []Item{coffee, mug}, []int64{120, 100}},
+		{"priorityClaimsLines", []Promotion{
+			{ID: 1, Kind: KindPercentOff, PercentBasisPoints: 1000},
+			{ID: 2, Kind: KindAmountOff, ProductIDs: []int64{1}, AmountCents: 100, Priority: 5},
+		}, []Item{coffee, mug}, []int64{300, 100}},
+		{"thresholdStacksAfterLinePromotions", []Promotion{
+			{ID: 1, Kind: KindPercentOff, ProductIDs: []int64{3}, PercentBasisPoints: 5000},
+			{ID: 2, Kind: KindSpendThreshold, MinSpendCents: 1500, PercentBasisPoints: 1000},
+		}, []Item{coffee, mug}, []int64{120, 550}},
+		{"ineligibleLinesSkipped", []Promotion{{ID: 1, Kind: KindPercentOff, PercentBasisPoints: 1000}}, []Item{{ProductID: 3, UnitPriceCents: 1000, Quantity: 1}}, []int64{0}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := totals(tc.promotions, tc.items); !slices.Equal(got, tc.want) {
+				t.Fatalf("discounts %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	applied := Apply([]Promotion{{ID: 7, Name: "Ten off", Kind: KindPercentOff, PercentBasisPoints: 1000, CouponCode: "TEN"}}, []Item{mug})
+	if len(applied[0]) != 1 || applied[0][0].PromotionID != 7 || applied[0][0].Name != "Ten off" || applied[0][0].CouponCode != "TEN" {
+		t.Fatalf("unexpected applied promotion %+v", applied)
+	}
+}
+
+func TestActiveAt(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	happyHour := Promotion{Active: true, StartsAt: &start, EndsAt: &end, Days: []int{int(time.Friday)}, StartMinute: 17 * 60, EndMinute: 19 * 60}
+
+	friday := time.Date(2026, 3, 6, 18, 30, 0, 0, time.UTC)
+	if !happyHour.ActiveAt(friday) {
+		t.Fatal("expected the happy hour to run on Friday evening")
+	}
+	if happyHour.ActiveAt(friday.Add(time.Hour)) {
+		t.Fatal("expected the happy hour to end at 19:00")
+	}
+	if happyHour.ActiveAt(friday.AddDate(0, 0, 1)) {
+		t.Fatal("expected the happy hour not to run on Saturday")
+	}
+	if happyHour.ActiveAt(friday.AddDate(0, 0, 28)) {
+		t.Fatal("expected the promotion to end with its date range")
+	}
+
+	lateNight := Promotion{Active: true, StartMinute: 22 * 60, EndMinute: 2 * 60}
+	if !lateNight.ActiveAt(time.Date(2026, 3, 6, 1, 0, 0, 0, time.UTC)) || lateNight.ActiveAt(time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)) {
+		t.Fatal("expected a window past midnight to wrap")
+	}
+	if (Promotion{}).ActiveAt(friday) {
+		t.Fatal("expected an inactive promotion never to run")
+	}
+}
+
+func TestInputValidate(t *testing.T) {
+	valid := []Input{
+		{Name: "Ten off", Kind: KindPercentOff, PercentBasisPoints: 1000},
+		{Name: "BOGOF", Kind: KindBuyXGetY, BuyQty: 1, GetQty: 1},
+		{Name: "3 for 2", Kind: KindMultiBuy, BuyQty: 3, AmountCents: 800},
+		{Name: "Spend 50", Kind: KindSpendThreshold, MinSpendCents: 5000, AmountCents: 500, CouponCode: "spend 50", UsageLimit: 100},
+	}
+	for _, in := range valid {
+		in.Normalize()
+		if err := in.Validate(); err != nil {
+			t.Fatalf("%s: %v", in.Name, err)
+		}
+	}
+
+	invalid := map[string]Input{
+		"noName":              {Kind: KindPercentOff, PercentBasisPoints: 1000},
+		"unknownKind":         {Name: "x", Kind: "Mystery"},
+		"overHundredPercent":  {Name: "x", Kind: KindPercentOff, PercentBasisPoints: 10001},
+		"multiBuyOfOne":       {Name: "x", Kind: KindMultiBuy, BuyQty: 1, AmountCents: 100},
+		"thresholdBothOffers": {Name: "x", Kind: KindSpendThreshold, MinSpendCents: 1000, AmountCents: 100, PercentBasisPoints: 100},
+		"limitWithoutCoupon":  {Name: "x", Kind: KindPercentOff, PercentBasisPoints: 100, UsageLimit: 5},
+		"badWeekday":          {Name: "x", Kind: KindPercentOff, PercentBasisPoints: 100, Days: []int{7}},
+	}
+	for name, in := range invalid {
+		in.Normalize()
+		if err := in.Validate(); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
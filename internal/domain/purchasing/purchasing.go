@@ -0,0 +1,84 @@
+package purchasing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Purchase order statuses.
+const (
+	StatusDraft = "Draft"
+)
+
+// Supplier is a vendor stock is replenished from.
+type Supplier struct {
+	ID           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Contact      string    `json:"contact"`
+	Phone        string    `json:"phone"`
+	Email        string    `json:"email"`
+	LeadTimeDays int64     `json:"leadTimeDays"`
+	Notes        string    `json:"notes"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// SupplierInput describes editable supplier fields.
+type SupplierInput struct {
+	Name         string
+	Contact      string
+	Phone        string
+	Email        string
+	LeadTimeDays int64
+	Notes        string
+}
+
+// Validate ensures supplier input is usable.
+func (in SupplierInput) Validate() error {
+	if strings.TrimSpace(in.Name) == "" {
+		return errors.New("supplier name is required")
+	}
+	if in.LeadTimeDays < 0 {
+		return fmt.Errorf("lead time must be >= 0 (got %d)", in.LeadTimeDays)
+	}
+	return nil
+}
+
+// PurchaseOrder is an order placed (or to be placed) with a supplier.
+type PurchaseOrder struct {
+	ID           int64       `json:"id"`
+	OrderNumber  string      `json:"orderNumber"`
+	SupplierID   int64       `json:"supplierId"`
+	SupplierName string      `json:"supplierName"`
+	Status       string      `json:"status"`
+	CreatedAt    time.Time   `json:"createdAt"`
+	Note         string      `json:"note"`
+	Lines        []OrderLine `json:"lines"`
+}
+
+// OrderLine is one product on a purchase order.
+type OrderLine struct {
+	ProductID     int64  `json:"productId"`
+	ProductName   string `json:"productName"`
+	SKU           string `json:"sku"`
+	Quantity      int64  `json:"quantity"`
+	UnitCostCents int64  `json:"unitCostCents"`
+}
+
+// DraftLine requests a quantity of a product on a draft order.
+type DraftLine struct {
+	ProductID int64
+	Quantity  int64
+}
+
+// Validate ensures the draft line is orderable.
+func (l DraftLine) Validate() error {
+	if l.ProductID <= 0 {
+		return errors.New("product id required")
+	}
+	if l.Quantity <= 0 {
+		return fmt.Errorf("quantity must be > 0 (got %d)", l.Quantity)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package purchasing
+
+import (
+	"errors"
+	"math"
+)
+
+// Policy tunes how reorder points and quantities are suggested.
+type Policy struct {
+	// WindowDays is the sales history used to compute average daily sales.
+	WindowDays int64 `json:"windowDays"`
+	// SafetyDays of average demand are held as safety stock.
+	SafetyDays int64 `json:"safetyDays"`
+	// CoverDays of demand an order should cover beyond the reorder point.
+	CoverDays int64 `json:"coverDays"`
+	// DefaultLeadTimeDays applies when neither product nor supplier sets one.
+	DefaultLeadTimeDays int64 `json:"defaultLeadTimeDays"`
+}
+
+// DefaultPolicy looks back four weeks, covers two weeks of demand and assumes a week's
+// lead time.
+func DefaultPolicy() Policy {
+	return Policy{WindowDays: 28, CoverDays: 14, DefaultLeadTimeDays: 7}
+}
+
+// ApplyDefaults fills an unset sales window and clears negative day counts. Safety, cover
+// and lead time days of 0 are kept; they come from DefaultPolicy until a policy is saved.
+func (p *Policy) ApplyDefaults() {
+	if p.WindowDays <= 0 {
+		p.WindowDays = 28
+	}
+	if p.SafetyDays < 0 {
+		p.SafetyDays = 0
+	}
+	if p.CoverDays < 0 {
+		p.CoverDays = 0
+	}
+	if p.DefaultLeadTimeDays < 0 {
+		p.DefaultLeadTimeDays = 0
+	}
+}
+
+// Validate rejects nonsensical policies.
+func (p Policy) Validate() error {
+	if p.WindowDays <= 0 || p.WindowDays > 365 {
+		return errors.New("sales window must be between 1 and 365 days")
+	}
+	if p.SafetyDays < 0 || p.CoverDays < 0 || p.DefaultLeadTimeDays < 0 {
+		return errors.New("safety, cover and lead time days must be >= 0")
+	}
+	return nil
+}
+
+// Demand is the stock and sales history of a product used to suggest reorders.
+type Demand struct {
+	ProductID    int64
+	ProductName  string
+	SKU          string
+	SupplierID   int64
+	SupplierName string
+	CurrentQty   int64
+	ReorderLevel int64
+	LeadTimeDays int64
+	UnitsSold    int64
+}
+
+// Suggestion proposes a reorder for one product.
+type Suggestion struct {
+	ProductID       int64   `json:"productId"`
+	ProductName     string  `json:"productName"`
+	SKU             string  `json:"sku"`
+	SupplierID      int64   `json:"supplierId"`
+	SupplierName    string  `json:"supplierName"`
+	CurrentQty      int64   `json:"currentQty"`
+	AvgDailySales   float64 `json:"avgDailySales"`
+	LeadTimeDays    int64   `json:"leadTimeDays"`
+	SafetyStock     int64   `json:"safetyStock"`
+	ReorderPoint    int64   `json:"reorderPoint"`
+	SuggestedQty    int64   `json:"suggestedQty"`
+	DaysOfCover     float64 `json:"daysOfCover"`
+	ManualThreshold bool    `json:"manualThreshold"`
+}
+
+// SupplierSuggestions groups suggestions for one supplier; SupplierID 0 collects unassigned products.
+type SupplierSuggestions struct {
+	SupplierID   int64        `json:"supplierId"`
+	SupplierName string       `json:"supplierName"`
+	Suggestions  []Suggestion `json:"suggestions"`
+}
+
+// Suggest computes the reorder point for a product and, when stock is at or below it,
+// the quantity that restores lead-time demand, safety stock and the cover period.
+// A manual reorder level acts as a floor for the computed reorder point.
+func Suggest(d Demand, policy Policy) (Suggestion, bool) {
+	policy.ApplyDefaults()
+
+	lead := d.LeadTimeDays
+	if lead <= 0 {
+		lead = policy.DefaultLeadTimeDays
+	}
+	avg := float64(d.UnitsSold) / float64(policy.WindowDays)
+
+	s := Suggestion{
+		ProductID:     d.ProductID,
+		ProductName:   d.ProductName,
+		SKU:           d.SKU,
+		SupplierID:    d.SupplierID,
+		SupplierName:  d.SupplierName,
+		CurrentQty:    d.CurrentQty,
+		AvgDailySales: avg,
+		LeadTimeDays:  lead,
+		SafetyStock:   ceilQty(avg * float64(policy.SafetyDays)),
+	}
+	s.ReorderPoint = ceilQty(avg*float64(lead)) + s.SafetyStock
+	if d.ReorderLevel > s.ReorderPoint {
+		s.ReorderPoint = d.ReorderLevel
+		s.ManualThreshold = true
+	}
+	if avg > 0 {
+		s.DaysOfCover = math.Max(float64(d.CurrentQty), 0) / avg
+	}
+
+	if s.ReorderPoint <= 0 || d.CurrentQty > s.ReorderPoint {
+		return s, false
+	}
+
+	target := s.ReorderPoint + ceilQty(avg*float64(policy.CoverDays))
+	s.SuggestedQty = target - d.CurrentQty
+	if s.SuggestedQty <= 0 {
+		s.SuggestedQty = 1
+	}
+	return s, true
+}
+
+func ceilQty(v float64) int64 {
+	return int64(math.Ceil(v - 1e-9))
+}
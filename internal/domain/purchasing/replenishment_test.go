@@ -0,0 +1,44 @@
+package purchasing
+
+import "testing"
+
+func TestSuggest(t *testing.T) {
+	policy := Policy{WindowDays: 10, SafetyDays: 2, CoverDays: 7, DefaultLeadTimeDays: 5}
+
+	tests := []struct {
+		name      string
+		demand    Demand
+		wantOK    bool
+		wantPoint int64
+		wantQty   int64
+	}{
+		{"noSalesNoLevel", Demand{CurrentQty: 0}, false, 0, 0},
+		{"velocityBelowPoint", Demand{CurrentQty: 8, UnitsSold: 20, LeadTimeDays: 3}, true, 10, 16},
+		{"velocityAbovePoint", Demand{CurrentQty: 11, UnitsSold: 20, LeadTimeDays: 3}, false, 10, 0},
+		{"defaultLeadTime", Demand{CurrentQty: 5, UnitsSold: 10}, true, 7, 9},
+		{"manualFloor", Demand{CurrentQty: 4, ReorderLevel: 5}, true, 5, 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := Suggest(tc.demand, policy)
+			if ok != tc.wantOK {
+				t.Fatalf("Suggest ok = %v want %v (%+v)", ok, tc.wantOK, got)
+			}
+			if got.ReorderPoint != tc.wantPoint {
+				t.Fatalf("reorder point = %d want %d", got.ReorderPoint, tc.wantPoint)
+			}
+			if got.SuggestedQty != tc.wantQty {
+				t.Fatalf("suggested qty = %d want %d", got.SuggestedQty, tc.wantQty)
+			}
+		})
+	}
+}
+
+func TestSuggestWithoutCover(t *testing.T) {
+	policy := Policy{WindowDays: 10, CoverDays: 0, DefaultLeadTimeDays: 5}
+	got, ok := Suggest(Demand{CurrentQty: 5, UnitsSold: 20}, policy)
+	if !ok || got.ReorderPoint != 10 || got.SuggestedQty != 5 {
+		t.Fatalf("expected an order up to the reorder point only, got %+v (%v)", got, ok)
+	}
+}
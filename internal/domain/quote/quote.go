@@ -0,0 +1,123 @@
+// Package quote models written quotes and estimates that can later be converted into sales.
+package quote
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Quote statuses. Draft and Sent quotes are open: they can be converted into a sale until
+// they expire. Only drafts can be edited.
+const (
+	StatusDraft    = "Draft"
+	StatusSent     = "Sent"
+	StatusAccepted = "Accepted"
+	StatusExpired  = "Expired"
+)
+
+// DefaultValidityDays is how long a quote is valid when no validity is given.
+const DefaultValidityDays = 30
+
+// maxValidityDays bounds how far ahead a quote may be valid.
+const maxValidityDays = 365
+
+// Line is a priced product on a quote.
+type Line struct {
+	ID                 int64  `json:"id"`
+	ProductID          int64  `json:"productId"`
+	ProductName        string `json:"productName"`
+	SKU                string `json:"sku"`
+	Quantity           int64  `json:"quantity"`
+	UnitPriceCents     int64  `json:"unitPriceCents"`
+	TaxRateBasisPoints int64  `json:"taxRateBasisPoints"`
+	LineSubtotalCents  int64  `json:"lineSubtotalCents"`
+	LineDiscountCents  int64  `json:"lineDiscountCents"`
+	LineTaxCents       int64  `json:"lineTaxCents"`
+	LineTotalCents     int64  `json:"lineTotalCents"`
+}
+
+// Quote is a priced offer to a customer, valid until ValidUntil. CustomerID links it to a
+// customer record when set. SaleID links an accepted quote to the sale it was converted
+// into.
+type Quote struct {
+	ID            int64      `json:"id"`
+	QuoteNumber   string     `json:"quoteNumber"`
+	CustomerID    int64      `json:"customerId"`
+	CustomerName  string     `json:"customerName"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	ValidUntil    time.Time  `json:"validUntil"`
+	SentAt        *time.Time `json:"sentAt,omitempty"`
+	AcceptedAt    *time.Time `json:"acceptedAt,omitempty"`
+	SaleID        int64      `json:"saleId,omitempty"`
+	SubtotalCents int64      `json:"subtotalCents"`
+	DiscountCents int64      `json:"discountCents"`
+	TaxCents      int64      `json:"taxCents"`
+	TotalCents    int64      `json:"totalCents"`
+	Note          string     `json:"note"`
+	Lines         []Line     `json:"lines"`
+}
+
+// Open reports whether the quote can still be accepted, ignoring its validity date.
+func (q Quote) Open() bool {
+	return q.Status == StatusDraft || q.Status == StatusSent
+}
+
+// OrderDiscountCents returns the part of the quote's discount not given on its lines.
+func (q Quote) OrderDiscountCents() int64 {
+	discount := q.DiscountCents
+	for _, line := range q.Lines {
+		discount -= line.LineDiscountCents
+	}
+	return max(discount, 0)
+}
+
+// Lapsed reports whether an open quote is past its validity date at now.
+func (q Quote) Lapsed(now time.Time) bool {
+	return q.Open() && !now.Before(q.ValidUntil)
+}
+
+// Draft is a priced quote ready to be saved.
+type Draft struct {
+	CustomerID    int64
+	CustomerName  string
+	ValidUntil    time.Time
+	SubtotalCents int64
+	DiscountCents int64
+	TaxCents      int64
+	TotalCents    int64
+	Note          string
+	Lines         []Line
+}
+
+// Validate ensures the draft holds at least one product and a validity date.
+func (d Draft) Validate() error {
+	if len(d.Lines) == 0 {
+		return errors.New("at least one line item required")
+	}
+	if d.ValidUntil.IsZero() {
+		return errors.New("valid until date required")
+	}
+	for i, line := range d.Lines {
+		if line.ProductID <= 0 {
+			return fmt.Errorf("line %d: product id required", i)
+		}
+		if line.Quantity <= 0 {
+			return fmt.Errorf("line %d: quantity must be > 0", i)
+		}
+	}
+	return nil
+}
+
+// ValidUntil returns the end of a quote's validity when issued at issuedAt for days
+// days; zero days uses DefaultValidityDays.
+func ValidUntil(issuedAt time.Time, days int) (time.Time, error) {
+	if days == 0 {
+		days = DefaultValidityDays
+	}
+	if days < 0 || days > maxValidityDays {
+		return time.Time{}, fmt.Errorf("quote validity must be between 1 and %d days", maxValidityDays)
+	}
+	return issuedAt.AddDate(0, 0, days), nil
+}
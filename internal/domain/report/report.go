@@ -18,3 +18,70 @@ type TopProduct struct {
 	QuantitySold int64  `json:"quantitySold"`
 	RevenueCents int64  `json:"revenueCents"`
 }
+
+// CategorySales rolls up sales for a category and all of its subcategories.
+type CategorySales struct {
+	CategoryID   int64  `json:"categoryId"`
+	CategoryPath string `json:"categoryPath"`
+	QuantitySold int64  `json:"quantitySold"`
+	RevenueCents int64  `json:"revenueCents"`
+}
+
+// Valuation is the value of stock on hand at a point in time.
+type Valuation struct {
+	AsOf            time.Time       `json:"asOf"`
+	Method          string          `json:"method"`
+	TotalQty        int64           `json:"totalQty"`
+	TotalValueCents int64           `json:"totalValueCents"`
+	Lines           []ValuationLine `json:"lines"`
+}
+
+// ValuationLine is one product's stock and value. UnitCostCents is the average cost of the
+// units held, or zero when none are.
+type ValuationLine struct {
+	ProductID     int64  `json:"productId"`
+	SKU           string `json:"sku"`
+	ProductName   string `json:"productName"`
+	Category      string `json:"category"`
+	Qty           int64  `json:"qty"`
+	UnitCostCents int64  `json:"unitCostCents"`
+	ValueCents    int64  `json:"valueCents"`
+}
+
+// NegativeStockLine is a product held below zero at a location, with the policy that let it
+// go there and the backorders still open against it.
+type NegativeStockLine struct {
+	ProductID      int64  `json:"productId"`
+	SKU            string `json:"sku"`
+	ProductName    string `json:"productName"`
+	LocationID     int64  `json:"locationId"`
+	LocationName   string `json:"locationName"`
+	Qty            int64  `json:"qty"`
+	Policy         string `json:"policy"`
+	BackorderedQty int64  `json:"backorderedQty"`
+}
+
+// PaymentTotal is the money taken and paid back in one payment method over a period.
+// ReceivedCents is what the tenders paid towards sales (tendered less change) and
+// NetCents is that less refunds paid out.
+type PaymentTotal struct {
+	Method        string `json:"method"`
+	SaleCount     int64  `json:"saleCount"`
+	TenderedCents int64  `json:"tenderedCents"`
+	ChangeCents   int64  `json:"changeCents"`
+	ReceivedCents int64  `json:"receivedCents"`
+	RefundedCents int64  `json:"refundedCents"`
+	NetCents      int64  `json:"netCents"`
+}
+
+// PromotionTotal is what one promotion gave away over a period. PromotionID is zero once
+// the promotion has been deleted. Units counts the units on the lines it discounted.
+type PromotionTotal struct {
+	PromotionID   int64  `json:"promotionId"`
+	Name          string `json:"name"`
+	CouponCode    string `json:"couponCode"`
+	SaleCount     int64  `json:"saleCount"`
+	LineCount     int64  `json:"lineCount"`
+	Units         int64  `json:"units"`
+	DiscountCents int64  `json:"discountCents"`
+}
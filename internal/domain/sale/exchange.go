@@ -0,0 +1,46 @@
+package sale
+
+import (
+	"errors"
+	"time"
+)
+
+// Exchange swaps items returned from a sale for new ones in one visit. The returned items
+// are credited on Return and the replacements sold on Sale; the customer settles the
+// difference with a single payment or refund.
+type Exchange struct {
+	ID            int64     `json:"id"`
+	Timestamp     time.Time `json:"timestamp"`
+	PaymentMethod string    `json:"paymentMethod"`
+	// NetCents is the replacement sale's total less the credit for the returned items:
+	// positive when the customer pays the difference, negative when they are refunded it.
+	NetCents int64  `json:"netCents"`
+	Return   Refund `json:"return"`
+	Sale     Sale   `json:"sale"`
+}
+
+// NetTotal returns what the customer owes (positive) or is owed (negative).
+func (e Exchange) NetTotal() int64 {
+	return e.Sale.TotalCents - e.Return.TotalCents
+}
+
+// ExchangeDraft is the data needed to record an exchange. Both sides share the exchange's
+// payment method and timestamp.
+type ExchangeDraft struct {
+	Return RefundDraft
+	Sale   Sale
+}
+
+// Validate ensures the draft returns and sells at least one line.
+func (d ExchangeDraft) Validate() error {
+	if err := d.Return.Validate(); err != nil {
+		return err
+	}
+	if len(d.Sale.Lines) == 0 {
+		return errors.New("at least one replacement line required")
+	}
+	if d.Sale.PaymentMethod == "" {
+		return errors.New("payment method required")
+	}
+	return nil
+}
@@ -9,8 +9,10 @@ type Filter struct {
 	PaymentMethods []string
 	Status         []string
 	CustomerQuery  string
-	Limit          int
-	Offset         int
+	// CustomerID limits the results to one customer's sales when set.
+	CustomerID int64
+	Limit      int
+	Offset     int
 }
 
 // Normalize ensures sane defaults for ranges and paging.
@@ -0,0 +1,122 @@
+package sale
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// PaymentCash is the only tender that may exceed what it pays for and give change.
+	PaymentCash = "Cash"
+	// PaymentSplit is a sale's payment method when it was paid with more than one method.
+	PaymentSplit = "Split"
+	// PaymentPoints pays with the customer's loyalty points; refunds to it credit points.
+	PaymentPoints = "Points"
+	// PaymentGiftCard and PaymentStoreCredit pay from a stored-value account named by the
+	// payment's reference code.
+	PaymentGiftCard    = "Gift Card"
+	PaymentStoreCredit = "Store Credit"
+)
+
+// Tender is an amount the customer hands over in one payment method. Reference names the
+// account paid from for stored-value methods.
+type Tender struct {
+	Method      string
+	AmountCents int64
+	Reference   string
+}
+
+// Payment is one tender recorded against a sale. AppliedCents is the part that paid for
+// the sale; the rest of TenderedCents went back to the customer as change.
+type Payment struct {
+	Method        string `json:"method"`
+	TenderedCents int64  `json:"tenderedCents"`
+	AppliedCents  int64  `json:"appliedCents"`
+	Reference     string `json:"reference,omitempty"`
+}
+
+// ChangeCents returns the change given back from the tender.
+func (p Payment) ChangeCents() int64 {
+	return p.TenderedCents - p.AppliedCents
+}
+
+// IsCash reports whether method is cash.
+func IsCash(method string) bool {
+	return strings.EqualFold(strings.TrimSpace(method), PaymentCash)
+}
+
+// IsPoints reports whether method pays with loyalty points.
+func IsPoints(method string) bool {
+	return strings.EqualFold(strings.TrimSpace(method), PaymentPoints)
+}
+
+// IsStoredValue reports whether method pays from a gift card or store credit account.
+func IsStoredValue(method string) bool {
+	method = strings.TrimSpace(method)
+	return strings.EqualFold(method, PaymentGiftCard) || strings.EqualFold(method, PaymentStoreCredit)
+}
+
+// SettlePayments applies tenders to a sale total. Non-cash tenders apply in full and may not
+// together exceed the total; cash tenders cover what remains in order and any excess is
+// returned as change.
+func SettlePayments(totalCents int64, tenders []Tender) ([]Payment, int64, error) {
+	if len(tenders) == 0 {
+		return nil, 0, errors.New("at least one payment required")
+	}
+
+	var tendered, nonCash int64
+	for i, t := range tenders {
+		if strings.TrimSpace(t.Method) == "" {
+			return nil, 0, fmt.Errorf("payment %d: method required", i)
+		}
+		if t.AmountCents < 0 || (t.AmountCents == 0 && len(tenders) > 1) {
+			return nil, 0, fmt.Errorf("payment %d: amount must be > 0", i)
+		}
+		if IsStoredValue(t.Method) && strings.TrimSpace(t.Reference) == "" {
+			return nil, 0, fmt.Errorf("payment %d: %s code required", i, strings.TrimSpace(t.Method))
+		}
+		tendered += t.AmountCents
+		if !IsCash(t.Method) {
+			nonCash += t.AmountCents
+		}
+	}
+	if tendered < totalCents {
+		return nil, 0, fmt.Errorf("payments of %d do not cover the total of %d", tendered, totalCents)
+	}
+	if nonCash > totalCents {
+		return nil, 0, fmt.Errorf("non-cash payments of %d exceed the total of %d; only cash can give change", nonCash, totalCents)
+	}
+
+	remaining := totalCents - nonCash
+	payments := make([]Payment, 0, len(tenders))
+	for _, t := range tenders {
+		applied := t.AmountCents
+		if IsCash(t.Method) {
+			applied = min(t.AmountCents, remaining)
+			remaining -= applied
+		}
+		payments = append(payments, Payment{
+			Method:        strings.TrimSpace(t.Method),
+			TenderedCents: t.AmountCents,
+			AppliedCents:  applied,
+			Reference:     strings.TrimSpace(t.Reference),
+		})
+	}
+	return payments, tendered - totalCents, nil
+}
+
+// PaymentMethodOf summarises payments as a sale's single payment method: the method they
+// share, or PaymentSplit when they differ.
+func PaymentMethodOf(payments []Payment) string {
+	if len(payments) == 0 {
+		return ""
+	}
+	method := payments[0].Method
+	for _, p := range payments[1:] {
+		if !strings.EqualFold(p.Method, method) {
+			return PaymentSplit
+		}
+	}
+	return method
+}
@@ -0,0 +1,46 @@
+package sale
+
+import "testing"
+
+func TestSettlePayments(t *testing.T) {
+	payments, change, err := SettlePayments(4250, []Tender{{Method: "Card", AmountCents: 3000}, {Method: "cash", AmountCents: 2000}})
+	if err != nil {
+		t.Fatalf("settle: %v", err)
+	}
+	if change != 750 {
+		t.Fatalf("expected 750 change, got %d", change)
+	}
+	if payments[0].AppliedCents != 3000 || payments[1].AppliedCents != 1250 || payments[1].ChangeCents() != 750 {
+		t.Fatalf("unexpected payments %+v", payments)
+	}
+	if got := PaymentMethodOf(payments); got != PaymentSplit {
+		t.Fatalf("expected split payment method, got %s", got)
+	}
+
+	// Cash tenders cover the remainder in order; a second note only makes change.
+	payments, change, err = SettlePayments(1500, []Tender{{Method: "Cash", AmountCents: 1000}, {Method: "Cash", AmountCents: 1000}})
+	if err != nil || change != 500 || payments[0].AppliedCents != 1000 || payments[1].AppliedCents != 500 {
+		t.Fatalf("unexpected settlement %+v change %d err %v", payments, change, err)
+	}
+	if got := PaymentMethodOf(payments); got != "Cash" {
+		t.Fatalf("expected cash payment method, got %s", got)
+	}
+
+	invalid := []struct {
+		name    string
+		total   int64
+		tenders []Tender
+	}{
+		{"none", 100, nil},
+		{"short", 1000, []Tender{{Method: "Cash", AmountCents: 600}, {Method: "Card", AmountCents: 300}}},
+		{"cardChange", 1000, []Tender{{Method: "Card", AmountCents: 1200}}},
+		{"noMethod", 1000, []Tender{{AmountCents: 1000}}},
+		{"zeroSplit", 1000, []Tender{{Method: "Card", AmountCents: 1000}, {Method: "Cash"}}},
+		{"giftCardWithoutCode", 1000, []Tender{{Method: PaymentGiftCard, AmountCents: 1000}}},
+	}
+	for _, tc := range invalid {
+		if _, _, err := SettlePayments(tc.total, tc.tenders); err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package sale
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Refund is a credit note returning some or all of a sale's lines.
+type Refund struct {
+	ID            int64     `json:"id"`
+	RefundNumber  string    `json:"refundNumber"`
+	SaleID        int64     `json:"saleId"`
+	SaleNumber    string    `json:"saleNumber"`
+	Timestamp     time.Time `json:"timestamp"`
+	PaymentMethod string    `json:"paymentMethod"`
+	// Reference is the code of the gift card or store credit account refunded to.
+	Reference     string `json:"reference,omitempty"`
+	Reason        string `json:"reason"`
+	SubtotalCents int64  `json:"subtotalCents"`
+	DiscountCents int64  `json:"discountCents"`
+	TaxCents      int64  `json:"taxCents"`
+	TotalCents    int64  `json:"totalCents"`
+	// TaxIncluded follows the sale: the refund's tax is inside its line amounts.
+	TaxIncluded bool         `json:"taxIncluded"`
+	LocationID  int64        `json:"locationId"`
+	Lines       []RefundLine `json:"lines"`
+}
+
+// RefundLine is the part of a sale line being returned, priced as its share of the line.
+// TotalCents mirrors the sale line's total; OrderDiscountCents is the line's share of the
+// sale's order discount, which the refund total deducts as the sale total did.
+type RefundLine struct {
+	SaleLineID         int64    `json:"saleLineId"`
+	ProductID          int64    `json:"productId"`
+	ProductName        string   `json:"productName"`
+	SKU                string   `json:"sku"`
+	Quantity           int64    `json:"quantity"`
+	SubtotalCents      int64    `json:"subtotalCents"`
+	LineDiscountCents  int64    `json:"lineDiscountCents"`
+	OrderDiscountCents int64    `json:"orderDiscountCents"`
+	TaxCents           int64    `json:"taxCents"`
+	TotalCents         int64    `json:"totalCents"`
+	CostCents          int64    `json:"costCents"`
+	SerialNumbers      []string `json:"serialNumbers,omitempty"`
+}
+
+// ReturnLine selects units of a sale line to return. Serialised lines name the units
+// returned unless every remaining unit is.
+type ReturnLine struct {
+	SaleLineID    int64
+	Quantity      int64
+	SerialNumbers []string
+}
+
+// RefundDraft is the data needed to record a refund. An empty PaymentMethod refunds to the
+// sale's payment method. Reference names the gift card or store credit account refunded to;
+// without it the account the sale was paid from is used, or new store credit is issued.
+type RefundDraft struct {
+	SaleID        int64
+	PaymentMethod string
+	Reference     string
+	Reason        string
+	Timestamp     time.Time
+	Lines         []ReturnLine
+}
+
+// Validate ensures the draft names a sale and each line at most once.
+func (d RefundDraft) Validate() error {
+	if d.SaleID <= 0 {
+		return errors.New("sale id required")
+	}
+	if len(d.Lines) == 0 {
+		return errors.New("at least one line to refund required")
+	}
+	seen := make(map[int64]bool, len(d.Lines))
+	for i, line := range d.Lines {
+		if line.SaleLineID <= 0 {
+			return fmt.Errorf("line %d: sale line id required", i)
+		}
+		if line.Quantity <= 0 {
+			return fmt.Errorf("line %d: quantity must be > 0", i)
+		}
+		if seen[line.SaleLineID] {
+			return fmt.Errorf("line %d: sale line %d listed twice", i, line.SaleLineID)
+		}
+		seen[line.SaleLineID] = true
+	}
+	return nil
+}
+
+// AllocateOrderDiscount splits a sale's discount across its lines. The discount covers the
+// lines' own discounts, which are left out of the shares; each line keeps its own promotion
+// discount and the rest is split in proportion to the lines' amounts after line discounts
+// and promotions, handing leftover cents to the earliest lines.
+func AllocateOrderDiscount(discount int64, lines []Line) []int64 {
+	shares := make([]int64, len(lines))
+	for i, line := range lines {
+		shares[i] = line.PromotionDiscountCents
+		discount -= line.LineDiscountCents + line.PromotionDiscountCents
+	}
+	if discount <= 0 || len(lines) == 0 {
+		return shares
+	}
+	weights := make([]int64, len(lines))
+	var total int64
+	for i, line := range lines {
+		weights[i] = max(line.LineSubtotalCents-line.LineDiscountCents-line.PromotionDiscountCents, 0)
+		total += weights[i]
+	}
+	if total == 0 {
+		shares[0] += discount
+		return shares
+	}
+	var allocated int64
+	for i, weight := range weights {
+		share := discount * weight / total
+		shares[i] += share
+		allocated += share
+	}
+	for i := 0; allocated < discount; i = (i + 1) % len(lines) {
+		if weights[i] > 0 {
+			shares[i]++
+			allocated++
+		}
+	}
+	return shares
+}
+
+// ProrateReturn prices returning qty units of a line of which refunded units were already
+// returned. Amounts are split cumulatively, so refunding every unit across any number of
+// refunds returns exactly the line's amounts.
+func ProrateReturn(line Line, orderDiscount, refunded, qty int64) RefundLine {
+	return RefundLine{
+		ProductID:          line.ProductID,
+		ProductName:        line.ProductName,
+		SKU:                line.SKU,
+		Quantity:           qty,
+		SubtotalCents:      Prorate(line.LineSubtotalCents, line.Quantity, refunded, qty),
+		LineDiscountCents:  Prorate(line.LineDiscountCents, line.Quantity, refunded, qty),
+		OrderDiscountCents: Prorate(orderDiscount, line.Quantity, refunded, qty),
+		TaxCents:           Prorate(line.LineTaxCents, line.Quantity, refunded, qty),
+		TotalCents:         Prorate(line.LineTotalCents, line.Quantity, refunded, qty),
+		CostCents:          Prorate(line.CostCents, line.Quantity, refunded, qty),
+	}
+}
+
+// Prorate returns the share of amount spread over total units that falls on the qty units
+// following the first from units.
+func Prorate(amount, total, from, qty int64) int64 {
+	if total <= 0 {
+		return 0
+	}
+	return amount*(from+qty)/total - amount*from/total
+}
+
+// Totals sums the refund's lines into its subtotal, discount, tax and total, the same way a
+// sale's totals are formed.
+func (r *Refund) Totals() {
+	r.SubtotalCents, r.DiscountCents, r.TaxCents = 0, 0, 0
+	for _, line := range r.Lines {
+		r.SubtotalCents += line.SubtotalCents
+		r.DiscountCents += line.LineDiscountCents + line.OrderDiscountCents
+		r.TaxCents += line.TaxCents
+	}
+	r.TotalCents = r.SubtotalCents - r.DiscountCents
+	if !r.TaxIncluded {
+		r.TotalCents += r.TaxCents
+	}
+}
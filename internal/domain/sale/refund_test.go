@@ -0,0 +1,77 @@
+package sale
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAllocateOrderDiscount(t *testing.T) {
+	lines := []Line{
+		{LineSubtotalCents: 1000},
+		{LineSubtotalCents: 1000, LineDiscountCents: 500},
+		{LineSubtotalCents: 500, LineDiscountCents: 500},
+	}
+	// The sale's discount includes the lines' own 1000, which stays on them.
+	got := AllocateOrderDiscount(1100, lines)
+	if want := []int64{67, 33, 0}; !slices.Equal(got, want) {
+		t.Fatalf("AllocateOrderDiscount = %v want %v", got, want)
+	}
+	if got := AllocateOrderDiscount(1000, lines); !slices.Equal(got, []int64{0, 0, 0}) {
+		t.Fatalf("expected no discount, got %v", got)
+	}
+
+	// Promotions stay on their lines; only the rest is shared out.
+	lines[1].PromotionDiscountCents = 250
+	if got, want := AllocateOrderDiscount(1350, lines), []int64{80, 270, 0}; !slices.Equal(got, want) {
+		t.Fatalf("AllocateOrderDiscount with promotions = %v want %v", got, want)
+	}
+}
+
+func TestProrateReturnAddsUpToLine(t *testing.T) {
+	line := Line{
+		Quantity:          3,
+		LineSubtotalCents: 1000,
+		LineDiscountCents: 100,
+		LineTaxCents:      47,
+		LineTotalCents:    947,
+		CostCents:         500,
+	}
+
+	var subtotal, discount, orderDiscount, tax, total, cost int64
+	refunded := int64(0)
+	for _, qty := range []int64{1, 1, 1} {
+		r := ProrateReturn(line, 20, refunded, qty)
+		subtotal += r.SubtotalCents
+		discount += r.LineDiscountCents
+		orderDiscount += r.OrderDiscountCents
+		tax += r.TaxCents
+		total += r.TotalCents
+		cost += r.CostCents
+		refunded += qty
+	}
+	if subtotal != 1000 || discount != 100 || orderDiscount != 20 || tax != 47 || total != 947 || cost != 500 {
+		t.Fatalf("refunds do not add up to the line: %d %d %d %d %d %d", subtotal, discount, orderDiscount, tax, total, cost)
+	}
+
+	first := ProrateReturn(line, 20, 0, 1)
+	if first.SubtotalCents != 333 || first.TaxCents != 15 {
+		t.Fatalf("unexpected first unit %+v", first)
+	}
+}
+
+func TestRefundDraftValidate(t *testing.T) {
+	invalid := []RefundDraft{
+		{Lines: []ReturnLine{{SaleLineID: 1, Quantity: 1}}},
+		{SaleID: 1},
+		{SaleID: 1, Lines: []ReturnLine{{SaleLineID: 1, Quantity: 0}}},
+		{SaleID: 1, Lines: []ReturnLine{{SaleLineID: 1, Quantity: 1}, {SaleLineID: 1, Quantity: 1}}},
+	}
+	for _, d := range invalid {
+		if err := d.Validate(); err == nil {
+			t.Fatalf("expected %+v invalid", d)
+		}
+	}
+	if err := (RefundDraft{SaleID: 1, Lines: []ReturnLine{{SaleLineID: 2, Quantity: 1}}}).Validate(); err != nil {
+		t.Fatalf("expected valid draft, got %v", err)
+	}
+}
@@ -8,37 +8,120 @@ import (
 
 // Line represents a sale line item.
 type Line struct {
-	ProductID          int64  `json:"productId"`
-	ProductName        string `json:"productName"`
-	SKU                string `json:"sku"`
-	Quantity           int64  `json:"quantity"`
-	UnitPriceCents     int64  `json:"unitPriceCents"`
-	TaxRateBasisPoints int64  `json:"taxRateBasisPoints"`
-	LineSubtotalCents  int64  `json:"lineSubtotalCents"`
-	LineDiscountCents  int64  `json:"lineDiscountCents"`
-	LineTaxCents       int64  `json:"lineTaxCents"`
-	LineTotalCents     int64  `json:"lineTotalCents"`
+	ID                 int64    `json:"id"`
+	ProductID          int64    `json:"productId"`
+	ProductName        string   `json:"productName"`
+	SKU                string   `json:"sku"`
+	Quantity           int64    `json:"quantity"`
+	UnitPriceCents     int64    `json:"unitPriceCents"`
+	TaxRateBasisPoints int64    `json:"taxRateBasisPoints"`
+	LineSubtotalCents  int64    `json:"lineSubtotalCents"`
+	LineDiscountCents  int64    `json:"lineDiscountCents"`
+	LineTaxCents       int64    `json:"lineTaxCents"`
+	LineTotalCents     int64    `json:"lineTotalCents"`
+	SerialNumbers      []string `json:"serialNumbers,omitempty"`
+	// PromotionDiscountCents is the discount Promotions give the line. It counts towards the
+	// sale's DiscountCents and is taken off the line before tax.
+	PromotionDiscountCents int64           `json:"promotionDiscountCents"`
+	Promotions             []LinePromotion `json:"promotions,omitempty"`
+	// CostCents is the line's cost of goods sold, valued when the sale was recorded.
+	CostCents int64 `json:"costCents"`
+	// RefundedQty counts units returned by refunds so far.
+	RefundedQty int64 `json:"refundedQty"`
+}
+
+// LinePromotion is the discount a promotion gave a sale line. CouponCode is set when the
+// promotion was redeemed with a coupon.
+type LinePromotion struct {
+	PromotionID   int64  `json:"promotionId"`
+	Name          string `json:"name"`
+	CouponCode    string `json:"couponCode,omitempty"`
+	DiscountCents int64  `json:"discountCents"`
 }
 
 // Sale aggregates invoice information.
 type Sale struct {
-	ID            int64     `json:"id"`
-	SaleNumber    string    `json:"saleNumber"`
-	Timestamp     time.Time `json:"timestamp"`
-	CustomerName  string    `json:"customerName"`
-	SubtotalCents int64     `json:"subtotalCents"`
-	DiscountCents int64     `json:"discountCents"`
-	TaxCents      int64     `json:"taxCents"`
-	TotalCents    int64     `json:"totalCents"`
+	ID         int64     `json:"id"`
+	SaleNumber string    `json:"saleNumber"`
+	Timestamp  time.Time `json:"timestamp"`
+	// CustomerID links the sale to a customer record; CustomerName is the name it was sold
+	// under.
+	CustomerID    int64  `json:"customerId"`
+	CustomerName  string `json:"customerName"`
+	SubtotalCents int64  `json:"subtotalCents"`
+	// DiscountCents is the order discount together with the lines' promotion discounts.
+	DiscountCents int64 `json:"discountCents"`
+	TaxCents      int64 `json:"taxCents"`
+	TotalCents    int64 `json:"totalCents"`
+	// TaxIncluded marks a sale priced with tax inside its line amounts; its total is the
+	// subtotal less discounts, and TaxCents is the tax that total contains.
+	TaxIncluded bool `json:"taxIncluded"`
+	// TaxExempt marks a sale to an exempt customer, under the exemption number they held.
+	TaxExempt          bool   `json:"taxExempt"`
+	TaxExemptionNumber string `json:"taxExemptionNumber,omitempty"`
+	// Taxes breaks TaxCents down by tax component and rate.
+	Taxes []TaxLine `json:"taxes"`
+	// PaymentMethod is the method of every payment, or PaymentSplit when they differ.
 	PaymentMethod string    `json:"paymentMethod"`
-	Status        string    `json:"status"`
-	Note          string    `json:"note"`
-	Lines         []Line    `json:"lines"`
+	Payments      []Payment `json:"payments"`
+	// ChangeCents is the change given back across all payments.
+	ChangeCents int64 `json:"changeCents"`
+	// PointsEarned and PointsRedeemed are the loyalty points the customer earned on the
+	// sale and spent paying for it. PointsBalance is the customer's balance once they were
+	// posted, when the sale was made in the loyalty programme.
+	PointsEarned   int64  `json:"pointsEarned"`
+	PointsRedeemed int64  `json:"pointsRedeemed"`
+	PointsBalance  *int64 `json:"pointsBalance,omitempty"`
+	Status         string `json:"status"`
+	Note           string `json:"note"`
+	LocationID     int64  `json:"locationId"`
+	Lines          []Line `json:"lines"`
+	// Shortages lists products sold beyond the stock on hand. It is only reported when the
+	// sale is recorded.
+	Shortages []Shortage `json:"shortages,omitempty"`
+}
+
+// TaxLine is one row of a sale's tax breakdown: the tax a component charged at its rate,
+// and the amount it was charged on. A compound component's taxable amount includes the
+// components before it.
+type TaxLine struct {
+	Name            string `json:"name"`
+	RateBasisPoints int64  `json:"rateBasisPoints"`
+	Compound        bool   `json:"compound"`
+	TaxableCents    int64  `json:"taxableCents"`
+	TaxCents        int64  `json:"taxCents"`
+}
+
+// Shortage describes a product a sale took below zero under a Warn or Backorder policy.
+type Shortage struct {
+	ProductID   int64  `json:"productId"`
+	SKU         string `json:"sku"`
+	ProductName string `json:"productName"`
+	OnHand      int64  `json:"onHand"`
+	Requested   int64  `json:"requested"`
+	Policy      string `json:"policy"`
+	// BackorderedQty is the quantity owed to the customer under the Backorder policy.
+	BackorderedQty int64 `json:"backorderedQty"`
+}
+
+// Backorder is stock sold but not yet on hand, owed against a sale. Refunding the units
+// before they are handed over, or voiding the sale, cancels what is still owed.
+type Backorder struct {
+	ID          int64      `json:"id"`
+	SaleID      int64      `json:"saleId"`
+	SaleNumber  string     `json:"saleNumber"`
+	ProductID   int64      `json:"productId"`
+	SKU         string     `json:"sku"`
+	ProductName string     `json:"productName"`
+	LocationID  int64      `json:"locationId"`
+	Qty         int64      `json:"qty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	FulfilledAt *time.Time `json:"fulfilledAt,omitempty"`
+	CancelledAt *time.Time `json:"cancelledAt,omitempty"`
 }
 
 // Draft represents the data needed to save a sale.
 type Draft struct {
-	SaleNumber    string
 	Timestamp     time.Time
 	CustomerName  string
 	PaymentMethod string
@@ -60,9 +143,6 @@ type DraftLine struct {
 
 // Validate ensures the draft meets business constraints.
 func (d Draft) Validate() error {
-	if d.SaleNumber == "" {
-		return errors.New("sale number required")
-	}
 	if len(d.Lines) == 0 {
 		return errors.New("at least one line item required")
 	}
@@ -0,0 +1,122 @@
+package sequence
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sequence names.
+const (
+	// Sale numbers the shop's invoices.
+	Sale = "sale"
+	// CreditNote numbers refunds.
+	CreditNote = "credit_note"
+	// Quote numbers quotes and estimates.
+	Quote = "quote"
+)
+
+// defaultPrefixes start the default pattern of each sequence.
+var defaultPrefixes = map[string]string{
+	Sale:       "INV",
+	CreditNote: "CN",
+	Quote:      "QT",
+}
+
+// maxPadding bounds the zero padding of the counter.
+const maxPadding = 12
+
+var tokenPattern = regexp.MustCompile(`\{([A-Z]+)(?::(\d+))?\}`)
+
+// Format describes how a sequence's numbers are rendered. Pattern is literal text with
+// tokens: {YYYY}, {YY}, {MM} and {DD} for the date a number is allocated, and {SEQ} or
+// {SEQ:n} for the counter zero-padded to n digits. With ResetYearly the counter restarts at
+// 1 each calendar year, so the pattern must include the year.
+type Format struct {
+	Name        string `json:"name"`
+	Pattern     string `json:"pattern"`
+	ResetYearly bool   `json:"resetYearly"`
+}
+
+// DefaultFormat returns the format used for a sequence that has not been configured.
+func DefaultFormat(name string) Format {
+	prefix, ok := defaultPrefixes[name]
+	if !ok {
+		prefix = strings.ToUpper(name)
+	}
+	return Format{Name: name, Pattern: prefix + "-{YYYY}-{SEQ:6}", ResetYearly: true}
+}
+
+// Validate ensures the pattern has exactly one counter and only known tokens.
+func (f Format) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return errors.New("sequence name is required")
+	}
+	if strings.TrimSpace(f.Pattern) == "" {
+		return errors.New("sequence pattern is required")
+	}
+	var counters int
+	hasYear := false
+	for _, match := range tokenPattern.FindAllStringSubmatch(f.Pattern, -1) {
+		switch match[1] {
+		case "SEQ":
+			counters++
+			if match[2] != "" {
+				if n, _ := strconv.Atoi(match[2]); n < 1 || n > maxPadding {
+					return fmt.Errorf("counter padding must be between 1 and %d", maxPadding)
+				}
+			}
+		case "YYYY", "YY":
+			hasYear = true
+			if match[2] != "" {
+				return fmt.Errorf("token {%s} takes no width", match[1])
+			}
+		case "MM", "DD":
+			if match[2] != "" {
+				return fmt.Errorf("token {%s} takes no width", match[1])
+			}
+		default:
+			return fmt.Errorf("unknown token {%s}", match[1])
+		}
+	}
+	if counters != 1 {
+		return errors.New("sequence pattern must contain exactly one {SEQ} counter")
+	}
+	if f.ResetYearly && !hasYear {
+		return errors.New("a yearly reset needs {YYYY} or {YY} in the pattern to keep numbers unique")
+	}
+	return nil
+}
+
+// Period returns the counter bucket for numbers allocated at t: the year when the counter
+// resets yearly, otherwise a single bucket.
+func (f Format) Period(t time.Time) string {
+	if f.ResetYearly {
+		return strconv.Itoa(t.Year())
+	}
+	return ""
+}
+
+// Render produces the number for counter value n allocated at t.
+func (f Format) Render(t time.Time, n int64) string {
+	return tokenPattern.ReplaceAllStringFunc(f.Pattern, func(token string) string {
+		match := tokenPattern.FindStringSubmatch(token)
+		switch match[1] {
+		case "YYYY":
+			return fmt.Sprintf("%04d", t.Year())
+		case "YY":
+			return fmt.Sprintf("%02d", t.Year()%100)
+		case "MM":
+			return fmt.Sprintf("%02d", int(t.Month()))
+		case "DD":
+			return fmt.Sprintf("%02d", t.Day())
+		case "SEQ":
+			width, _ := strconv.Atoi(match[2])
+			return fmt.Sprintf("%0*d", width, n)
+		}
+		return token
+	})
+}
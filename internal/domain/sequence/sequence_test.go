@@ -0,0 +1,61 @@
+package sequence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatRender(t *testing.T) {
+	at := time.Date(2026, time.March, 7, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		pattern string
+		n       int64
+		want    string
+	}{
+		{"INV-{YYYY}-{SEQ:6}", 42, "INV-2026-000042"},
+		{"{YY}{MM}{DD}/{SEQ}", 7, "260307/7"},
+		{"S{SEQ:3}", 12345, "S12345"},
+	}
+	for _, tc := range tests {
+		if got := (Format{Name: Sale, Pattern: tc.pattern}).Render(at, tc.n); got != tc.want {
+			t.Fatalf("Render(%q, %d) = %q want %q", tc.pattern, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestFormatValidate(t *testing.T) {
+	valid := []Format{
+		DefaultFormat(Sale),
+		{Name: Sale, Pattern: "{SEQ}"},
+		{Name: Sale, Pattern: "CN{YY}-{SEQ:4}", ResetYearly: true},
+	}
+	for _, f := range valid {
+		if err := f.Validate(); err != nil {
+			t.Fatalf("expected %+v valid, got %v", f, err)
+		}
+	}
+
+	invalid := []Format{
+		{Name: Sale, Pattern: "INV-"},
+		{Name: Sale, Pattern: "{SEQ}-{SEQ}"},
+		{Name: Sale, Pattern: "{SEQ:0}"},
+		{Name: Sale, Pattern: "{WEEK}-{SEQ}"},
+		{Name: Sale, Pattern: "INV-{SEQ}", ResetYearly: true},
+		{Name: "", Pattern: "{SEQ}"},
+	}
+	for _, f := range invalid {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("expected %+v invalid", f)
+		}
+	}
+}
+
+func TestFormatPeriod(t *testing.T) {
+	at := time.Date(2026, time.December, 31, 23, 0, 0, 0, time.UTC)
+	if got := (Format{ResetYearly: true}).Period(at); got != "2026" {
+		t.Fatalf("yearly period = %q", got)
+	}
+	if got := (Format{}).Period(at); got != "" {
+		t.Fatalf("continuous period = %q", got)
+	}
+}
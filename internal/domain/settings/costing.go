@@ -0,0 +1,33 @@
+package settings
+
+import "fmt"
+
+// Costing methods.
+const (
+	CostingFIFO            = "FIFO"
+	CostingWeightedAverage = "WeightedAverage"
+)
+
+// Costing selects how stock leaving the shop is valued. FIFO charges the cost of the
+// oldest stock received; weighted average charges the running average cost of stock on
+// hand. Switching method applies to movements from then on.
+type Costing struct {
+	Method string `json:"method"`
+}
+
+// ApplyDefaults selects FIFO when no method is set.
+func (c *Costing) ApplyDefaults() {
+	if c.Method == "" {
+		c.Method = CostingFIFO
+	}
+}
+
+// Validate ensures the method is supported.
+func (c Costing) Validate() error {
+	switch c.Method {
+	case CostingFIFO, CostingWeightedAverage:
+		return nil
+	default:
+		return fmt.Errorf("unsupported costing method %q", c.Method)
+	}
+}
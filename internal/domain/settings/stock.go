@@ -0,0 +1,21 @@
+package settings
+
+import "shopmate/internal/domain/product"
+
+// StockPolicy holds shop-wide stock rules. NegativeStock applies to products without their
+// own negative-stock policy.
+type StockPolicy struct {
+	NegativeStock string `json:"negativeStock"`
+}
+
+// ApplyDefaults blocks sales beyond stock on hand when no policy is set.
+func (p *StockPolicy) ApplyDefaults() {
+	if p.NegativeStock == "" {
+		p.NegativeStock = product.NegativeStockBlock
+	}
+}
+
+// Validate ensures the policy is supported.
+func (p StockPolicy) Validate() error {
+	return product.ValidateNegativeStockPolicy(p.NegativeStock, false)
+}
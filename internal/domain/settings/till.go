@@ -0,0 +1,17 @@
+package settings
+
+import "errors"
+
+// Till holds configuration for the point-of-sale register on this machine.
+type Till struct {
+	// LocationID is the stock location sales decrement; zero uses the default location.
+	LocationID int64 `json:"locationId"`
+}
+
+// Validate ensures the till configuration is consistent.
+func (t Till) Validate() error {
+	if t.LocationID < 0 {
+		return errors.New("till location must be >= 0")
+	}
+	return nil
+}
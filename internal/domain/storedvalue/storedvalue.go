@@ -0,0 +1,162 @@
+// Package storedvalue models gift cards and store credit: prepaid balances identified by a
+// code and spent as a sale tender.
+package storedvalue
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/sale"
+)
+
+// Account kinds. Gift cards are sold to customers; store credit is issued on returns.
+const (
+	KindGiftCard    = "GiftCard"
+	KindStoreCredit = "StoreCredit"
+)
+
+// Account statuses. A voided account cannot be spent or topped up.
+const (
+	StatusActive = "Active"
+	StatusVoided = "Voided"
+)
+
+// Ledger entry kinds. Issue and TopUp load value, Redeem spends it on a sale, Refund puts
+// back value a refund or void returned, and Void forfeits what is left.
+const (
+	EntryIssue  = "Issue"
+	EntryTopUp  = "TopUp"
+	EntryRedeem = "Redeem"
+	EntryRefund = "Refund"
+	EntryVoid   = "Void"
+)
+
+// MaxLoadCents caps the value loaded onto an account at once.
+const MaxLoadCents = 1_000_000
+
+// codeAlphabet leaves out characters easily misread on a printed card.
+const codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// Account is a stored-value balance. CustomerID links it to a customer record when known.
+type Account struct {
+	ID           int64      `json:"id"`
+	Code         string     `json:"code"`
+	Kind         string     `json:"kind"`
+	CustomerID   int64      `json:"customerId"`
+	CustomerName string     `json:"customerName,omitempty"`
+	BalanceCents int64      `json:"balanceCents"`
+	Status       string     `json:"status"`
+	IssuedAt     time.Time  `json:"issuedAt"`
+	VoidedAt     *time.Time `json:"voidedAt,omitempty"`
+	Note         string     `json:"note"`
+}
+
+// Entry is one movement of an account's balance. AmountCents is positive when value is
+// loaded and negative when it is spent or forfeited.
+type Entry struct {
+	ID                int64     `json:"id"`
+	AccountID         int64     `json:"accountId"`
+	Timestamp         time.Time `json:"timestamp"`
+	Kind              string    `json:"kind"`
+	AmountCents       int64     `json:"amountCents"`
+	BalanceAfterCents int64     `json:"balanceAfterCents"`
+	PaymentMethod     string    `json:"paymentMethod,omitempty"`
+	SaleID            int64     `json:"saleId,omitempty"`
+	SaleNumber        string    `json:"saleNumber,omitempty"`
+	RefundID          int64     `json:"refundId,omitempty"`
+	Note              string    `json:"note,omitempty"`
+}
+
+// Issue is the data needed to open an account. An empty Code generates one; a code given
+// is that of a pre-printed card. PaymentMethod is how a gift card was paid for.
+type Issue struct {
+	Kind          string
+	Code          string
+	CustomerID    int64
+	AmountCents   int64
+	PaymentMethod string
+	Note          string
+}
+
+// Normalize tidies the code and kind.
+func (i *Issue) Normalize() {
+	i.Code = NormalizeCode(i.Code)
+	i.PaymentMethod = strings.TrimSpace(i.PaymentMethod)
+	i.Note = strings.TrimSpace(i.Note)
+	if i.Kind == "" {
+		i.Kind = KindGiftCard
+	}
+}
+
+// Validate ensures the account can be opened.
+func (i Issue) Validate() error {
+	if i.Kind != KindGiftCard && i.Kind != KindStoreCredit {
+		return fmt.Errorf("unknown account kind %q", i.Kind)
+	}
+	if err := ValidateAmount(i.AmountCents); err != nil {
+		return err
+	}
+	if i.Kind == KindGiftCard && i.PaymentMethod == "" {
+		return errors.New("payment method required to sell a gift card")
+	}
+	if i.Code != "" && (len(i.Code) < 6 || len(i.Code) > 32) {
+		return errors.New("code must be 6 to 32 characters")
+	}
+	return nil
+}
+
+// ValidateAmount checks a value loaded onto an account.
+func ValidateAmount(amountCents int64) error {
+	if amountCents <= 0 {
+		return errors.New("amount must be > 0")
+	}
+	if amountCents > MaxLoadCents {
+		return fmt.Errorf("amount must not exceed %d", MaxLoadCents)
+	}
+	return nil
+}
+
+// NormalizeCode trims and upper-cases a code so it matches however it was typed.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), ""))
+}
+
+// NewCode generates a random code such as GC-7KQ2-MX9P-4TRA for an account of kind.
+func NewCode(kind string) (string, error) {
+	prefix := "GC"
+	if kind == KindStoreCredit {
+		prefix = "SC"
+	}
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate code: %w", err)
+	}
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	for i, b := range raw {
+		if i%4 == 0 {
+			sb.WriteByte('-')
+		}
+		sb.WriteByte(codeAlphabet[int(b)%len(codeAlphabet)])
+	}
+	return sb.String(), nil
+}
+
+// MethodFor returns the payment method that spends an account of kind.
+func MethodFor(kind string) string {
+	if kind == KindStoreCredit {
+		return sale.PaymentStoreCredit
+	}
+	return sale.PaymentGiftCard
+}
+
+// KindForMethod returns the account kind a stored-value payment method spends.
+func KindForMethod(method string) string {
+	if strings.EqualFold(strings.TrimSpace(method), sale.PaymentStoreCredit) {
+		return KindStoreCredit
+	}
+	return KindGiftCard
+}
@@ -0,0 +1,53 @@
+package storedvalue
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 100 {
+		code, err := NewCode(KindStoreCredit)
+		if err != nil {
+			t.Fatalf("new code: %v", err)
+		}
+		if len(code) != len("SC-XXXX-XXXX-XXXX") || !strings.HasPrefix(code, "SC-") || strings.ContainsAny(code, "01IO") {
+			t.Fatalf("unexpected code %q", code)
+		}
+		if seen[code] {
+			t.Fatalf("code %q generated twice", code)
+		}
+		seen[code] = true
+	}
+	if code, _ := NewCode(KindGiftCard); !strings.HasPrefix(code, "GC-") {
+		t.Fatalf("expected a gift card code, got %q", code)
+	}
+	if got := NormalizeCode(" gc-7kq2 mx9p "); got != "GC-7KQ2MX9P" {
+		t.Fatalf("normalised code = %q", got)
+	}
+}
+
+func TestIssueValidate(t *testing.T) {
+	valid := Issue{AmountCents: 5000, PaymentMethod: "Card"}
+	valid.Normalize()
+	if err := valid.Validate(); err != nil || valid.Kind != KindGiftCard {
+		t.Fatalf("expected a valid gift card, got %v (%s)", err, valid.Kind)
+	}
+
+	invalid := []struct {
+		name  string
+		issue Issue
+	}{
+		{"unpaidGiftCard", Issue{Kind: KindGiftCard, AmountCents: 5000}},
+		{"zeroAmount", Issue{Kind: KindStoreCredit}},
+		{"tooLarge", Issue{Kind: KindStoreCredit, AmountCents: MaxLoadCents + 1}},
+		{"shortCode", Issue{Kind: KindStoreCredit, AmountCents: 100, Code: "AB1"}},
+		{"unknownKind", Issue{Kind: "Voucher", AmountCents: 100}},
+	}
+	for _, tc := range invalid {
+		if err := tc.issue.Validate(); err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+	}
+}
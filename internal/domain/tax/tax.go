@@ -0,0 +1,265 @@
+// Package tax models tax groups of one or more components, the shop's tax policy and how
+// sale lines are taxed under them.
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"shopmate/internal/domain/sale"
+)
+
+// Rounding modes. PerLine rounds each component's tax on every line; PerInvoice rounds
+// each component once over the whole sale and shares the result across the lines.
+const (
+	RoundPerLine    = "PerLine"
+	RoundPerInvoice = "PerInvoice"
+)
+
+// maxRateBasisPoints caps a component's rate at 100%.
+const maxRateBasisPoints = 10000
+
+// Policy is how the shop charges tax. With PricesIncludeTax, shelf prices already contain
+// the tax, which is worked out of each line rather than added to it.
+type Policy struct {
+	PricesIncludeTax bool   `json:"pricesIncludeTax"`
+	Rounding         string `json:"rounding"`
+}
+
+// ApplyDefaults adds tax to prices and rounds each line when nothing is set.
+func (p *Policy) ApplyDefaults() {
+	if p.Rounding == "" {
+		p.Rounding = RoundPerLine
+	}
+}
+
+// Validate ensures the rounding mode is supported.
+func (p Policy) Validate() error {
+	switch p.Rounding {
+	case RoundPerLine, RoundPerInvoice:
+		return nil
+	default:
+		return fmt.Errorf("unknown tax rounding %q", p.Rounding)
+	}
+}
+
+// Component is one tax charged by a group, such as a state or a city tax. A Compound
+// component is charged on the amount plus the components listed before it.
+type Component struct {
+	Name            string `json:"name"`
+	RateBasisPoints int64  `json:"rateBasisPoints"`
+	Compound        bool   `json:"compound"`
+}
+
+// Group is a named set of tax components assigned to products, charged in order.
+type Group struct {
+	ID         int64       `json:"id"`
+	Name       string      `json:"name"`
+	Components []Component `json:"components"`
+	// ProductCount is the number of products assigned to the group.
+	ProductCount int64     `json:"productCount"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Input holds the editable fields of a tax group.
+type Input struct {
+	Name       string
+	Components []Component
+}
+
+// Normalize trims the group and component names.
+func (in *Input) Normalize() {
+	in.Name = strings.TrimSpace(in.Name)
+	for i := range in.Components {
+		in.Components[i].Name = strings.TrimSpace(in.Components[i].Name)
+	}
+}
+
+// Validate requires a name and at least one component, each named once with a rate from
+// 0 to 100%. The first component has nothing to compound on.
+func (in Input) Validate() error {
+	if in.Name == "" {
+		return errors.New("tax group name is required")
+	}
+	if len(in.Components) == 0 {
+		return errors.New("a tax group needs at least one component")
+	}
+	seen := make(map[string]bool, len(in.Components))
+	for i, c := range in.Components {
+		if c.Name == "" {
+			return fmt.Errorf("component %d: name is required", i+1)
+		}
+		if seen[strings.ToLower(c.Name)] {
+			return fmt.Errorf("component %s is listed twice", c.Name)
+		}
+		seen[strings.ToLower(c.Name)] = true
+		if c.RateBasisPoints < 0 || c.RateBasisPoints > maxRateBasisPoints {
+			return fmt.Errorf("component %s: rate must be between 0 and 100%%", c.Name)
+		}
+		if c.Compound && i == 0 {
+			return fmt.Errorf("component %s: the first component cannot be compound", c.Name)
+		}
+	}
+	return nil
+}
+
+// Single is the component a product without a tax group is charged at its own rate.
+func Single(rateBasisPoints int64) []Component {
+	if rateBasisPoints <= 0 {
+		return nil
+	}
+	return []Component{{Name: "Tax", RateBasisPoints: rateBasisPoints}}
+}
+
+// fraction returns the components' tax as a fraction of the amount before tax.
+func fraction(components []Component) float64 {
+	var total float64
+	for _, c := range components {
+		base := 1.0
+		if c.Compound {
+			base += total
+		}
+		total += base * float64(c.RateBasisPoints) / 10000
+	}
+	return total
+}
+
+// EffectiveRate is the combined rate of components in basis points of the amount before
+// tax, compounding included.
+func EffectiveRate(components []Component) int64 {
+	total := fraction(components)
+	return int64(math.Round(total * 10000))
+}
+
+// Exclude returns an amount with the tax the components would include in it taken out.
+func Exclude(amountCents int64, components []Component) int64 {
+	total := fraction(components)
+	return int64(math.Round(float64(amountCents) / (1 + total)))
+}
+
+// Line is a sale line offered for tax. AmountCents is what the line sells for after its
+// discounts, tax included when the policy's prices include it.
+type Line struct {
+	AmountCents int64
+	Components  []Component
+}
+
+// LineTax is the tax on a line and the line's amount before tax.
+type LineTax struct {
+	NetCents int64
+	TaxCents int64
+}
+
+type breakdownKey struct {
+	name     string
+	rate     int64
+	compound bool
+}
+
+type breakdownRow struct {
+	line    sale.TaxLine
+	taxable float64
+	lines   []int
+	exact   []float64
+}
+
+// Compute works out the tax on lines under the policy and the sale's tax breakdown, one
+// row per component name and rate. Lines taxed per invoice share each row's rounded tax
+// by largest remainder, so the lines always add up to the breakdown.
+func (p Policy) Compute(lines []Line) ([]LineTax, []sale.TaxLine) {
+	taxes := make([]LineTax, len(lines))
+	var (
+		order []breakdownKey
+		rows  = make(map[breakdownKey]*breakdownRow)
+	)
+	for i, line := range lines {
+		if line.AmountCents <= 0 || len(line.Components) == 0 {
+			continue
+		}
+		net := float64(line.AmountCents)
+		if p.PricesIncludeTax {
+			total := fraction(line.Components)
+			net /= 1 + total
+		}
+		var prior float64
+		for _, c := range line.Components {
+			taxable := net
+			if c.Compound {
+				taxable += prior
+			}
+			amount := taxable * float64(c.RateBasisPoints) / 10000
+			prior += amount
+
+			key := breakdownKey{name: c.Name, rate: c.RateBasisPoints, compound: c.Compound}
+			row, ok := rows[key]
+			if !ok {
+				row = &breakdownRow{line: sale.TaxLine{Name: c.Name, RateBasisPoints: c.RateBasisPoints, Compound: c.Compound}}
+				rows[key] = row
+				order = append(order, key)
+			}
+			row.taxable += taxable
+			row.lines = append(row.lines, i)
+			row.exact = append(row.exact, amount)
+		}
+	}
+
+	breakdown := make([]sale.TaxLine, 0, len(order))
+	for _, key := range order {
+		row := rows[key]
+		var amounts []int64
+		if p.Rounding == RoundPerInvoice {
+			var sum float64
+			for _, exact := range row.exact {
+				sum += exact
+			}
+			amounts = share(int64(math.Round(sum)), row.exact)
+		} else {
+			amounts = make([]int64, len(row.exact))
+			for k, exact := range row.exact {
+				amounts[k] = int64(math.Round(exact))
+			}
+		}
+		for k, amount := range amounts {
+			taxes[row.lines[k]].TaxCents += amount
+			row.line.TaxCents += amount
+		}
+		row.line.TaxableCents = int64(math.Round(row.taxable))
+		breakdown = append(breakdown, row.line)
+	}
+
+	for i, line := range lines {
+		taxes[i].NetCents = line.AmountCents
+		if p.PricesIncludeTax {
+			taxes[i].NetCents -= taxes[i].TaxCents
+		}
+	}
+	return taxes, breakdown
+}
+
+// share splits total across exact amounts: each takes its whole cents and the cents left
+// go to the largest fractions, earliest first.
+func share(total int64, exact []float64) []int64 {
+	amounts := make([]int64, len(exact))
+	fractions := make([]float64, len(exact))
+	var given int64
+	for i, e := range exact {
+		amounts[i] = int64(math.Floor(e))
+		fractions[i] = e - math.Floor(e)
+		given += amounts[i]
+	}
+	for left := total - given; left > 0; left-- {
+		best := 0
+		for i, f := range fractions {
+			if f > fractions[best] {
+				best = i
+			}
+		}
+		amounts[best]++
+		fractions[best] = -1
+	}
+	return amounts
+}
@@ -0,0 +1,113 @@
+package tax
+
+import (
+	"slices"
+	"testing"
+)
+
+func lineTaxes(taxes []LineTax) []int64 {
+	out := make([]int64, len(taxes))
+	for i, t := range taxes {
+		out[i] = t.TaxCents
+	}
+	return out
+}
+
+func TestComputeExclusive(t *testing.T) {
+	stateAndCity := []Component{{Name: "State", RateBasisPoints: 600}, {Name: "City", RateBasisPoints: 225}}
+	policy := Policy{Rounding: RoundPerLine}
+
+	taxes, breakdown := policy.Compute([]Line{
+		{AmountCents: 1000, Components: stateAndCity},
+		{AmountCents: 333, Components: stateAndCity},
+		{AmountCents: 500, Components: Single(1000)},
+		{AmountCents: 700},
+	})
+	if got := lineTaxes(taxes); !slices.Equal(got, []int64{83, 27, 50, 0}) {
+		t.Fatalf("line taxes %v", got)
+	}
+	if taxes[0].NetCents != 1000 || taxes[3].NetCents != 700 {
+		t.Fatalf("expected net amounts unchanged, got %+v", taxes)
+	}
+	if len(breakdown) != 3 {
+		t.Fatalf("expected three breakdown rows, got %+v", breakdown)
+	}
+	state, city, single := breakdown[0], breakdown[1], breakdown[2]
+	if state.Name != "State" || state.TaxableCents != 1333 || state.TaxCents != 80 {
+		t.Fatalf("unexpected state row %+v", state)
+	}
+	if city.TaxableCents != 1333 || city.TaxCents != 30 || single.TaxCents != 50 {
+		t.Fatalf("unexpected rows %+v %+v", city, single)
+	}
+
+	// Rounded once per component, the invoice's tax differs from the sum of rounded lines.
+	perInvoice := Policy{Rounding: RoundPerInvoice}
+	lines := []Line{{AmountCents: 5, Components: Single(1000)}, {AmountCents: 5, Components: Single(1000)}, {AmountCents: 5, Components: Single(1000)}}
+	perLine, _ := policy.Compute(lines)
+	if got := lineTaxes(perLine); !slices.Equal(got, []int64{1, 1, 1}) {
+		t.Fatalf("per line %v", got)
+	}
+	taxes, breakdown = perInvoice.Compute(lines)
+	if got := lineTaxes(taxes); !slices.Equal(got, []int64{1, 1, 0}) || breakdown[0].TaxCents != 2 {
+		t.Fatalf("per invoice %v, breakdown %+v", got, breakdown)
+	}
+}
+
+func TestComputeCompound(t *testing.T) {
+	components := []Component{{Name: "GST", RateBasisPoints: 500}, {Name: "QST", RateBasisPoints: 1000, Compound: true}}
+	taxes, breakdown := Policy{Rounding: RoundPerLine}.Compute([]Line{{AmountCents: 10000, Components: components}})
+	// QST is charged on the price plus GST: 10% of 10500.
+	if taxes[0].TaxCents != 1550 || breakdown[1].TaxableCents != 10500 || breakdown[1].TaxCents != 1050 || !breakdown[1].Compound {
+		t.Fatalf("unexpected compound tax %+v %+v", taxes, breakdown)
+	}
+	if EffectiveRate(components) != 1550 {
+		t.Fatalf("expected an effective rate of 15.5%%, got %d", EffectiveRate(components))
+	}
+}
+
+func TestComputeInclusive(t *testing.T) {
+	policy := Policy{PricesIncludeTax: true, Rounding: RoundPerLine}
+	taxes, breakdown := policy.Compute([]Line{
+		{AmountCents: 1100, Components: Single(1000)},
+		{AmountCents: 1155, Components: []Component{{Name: "GST", RateBasisPoints: 500}, {Name: "QST", RateBasisPoints: 1000, Compound: true}}},
+	})
+	if taxes[0].TaxCents != 100 || taxes[0].NetCents != 1000 {
+		t.Fatalf("expected 100 of tax inside 1100, got %+v", taxes[0])
+	}
+	if taxes[1].TaxCents != 155 || taxes[1].NetCents != 1000 {
+		t.Fatalf("expected 155 of compound tax inside 1155, got %+v", taxes[1])
+	}
+	if breakdown[0].TaxableCents != 1000 || breakdown[2].TaxableCents != 1050 {
+		t.Fatalf("unexpected breakdown %+v", breakdown)
+	}
+	if Exclude(1100, Single(1000)) != 1000 {
+		t.Fatalf("expected 1100 to exclude to 1000, got %d", Exclude(1100, Single(1000)))
+	}
+}
+
+func TestInputValidate(t *testing.T) {
+	valid := Input{Name: " Quebec ", Components: []Component{{Name: "GST", RateBasisPoints: 500}, {Name: "QST", RateBasisPoints: 998, Compound: true}}}
+	valid.Normalize()
+	if err := valid.Validate(); err != nil || valid.Name != "Quebec" {
+		t.Fatalf("expected a valid group, got %v (%q)", err, valid.Name)
+	}
+
+	invalid := map[string]Input{
+		"noName":        {Components: []Component{{Name: "VAT", RateBasisPoints: 2000}}},
+		"noComponents":  {Name: "Empty"},
+		"duplicate":     {Name: "x", Components: []Component{{Name: "VAT", RateBasisPoints: 100}, {Name: "vat", RateBasisPoints: 100}}},
+		"overHundred":   {Name: "x", Components: []Component{{Name: "VAT", RateBasisPoints: 10001}}},
+		"compoundFirst": {Name: "x", Components: []Component{{Name: "VAT", RateBasisPoints: 100, Compound: true}}},
+		"unnamedPart":   {Name: "x", Components: []Component{{RateBasisPoints: 100}}},
+	}
+	for name, in := range invalid {
+		in.Normalize()
+		if err := in.Validate(); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+
+	if err := (Policy{Rounding: "Sometimes"}).Validate(); err == nil {
+		t.Fatal("expected an unknown rounding to be rejected")
+	}
+}
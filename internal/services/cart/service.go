@@ -0,0 +1,188 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/cart"
+)
+
+// expirySchedulerInterval is how often held carts are checked for expiry.
+const expirySchedulerInterval = time.Minute
+
+// Service parks carts at the till and resumes them from any POS window.
+type Service struct {
+	repo     *sqlite.CartRepository
+	settings *sqlite.SettingsRepository
+	now      func() time.Time
+
+	mu              sync.Mutex
+	schedulerCancel context.CancelFunc
+}
+
+// NewService constructs a held cart service.
+func NewService(repo *sqlite.CartRepository, settings *sqlite.SettingsRepository) *Service {
+	return &Service{repo: repo, settings: settings, now: time.Now}
+}
+
+// ParkRequestLine is a product in the cart being parked.
+type ParkRequestLine struct {
+	ProductID     int64
+	Quantity      int64
+	DiscountCents int64
+	SerialNumbers []string
+}
+
+// ParkRequest is the cart to park. A zero LocationID holds it at the till's location.
+// ReserveStock keeps its products from other sales while it is held.
+type ParkRequest struct {
+	CustomerName  string
+	DiscountCents int64
+	Note          string
+	ParkedBy      string
+	LocationID    int64
+	ReserveStock  bool
+	Lines         []ParkRequestLine
+}
+
+// Park holds a cart until the policy's expiry.
+func (s *Service) Park(ctx context.Context, req ParkRequest) (*domain.Held, error) {
+	draft := domain.Draft{
+		CustomerName:  req.CustomerName,
+		DiscountCents: req.DiscountCents,
+		Note:          req.Note,
+		ParkedBy:      req.ParkedBy,
+		LocationID:    req.LocationID,
+		ReserveStock:  req.ReserveStock,
+	}
+	for _, line := range req.Lines {
+		draft.Lines = append(draft.Lines, domain.Line{
+			ProductID:     line.ProductID,
+			Quantity:      line.Quantity,
+			DiscountCents: line.DiscountCents,
+			SerialNumbers: line.SerialNumbers,
+		})
+	}
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+
+	if draft.LocationID == 0 {
+		till, err := s.settings.LoadTill(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load till: %w", err)
+		}
+		draft.LocationID = till.LocationID
+	}
+	policy, err := s.settings.LoadCartPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load cart policy: %w", err)
+	}
+
+	now := s.now()
+	held, err := s.repo.Park(ctx, draft, now, policy.Expiry(now))
+	if err != nil {
+		return nil, fmt.Errorf("park cart: %w", err)
+	}
+	return held, nil
+}
+
+// List returns carts still held, most recently parked first.
+func (s *Service) List(ctx context.Context) ([]domain.Held, error) {
+	if _, err := s.ExpireStale(ctx); err != nil {
+		return nil, err
+	}
+	return s.repo.Held(ctx)
+}
+
+// Get retrieves a held cart by id.
+func (s *Service) Get(ctx context.Context, id int64) (*domain.Held, error) {
+	if id <= 0 {
+		return nil, errors.New("cart id required")
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// Resume takes a held cart back to the till for checkout and releases its reserved stock.
+func (s *Service) Resume(ctx context.Context, id int64) (*domain.Held, error) {
+	if id <= 0 {
+		return nil, errors.New("cart id required")
+	}
+	return s.repo.Resume(ctx, id, s.now())
+}
+
+// Discard abandons a held cart and releases its reserved stock.
+func (s *Service) Discard(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("cart id required")
+	}
+	return s.repo.Discard(ctx, id, s.now())
+}
+
+// ExpireStale expires carts held past their expiry.
+func (s *Service) ExpireStale(ctx context.Context) (int, error) {
+	expired, err := s.repo.ExpireDue(ctx, s.now())
+	if err != nil {
+		return 0, fmt.Errorf("expire held carts: %w", err)
+	}
+	return expired, nil
+}
+
+// Policy returns how long carts are held.
+func (s *Service) Policy(ctx context.Context) (domain.Policy, error) {
+	return s.settings.LoadCartPolicy(ctx)
+}
+
+// SavePolicy stores how long new carts are held.
+func (s *Service) SavePolicy(ctx context.Context, policy domain.Policy) (domain.Policy, error) {
+	if err := s.settings.SaveCartPolicy(ctx, policy); err != nil {
+		return domain.Policy{}, fmt.Errorf("save cart policy: %w", err)
+	}
+	return s.settings.LoadCartPolicy(ctx)
+}
+
+// StartExpiryScheduler expires stale carts now and keeps expiring them as they fall due.
+func (s *Service) StartExpiryScheduler(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.schedulerCancel != nil {
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.schedulerCancel = cancel
+
+	go s.expirySchedulerLoop(runCtx)
+}
+
+// StopExpiryScheduler stops the background expiry scheduler if running.
+func (s *Service) StopExpiryScheduler() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.schedulerCancel != nil {
+		s.schedulerCancel()
+		s.schedulerCancel = nil
+	}
+}
+
+func (s *Service) expirySchedulerLoop(ctx context.Context) {
+	_, _ = s.ExpireStale(context.Background())
+
+	ticker := time.NewTicker(expirySchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = s.ExpireStale(context.Background())
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package cart_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/cart"
+	productdomain "shopmate/internal/domain/product"
+	cartservice "shopmate/internal/services/cart"
+	saleservice "shopmate/internal/services/sale"
+)
+
+func TestParkedCartReservesStockUntilResumed(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "carts.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	cartRepo := sqlite.NewCartRepository(store.DB())
+	service := cartservice.NewService(cartRepo, settingsRepo)
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), settingsRepo)
+
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, CurrentQty: 3})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	held, err := service.Park(ctx, cartservice.ParkRequest{
+		CustomerName: "Alice",
+		ParkedBy:     "Till 1",
+		Note:         "Back with wallet",
+		ReserveStock: true,
+		Lines:        []cartservice.ParkRequestLine{{ProductID: mug.ID, Quantity: 2, DiscountCents: 100}},
+	})
+	if err != nil {
+		t.Fatalf("park: %v", err)
+	}
+	if held.Status != domain.StatusHeld || held.Lines[0].SKU != "MUG" || !held.ExpiresAt.After(held.ParkedAt) {
+		t.Fatalf("unexpected held cart %+v", held)
+	}
+
+	sell := func(qty int64) error {
+		_, err := sales.Create(ctx, saleservice.CreateRequest{PaymentMethod: "Cash", Lines: []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: qty}}})
+		return err
+	}
+	var shortage *productdomain.StockShortageError
+	if err := sell(2); !errors.As(err, &shortage) || shortage.OnHand != 1 {
+		t.Fatalf("expected reserved mugs to be unavailable, got %v", err)
+	}
+	if err := sell(1); err != nil {
+		t.Fatalf("sell unreserved mug: %v", err)
+	}
+	if _, err := service.Park(ctx, cartservice.ParkRequest{
+		ParkedBy:     "Till 2",
+		ReserveStock: true,
+		Lines:        []cartservice.ParkRequestLine{{ProductID: mug.ID, Quantity: 1}},
+	}); !errors.As(err, &shortage) {
+		t.Fatalf("expected reservation beyond available stock to fail, got %v", err)
+	}
+
+	carts, err := service.List(ctx)
+	if err != nil || len(carts) != 1 {
+		t.Fatalf("expected one held cart, got %d (%v)", len(carts), err)
+	}
+
+	resumed, err := service.Resume(ctx, held.ID)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if resumed.Status != domain.StatusResumed || resumed.CustomerName != "Alice" || resumed.Lines[0].DiscountCents != 100 {
+		t.Fatalf("unexpected resumed cart %+v", resumed)
+	}
+	if _, err := service.Resume(ctx, held.ID); err == nil {
+		t.Fatalf("expected a cart to be resumed only once")
+	}
+	if err := sell(2); err != nil {
+		t.Fatalf("expected released stock to sell: %v", err)
+	}
+
+	stale, err := service.Park(ctx, cartservice.ParkRequest{
+		ParkedBy: "Till 1",
+		Lines:    []cartservice.ParkRequestLine{{ProductID: mug.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("park without reservation: %v", err)
+	}
+	expired, err := cartRepo.ExpireDue(ctx, stale.ExpiresAt)
+	if err != nil || expired != 1 {
+		t.Fatalf("expected one cart expired, got %d (%v)", expired, err)
+	}
+	if carts, _ := service.List(ctx); len(carts) != 0 {
+		t.Fatalf("expected no held carts after expiry, got %d", len(carts))
+	}
+	if _, err := cartRepo.Resume(ctx, stale.ID, time.Now()); err == nil {
+		t.Fatalf("expected an expired cart not to resume")
+	}
+}
@@ -0,0 +1,79 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/category"
+)
+
+// Service manages the product category hierarchy.
+type Service struct {
+	repo *sqlite.CategoryRepository
+}
+
+// NewService constructs a category service.
+func NewService(repo *sqlite.CategoryRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// List returns every category ordered by path.
+func (s *Service) List(ctx context.Context) ([]domain.Category, error) {
+	categories, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list categories: %w", err)
+	}
+	return categories, nil
+}
+
+// Create adds a category.
+func (s *Service) Create(ctx context.Context, input domain.Input) (*domain.Category, error) {
+	if err := input.Validate(); err != nil {
+		return nil, fmt.Errorf("validate category: %w", err)
+	}
+	c, err := s.repo.Create(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("create category: %w", err)
+	}
+	return c, nil
+}
+
+// Update renames, moves or changes the defaults of a category. Product category paths follow.
+func (s *Service) Update(ctx context.Context, id int64, input domain.Input) (*domain.Category, error) {
+	if id <= 0 {
+		return nil, errors.New("category id required")
+	}
+	if err := input.Validate(); err != nil {
+		return nil, fmt.Errorf("validate category: %w", err)
+	}
+	c, err := s.repo.Update(ctx, id, input)
+	if err != nil {
+		return nil, fmt.Errorf("update category: %w", err)
+	}
+	return c, nil
+}
+
+// Merge folds source into target, moving its products and subcategories.
+func (s *Service) Merge(ctx context.Context, sourceID, targetID int64) (*domain.Category, error) {
+	if sourceID <= 0 || targetID <= 0 {
+		return nil, errors.New("source and target category ids required")
+	}
+	c, err := s.repo.Merge(ctx, sourceID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("merge category: %w", err)
+	}
+	return c, nil
+}
+
+// Delete removes an empty category.
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("category id required")
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("delete category: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package category_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domaincategory "shopmate/internal/domain/category"
+	productdomain "shopmate/internal/domain/product"
+	categoryservice "shopmate/internal/services/category"
+	reportservice "shopmate/internal/services/report"
+	saleservice "shopmate/internal/services/sale"
+	"shopmate/migrations"
+)
+
+func TestCategoryPathsCascadeAndRollUp(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "categories.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	service := categoryservice.NewService(sqlite.NewCategoryRepository(store.DB()))
+
+	taxRate := int64(500)
+	food, err := service.Create(ctx, domaincategory.Input{Name: "Food", DefaultTaxRateBasisPoints: &taxRate})
+	if err != nil {
+		t.Fatalf("create category: %v", err)
+	}
+
+	milk, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Milk", SKU: "MILK", Category: "food >  dairy", UnitPriceCents: 200, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create milk: %v", err)
+	}
+	if milk.Category != "Food > dairy" || milk.TaxRateBasisPoints != taxRate || milk.CategoryID == 0 {
+		t.Fatalf("expected path mapped onto tree with inherited tax, got %+v", milk)
+	}
+
+	cheese, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Cheese", SKU: "CHEESE", Category: "Dairy Products", UnitPriceCents: 500, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create cheese: %v", err)
+	}
+
+	if _, err := service.Update(ctx, milk.CategoryID, domaincategory.Input{Name: "Dairy", ParentID: food.ID}); err != nil {
+		t.Fatalf("rename category: %v", err)
+	}
+	if _, err := service.Update(ctx, food.ID, domaincategory.Input{Name: "Food", ParentID: milk.CategoryID}); err == nil {
+		t.Fatalf("expected moving a category beneath its descendant to fail")
+	}
+
+	// Move the stray top-level category under Food, then fold it into Dairy.
+	if _, err := service.Update(ctx, cheese.CategoryID, domaincategory.Input{Name: "Dairy Products", ParentID: food.ID}); err != nil {
+		t.Fatalf("move category: %v", err)
+	}
+	if _, err := service.Merge(ctx, cheese.CategoryID, milk.CategoryID); err != nil {
+		t.Fatalf("merge category: %v", err)
+	}
+
+	cheese, err = productRepo.GetByID(ctx, cheese.ID)
+	if err != nil {
+		t.Fatalf("reload cheese: %v", err)
+	}
+	if cheese.Category != "Food > Dairy" || cheese.CategoryID != milk.CategoryID {
+		t.Fatalf("expected merged category path, got %+v", cheese)
+	}
+
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+	if _, err := sales.Create(ctx, saleservice.CreateRequest{
+		PaymentMethod: "Cash",
+		Lines: []saleservice.CreateRequestLine{
+			{ProductID: milk.ID, Quantity: 2},
+			{ProductID: cheese.ID, Quantity: 1},
+		},
+	}); err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+
+	reports := reportservice.NewService(sqlite.NewReportRepository(store.DB()))
+	now := time.Now()
+	rollup, err := reports.CategorySales(ctx, now.Add(-time.Hour), now.Add(time.Hour), 0)
+	if err != nil {
+		t.Fatalf("category sales: %v", err)
+	}
+	if len(rollup) != 1 || rollup[0].CategoryID != food.ID || rollup[0].QuantitySold != 3 {
+		t.Fatalf("expected sales rolled up to Food, got %+v", rollup)
+	}
+
+	if err := service.Delete(ctx, food.ID); err == nil {
+		t.Fatalf("expected deleting a non-empty category to fail")
+	}
+}
+
+func TestLegacyCategoryPathsBackfillAsTree(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "legacy.sqlite")
+
+	// Bring a database up to the schema before categories existed, with free-text categories.
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	exec := func(query string, args ...interface{}) {
+		t.Helper()
+		if _, err := db.ExecContext(ctx, query, args...); err != nil {
+			t.Fatalf("exec %.40q: %v", query, err)
+		}
+	}
+	exec(`CREATE TABLE schema_migrations (
+		name TEXT PRIMARY KEY,
+		applied_at INTEGER NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000)
+	)`)
+	entries, err := migrations.Files.ReadDir(".")
+	if err != nil {
+		t.Fatalf("read migrations: %v", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if name >= "0007" {
+			break
+		}
+		content, err := migrations.Files.ReadFile(name)
+		if err != nil {
+			t.Fatalf("read migration %s: %v", name, err)
+		}
+		exec(string(content))
+		exec(`INSERT INTO schema_migrations (name) VALUES (?)`, name)
+	}
+	for i, category := range []string{"Food > Dairy", "food>dairy ", "Food", " > Food >  > Bakery", "Garden", "", " > ", "Food > Dairy  Products", "food >dairy\t products  "} {
+		exec(`INSERT INTO products (sku, name, category) VALUES (?, ?, ?)`, "P"+string(rune('A'+i)), "Product", category)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("close sqlite: %v", err)
+	}
+
+	store, err := sqlite.Open(ctx, path)
+	if err != nil {
+		t.Fatalf("migrate sqlite: %v", err)
+	}
+	defer store.Close()
+
+	categories, err := categoryservice.NewService(sqlite.NewCategoryRepository(store.DB())).List(ctx)
+	if err != nil {
+		t.Fatalf("list categories: %v", err)
+	}
+	ids := make(map[string]int64, len(categories))
+	paths := make([]string, 0, len(categories))
+	for _, c := range categories {
+		if strings.Contains(c.Name, ">") {
+			t.Fatalf("expected no category named with the separator, got %+v", c)
+		}
+		ids[c.Path] = c.ID
+		paths = append(paths, c.Path)
+	}
+	if got := strings.Join(paths, "|"); got != "Food|Food > Bakery|Food > Dairy|Food > Dairy Products|Garden" {
+		t.Fatalf("unexpected category tree %s", got)
+	}
+
+	products, err := sqlite.NewProductRepository(store.DB()).List(ctx, true)
+	if err != nil || len(products) != 9 {
+		t.Fatalf("list products: %d (%v)", len(products), err)
+	}
+	want := map[string]string{"PA": "Food > Dairy", "PB": "Food > Dairy", "PC": "Food", "PD": "Food > Bakery", "PE": "Garden", "PF": "", "PG": "",
+		"PH": "Food > Dairy Products", "PI": "Food > Dairy Products"}
+	for _, p := range products {
+		if p.Category != want[p.SKU] || p.CategoryID != ids[want[p.SKU]] {
+			t.Fatalf("product %s in %q (%d), want %q (%d)", p.SKU, p.Category, p.CategoryID, want[p.SKU], ids[want[p.SKU]])
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package customer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/customer"
+)
+
+const (
+	// searchLimit caps the customers returned by a lookup.
+	searchLimit = 50
+	// purchaseHistoryLimit caps the purchases shown on a customer's detail.
+	purchaseHistoryLimit = 100
+)
+
+// Service manages customer records, their purchase history and CSV exchange.
+type Service struct {
+	repo *sqlite.CustomerRepository
+	now  func() time.Time
+}
+
+// NewService constructs a customer service.
+func NewService(repo *sqlite.CustomerRepository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Create adds a customer, as when a new customer is met at the till.
+func (s *Service) Create(ctx context.Context, input domain.Input) (*domain.Customer, error) {
+	created, err := s.repo.Create(ctx, input, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("create customer: %w", err)
+	}
+	return created, nil
+}
+
+// Update replaces a customer's details.
+func (s *Service) Update(ctx context.Context, id int64, input domain.Input) (*domain.Customer, error) {
+	if id <= 0 {
+		return nil, errors.New("customer id required")
+	}
+	updated, err := s.repo.Update(ctx, id, input, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("update customer: %w", err)
+	}
+	return updated, nil
+}
+
+// Get retrieves a customer by id.
+func (s *Service) Get(ctx context.Context, id int64) (*domain.Customer, error) {
+	if id <= 0 {
+		return nil, errors.New("customer id required")
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// Search looks customers up by part of their name, phone number or email.
+func (s *Service) Search(ctx context.Context, query string) ([]domain.Customer, error) {
+	return s.repo.Search(ctx, query, searchLimit)
+}
+
+// Detail returns a customer with their lifetime spend and recent purchases.
+func (s *Service) Detail(ctx context.Context, id int64) (*domain.Detail, error) {
+	if id <= 0 {
+		return nil, errors.New("customer id required")
+	}
+	return s.repo.Detail(ctx, id, purchaseHistoryLimit)
+}
+
+// ImportSummary reports the result of a customer import.
+type ImportSummary struct {
+	Created int      `json:"created"`
+	Updated int      `json:"updated"`
+	Errors  []string `json:"errors"`
+}
+
+// ImportCSV creates or updates customers from CSV data. Nothing is imported when any row
+// is invalid.
+func (s *Service) ImportCSV(ctx context.Context, data []byte) (ImportSummary, error) {
+	summary := ImportSummary{}
+	rows, err := domain.ParseCSV(bytes.NewReader(data))
+	if err != nil {
+		summary.Errors = append(summary.Errors, err.Error())
+		return summary, err
+	}
+	inputs := make([]domain.Input, 0, len(rows))
+	for _, row := range rows {
+		inputs = append(inputs, row.Input)
+	}
+	if summary.Created, summary.Updated, err = s.repo.Import(ctx, inputs, s.now()); err != nil {
+		summary.Errors = append(summary.Errors, err.Error())
+		return summary, fmt.Errorf("import customers: %w", err)
+	}
+	return summary, nil
+}
+
+// ExportCSV renders every customer to CSV following the import contract.
+func (s *Service) ExportCSV(ctx context.Context) ([]byte, error) {
+	customers, err := s.repo.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list customers: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := domain.WriteCSV(&buf, customers); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,114 @@
+package customer_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/customer"
+	productdomain "shopmate/internal/domain/product"
+	domainsale "shopmate/internal/domain/sale"
+	customerservice "shopmate/internal/services/customer"
+	saleservice "shopmate/internal/services/sale"
+)
+
+func TestCustomerHistoryFollowsLinkedSales(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "customers.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), settingsRepo)
+	service := customerservice.NewService(sqlite.NewCustomerRepository(store.DB()))
+
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	john, err := service.Create(ctx, domain.Input{Name: " John Smith ", Phone: "555-0100", Email: "John@Example.com"})
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	if john.Name != "John Smith" || john.Email != "john@example.com" {
+		t.Fatalf("expected details normalised, got %+v", john)
+	}
+
+	sell := func(customerID int64, name string, qty int64) *domainsale.Sale {
+		t.Helper()
+		created, err := sales.Create(ctx, saleservice.CreateRequest{
+			CustomerID:    customerID,
+			CustomerName:  name,
+			PaymentMethod: "Cash",
+			Lines:         []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: qty}},
+		})
+		if err != nil {
+			t.Fatalf("create sale: %v", err)
+		}
+		return created
+	}
+	first := sell(john.ID, "", 2)
+	if first.CustomerName != "John Smith" || first.CustomerID != john.ID {
+		t.Fatalf("expected the sale under the customer's name, got %q (%d)", first.CustomerName, first.CustomerID)
+	}
+	second := sell(john.ID, "J. Smith", 1)
+	voided := sell(john.ID, "", 3)
+	sell(0, "john", 1)
+
+	if _, err := sales.CreateRefund(ctx, saleservice.RefundRequest{
+		SaleID: first.ID,
+		Lines:  []saleservice.RefundRequestLine{{SaleLineID: first.Lines[0].ID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if err := sales.Void(ctx, voided.ID, "mistake"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+
+	detail, err := service.Detail(ctx, john.ID)
+	if err != nil {
+		t.Fatalf("detail: %v", err)
+	}
+	if detail.SaleCount != 2 || detail.LifetimeSpendCents != 1000+1000 || detail.LastPurchaseAt == nil {
+		t.Fatalf("expected two sales worth 20.00 net, got %d worth %d", detail.SaleCount, detail.LifetimeSpendCents)
+	}
+	if len(detail.Purchases) != 3 || detail.Purchases[0].SaleID != voided.ID || detail.Purchases[2].RefundedCents != 1000 {
+		t.Fatalf("unexpected purchase history %+v", detail.Purchases)
+	}
+
+	linked, err := sales.List(ctx, domainsale.Filter{CustomerID: john.ID})
+	if err != nil || len(linked) != 3 || linked[1].ID != second.ID {
+		t.Fatalf("expected john's three sales, got %d (%v)", len(linked), err)
+	}
+
+	if found, err := service.Search(ctx, "0100"); err != nil || len(found) != 1 || found[0].ID != john.ID {
+		t.Fatalf("expected lookup by phone, got %+v (%v)", found, err)
+	}
+
+	summary, err := service.ImportCSV(ctx, []byte("name,email,phone,address\nJohn Smith,john@example.com,555-0199,1 High St\nAcme Ltd,,555-0200,\n"))
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if summary.Created != 1 || summary.Updated != 1 {
+		t.Fatalf("expected one created and one updated, got %+v", summary)
+	}
+	if updated, _ := service.Get(ctx, john.ID); updated.Address != "1 High St" || updated.Phone != "555-0199" {
+		t.Fatalf("expected import to update john by email, got %+v", updated)
+	}
+	if _, err := service.ImportCSV(ctx, []byte("name,email\nBob,not-an-email\n")); err == nil {
+		t.Fatal("expected invalid rows to reject the import")
+	}
+
+	exported, err := service.ExportCSV(ctx)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(exported)), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[1], "Acme Ltd") {
+		t.Fatalf("unexpected export %q", exported)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"shopmate/internal/adapters/storage/sqlite"
+	domainquote "shopmate/internal/domain/quote"
 	domainsale "shopmate/internal/domain/sale"
 	domainsettings "shopmate/internal/domain/settings"
 )
@@ -17,35 +18,104 @@ import (
 //go:embed templates/*.html
 var templateFS embed.FS
 
-// Service generates invoice representations for sales.
+// Service generates invoice representations for sales and quotes.
 type Service struct {
 	salesRepo    *sqlite.SaleRepository
+	quotesRepo   *sqlite.QuoteRepository
 	settingsRepo *sqlite.SettingsRepository
 	baseTemplate *template.Template
 }
 
 // NewService constructs an invoice service.
-func NewService(sales *sqlite.SaleRepository, settings *sqlite.SettingsRepository) (*Service, error) {
+func NewService(sales *sqlite.SaleRepository, quotes *sqlite.QuoteRepository, settings *sqlite.SettingsRepository) (*Service, error) {
 	tpl, err := template.New("invoice.html").Funcs(template.FuncMap{
 		"currency": func(int64) string { return "" },
-	}).ParseFS(templateFS, "templates/invoice.html")
+		"neg":      func(cents int64) int64 { return -cents },
+		"masked":   maskedReference,
+		"percent":  formatPercent,
+	}).ParseFS(templateFS, "templates/*.html")
 	if err != nil {
-		return nil, fmt.Errorf("parse invoice template: %w", err)
+		return nil, fmt.Errorf("parse invoice templates: %w", err)
 	}
 	return &Service{
 		salesRepo:    sales,
+		quotesRepo:   quotes,
 		settingsRepo: settings,
 		baseTemplate: tpl,
 	}, nil
 }
 
-// GenerateHTML renders the invoice as HTML.
+// GenerateHTML renders the invoice as HTML. Invoices for members of the loyalty programme
+// show the points earned and redeemed and the customer's balance as it stood after the sale.
 func (s *Service) GenerateHTML(ctx context.Context, saleID int64) (string, error) {
 	saleData, profile, err := s.loadContext(ctx, saleID)
 	if err != nil {
 		return "", err
 	}
 
+	return s.execute("invoice.html", profile, map[string]interface{}{
+		"Sale":    saleData,
+		"Profile": profile,
+	})
+}
+
+// GeneratePDF renders the invoice as a PDF byte slice.
+func (s *Service) GeneratePDF(ctx context.Context, saleID int64) ([]byte, error) {
+	saleData, profile, err := s.loadContext(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	pdfBytes, err := renderSimplePDF(profile, saleData)
+	if err != nil {
+		return nil, err
+	}
+	return pdfBytes, nil
+}
+
+// ExchangeHTML renders a single receipt for an exchange: the items returned, their
+// replacements and the difference settled.
+func (s *Service) ExchangeHTML(ctx context.Context, exchangeID int64) (string, error) {
+	exchange, profile, err := s.loadExchange(ctx, exchangeID)
+	if err != nil {
+		return "", err
+	}
+	return s.execute("exchange.html", profile, map[string]interface{}{
+		"Exchange": exchange,
+		"Profile":  profile,
+	})
+}
+
+// ExchangePDF renders the exchange receipt as a PDF byte slice.
+func (s *Service) ExchangePDF(ctx context.Context, exchangeID int64) ([]byte, error) {
+	exchange, profile, err := s.loadExchange(ctx, exchangeID)
+	if err != nil {
+		return nil, err
+	}
+	return renderExchangePDF(profile, exchange), nil
+}
+
+// QuoteHTML renders a quote for the customer.
+func (s *Service) QuoteHTML(ctx context.Context, quoteID int64) (string, error) {
+	q, profile, err := s.loadQuote(ctx, quoteID)
+	if err != nil {
+		return "", err
+	}
+	return s.execute("quote.html", profile, map[string]interface{}{
+		"Quote":   q,
+		"Profile": profile,
+	})
+}
+
+// QuotePDF renders the quote as a PDF byte slice.
+func (s *Service) QuotePDF(ctx context.Context, quoteID int64) ([]byte, error) {
+	q, profile, err := s.loadQuote(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	return renderQuotePDF(profile, q), nil
+}
+
+func (s *Service) execute(name string, profile domainsettings.Profile, data map[string]interface{}) (string, error) {
 	tpl, err := s.baseTemplate.Clone()
 	if err != nil {
 		return "", fmt.Errorf("clone template: %w", err)
@@ -58,26 +128,34 @@ func (s *Service) GenerateHTML(ctx context.Context, saleID int64) (string, error
 	})
 
 	var buf bytes.Buffer
-	if err := tpl.ExecuteTemplate(&buf, "invoice.html", map[string]interface{}{
-		"Sale":    saleData,
-		"Profile": profile,
-	}); err != nil {
-		return "", fmt.Errorf("execute invoice template: %w", err)
+	if err := tpl.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("execute %s: %w", name, err)
 	}
 	return buf.String(), nil
 }
 
-// GeneratePDF renders the invoice as a PDF byte slice.
-func (s *Service) GeneratePDF(ctx context.Context, saleID int64) ([]byte, error) {
-	saleData, profile, err := s.loadContext(ctx, saleID)
+func (s *Service) loadExchange(ctx context.Context, exchangeID int64) (*domainsale.Exchange, domainsettings.Profile, error) {
+	exchange, err := s.salesRepo.GetExchange(ctx, exchangeID)
 	if err != nil {
-		return nil, err
+		return nil, domainsettings.Profile{}, fmt.Errorf("load exchange: %w", err)
 	}
-	pdfBytes, err := renderSimplePDF(profile, saleData)
+	profile, err := s.settingsRepo.LoadProfile(ctx)
 	if err != nil {
-		return nil, err
+		return nil, domainsettings.Profile{}, fmt.Errorf("load profile: %w", err)
 	}
-	return pdfBytes, nil
+	return exchange, profile, nil
+}
+
+func (s *Service) loadQuote(ctx context.Context, quoteID int64) (*domainquote.Quote, domainsettings.Profile, error) {
+	q, err := s.quotesRepo.Get(ctx, quoteID)
+	if err != nil {
+		return nil, domainsettings.Profile{}, fmt.Errorf("load quote: %w", err)
+	}
+	profile, err := s.settingsRepo.LoadProfile(ctx)
+	if err != nil {
+		return nil, domainsettings.Profile{}, fmt.Errorf("load profile: %w", err)
+	}
+	return q, profile, nil
 }
 
 func (s *Service) loadContext(ctx context.Context, saleID int64) (*domainsale.Sale, domainsettings.Profile, error) {
@@ -99,6 +177,11 @@ func formatCurrency(symbol string, cents int64) string {
 	return fmt.Sprintf("%s%.2f", symbol, float64(cents)/100.0)
 }
 
+// formatPercent renders a rate in basis points as a percentage, such as 8.25%.
+func formatPercent(basisPoints int64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", float64(basisPoints)/100), "0"), ".") + "%"
+}
+
 func renderSimplePDF(profile domainsettings.Profile, sale *domainsale.Sale) ([]byte, error) {
 	lines := []string{
 		fmt.Sprintf("%s Invoice %s", profile.Name, sale.SaleNumber),
@@ -107,6 +190,9 @@ func renderSimplePDF(profile domainsettings.Profile, sale *domainsale.Sale) ([]b
 	if sale.CustomerName != "" {
 		lines = append(lines, fmt.Sprintf("Customer: %s", sale.CustomerName))
 	}
+	if sale.TaxExempt {
+		lines = append(lines, strings.TrimSpace("Tax exempt "+sale.TaxExemptionNumber))
+	}
 	lines = append(lines,
 		fmt.Sprintf("Payment Method: %s", sale.PaymentMethod),
 		"",
@@ -115,20 +201,123 @@ func renderSimplePDF(profile domainsettings.Profile, sale *domainsale.Sale) ([]b
 
 	for _, line := range sale.Lines {
 		lines = append(lines, fmt.Sprintf("- %s x%d @ %s = %s", line.ProductName, line.Quantity, formatCurrency(profile.CurrencySymbol, line.UnitPriceCents), formatCurrency(profile.CurrencySymbol, line.LineTotalCents)))
+		for _, p := range line.Promotions {
+			name := p.Name
+			if p.CouponCode != "" {
+				name += " (" + p.CouponCode + ")"
+			}
+			lines = append(lines, fmt.Sprintf("    %s: -%s", name, formatCurrency(profile.CurrencySymbol, p.DiscountCents)))
+		}
 	}
 
 	lines = append(lines,
 		"",
 		fmt.Sprintf("Subtotal: %s", formatCurrency(profile.CurrencySymbol, sale.SubtotalCents)),
 		fmt.Sprintf("Discount: %s", formatCurrency(profile.CurrencySymbol, sale.DiscountCents)),
-		fmt.Sprintf("Tax: %s", formatCurrency(profile.CurrencySymbol, sale.TaxCents)),
-		fmt.Sprintf("Total: %s", formatCurrency(profile.CurrencySymbol, sale.TotalCents)),
 	)
+	taxLabel := "Tax"
+	if sale.TaxIncluded {
+		taxLabel = "Tax included"
+	}
+	lines = append(lines, fmt.Sprintf("%s: %s", taxLabel, formatCurrency(profile.CurrencySymbol, sale.TaxCents)))
+	for _, t := range sale.Taxes {
+		lines = append(lines, fmt.Sprintf("    %s %s on %s: %s", t.Name, formatPercent(t.RateBasisPoints),
+			formatCurrency(profile.CurrencySymbol, t.TaxableCents), formatCurrency(profile.CurrencySymbol, t.TaxCents)))
+	}
+	lines = append(lines, fmt.Sprintf("Total: %s", formatCurrency(profile.CurrencySymbol, sale.TotalCents)))
+	for _, payment := range sale.Payments {
+		method := payment.Method
+		if payment.Reference != "" {
+			method += " " + maskedReference(payment.Reference)
+		}
+		lines = append(lines, fmt.Sprintf("Paid by %s: %s", method, formatCurrency(profile.CurrencySymbol, payment.TenderedCents)))
+	}
+	if sale.ChangeCents > 0 {
+		lines = append(lines, fmt.Sprintf("Change: %s", formatCurrency(profile.CurrencySymbol, sale.ChangeCents)))
+	}
+	if sale.PointsBalance != nil {
+		lines = append(lines, "")
+		if sale.PointsRedeemed > 0 {
+			lines = append(lines, fmt.Sprintf("Points redeemed: %d", sale.PointsRedeemed))
+		}
+		lines = append(lines,
+			fmt.Sprintf("Points earned: %d", sale.PointsEarned),
+			fmt.Sprintf("Points balance: %d", *sale.PointsBalance),
+		)
+	}
 
 	if profile.InvoiceFooter != "" {
 		lines = append(lines, "", profile.InvoiceFooter)
 	}
+	return renderTextPDF(lines), nil
+}
+
+func renderExchangePDF(profile domainsettings.Profile, exchange *domainsale.Exchange) []byte {
+	money := func(cents int64) string { return formatCurrency(profile.CurrencySymbol, cents) }
+	lines := []string{
+		fmt.Sprintf("%s Exchange", profile.Name),
+		fmt.Sprintf("Date: %s", exchange.Timestamp.Format(time.RFC1123)),
+		fmt.Sprintf("Original sale: %s", exchange.Return.SaleNumber),
+	}
+	if exchange.Sale.CustomerName != "" {
+		lines = append(lines, fmt.Sprintf("Customer: %s", exchange.Sale.CustomerName))
+	}
+
+	lines = append(lines, "", fmt.Sprintf("Returned (credit note %s):", exchange.Return.RefundNumber))
+	for _, line := range exchange.Return.Lines {
+		lines = append(lines, fmt.Sprintf("- %s x%d = %s", line.ProductName, line.Quantity, money(line.TotalCents)))
+	}
+	lines = append(lines, fmt.Sprintf("Credit: %s", money(exchange.Return.TotalCents)))
 
+	lines = append(lines, "", fmt.Sprintf("New items (invoice %s):", exchange.Sale.SaleNumber))
+	for _, line := range exchange.Sale.Lines {
+		lines = append(lines, fmt.Sprintf("- %s x%d @ %s = %s", line.ProductName, line.Quantity, money(line.UnitPriceCents), money(line.LineTotalCents)))
+	}
+	lines = append(lines, fmt.Sprintf("Total: %s", money(exchange.Sale.TotalCents)), "")
+
+	if exchange.NetCents < 0 {
+		lines = append(lines, fmt.Sprintf("Refunded to customer (%s): %s", exchange.PaymentMethod, money(-exchange.NetCents)))
+	} else {
+		lines = append(lines, fmt.Sprintf("Paid by customer (%s): %s", exchange.PaymentMethod, money(exchange.NetCents)))
+	}
+
+	if profile.InvoiceFooter != "" {
+		lines = append(lines, "", profile.InvoiceFooter)
+	}
+	return renderTextPDF(lines)
+}
+
+func renderQuotePDF(profile domainsettings.Profile, q *domainquote.Quote) []byte {
+	money := func(cents int64) string { return formatCurrency(profile.CurrencySymbol, cents) }
+	lines := []string{
+		fmt.Sprintf("%s Quote %s", profile.Name, q.QuoteNumber),
+		fmt.Sprintf("Date: %s", q.CreatedAt.Format(time.RFC1123)),
+		fmt.Sprintf("Valid until: %s", q.ValidUntil.Format("2006-01-02")),
+	}
+	if q.CustomerName != "" {
+		lines = append(lines, fmt.Sprintf("Customer: %s", q.CustomerName))
+	}
+	lines = append(lines, "", "Items:")
+	for _, line := range q.Lines {
+		lines = append(lines, fmt.Sprintf("- %s x%d @ %s = %s", line.ProductName, line.Quantity, money(line.UnitPriceCents), money(line.LineTotalCents)))
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("Subtotal: %s", money(q.SubtotalCents)),
+		fmt.Sprintf("Discount: %s", money(q.DiscountCents)),
+		fmt.Sprintf("Tax: %s", money(q.TaxCents)),
+		fmt.Sprintf("Total: %s", money(q.TotalCents)),
+	)
+	if q.Note != "" {
+		lines = append(lines, "", q.Note)
+	}
+	if profile.InvoiceFooter != "" {
+		lines = append(lines, "", profile.InvoiceFooter)
+	}
+	return renderTextPDF(lines)
+}
+
+func renderTextPDF(lines []string) []byte {
 	content := buildTextContent(lines)
 
 	var buf bytes.Buffer
@@ -157,7 +346,7 @@ func renderSimplePDF(profile domainsettings.Profile, sale *domainsale.Sale) ([]b
 	buf.WriteString("startxref\n")
 	fmt.Fprintf(&buf, "%d\n%%%%EOF", xrefOffset)
 
-	return buf.Bytes(), nil
+	return buf.Bytes()
 }
 
 func buildTextContent(lines []string) string {
@@ -180,3 +369,12 @@ func pdfEscape(input string) string {
 	replacer := strings.NewReplacer("\\", "\\\\", "(", "\\(", ")", "\\)")
 	return replacer.Replace(input)
 }
+
+// maskedReference shows only the last four characters of a gift card or store credit code,
+// so a printed invoice cannot be used to spend the account.
+func maskedReference(code string) string {
+	if len(code) <= 4 {
+		return code
+	}
+	return "****" + code[len(code)-4:]
+}
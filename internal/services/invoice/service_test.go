@@ -0,0 +1,98 @@
+package invoice_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	productdomain "shopmate/internal/domain/product"
+	domainsettings "shopmate/internal/domain/settings"
+	"shopmate/internal/services/invoice"
+	quoteservice "shopmate/internal/services/quote"
+	saleservice "shopmate/internal/services/sale"
+)
+
+func TestQuoteRendersLinesTotalsAndValidity(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "invoice.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	saleRepo := sqlite.NewSaleRepository(store.DB())
+	quoteRepo := sqlite.NewQuoteRepository(store.DB())
+	quotes := quoteservice.NewService(quoteRepo, saleservice.NewService(productRepo, saleRepo, settingsRepo))
+	service, err := invoice.NewService(saleRepo, quoteRepo, settingsRepo)
+	if err != nil {
+		t.Fatalf("invoice service: %v", err)
+	}
+
+	if err := settingsRepo.SaveProfile(ctx, domainsettings.Profile{Name: "Corner Shop", CurrencySymbol: "€"}); err != nil {
+		t.Fatalf("save profile: %v", err)
+	}
+	lamp, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Desk Lamp", SKU: "LAMP", UnitPriceCents: 1000, TaxRateBasisPoints: 1000, CurrentQty: 5})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	// 3000 less 100 off the line and 200 off the quote leaves 2700, taxed at 10%.
+	q, err := quotes.Create(ctx, quoteservice.Request{
+		CustomerName:  "Trade Co",
+		ValidDays:     14,
+		DiscountCents: 200,
+		Note:          "Delivery included",
+		Lines:         []quoteservice.RequestLine{{ProductID: lamp.ID, Quantity: 3, DiscountCents: 100}},
+	})
+	if err != nil {
+		t.Fatalf("create quote: %v", err)
+	}
+	validUntil := q.ValidUntil.Format("2006-01-02")
+	if want := q.CreatedAt.AddDate(0, 0, 14).Format("2006-01-02"); validUntil != want {
+		t.Fatalf("expected the quote valid until %s, got %s", want, validUntil)
+	}
+
+	html, err := service.QuoteHTML(ctx, q.ID)
+	if err != nil {
+		t.Fatalf("quote html: %v", err)
+	}
+	html = strings.Join(strings.Fields(html), " ")
+	for _, want := range []string{
+		"<title>Quote " + q.QuoteNumber + "</title>",
+		"<h1>Corner Shop</h1>",
+		"<strong>Valid until:</strong> " + validUntil,
+		"<strong>Customer:</strong> Trade Co",
+		"<td>Desk Lamp</td> <td>LAMP</td> <td>3</td> <td>€10.00</td> <td>€1.00</td> <td>€2.70</td> <td>€29.70</td>",
+		"<td>Subtotal</td> <td>€30.00</td>",
+		"<td>Discount</td> <td>€3.00</td>",
+		"<td>Tax</td> <td>€2.70</td>",
+		"<td>Total</td> <td>€29.70</td>",
+		"<p>Delivery included</p>",
+		"Prices are quoted until " + validUntil,
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected %q in quote html:\n%s", want, html)
+		}
+	}
+
+	pdf, err := service.QuotePDF(ctx, q.ID)
+	if err != nil {
+		t.Fatalf("quote pdf: %v", err)
+	}
+	for _, want := range []string{
+		"(Corner Shop Quote " + q.QuoteNumber + ") Tj",
+		"(Valid until: " + validUntil + ") Tj",
+		"(- Desk Lamp x3 @ €10.00 = €29.70) Tj",
+		"(Discount: €3.00) Tj",
+		"(Tax: €2.70) Tj",
+		"(Total: €29.70) Tj",
+	} {
+		if !strings.Contains(string(pdf), want) {
+			t.Fatalf("expected %q in quote pdf", want)
+		}
+	}
+}
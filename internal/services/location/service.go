@@ -0,0 +1,107 @@
+package location
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/location"
+)
+
+const defaultTransferLimit = 100
+
+// Service manages stock locations and transfers between them.
+type Service struct {
+	repo *sqlite.LocationRepository
+}
+
+// NewService constructs a location service.
+func NewService(repo *sqlite.LocationRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// List returns all locations.
+func (s *Service) List(ctx context.Context) ([]domain.Location, error) {
+	locations, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list locations: %w", err)
+	}
+	return locations, nil
+}
+
+// Create adds a location.
+func (s *Service) Create(ctx context.Context, input domain.Input) (*domain.Location, error) {
+	if err := input.Validate(); err != nil {
+		return nil, fmt.Errorf("validate location: %w", err)
+	}
+	loc, err := s.repo.Create(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("create location: %w", err)
+	}
+	return loc, nil
+}
+
+// Update renames or (de)activates a location.
+func (s *Service) Update(ctx context.Context, id int64, input domain.Input) (*domain.Location, error) {
+	if id <= 0 {
+		return nil, errors.New("location id required")
+	}
+	loc, err := s.repo.Update(ctx, id, input)
+	if err != nil {
+		return nil, fmt.Errorf("update location: %w", err)
+	}
+	return loc, nil
+}
+
+// SetDefault changes the location used when none is chosen.
+func (s *Service) SetDefault(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("location id required")
+	}
+	if err := s.repo.SetDefault(ctx, id); err != nil {
+		return fmt.Errorf("set default location: %w", err)
+	}
+	return nil
+}
+
+// StockLevels returns per-location quantities filtered by product and/or location.
+func (s *Service) StockLevels(ctx context.Context, productID, locationID int64) ([]domain.StockLevel, error) {
+	levels, err := s.repo.StockLevels(ctx, productID, locationID)
+	if err != nil {
+		return nil, fmt.Errorf("stock levels: %w", err)
+	}
+	return levels, nil
+}
+
+// Transfer records a stock transfer between two locations.
+func (s *Service) Transfer(ctx context.Context, draft domain.TransferDraft) (*domain.Transfer, error) {
+	if err := draft.Validate(); err != nil {
+		return nil, fmt.Errorf("validate transfer: %w", err)
+	}
+	transfer, err := s.repo.CreateTransfer(ctx, draft)
+	if err != nil {
+		return nil, fmt.Errorf("create transfer: %w", err)
+	}
+	return transfer, nil
+}
+
+// GetTransfer loads a transfer document.
+func (s *Service) GetTransfer(ctx context.Context, id int64) (*domain.Transfer, error) {
+	if id <= 0 {
+		return nil, errors.New("transfer id required")
+	}
+	return s.repo.GetTransfer(ctx, id)
+}
+
+// Transfers lists recent transfers.
+func (s *Service) Transfers(ctx context.Context, limit int) ([]domain.Transfer, error) {
+	if limit <= 0 {
+		limit = defaultTransferLimit
+	}
+	transfers, err := s.repo.ListTransfers(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list transfers: %w", err)
+	}
+	return transfers, nil
+}
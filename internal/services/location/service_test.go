@@ -0,0 +1,171 @@
+package location_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domainlocation "shopmate/internal/domain/location"
+	productdomain "shopmate/internal/domain/product"
+	domainsettings "shopmate/internal/domain/settings"
+	cartservice "shopmate/internal/services/cart"
+	locationservice "shopmate/internal/services/location"
+	saleservice "shopmate/internal/services/sale"
+)
+
+func TestTransferAndTillLocationSale(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "locations.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	service := locationservice.NewService(sqlite.NewLocationRepository(store.DB()))
+
+	item, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Tea", SKU: "TEA", UnitPriceCents: 300, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	backRoom, err := service.Create(ctx, domainlocation.Input{Name: "Back Room", Active: true})
+	if err != nil {
+		t.Fatalf("create location: %v", err)
+	}
+	locations, err := service.List(ctx)
+	if err != nil || len(locations) != 2 || !locations[0].IsDefault {
+		t.Fatalf("expected default plus new location, got %+v (%v)", locations, err)
+	}
+	floor := locations[0]
+
+	if _, err := service.Transfer(ctx, domainlocation.TransferDraft{
+		FromLocationID: floor.ID,
+		ToLocationID:   backRoom.ID,
+		Lines:          []domainlocation.TransferLine{{ProductID: item.ID, Quantity: 11}},
+	}); err == nil {
+		t.Fatalf("expected transfer beyond stock to fail")
+	}
+
+	transfer, err := service.Transfer(ctx, domainlocation.TransferDraft{
+		FromLocationID: floor.ID,
+		ToLocationID:   backRoom.ID,
+		Lines:          []domainlocation.TransferLine{{ProductID: item.ID, Quantity: 4}},
+	})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if transfer.TransferNumber == "" || len(transfer.Lines) != 1 {
+		t.Fatalf("unexpected transfer %+v", transfer)
+	}
+
+	if err := settingsRepo.SaveTill(ctx, domainsettings.Till{LocationID: backRoom.ID}); err != nil {
+		t.Fatalf("save till: %v", err)
+	}
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), settingsRepo)
+	created, err := sales.Create(ctx, saleservice.CreateRequest{
+		PaymentMethod: "Cash",
+		Lines:         []saleservice.CreateRequestLine{{ProductID: item.ID, Quantity: 3}},
+	})
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+	if created.LocationID != backRoom.ID {
+		t.Fatalf("expected sale from till location %d, got %d", backRoom.ID, created.LocationID)
+	}
+
+	levels, err := service.StockLevels(ctx, item.ID, 0)
+	if err != nil {
+		t.Fatalf("stock levels: %v", err)
+	}
+	byLocation := map[int64]int64{}
+	for _, level := range levels {
+		byLocation[level.LocationID] = level.Qty
+	}
+	if byLocation[floor.ID] != 6 || byLocation[backRoom.ID] != 1 {
+		t.Fatalf("unexpected per-location stock %v", byLocation)
+	}
+
+	reloaded, err := productRepo.GetByID(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("get product: %v", err)
+	}
+	if reloaded.CurrentQty != 7 {
+		t.Fatalf("expected derived total 7, got %d", reloaded.CurrentQty)
+	}
+}
+
+func TestDeactivateRefusesLocationInUse(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "locations.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	service := locationservice.NewService(sqlite.NewLocationRepository(store.DB()))
+	carts := cartservice.NewService(sqlite.NewCartRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+
+	item, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Tea", SKU: "TEA", UnitPriceCents: 300, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	storeRoom, err := service.Create(ctx, domainlocation.Input{Name: "Store Room", Active: true})
+	if err != nil {
+		t.Fatalf("create location: %v", err)
+	}
+	locations, err := service.List(ctx)
+	if err != nil {
+		t.Fatalf("list locations: %v", err)
+	}
+	floor := locations[0]
+	move := func(from, to int64) {
+		t.Helper()
+		if _, err := service.Transfer(ctx, domainlocation.TransferDraft{
+			FromLocationID: from,
+			ToLocationID:   to,
+			Lines:          []domainlocation.TransferLine{{ProductID: item.ID, Quantity: 2}},
+		}); err != nil {
+			t.Fatalf("transfer: %v", err)
+		}
+	}
+	deactivate := func(wantErr string) {
+		t.Helper()
+		_, err := service.Update(ctx, storeRoom.ID, domainlocation.Input{Name: "Store Room", Active: false})
+		if wantErr == "" && err != nil {
+			t.Fatalf("deactivate: %v", err)
+		}
+		if wantErr != "" && (err == nil || !strings.Contains(err.Error(), wantErr)) {
+			t.Fatalf("expected deactivation refused with %q, got %v", wantErr, err)
+		}
+	}
+
+	move(floor.ID, storeRoom.ID)
+	deactivate("holds stock of 1 products")
+	if renamed, err := service.Update(ctx, storeRoom.ID, domainlocation.Input{Name: "Stock Room", Active: true}); err != nil || renamed.Name != "Stock Room" {
+		t.Fatalf("expected a location with stock to be renamed, got %+v (%v)", renamed, err)
+	}
+
+	move(storeRoom.ID, floor.ID)
+	held, err := carts.Park(ctx, cartservice.ParkRequest{
+		ParkedBy:   "Sam",
+		LocationID: storeRoom.ID,
+		Lines:      []cartservice.ParkRequestLine{{ProductID: item.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("park cart: %v", err)
+	}
+	deactivate("1 held carts")
+
+	if err := carts.Discard(ctx, held.ID); err != nil {
+		t.Fatalf("discard cart: %v", err)
+	}
+	deactivate("")
+	if loc, err := service.Update(ctx, storeRoom.ID, domainlocation.Input{Name: "Store Room", Active: true}); err != nil || !loc.Active {
+		t.Fatalf("expected the location reactivated, got %+v (%v)", loc, err)
+	}
+}
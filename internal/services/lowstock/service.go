@@ -0,0 +1,158 @@
+package lowstock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/lowstock"
+)
+
+// Service lists products running low on stock and manages their alerts.
+type Service struct {
+	repo     *sqlite.LowStockRepository
+	settings *sqlite.SettingsRepository
+	now      func() time.Time
+}
+
+// NewService constructs a low-stock service.
+func NewService(repo *sqlite.LowStockRepository, settings *sqlite.SettingsRepository) *Service {
+	return &Service{repo: repo, settings: settings, now: time.Now}
+}
+
+// List returns every product the policy flags, largest shortfall first, with its open alerts.
+// Alerts are reconciled on the way so stock received elsewhere clears stale ones.
+func (s *Service) List(ctx context.Context) ([]domain.Item, error) {
+	items, _, err := s.evaluate(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	alerts, err := s.repo.OpenAlerts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list alerts: %w", err)
+	}
+	index := make(map[int64]int, len(items))
+	for i, item := range items {
+		index[item.ProductID] = i
+	}
+	for _, alert := range alerts {
+		if i, ok := index[alert.ProductID]; ok {
+			items[i].Alerts = append(items[i].Alerts, alert)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Shortfall != items[j].Shortfall {
+			return items[i].Shortfall > items[j].Shortfall
+		}
+		return items[i].ProductName < items[j].ProductName
+	})
+	return items, nil
+}
+
+// Check re-evaluates the given products after their stock changed and returns the alerts
+// newly raised by the change. Products already alerted for a rule are not alerted again
+// until they recover.
+func (s *Service) Check(ctx context.Context, productIDs []int64) ([]domain.Alert, error) {
+	if len(productIDs) == 0 {
+		return nil, nil
+	}
+	_, raised, err := s.evaluate(ctx, productIDs)
+	return raised, err
+}
+
+// Alerts lists open alerts that are not snoozed, newest first.
+func (s *Service) Alerts(ctx context.Context) ([]domain.Alert, error) {
+	alerts, err := s.repo.OpenAlerts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list alerts: %w", err)
+	}
+	now := s.now()
+	visible := make([]domain.Alert, 0, len(alerts))
+	for _, alert := range alerts {
+		if !alert.Snoozed(now) {
+			visible = append(visible, alert)
+		}
+	}
+	return visible, nil
+}
+
+// Acknowledge marks an alert as seen.
+func (s *Service) Acknowledge(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("alert id required")
+	}
+	if err := s.repo.Acknowledge(ctx, id, s.now()); err != nil {
+		return fmt.Errorf("acknowledge alert: %w", err)
+	}
+	return nil
+}
+
+// Snooze hides an alert for the given number of hours, or the policy default when hours is zero.
+func (s *Service) Snooze(ctx context.Context, id, hours int64) error {
+	if id <= 0 {
+		return errors.New("alert id required")
+	}
+	if hours < 0 {
+		return errors.New("snooze hours must be >= 0")
+	}
+	if hours == 0 {
+		policy, err := s.settings.LoadLowStockPolicy(ctx)
+		if err != nil {
+			return fmt.Errorf("load policy: %w", err)
+		}
+		hours = policy.SnoozeHours
+	}
+	if err := s.repo.Snooze(ctx, id, s.now().Add(time.Duration(hours)*time.Hour)); err != nil {
+		return fmt.Errorf("snooze alert: %w", err)
+	}
+	return nil
+}
+
+// Policy returns the alert policy.
+func (s *Service) Policy(ctx context.Context) (domain.Policy, error) {
+	return s.settings.LoadLowStockPolicy(ctx)
+}
+
+// SavePolicy stores the alert policy.
+func (s *Service) SavePolicy(ctx context.Context, policy domain.Policy) (domain.Policy, error) {
+	if err := s.settings.SaveLowStockPolicy(ctx, policy); err != nil {
+		return domain.Policy{}, err
+	}
+	return s.settings.LoadLowStockPolicy(ctx)
+}
+
+// evaluate applies the policy to the given products (all when nil), syncs their alerts and
+// returns the flagged items together with newly raised alerts.
+func (s *Service) evaluate(ctx context.Context, productIDs []int64) ([]domain.Item, []domain.Alert, error) {
+	policy, err := s.settings.LoadLowStockPolicy(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load policy: %w", err)
+	}
+
+	now := s.now()
+	since := now.Add(-time.Duration(policy.WindowDays) * 24 * time.Hour)
+	levels, err := s.repo.Levels(ctx, since, productIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load stock levels: %w", err)
+	}
+
+	items := make([]domain.Item, 0)
+	evaluated := make([]int64, 0, len(levels))
+	for _, level := range levels {
+		evaluated = append(evaluated, level.ProductID)
+		if item, ok := domain.Evaluate(level, policy); ok {
+			items = append(items, item)
+		}
+	}
+
+	raised, err := s.repo.SyncAlerts(ctx, evaluated, items, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sync alerts: %w", err)
+	}
+	return items, raised, nil
+}
@@ -0,0 +1,106 @@
+package lowstock_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/lowstock"
+	productdomain "shopmate/internal/domain/product"
+	lowstockservice "shopmate/internal/services/lowstock"
+	productservice "shopmate/internal/services/product"
+	saleservice "shopmate/internal/services/sale"
+)
+
+func TestSaleCrossingThresholdRaisesOneAlert(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "lowstock.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	service := lowstockservice.NewService(sqlite.NewLowStockRepository(store.DB()), settingsRepo)
+	products := productservice.NewService(productRepo, settingsRepo)
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), settingsRepo)
+
+	var raised []domain.Alert
+	observe := func(ctx context.Context, ids []int64) {
+		alerts, err := service.Check(ctx, ids)
+		if err != nil {
+			t.Fatalf("check: %v", err)
+		}
+		raised = append(raised, alerts...)
+	}
+	products.SetStockObserver(observe)
+	sales.SetStockObserver(observe)
+
+	tea, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Tea", SKU: "TEA", UnitPriceCents: 300, CurrentQty: 6, ReorderLevel: 4})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	sell := func(qty int64) {
+		t.Helper()
+		if _, err := sales.Create(ctx, saleservice.CreateRequest{
+			PaymentMethod: "Cash",
+			Lines:         []saleservice.CreateRequestLine{{ProductID: tea.ID, Quantity: qty}},
+		}); err != nil {
+			t.Fatalf("create sale: %v", err)
+		}
+	}
+
+	sell(1)
+	if len(raised) != 0 {
+		t.Fatalf("expected no alert above the reorder level, got %+v", raised)
+	}
+	sell(2)
+	if len(raised) != 1 || raised[0].Rule != domain.RuleReorderLevel || raised[0].CurrentQty != 3 {
+		t.Fatalf("expected one reorder-level alert, got %+v", raised)
+	}
+	sell(1)
+	if len(raised) != 1 {
+		t.Fatalf("expected repeat sales not to alert again, got %+v", raised)
+	}
+
+	items, err := service.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 1 || items[0].Shortfall != 2 || items[0].DaysOfCover == nil || len(items[0].Alerts) != 1 {
+		t.Fatalf("unexpected low-stock list %+v", items)
+	}
+
+	alertID := raised[0].ID
+	if err := service.Acknowledge(ctx, alertID); err != nil {
+		t.Fatalf("acknowledge: %v", err)
+	}
+	if err := service.Snooze(ctx, alertID, 2); err != nil {
+		t.Fatalf("snooze: %v", err)
+	}
+	visible, err := service.Alerts(ctx)
+	if err != nil || len(visible) != 0 {
+		t.Fatalf("expected snoozed alert to be hidden, got %+v (%v)", visible, err)
+	}
+
+	if _, err := products.AdjustStock(ctx, productdomain.AdjustmentInput{ProductID: tea.ID, Delta: 10, Reason: "Restock"}); err != nil {
+		t.Fatalf("adjust stock: %v", err)
+	}
+	if err := service.Acknowledge(ctx, alertID); err == nil {
+		t.Fatalf("expected restock to resolve the alert")
+	}
+
+	if _, err := products.AdjustStock(ctx, productdomain.AdjustmentInput{ProductID: tea.ID, Delta: -11, Reason: "Damaged"}); err != nil {
+		t.Fatalf("adjust stock: %v", err)
+	}
+	if len(raised) != 2 || raised[1].ID == alertID {
+		t.Fatalf("expected a fresh alert after recovering, got %+v", raised)
+	}
+
+	if _, err := service.SavePolicy(ctx, domain.Policy{ReorderLevel: true, ZeroStock: true, WindowDays: 400}); err == nil {
+		t.Fatalf("expected oversized window to be rejected")
+	}
+}
@@ -0,0 +1,135 @@
+package loyalty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/loyalty"
+)
+
+const (
+	// expirySchedulerInterval is how often points are checked for expiry.
+	expirySchedulerInterval = time.Hour
+	// ledgerLimit caps the points movements shown for a customer.
+	ledgerLimit = 200
+)
+
+// Service manages the loyalty programme's rules and customers' points. Points are earned,
+// redeemed and reversed by sales, refunds and voids.
+type Service struct {
+	repo     *sqlite.LoyaltyRepository
+	settings *sqlite.SettingsRepository
+	now      func() time.Time
+
+	mu              sync.Mutex
+	schedulerCancel context.CancelFunc
+}
+
+// NewService constructs a loyalty service.
+func NewService(repo *sqlite.LoyaltyRepository, settings *sqlite.SettingsRepository) *Service {
+	return &Service{repo: repo, settings: settings, now: time.Now}
+}
+
+// Policy returns the earn and redemption rules.
+func (s *Service) Policy(ctx context.Context) (domain.Policy, error) {
+	return s.settings.LoadLoyaltyPolicy(ctx)
+}
+
+// SavePolicy stores the earn and redemption rules. Points already earned keep their
+// expiry.
+func (s *Service) SavePolicy(ctx context.Context, policy domain.Policy) (domain.Policy, error) {
+	if err := s.settings.SaveLoyaltyPolicy(ctx, policy); err != nil {
+		return domain.Policy{}, fmt.Errorf("save loyalty policy: %w", err)
+	}
+	return s.settings.LoadLoyaltyPolicy(ctx)
+}
+
+// Account returns a customer's points balance once lapsed points have expired.
+func (s *Service) Account(ctx context.Context, customerID int64) (*domain.Account, error) {
+	if customerID <= 0 {
+		return nil, errors.New("customer id required")
+	}
+	if _, err := s.ExpireStale(ctx); err != nil {
+		return nil, err
+	}
+	policy, err := s.settings.LoadLoyaltyPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load loyalty policy: %w", err)
+	}
+	return s.repo.Account(ctx, customerID, policy)
+}
+
+// Ledger lists a customer's recent points movements, newest first.
+func (s *Service) Ledger(ctx context.Context, customerID int64) ([]domain.Entry, error) {
+	if customerID <= 0 {
+		return nil, errors.New("customer id required")
+	}
+	return s.repo.Ledger(ctx, customerID, ledgerLimit)
+}
+
+// Adjust credits a customer with points, or debits them when points is negative, and
+// returns the new balance.
+func (s *Service) Adjust(ctx context.Context, customerID, points int64, note string) (*domain.Account, error) {
+	if customerID <= 0 {
+		return nil, errors.New("customer id required")
+	}
+	if err := s.repo.Adjust(ctx, customerID, points, note, s.now()); err != nil {
+		return nil, fmt.Errorf("adjust points: %w", err)
+	}
+	return s.Account(ctx, customerID)
+}
+
+// ExpireStale expires points past their expiry and returns how many expired.
+func (s *Service) ExpireStale(ctx context.Context) (int64, error) {
+	expired, err := s.repo.ExpireDue(ctx, s.now())
+	if err != nil {
+		return 0, fmt.Errorf("expire points: %w", err)
+	}
+	return expired, nil
+}
+
+// StartExpiryScheduler expires lapsed points now and keeps expiring them as they fall due.
+func (s *Service) StartExpiryScheduler(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.schedulerCancel != nil {
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.schedulerCancel = cancel
+
+	go s.expirySchedulerLoop(runCtx)
+}
+
+// StopExpiryScheduler stops the background expiry scheduler if running.
+func (s *Service) StopExpiryScheduler() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.schedulerCancel != nil {
+		s.schedulerCancel()
+		s.schedulerCancel = nil
+	}
+}
+
+func (s *Service) expirySchedulerLoop(ctx context.Context) {
+	_, _ = s.ExpireStale(context.Background())
+
+	ticker := time.NewTicker(expirySchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = s.ExpireStale(context.Background())
+		}
+	}
+}
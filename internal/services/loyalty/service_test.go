@@ -0,0 +1,200 @@
+package loyalty_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	customerdomain "shopmate/internal/domain/customer"
+	domain "shopmate/internal/domain/loyalty"
+	productdomain "shopmate/internal/domain/product"
+	domainsale "shopmate/internal/domain/sale"
+	"shopmate/internal/services/invoice"
+	loyaltyservice "shopmate/internal/services/loyalty"
+	saleservice "shopmate/internal/services/sale"
+)
+
+func TestPointsFollowSalesRefundsAndVoids(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "loyalty.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	saleRepo := sqlite.NewSaleRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	loyaltyRepo := sqlite.NewLoyaltyRepository(store.DB())
+	sales := saleservice.NewService(productRepo, saleRepo, settingsRepo)
+	service := loyaltyservice.NewService(loyaltyRepo, settingsRepo)
+
+	create := func(input productdomain.CreateInput) *productdomain.Product {
+		t.Helper()
+		p, err := productRepo.Create(ctx, input)
+		if err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+		return p
+	}
+	latte := create(productdomain.CreateInput{Name: "Latte", SKU: "LAT", Category: "Drinks > Coffee", UnitPriceCents: 500, CurrentQty: 10})
+	mug := create(productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, CurrentQty: 10})
+	voucher := create(productdomain.CreateInput{Name: "Voucher", SKU: "VCH", UnitPriceCents: 2000, CurrentQty: 10})
+
+	categories, err := sqlite.NewCategoryRepository(store.DB()).List(ctx)
+	if err != nil || len(categories) != 2 {
+		t.Fatalf("expected two categories, got %d (%v)", len(categories), err)
+	}
+	if _, err := service.SavePolicy(ctx, domain.Policy{
+		Enabled:            true,
+		PointsPerUnit:      1,
+		PointValueCents:    1,
+		Multipliers:        []domain.CategoryMultiplier{{CategoryID: categories[0].ID, MultiplierPercent: 200}},
+		ExcludedProductIDs: []int64{voucher.ID},
+		ExpiryMonths:       12,
+	}); err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+
+	john, err := sqlite.NewCustomerRepository(store.DB()).Create(ctx, customerdomain.Input{Name: "John"}, time.Now())
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	balance := func(want int64) {
+		t.Helper()
+		account, err := service.Account(ctx, john.ID)
+		if err != nil {
+			t.Fatalf("account: %v", err)
+		}
+		if account.Points != want {
+			t.Fatalf("expected a balance of %d points, got %d", want, account.Points)
+		}
+	}
+
+	// Coffee earns double through its parent category; the voucher earns nothing.
+	first, err := sales.Create(ctx, saleservice.CreateRequest{
+		CustomerID:    john.ID,
+		PaymentMethod: "Card",
+		Lines: []saleservice.CreateRequestLine{
+			{ProductID: latte.ID, Quantity: 2},
+			{ProductID: mug.ID, Quantity: 1},
+			{ProductID: voucher.ID, Quantity: 1},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+	if first.PointsEarned != 30 {
+		t.Fatalf("expected 30 points earned, got %d", first.PointsEarned)
+	}
+	balance(30)
+
+	// Points paid with earn nothing.
+	second, err := sales.Create(ctx, saleservice.CreateRequest{
+		CustomerID: john.ID,
+		Payments:   []saleservice.CreatePaymentRequest{{Method: domainsale.PaymentPoints, AmountCents: 20}, {Method: "Cash", AmountCents: 1000}},
+		Lines:      []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("redeem points: %v", err)
+	}
+	if second.PointsRedeemed != 20 || second.PointsEarned != 9 {
+		t.Fatalf("expected 20 points redeemed and 9 earned, got %d and %d", second.PointsRedeemed, second.PointsEarned)
+	}
+	balance(19)
+
+	if _, err := sales.Create(ctx, saleservice.CreateRequest{
+		CustomerID: john.ID,
+		Payments:   []saleservice.CreatePaymentRequest{{Method: domainsale.PaymentPoints, AmountCents: 1000}},
+		Lines:      []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: 1}},
+	}); err == nil || !strings.Contains(err.Error(), "19 points") {
+		t.Fatalf("expected redeeming more than the balance to fail, got %v", err)
+	}
+	if _, err := sales.Create(ctx, saleservice.CreateRequest{
+		PaymentMethod: domainsale.PaymentPoints,
+		Lines:         []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: 1}},
+	}); err == nil {
+		t.Fatal("expected points to need a customer")
+	}
+
+	// A quarter of the first sale comes back, and with it a quarter of its points.
+	if _, err := sales.CreateRefund(ctx, saleservice.RefundRequest{
+		SaleID: first.ID,
+		Lines:  []saleservice.RefundRequestLine{{SaleLineID: first.Lines[0].ID, Quantity: 2}},
+	}); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	balance(12)
+
+	if err := sales.Void(ctx, second.ID, "wrong customer"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	balance(12 - 9 + 20)
+
+	// Refunding to points credits the refund's value.
+	if _, err := sales.CreateRefund(ctx, saleservice.RefundRequest{
+		SaleID:        first.ID,
+		PaymentMethod: domainsale.PaymentPoints,
+		Lines:         []saleservice.RefundRequestLine{{SaleLineID: first.Lines[1].ID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("refund to points: %v", err)
+	}
+	balance(23 - 8 + 1000)
+
+	ledger, err := service.Ledger(ctx, john.ID)
+	if err != nil || len(ledger) != 8 {
+		t.Fatalf("expected eight ledger entries, got %d (%v)", len(ledger), err)
+	}
+	if ledger[0].Kind != domain.KindRefund || ledger[len(ledger)-1].Kind != domain.KindEarn || ledger[len(ledger)-1].SaleNumber != first.SaleNumber {
+		t.Fatalf("unexpected ledger %+v", ledger)
+	}
+
+	invoices, err := invoice.NewService(saleRepo, sqlite.NewQuoteRepository(store.DB()), settingsRepo)
+	if err != nil {
+		t.Fatalf("invoice service: %v", err)
+	}
+	// Invoices keep the balance each sale left, whatever happened to the account since.
+	for _, tc := range []struct {
+		sale *domainsale.Sale
+		want []string
+	}{
+		{first, []string{"<td>Points earned</td> <td>30</td>", "<td>Points balance</td> <td>30</td>"}},
+		{second, []string{"<td>Points redeemed</td> <td>20</td>", "<td>Points earned</td> <td>9</td>", "<td>Points balance</td> <td>19</td>"}},
+	} {
+		html, err := invoices.GenerateHTML(ctx, tc.sale.ID)
+		if err != nil {
+			t.Fatalf("render invoice: %v", err)
+		}
+		html = strings.Join(strings.Fields(html), " ")
+		for _, want := range tc.want {
+			if !strings.Contains(html, want) {
+				t.Fatalf("expected %q on invoice %s:\n%s", want, tc.sale.SaleNumber, html)
+			}
+		}
+	}
+	pdf, err := invoices.GeneratePDF(ctx, second.ID)
+	if err != nil || !strings.Contains(string(pdf), "(Points balance: 19) Tj") {
+		t.Fatalf("expected the pdf to show the points balance at the sale (%v)", err)
+	}
+	walkIn, err := sales.Create(ctx, saleservice.CreateRequest{
+		PaymentMethod: "Cash",
+		Lines:         []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+	if html, err := invoices.GenerateHTML(ctx, walkIn.ID); err != nil || strings.Contains(html, "Points balance") {
+		t.Fatalf("expected no points on a sale without a customer (%v)", err)
+	}
+
+	expired, err := loyaltyRepo.ExpireDue(ctx, time.Now().AddDate(1, 0, 1))
+	if err != nil || expired != 1015 {
+		t.Fatalf("expected every point to expire after a year, got %d (%v)", expired, err)
+	}
+	if account, _ := loyaltyRepo.Account(ctx, john.ID, domain.DefaultPolicy()); account.Points != 0 {
+		t.Fatalf("expected no points left, got %d", account.Points)
+	}
+}
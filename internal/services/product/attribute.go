@@ -0,0 +1,76 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domain "shopmate/internal/domain/product"
+)
+
+// ListAttributes returns the custom attribute definitions in display order.
+func (s *Service) ListAttributes(ctx context.Context) ([]domain.Attribute, error) {
+	attributes, err := s.repo.ListAttributes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list attributes: %w", err)
+	}
+	return attributes, nil
+}
+
+// CreateAttribute defines a new custom attribute.
+func (s *Service) CreateAttribute(ctx context.Context, input domain.AttributeInput) (*domain.Attribute, error) {
+	attribute, err := s.repo.CreateAttribute(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("create attribute: %w", err)
+	}
+	return attribute, nil
+}
+
+// UpdateAttribute changes a custom attribute definition.
+func (s *Service) UpdateAttribute(ctx context.Context, id int64, input domain.AttributeInput) (*domain.Attribute, error) {
+	if id <= 0 {
+		return nil, errors.New("attribute id required")
+	}
+	attribute, err := s.repo.UpdateAttribute(ctx, id, input)
+	if err != nil {
+		return nil, fmt.Errorf("update attribute: %w", err)
+	}
+	return attribute, nil
+}
+
+// DeleteAttribute removes a custom attribute and its values from every product.
+func (s *Service) DeleteAttribute(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("attribute id required")
+	}
+	if err := s.repo.DeleteAttribute(ctx, id); err != nil {
+		return fmt.Errorf("delete attribute: %w", err)
+	}
+	return nil
+}
+
+// Search lists products matching a text query and attribute values. Attribute filter values
+// are read the same way as entered values, so "yes" matches a boolean stored as "true".
+func (s *Service) Search(ctx context.Context, filter domain.Filter) ([]domain.Product, error) {
+	if len(filter.Attributes) > 0 {
+		attributes, err := s.repo.ListAttributes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list attributes: %w", err)
+		}
+		values, err := domain.NormaliseAttributeValues(attributes, filter.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		filter.Attributes = make(map[string]string, len(values))
+		for code, value := range values {
+			if value != "" {
+				filter.Attributes[code] = value
+			}
+		}
+	}
+	products, err := s.repo.Search(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("search products: %w", err)
+	}
+	return products, nil
+}
@@ -0,0 +1,82 @@
+package product_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/product"
+	productservice "shopmate/internal/services/product"
+)
+
+func TestAttributesSearchAndCSVRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "attributes.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	service := productservice.NewService(sqlite.NewProductRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+
+	if _, err := service.CreateAttribute(ctx, domain.AttributeInput{Code: "brand", Name: "Brand", Type: domain.AttributeTypeText}); err != nil {
+		t.Fatalf("create brand: %v", err)
+	}
+	organic, err := service.CreateAttribute(ctx, domain.AttributeInput{Code: "organic", Name: "Organic", Type: domain.AttributeTypeBoolean})
+	if err != nil {
+		t.Fatalf("create organic: %v", err)
+	}
+
+	if _, err := service.Create(ctx, domain.CreateInput{Name: "Milk", SKU: "MILK", UnitPriceCents: 200,
+		Attributes: map[string]string{"brand": "Dairyco", "organic": "yes"}}); err != nil {
+		t.Fatalf("create milk: %v", err)
+	}
+	if _, err := service.Create(ctx, domain.CreateInput{Name: "Bread", SKU: "BREAD", UnitPriceCents: 300,
+		Attributes: map[string]string{"brand": "Bakeright"}}); err != nil {
+		t.Fatalf("create bread: %v", err)
+	}
+	if _, err := service.Create(ctx, domain.CreateInput{Name: "Eggs", SKU: "EGGS", Attributes: map[string]string{"organic": "maybe"}}); err == nil {
+		t.Fatalf("expected invalid boolean value to be rejected")
+	}
+
+	found, err := service.Search(ctx, domain.Filter{Query: "bakeri"})
+	if err != nil || len(found) != 1 || found[0].SKU != "BREAD" {
+		t.Fatalf("expected search to match attribute value, got %+v (%v)", found, err)
+	}
+	found, err = service.Search(ctx, domain.Filter{Attributes: map[string]string{"organic": "Y"}})
+	if err != nil || len(found) != 1 || found[0].SKU != "MILK" || found[0].Attributes["organic"] != "true" {
+		t.Fatalf("expected attribute filter to match milk, got %+v (%v)", found, err)
+	}
+
+	data, err := service.ExportCSV(ctx)
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if header := strings.SplitN(string(data), "\n", 2)[0]; !strings.HasSuffix(header, ",brand,organic") {
+		t.Fatalf("expected attribute columns in export header, got %q", header)
+	}
+
+	edited := strings.Replace(string(data), "Bakeright", "Crusty Co", 1)
+	if _, err := service.ImportCSV(ctx, []byte(edited)); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	found, err = service.Search(ctx, domain.Filter{Attributes: map[string]string{"brand": "crusty co"}})
+	if err != nil || len(found) != 1 || found[0].SKU != "BREAD" {
+		t.Fatalf("expected imported attribute value, got %+v (%v)", found, err)
+	}
+
+	if err := service.DeleteAttribute(ctx, organic.ID); err != nil {
+		t.Fatalf("delete attribute: %v", err)
+	}
+	products, err := service.List(ctx, false)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	for _, p := range products {
+		if _, ok := p.Attributes["organic"]; ok {
+			t.Fatalf("expected deleted attribute values removed, got %+v", p)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"shopmate/internal/adapters/spreadsheet"
+	domain "shopmate/internal/domain/product"
+)
+
+// ImportSource is an uploaded CSV or XLSX file and the column mapping to read it with.
+// A nil mapping falls back to the saved mapping, then to header matching. Mode is one of
+// the domain import modes and defaults to all-or-nothing.
+type ImportSource struct {
+	FileName string
+	Data     []byte
+	Mapping  domain.ColumnMapping
+	Mode     string
+}
+
+// ImportInspection describes an uploaded file so the user can confirm the column mapping.
+type ImportInspection struct {
+	Format     string               `json:"format"`
+	Headers    []string             `json:"headers"`
+	Fields     []string             `json:"fields"`
+	Mapping    domain.ColumnMapping `json:"mapping"`
+	SampleRows [][]string           `json:"sampleRows"`
+	RowCount   int                  `json:"rowCount"`
+}
+
+const importSampleRows = 5
+
+// InspectImport reads the file headers and proposes a column mapping.
+func (s *Service) InspectImport(ctx context.Context, fileName string, data []byte) (*ImportInspection, error) {
+	format := spreadsheet.DetectFormat(fileName, data)
+	table, err := spreadsheet.Read(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("read import file: %w", err)
+	}
+	saved, err := s.ImportMapping(ctx)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := s.repo.ListAttributes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list attributes: %w", err)
+	}
+
+	sample := table.Rows
+	if len(sample) > importSampleRows {
+		sample = sample[:importSampleRows]
+	}
+	return &ImportInspection{
+		Format:     format,
+		Headers:    table.Headers,
+		Fields:     domain.ImportFieldsWith(attributes),
+		Mapping:    domain.SuggestColumnMapping(table.Headers, saved, attributes...),
+		SampleRows: sample,
+		RowCount:   len(table.Rows),
+	}, nil
+}
+
+// PreviewImport performs a dry run, reporting per row whether it would create, update or fail.
+func (s *Service) PreviewImport(ctx context.Context, src ImportSource) (*domain.ImportPreview, error) {
+	preview, _, err := s.planImport(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// CommitImport applies the file as one import job. In all-or-nothing mode any row the dry
+// run rejects stops the whole import; in partial mode those rows are skipped and reported.
+func (s *Service) CommitImport(ctx context.Context, src ImportSource) (ImportSummary, error) {
+	summary := ImportSummary{}
+	mode, err := domain.NormaliseImportMode(src.Mode)
+	if err != nil {
+		summary.Errors = append(summary.Errors, err.Error())
+		return summary, err
+	}
+	preview, rows, err := s.planImport(ctx, src)
+	if err != nil {
+		summary.Errors = append(summary.Errors, err.Error())
+		return summary, err
+	}
+	for _, row := range preview.Rows {
+		if row.Action == domain.ImportActionError {
+			summary.Errors = append(summary.Errors, domain.ImportRowError{Line: row.Line, SKU: row.SKU, Error: row.Error}.String())
+		}
+	}
+	if mode == domain.ImportModeAllOrNothing && len(summary.Errors) > 0 {
+		return summary, fmt.Errorf("%w: %d row(s) failed", domain.ErrImportAborted, len(summary.Errors))
+	}
+
+	return s.applyImport(ctx, domain.ImportBatch{FileName: src.FileName, Mode: mode, Rows: rows}, summary)
+}
+
+// ImportJobs lists recent imports, newest first.
+func (s *Service) ImportJobs(ctx context.Context, limit int) ([]domain.ImportJob, error) {
+	jobs, err := s.repo.ImportJobs(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list import jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// ImportJob returns an import with the products it touched and their before-images.
+func (s *Service) ImportJob(ctx context.Context, id int64) (*domain.ImportJob, error) {
+	job, err := s.repo.ImportJob(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get import job: %w", err)
+	}
+	return job, nil
+}
+
+// RollbackImport undoes an import, restoring updated products and archiving created ones.
+func (s *Service) RollbackImport(ctx context.Context, id int64) (*domain.ImportJob, error) {
+	job, err := s.repo.RollbackImport(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("roll back import: %w", err)
+	}
+	return job, nil
+}
+
+// ImportMapping returns the saved import column mapping.
+func (s *Service) ImportMapping(ctx context.Context) (domain.ColumnMapping, error) {
+	mapping, err := s.settings.LoadImportMapping(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load import mapping: %w", err)
+	}
+	return mapping, nil
+}
+
+// SaveImportMapping remembers a column mapping for the next import.
+func (s *Service) SaveImportMapping(ctx context.Context, mapping domain.ColumnMapping) error {
+	if err := mapping.Validate(); err != nil {
+		return fmt.Errorf("validate import mapping: %w", err)
+	}
+	if err := s.settings.SaveImportMapping(ctx, mapping); err != nil {
+		return fmt.Errorf("save import mapping: %w", err)
+	}
+	return nil
+}
+
+// planImport reads and maps every row, returning the dry-run report alongside the rows that
+// are safe to apply.
+func (s *Service) planImport(ctx context.Context, src ImportSource) (*domain.ImportPreview, []domain.ImportRow, error) {
+	table, err := spreadsheet.Read(spreadsheet.DetectFormat(src.FileName, src.Data), src.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read import file: %w", err)
+	}
+
+	attributes, err := s.repo.ListAttributes(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list attributes: %w", err)
+	}
+	mapping := src.Mapping
+	if len(mapping) == 0 {
+		saved, err := s.ImportMapping(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		mapping = domain.SuggestColumnMapping(table.Headers, saved, attributes...)
+	}
+	if err := mapping.CheckAttributes(attributes); err != nil {
+		return nil, nil, err
+	}
+	columns, err := domain.ResolveColumns(table.Headers, mapping)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		preview  domain.ImportPreview
+		valid    []domain.ImportRow
+		seenSKUs = make(map[string]int)
+	)
+	for i, record := range table.Rows {
+		line := i + 2 // header is line 1
+		if isBlankRecord(record) {
+			continue
+		}
+
+		row, err := domain.MapImportRow(line, record, columns)
+		if err != nil {
+			preview.Add(domain.ImportPreviewRow{Line: line, Action: domain.ImportActionError, Error: err.Error()})
+			continue
+		}
+		result := domain.ImportPreviewRow{Line: line, SKU: row.SKU, Name: row.Name}
+
+		if first, dup := seenSKUs[strings.ToLower(row.SKU)]; dup {
+			result.Action = domain.ImportActionError
+			result.Error = fmt.Sprintf("sku repeats line %d", first)
+			preview.Add(result)
+			continue
+		}
+		seenSKUs[strings.ToLower(row.SKU)] = line
+
+		if row.Attributes, err = domain.NormaliseAttributeValues(attributes, row.Attributes); err != nil {
+			result.Action = domain.ImportActionError
+			result.Error = err.Error()
+			preview.Add(result)
+			continue
+		}
+		if err := row.ToCreateInput().Validate(); err != nil {
+			result.Action = domain.ImportActionError
+			result.Error = err.Error()
+			preview.Add(result)
+			continue
+		}
+
+		existing, err := s.repo.GetBySKU(ctx, row.SKU)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			result.Action = domain.ImportActionCreate
+		case err != nil:
+			return nil, nil, fmt.Errorf("look up sku %s: %w", row.SKU, err)
+		default:
+			row.FillUnmapped(*existing, columns)
+			result.Action = domain.ImportActionUpdate
+			result.Changes = domain.ImportChanges(*existing, row)
+		}
+		preview.Add(result)
+		valid = append(valid, row)
+	}
+
+	if len(preview.Rows) == 0 {
+		return nil, nil, domain.ErrNoImportRows
+	}
+	return &preview, valid, nil
+}
+
+// applyImport runs a batch through the repository and folds the outcome into summary.
+func (s *Service) applyImport(ctx context.Context, batch domain.ImportBatch, summary ImportSummary) (ImportSummary, error) {
+	job, rowErrs, err := s.repo.ImportProducts(ctx, batch)
+	for _, rowErr := range rowErrs {
+		summary.Errors = append(summary.Errors, rowErr.String())
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrImportAborted) {
+			return summary, fmt.Errorf("%w: %d row(s) failed", err, len(summary.Errors))
+		}
+		summary.Errors = append(summary.Errors, err.Error())
+		return summary, fmt.Errorf("import products: %w", err)
+	}
+
+	summary.JobID = job.ID
+	summary.JobNumber = job.JobNumber
+	summary.Created = job.Created
+	summary.Updated = job.Updated
+	if len(summary.Errors) > 0 {
+		return summary, fmt.Errorf("import completed with %d error(s)", len(summary.Errors))
+	}
+	return summary, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package product_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/product"
+	productservice "shopmate/internal/services/product"
+)
+
+func TestImportPreviewThenCommitWithMapping(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "import.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	repo := sqlite.NewProductRepository(store.DB())
+	service := productservice.NewService(repo, sqlite.NewSettingsRepository(store.DB()))
+
+	if _, err := service.Create(ctx, domain.CreateInput{Name: "Milk", SKU: "MILK", UnitPriceCents: 200, CurrentQty: 3}); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	file := []byte("Code,Description,Cost,Sell\n" +
+		"MILK,Milk 1L,1.00,2.25\n" +
+		"EGGS,Eggs,2.00,3.50\n" +
+		",Nameless,1,2\n" +
+		"EGGS,Eggs again,2,3\n")
+
+	inspection, err := service.InspectImport(ctx, "supplier.csv", file)
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+	if inspection.Mapping["sku"] != "Code" || inspection.Mapping["name"] != "Description" {
+		t.Fatalf("unexpected suggested mapping %v", inspection.Mapping)
+	}
+
+	mapping := inspection.Mapping
+	mapping["unit_price"] = "Sell"
+	if err := service.SaveImportMapping(ctx, mapping); err != nil {
+		t.Fatalf("save mapping: %v", err)
+	}
+
+	src := productservice.ImportSource{FileName: "supplier.csv", Data: file}
+	preview, err := service.PreviewImport(ctx, src)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.Creates != 1 || preview.Updates != 1 || preview.Errors != 2 {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+	if milk, _ := repo.GetBySKU(ctx, "MILK"); milk.UnitPriceCents != 200 {
+		t.Fatalf("dry run must not change products, got %+v", milk)
+	}
+
+	if summary, err := service.CommitImport(ctx, src); !errors.Is(err, domain.ErrImportAborted) || summary.JobID != 0 {
+		t.Fatalf("expected all-or-nothing import to abort, got %+v (%v)", summary, err)
+	}
+	if _, err := repo.GetBySKU(ctx, "EGGS"); err == nil {
+		t.Fatalf("aborted import must not create products")
+	}
+
+	src.Mode = domain.ImportModePartial
+	summary, err := service.CommitImport(ctx, src)
+	if err == nil || summary.JobID == 0 || summary.Created != 1 || summary.Updated != 1 || len(summary.Errors) != 2 {
+		t.Fatalf("unexpected commit summary %+v (%v)", summary, err)
+	}
+	milk, err := repo.GetBySKU(ctx, "MILK")
+	if err != nil || milk.UnitPriceCents != 225 || milk.Name != "Milk 1L" || milk.CurrentQty != 3 {
+		t.Fatalf("expected saved mapping applied without touching stock, got %+v (%v)", milk, err)
+	}
+}
+
+func TestRollbackImportRestoresProductsAndStock(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "rollback.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	repo := sqlite.NewProductRepository(store.DB())
+	service := productservice.NewService(repo, sqlite.NewSettingsRepository(store.DB()))
+
+	if _, err := service.Create(ctx, domain.CreateInput{Name: "Milk", SKU: "MILK", UnitPriceCents: 200, CurrentQty: 3}); err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	file := []byte("sku,name,unit_price,current_qty\n" +
+		"MILK,Milk 1L,2.50,10\n" +
+		"EGGS,Eggs,3.50,6\n")
+	summary, err := service.CommitImport(ctx, productservice.ImportSource{FileName: "stock.csv", Data: file})
+	if err != nil || summary.Created != 1 || summary.Updated != 1 {
+		t.Fatalf("unexpected import summary %+v (%v)", summary, err)
+	}
+
+	var movements int64
+	if err := store.DB().QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(delta), 0) FROM stock_movements WHERE reason = 'Import' AND ref = ?`, summary.JobNumber,
+	).Scan(&movements); err != nil || movements != 13 {
+		t.Fatalf("expected import stock movements totalling 13, got %d (%v)", movements, err)
+	}
+
+	job, err := service.RollbackImport(ctx, summary.JobID)
+	if err != nil || !job.RolledBack() || len(job.Items) != 2 {
+		t.Fatalf("unexpected rollback result %+v (%v)", job, err)
+	}
+
+	milk, err := repo.GetBySKU(ctx, "MILK")
+	if err != nil || milk.Name != "Milk" || milk.UnitPriceCents != 200 || milk.CurrentQty != 3 {
+		t.Fatalf("expected milk restored, got %+v (%v)", milk, err)
+	}
+	eggs, err := repo.GetBySKU(ctx, "EGGS")
+	if err != nil || !eggs.Archived() || eggs.CurrentQty != 0 {
+		t.Fatalf("expected created product archived with no stock, got %+v (%v)", eggs, err)
+	}
+
+	if _, err := service.RollbackImport(ctx, summary.JobID); !errors.Is(err, domain.ErrImportRolledBack) {
+		t.Fatalf("expected second rollback to fail, got %v", err)
+	}
+}
+
+func TestImportXLSXReadsPercentFormattedTaxRates(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "import.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	repo := sqlite.NewProductRepository(store.DB())
+	service := productservice.NewService(repo, sqlite.NewSettingsRepository(store.DB()))
+
+	// Spreadsheets store a cell shown as 20% as 0.2 under a percent number format.
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range map[string]string{
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+			<cellXfs count="2"><xf numFmtId="0"/><xf numFmtId="10"/></cellXfs></styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+			<row r="1"><c r="A1" t="inlineStr"><is><t>sku</t></is></c><c r="B1" t="inlineStr"><is><t>name</t></is></c><c r="C1" t="inlineStr"><is><t>unit_price</t></is></c><c r="D1" t="inlineStr"><is><t>tax_rate_percent</t></is></c></row>
+			<row r="2"><c r="A2" t="inlineStr"><is><t>LAMP</t></is></c><c r="B2" t="inlineStr"><is><t>Lamp</t></is></c><c r="C2"><v>12</v></c><c r="D2" s="1"><v>0.2</v></c></row>
+			<row r="3"><c r="A3" t="inlineStr"><is><t>BOOK</t></is></c><c r="B3" t="inlineStr"><is><t>Book</t></is></c><c r="C3"><v>8</v></c><c r="D3" s="1"><v>0.055</v></c></row>
+		</sheetData></worksheet>`,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+
+	summary, err := service.CommitImport(ctx, productservice.ImportSource{FileName: "products.xlsx", Data: buf.Bytes()})
+	if err != nil || summary.Created != 2 {
+		t.Fatalf("unexpected import %+v (%v)", summary, err)
+	}
+	for sku, want := range map[string]int64{"LAMP": 2000, "BOOK": 550} {
+		p, err := repo.GetBySKU(ctx, sku)
+		if err != nil || p.TaxRateBasisPoints != want {
+			t.Fatalf("expected %s taxed at %d bp, got %+v (%v)", sku, want, p, err)
+		}
+	}
+}
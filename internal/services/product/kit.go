@@ -0,0 +1,37 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domain "shopmate/internal/domain/product"
+)
+
+// KitComponents lists the components of a kit with their stock on hand.
+func (s *Service) KitComponents(ctx context.Context, kitID int64) ([]domain.KitComponent, error) {
+	if kitID <= 0 {
+		return nil, errors.New("kit id required")
+	}
+	components, err := s.repo.KitComponents(ctx, kitID)
+	if err != nil {
+		return nil, fmt.Errorf("kit components: %w", err)
+	}
+	return components, nil
+}
+
+// SetKitComponents defines the components consumed when a kit is sold. An empty list
+// turns the kit back into an ordinary product.
+func (s *Service) SetKitComponents(ctx context.Context, kitID int64, components []domain.KitComponent) ([]domain.KitComponent, error) {
+	if kitID <= 0 {
+		return nil, errors.New("kit id required")
+	}
+	if err := domain.ValidateKitComponents(kitID, components); err != nil {
+		return nil, fmt.Errorf("validate kit: %w", err)
+	}
+	saved, err := s.repo.SetKitComponents(ctx, kitID, components)
+	if err != nil {
+		return nil, fmt.Errorf("set kit components: %w", err)
+	}
+	return saved, nil
+}
@@ -0,0 +1,99 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "shopmate/internal/domain/product"
+)
+
+// priceSchedulerInterval is how often scheduled price changes are checked.
+const priceSchedulerInterval = time.Minute
+
+// PriceHistory lists applied, scheduled and cancelled prices for a product.
+func (s *Service) PriceHistory(ctx context.Context, productID int64) ([]domain.PriceChange, error) {
+	if productID <= 0 {
+		return nil, errors.New("product id required")
+	}
+	history, err := s.repo.PriceHistory(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("price history: %w", err)
+	}
+	return history, nil
+}
+
+// SchedulePriceChange records a new price, applying it now when its effective time has passed.
+func (s *Service) SchedulePriceChange(ctx context.Context, input domain.PriceChangeInput) (*domain.PriceChange, error) {
+	if err := input.Validate(); err != nil {
+		return nil, fmt.Errorf("validate price change: %w", err)
+	}
+	change, err := s.repo.SchedulePriceChange(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("schedule price change: %w", err)
+	}
+	return change, nil
+}
+
+// CancelPriceChange withdraws a pending price change.
+func (s *Service) CancelPriceChange(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("price change id required")
+	}
+	if err := s.repo.CancelPriceChange(ctx, id); err != nil {
+		return fmt.Errorf("cancel price change: %w", err)
+	}
+	return nil
+}
+
+// ApplyDuePriceChanges applies scheduled prices whose effective time has passed.
+func (s *Service) ApplyDuePriceChanges(ctx context.Context) (int, error) {
+	applied, err := s.repo.ApplyDuePriceChanges(ctx, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("apply price changes: %w", err)
+	}
+	return applied, nil
+}
+
+// StartPriceScheduler applies any overdue price changes and keeps applying them as they fall due.
+func (s *Service) StartPriceScheduler(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.schedulerCancel != nil {
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	s.schedulerCancel = cancel
+
+	go s.priceSchedulerLoop(runCtx)
+}
+
+// StopPriceScheduler stops the background price scheduler if running.
+func (s *Service) StopPriceScheduler() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.schedulerCancel != nil {
+		s.schedulerCancel()
+		s.schedulerCancel = nil
+	}
+}
+
+func (s *Service) priceSchedulerLoop(ctx context.Context) {
+	_, _ = s.ApplyDuePriceChanges(context.Background())
+
+	ticker := time.NewTicker(priceSchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, _ = s.ApplyDuePriceChanges(context.Background())
+		}
+	}
+}
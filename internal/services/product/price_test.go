@@ -0,0 +1,88 @@
+package product_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/product"
+	productservice "shopmate/internal/services/product"
+)
+
+func TestPriceHistoryAndScheduledChanges(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "prices.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	repo := sqlite.NewProductRepository(store.DB())
+	service := productservice.NewService(repo, sqlite.NewSettingsRepository(store.DB()))
+
+	item, err := service.Create(ctx, domain.CreateInput{Name: "Bread", SKU: "BREAD", UnitPriceCents: 300, ChangedBy: "owner"})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	if _, err := service.Update(ctx, item.ID, domain.UpdateInput{Name: "Bread", UnitPriceCents: 350, ChangedBy: "manager", Version: item.Version}); err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+	scheduled, err := service.SchedulePriceChange(ctx, domain.PriceChangeInput{ProductID: item.ID, PriceCents: 400, EffectiveFrom: tomorrow, ChangedBy: "owner"})
+	if err != nil {
+		t.Fatalf("schedule price: %v", err)
+	}
+	if scheduled.Status != domain.PriceStatusScheduled {
+		t.Fatalf("expected scheduled change, got %+v", scheduled)
+	}
+	if applied, err := service.ApplyDuePriceChanges(ctx); err != nil || applied != 0 {
+		t.Fatalf("expected nothing due yet, got %d (%v)", applied, err)
+	}
+
+	if applied, err := repo.ApplyDuePriceChanges(ctx, tomorrow.Add(time.Minute)); err != nil || applied != 1 {
+		t.Fatalf("expected scheduled change applied, got %d (%v)", applied, err)
+	}
+	current, err := repo.GetByID(ctx, item.ID)
+	if err != nil || current.UnitPriceCents != 400 {
+		t.Fatalf("expected price 400, got %+v (%v)", current, err)
+	}
+	if err := service.CancelPriceChange(ctx, scheduled.ID); !errors.Is(err, domain.ErrPriceChangeNotPending) {
+		t.Fatalf("expected applied change to be uncancellable, got %v", err)
+	}
+
+	history, err := service.PriceHistory(ctx, item.ID)
+	if err != nil {
+		t.Fatalf("price history: %v", err)
+	}
+	if len(history) != 3 || history[0].PriceCents != 400 || history[1].ChangedBy != "manager" || history[2].PriceCents != 300 {
+		t.Fatalf("unexpected history %+v", history)
+	}
+
+	// A price set by hand after a scheduled change fell due, but before it was applied,
+	// supersedes the scheduled change.
+	rolls, err := service.Create(ctx, domain.CreateInput{Name: "Rolls", SKU: "ROLLS", UnitPriceCents: 200})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	stale, err := service.SchedulePriceChange(ctx, domain.PriceChangeInput{ProductID: rolls.ID, PriceCents: 250, EffectiveFrom: time.Now().Add(20 * time.Millisecond)})
+	if err != nil {
+		t.Fatalf("schedule price: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := service.Update(ctx, rolls.ID, domain.UpdateInput{Name: "Rolls", UnitPriceCents: 220, ChangedBy: "manager", Version: rolls.Version}); err != nil {
+		t.Fatalf("update product: %v", err)
+	}
+	if applied, err := service.ApplyDuePriceChanges(ctx); err != nil || applied != 0 {
+		t.Fatalf("expected the superseded change skipped, got %d (%v)", applied, err)
+	}
+	if current, err = repo.GetByID(ctx, rolls.ID); err != nil || current.UnitPriceCents != 220 {
+		t.Fatalf("expected the manual price kept, got %+v (%v)", current, err)
+	}
+	if history, err = service.PriceHistory(ctx, rolls.ID); err != nil || len(history) != 3 || history[1].ID != stale.ID || history[1].Status != domain.PriceStatusCancelled {
+		t.Fatalf("expected the superseded change cancelled, got %+v (%v)", history, err)
+	}
+}
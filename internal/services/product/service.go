@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	"shopmate/internal/adapters/storage/sqlite"
 	domain "shopmate/internal/domain/product"
@@ -13,12 +14,22 @@ import (
 
 // Service encapsulates business rules for inventory product management.
 type Service struct {
-	repo *sqlite.ProductRepository
+	repo     *sqlite.ProductRepository
+	settings *sqlite.SettingsRepository
+	observer func(ctx context.Context, productIDs []int64)
+
+	mu              sync.Mutex
+	schedulerCancel context.CancelFunc
 }
 
 // NewService builds a product service instance.
-func NewService(repo *sqlite.ProductRepository) *Service {
-	return &Service{repo: repo}
+func NewService(repo *sqlite.ProductRepository, settings *sqlite.SettingsRepository) *Service {
+	return &Service{repo: repo, settings: settings}
+}
+
+// SetStockObserver registers a callback told which product a manual adjustment changed.
+func (s *Service) SetStockObserver(fn func(ctx context.Context, productIDs []int64)) {
+	s.observer = fn
 }
 
 // Create registers a new product after validation.
@@ -37,9 +48,9 @@ func (s *Service) Create(ctx context.Context, input domain.CreateInput) (*domain
 	return product, nil
 }
 
-// List returns products ordered for UI consumption.
-func (s *Service) List(ctx context.Context) ([]domain.Product, error) {
-	products, err := s.repo.List(ctx)
+// List returns products ordered for UI consumption. Archived products are hidden unless requested.
+func (s *Service) List(ctx context.Context, includeArchived bool) ([]domain.Product, error) {
+	products, err := s.repo.List(ctx, includeArchived)
 	if err != nil {
 		return nil, fmt.Errorf("list products: %w", err)
 	}
@@ -55,10 +66,18 @@ func (s *Service) Update(ctx context.Context, id int64, input domain.UpdateInput
 	return product, nil
 }
 
-// Delete removes a product.
-func (s *Service) Delete(ctx context.Context, id int64) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		return fmt.Errorf("delete product: %w", err)
+// Archive hides a product from sale; its sales history is kept.
+func (s *Service) Archive(ctx context.Context, id int64) error {
+	if err := s.repo.Archive(ctx, id); err != nil {
+		return fmt.Errorf("archive product: %w", err)
+	}
+	return nil
+}
+
+// Unarchive makes an archived product available for sale again.
+func (s *Service) Unarchive(ctx context.Context, id int64) error {
+	if err := s.repo.Unarchive(ctx, id); err != nil {
+		return fmt.Errorf("unarchive product: %w", err)
 	}
 	return nil
 }
@@ -69,52 +88,90 @@ func (s *Service) AdjustStock(ctx context.Context, input domain.AdjustmentInput)
 	if err != nil {
 		return nil, fmt.Errorf("adjust stock: %w", err)
 	}
+	if s.observer != nil {
+		s.observer(ctx, []int64{product.ID})
+	}
 	return product, nil
 }
 
-// ImportSummary captures the result of a bulk CSV import.
+// CostLayers lists the unsold stock a product holds from each receipt.
+func (s *Service) CostLayers(ctx context.Context, productID int64) ([]domain.CostLayer, error) {
+	if productID <= 0 {
+		return nil, errors.New("product id required")
+	}
+	layers, err := s.repo.CostLayers(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("list cost layers: %w", err)
+	}
+	return layers, nil
+}
+
+// Serials lists tracked units for a serialised product.
+func (s *Service) Serials(ctx context.Context, productID int64, status string) ([]domain.Serial, error) {
+	if productID <= 0 {
+		return nil, errors.New("product id required")
+	}
+	serials, err := s.repo.ListSerials(ctx, productID, status)
+	if err != nil {
+		return nil, fmt.Errorf("list serials: %w", err)
+	}
+	return serials, nil
+}
+
+// LookupSerial reports which sale, customer and date a serial number was sold under.
+func (s *Service) LookupSerial(ctx context.Context, serialNo string) ([]domain.SerialLookup, error) {
+	serialNo = strings.TrimSpace(serialNo)
+	if serialNo == "" {
+		return nil, errors.New("serial number required")
+	}
+	results, err := s.repo.LookupSerial(ctx, serialNo)
+	if err != nil {
+		return nil, fmt.Errorf("lookup serial: %w", err)
+	}
+	return results, nil
+}
+
+// ImportSummary captures the result of a bulk CSV import. JobID identifies the import for
+// rollback and is zero when nothing was applied.
 type ImportSummary struct {
-	Created int      `json:"created"`
-	Updated int      `json:"updated"`
-	Errors  []string `json:"errors"`
+	JobID     int64    `json:"jobId"`
+	JobNumber string   `json:"jobNumber"`
+	Created   int      `json:"created"`
+	Updated   int      `json:"updated"`
+	Errors    []string `json:"errors"`
 }
 
-// ImportCSV ingests CSV data following the product contract and upserts rows.
+// ImportCSV ingests CSV data following the product contract and upserts every row as one
+// all-or-nothing import job.
 func (s *Service) ImportCSV(ctx context.Context, data []byte) (ImportSummary, error) {
 	summary := ImportSummary{}
-	rows, err := domain.ParseImportCSV(bytes.NewReader(data))
+	attributes, err := s.repo.ListAttributes(ctx)
 	if err != nil {
 		summary.Errors = append(summary.Errors, err.Error())
-		return summary, err
+		return summary, fmt.Errorf("list attributes: %w", err)
 	}
-
-	for _, row := range rows {
-		_, created, upsertErr := s.repo.Upsert(ctx, row.ToCreateInput())
-		if upsertErr != nil {
-			summary.Errors = append(summary.Errors, fmt.Sprintf("line %d (sku=%s): %v", row.OriginalLine, row.SKU, upsertErr))
-			continue
-		}
-		if created {
-			summary.Created++
-		} else {
-			summary.Updated++
-		}
+	rows, err := domain.ParseImportCSV(bytes.NewReader(data), attributes...)
+	if err != nil {
+		summary.Errors = append(summary.Errors, err.Error())
+		return summary, err
 	}
 
-	if len(summary.Errors) > 0 {
-		return summary, fmt.Errorf("import completed with %d error(s)", len(summary.Errors))
-	}
-	return summary, nil
+	return s.applyImport(ctx, domain.ImportBatch{Mode: domain.ImportModeAllOrNothing, Rows: rows}, summary)
 }
 
-// ExportCSV renders the current inventory to CSV bytes following the contract.
+// ExportCSV renders the current (non-archived) inventory to CSV bytes following the contract,
+// with a column per custom attribute.
 func (s *Service) ExportCSV(ctx context.Context) ([]byte, error) {
-	products, err := s.repo.List(ctx)
+	products, err := s.repo.List(ctx, false)
 	if err != nil {
 		return nil, fmt.Errorf("list products: %w", err)
 	}
+	attributes, err := s.repo.ListAttributes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list attributes: %w", err)
+	}
 	var buf bytes.Buffer
-	if err := domain.WriteExportCSV(&buf, products); err != nil {
+	if err := domain.WriteExportCSV(&buf, products, attributes...); err != nil {
 		return nil, fmt.Errorf("write csv: %w", err)
 	}
 	return buf.Bytes(), nil
@@ -0,0 +1,61 @@
+package product_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/product"
+	productservice "shopmate/internal/services/product"
+)
+
+func TestUpdateRejectsStaleVersion(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "versions.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	service := productservice.NewService(sqlite.NewProductRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+
+	item, err := service.Create(ctx, domain.CreateInput{Name: "Jam", SKU: "JAM", UnitPriceCents: 400, CurrentQty: 5})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	if _, err := service.Update(ctx, item.ID, domain.UpdateInput{Name: "Jam"}); err == nil {
+		t.Fatalf("expected update without a version to fail")
+	}
+
+	adjusted, err := service.AdjustStock(ctx, domain.AdjustmentInput{ProductID: item.ID, Delta: 2, Reason: "Receipt"})
+	if err != nil {
+		t.Fatalf("adjust stock: %v", err)
+	}
+	if adjusted.Version != item.Version {
+		t.Fatalf("stock movements should not change the version (%d -> %d)", item.Version, adjusted.Version)
+	}
+
+	first, err := service.Update(ctx, item.ID, domain.UpdateInput{Name: "Strawberry Jam", UnitPriceCents: 450, Version: item.Version})
+	if err != nil {
+		t.Fatalf("first update: %v", err)
+	}
+	if first.Version <= item.Version {
+		t.Fatalf("expected version to advance, got %d after %d", first.Version, item.Version)
+	}
+
+	_, err = service.Update(ctx, item.ID, domain.UpdateInput{Name: "Jam (old form)", UnitPriceCents: 400, Version: item.Version})
+	var conflict *domain.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+	if conflict.Current.Name != "Strawberry Jam" || conflict.Current.Version != first.Version {
+		t.Fatalf("conflict should carry the current product, got %+v", conflict.Current)
+	}
+
+	if _, err := service.Update(ctx, item.ID, domain.UpdateInput{Name: "Strawberry Jam", UnitPriceCents: 475, Version: conflict.Current.Version}); err != nil {
+		t.Fatalf("update at current version: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/promotion"
+)
+
+// Service manages the promotions and coupons sales are priced with.
+type Service struct {
+	repo *sqlite.PromotionRepository
+	now  func() time.Time
+}
+
+// NewService constructs a promotion service.
+func NewService(repo *sqlite.PromotionRepository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Create adds a promotion.
+func (s *Service) Create(ctx context.Context, input domain.Input) (*domain.Promotion, error) {
+	created, err := s.repo.Create(ctx, input, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("create promotion: %w", err)
+	}
+	return created, nil
+}
+
+// Update replaces a promotion's rule; sales already recorded are not repriced.
+func (s *Service) Update(ctx context.Context, id int64, input domain.Input) (*domain.Promotion, error) {
+	if id <= 0 {
+		return nil, errors.New("promotion id required")
+	}
+	updated, err := s.repo.Update(ctx, id, input, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("update promotion: %w", err)
+	}
+	return updated, nil
+}
+
+// Get retrieves a promotion by id.
+func (s *Service) Get(ctx context.Context, id int64) (*domain.Promotion, error) {
+	if id <= 0 {
+		return nil, errors.New("promotion id required")
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// List returns every promotion, highest priority first.
+func (s *Service) List(ctx context.Context) ([]domain.Promotion, error) {
+	return s.repo.List(ctx)
+}
+
+// Delete removes a promotion. Sales it discounted keep the discount under its name.
+func (s *Service) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("promotion id required")
+	}
+	return s.repo.Delete(ctx, id)
+}
@@ -0,0 +1,177 @@
+package promotion_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	productdomain "shopmate/internal/domain/product"
+	domain "shopmate/internal/domain/promotion"
+	domainsale "shopmate/internal/domain/sale"
+	promotionservice "shopmate/internal/services/promotion"
+	saleservice "shopmate/internal/services/sale"
+)
+
+func TestPromotionsAndCouponsDiscountSales(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "promotions.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	promotionRepo := sqlite.NewPromotionRepository(store.DB())
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+	sales.SetPromotions(promotionRepo)
+	service := promotionservice.NewService(promotionRepo)
+
+	latte, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Latte", SKU: "LAT", Category: "Drinks > Coffee", UnitPriceCents: 500, TaxRateBasisPoints: 1000, CurrentQty: 20})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, CurrentQty: 20})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	categories, err := sqlite.NewCategoryRepository(store.DB()).List(ctx)
+	if err != nil || len(categories) != 2 {
+		t.Fatalf("expected two categories, got %d (%v)", len(categories), err)
+	}
+	drinks := categories[0].ID
+
+	create := func(input domain.Input) *domain.Promotion {
+		t.Helper()
+		p, err := service.Create(ctx, input)
+		if err != nil {
+			t.Fatalf("create promotion %s: %v", input.Name, err)
+		}
+		return p
+	}
+	tenOff := create(domain.Input{Name: "Drinks 10% off", Kind: domain.KindPercentOff, Active: true, CategoryIDs: []int64{drinks}, PercentBasisPoints: 1000})
+	welcome := create(domain.Input{Name: "Welcome", Kind: domain.KindSpendThreshold, Active: true, MinSpendCents: 1000, AmountCents: 200, CouponCode: " welcome ", UsageLimit: 1})
+	if welcome.CouponCode != "WELCOME" || len(tenOff.CategoryIDs) != 1 {
+		t.Fatalf("expected the promotions normalised, got %+v and %+v", welcome, tenOff)
+	}
+	mugs := create(domain.Input{Name: "Mugs half price", Kind: domain.KindPercentOff, Active: true, ProductIDs: []int64{mug.ID}, PercentBasisPoints: 5000, CouponCode: "MUGS"})
+	if _, err := service.Create(ctx, domain.Input{Name: "Copy", Kind: domain.KindPercentOff, PercentBasisPoints: 100, CouponCode: "Welcome"}); err == nil {
+		t.Fatal("expected a duplicate coupon code to be rejected")
+	}
+	if _, err := service.Update(ctx, mugs.ID, domain.Input{Name: "Mugs half price", Kind: domain.KindPercentOff, Active: true, ProductIDs: []int64{mug.ID}, PercentBasisPoints: 5000, CouponCode: "welcome"}); err == nil {
+		t.Fatal("expected an update to another promotion's coupon code to be rejected")
+	}
+	if _, err := service.Update(ctx, mugs.ID, domain.Input{Name: "Mugs half price", Kind: domain.KindPercentOff, Active: true, ProductIDs: []int64{mug.ID}, PercentBasisPoints: 5000, CouponCode: "mugs"}); err != nil {
+		t.Fatalf("expected a promotion to keep its own coupon code: %v", err)
+	}
+
+	sell := func(coupons []string, lines ...saleservice.CreateRequestLine) (*domainsale.Sale, error) {
+		return sales.Create(ctx, saleservice.CreateRequest{PaymentMethod: "Cash", CouponCodes: coupons, Lines: lines})
+	}
+
+	first, err := sell([]string{"welcome"}, saleservice.CreateRequestLine{ProductID: latte.ID, Quantity: 2}, saleservice.CreateRequestLine{ProductID: mug.ID, Quantity: 1})
+	if err != nil {
+		t.Fatalf("sell with coupon: %v", err)
+	}
+	coffee, cup := first.Lines[0], first.Lines[1]
+	if len(coffee.Promotions) != 2 || coffee.Promotions[0].PromotionID != tenOff.ID || coffee.Promotions[0].DiscountCents != 100 || coffee.Promotions[1].CouponCode != "WELCOME" {
+		t.Fatalf("expected the latte discounted by both promotions, got %+v", coffee.Promotions)
+	}
+	if coffee.PromotionDiscountCents+cup.PromotionDiscountCents != 300 || first.DiscountCents != 300 {
+		t.Fatalf("expected 300 off the sale, got %d+%d (%d)", coffee.PromotionDiscountCents, cup.PromotionDiscountCents, first.DiscountCents)
+	}
+	if want := (1000 - coffee.PromotionDiscountCents + 5) / 10; coffee.LineTaxCents != want {
+		t.Fatalf("expected tax on the discounted latte of %d, got %d", want, coffee.LineTaxCents)
+	}
+	if first.TotalCents != 2000-300+first.TaxCents {
+		t.Fatalf("unexpected total %d", first.TotalCents)
+	}
+	stored, err := sales.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("get sale: %v", err)
+	}
+	if len(stored.Lines[0].Promotions) != 2 || stored.Lines[0].PromotionDiscountCents != coffee.PromotionDiscountCents {
+		t.Fatalf("expected the line promotions stored, got %+v", stored.Lines[0])
+	}
+
+	if _, err := sell([]string{"WELCOME"}, saleservice.CreateRequestLine{ProductID: mug.ID, Quantity: 2}); err == nil || !strings.Contains(err.Error(), "used up") {
+		t.Fatalf("expected the coupon used up, got %v", err)
+	}
+	if _, err := sell([]string{"NOPE"}, saleservice.CreateRequestLine{ProductID: mug.ID, Quantity: 1}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected an unknown coupon to fail, got %v", err)
+	}
+	if _, err := sell([]string{"MUGS"}, saleservice.CreateRequestLine{ProductID: latte.ID, Quantity: 1}); err == nil || !strings.Contains(err.Error(), "does not apply") {
+		t.Fatalf("expected a coupon for other products to fail, got %v", err)
+	}
+
+	manual, err := sell(nil, saleservice.CreateRequestLine{ProductID: latte.ID, Quantity: 1, DiscountCents: 50})
+	if err != nil {
+		t.Fatalf("sell with manual discount: %v", err)
+	}
+	if manual.Lines[0].PromotionDiscountCents != 0 || manual.DiscountCents != 50 {
+		t.Fatalf("expected a manually discounted line left alone, got %+v", manual.Lines[0])
+	}
+
+	// Voiding the sale gives the coupon's use back.
+	if err := sales.Void(ctx, first.ID, "till error"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	second, err := sell([]string{"WELCOME"}, saleservice.CreateRequestLine{ProductID: mug.ID, Quantity: 2})
+	if err != nil {
+		t.Fatalf("reuse coupon after void: %v", err)
+	}
+	if second.DiscountCents != 200 {
+		t.Fatalf("expected 200 off, got %d", second.DiscountCents)
+	}
+
+	refund, err := sales.CreateRefund(ctx, saleservice.RefundRequest{
+		SaleID: second.ID,
+		Lines:  []saleservice.RefundRequestLine{{SaleLineID: second.Lines[0].ID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if refund.TotalCents != second.TotalCents {
+		t.Fatalf("expected the refund to return %d, got %d", second.TotalCents, refund.TotalCents)
+	}
+
+	// Quotes are priced without promotions.
+	quoted, err := sales.Price(ctx, 0, []saleservice.CreateRequestLine{{ProductID: latte.ID, Quantity: 2}}, 0)
+	if err != nil {
+		t.Fatalf("price: %v", err)
+	}
+	if quoted.DiscountCents != 0 {
+		t.Fatalf("expected no promotions on a quote, got %d", quoted.DiscountCents)
+	}
+
+	totals, err := sqlite.NewReportRepository(store.DB()).Promotions(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("promotion report: %v", err)
+	}
+	if len(totals) != 1 || totals[0].PromotionID != welcome.ID || totals[0].SaleCount != 1 || totals[0].DiscountCents != 200 {
+		t.Fatalf("expected only the coupon on the sale not voided, got %+v", totals)
+	}
+
+	if _, err := service.Update(ctx, tenOff.ID, domain.Input{Name: "Drinks 10% off", Kind: domain.KindPercentOff, CategoryIDs: []int64{drinks}, PercentBasisPoints: 1000}); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+	plain, err := sell(nil, saleservice.CreateRequestLine{ProductID: latte.ID, Quantity: 1})
+	if err != nil {
+		t.Fatalf("sell: %v", err)
+	}
+	if plain.DiscountCents != 0 {
+		t.Fatalf("expected an inactive promotion not to apply, got %d", plain.DiscountCents)
+	}
+	if err := service.Delete(ctx, welcome.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	kept, err := sales.Get(ctx, second.ID)
+	if err != nil {
+		t.Fatalf("get sale: %v", err)
+	}
+	if kept.Lines[0].Promotions[0].Name != "Welcome" || kept.Lines[0].Promotions[0].PromotionID != 0 {
+		t.Fatalf("expected the sale to keep the deleted promotion's name, got %+v", kept.Lines[0].Promotions)
+	}
+}
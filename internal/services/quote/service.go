@@ -0,0 +1,237 @@
+package quote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/quote"
+	domainsale "shopmate/internal/domain/sale"
+	saleservice "shopmate/internal/services/sale"
+)
+
+// Service issues quotes priced like sales and converts accepted quotes into sales.
+type Service struct {
+	repo     *sqlite.QuoteRepository
+	sales    *saleservice.Service
+	now      func() time.Time
+	observer func(ctx context.Context, productIDs []int64)
+}
+
+// NewService constructs a quote service. Quotes are priced and sold through sales.
+func NewService(repo *sqlite.QuoteRepository, sales *saleservice.Service) *Service {
+	return &Service{repo: repo, sales: sales, now: time.Now}
+}
+
+// SetStockObserver registers a callback told which products' stock a converted quote
+// changed.
+func (s *Service) SetStockObserver(fn func(ctx context.Context, productIDs []int64)) {
+	s.observer = fn
+}
+
+// RequestLine is a product to quote.
+type RequestLine struct {
+	ProductID     int64
+	Quantity      int64
+	DiscountCents int64
+}
+
+// Request is the content of a quote. A zero ValidDays keeps the quote valid for
+// domain.DefaultValidityDays. A quote for a customer record sets CustomerID.
+type Request struct {
+	CustomerID    int64
+	CustomerName  string
+	ValidDays     int
+	DiscountCents int64
+	Note          string
+	Lines         []RequestLine
+}
+
+// Create prices a quote at current product prices and saves it as a draft.
+func (s *Service) Create(ctx context.Context, req Request) (*domain.Quote, error) {
+	now := s.now()
+	draft, err := s.draft(ctx, req, now)
+	if err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Create(ctx, *draft, now)
+	if err != nil {
+		return nil, fmt.Errorf("create quote: %w", err)
+	}
+	return created, nil
+}
+
+// Update re-prices a draft quote with new content. Its validity runs from now.
+func (s *Service) Update(ctx context.Context, id int64, req Request) (*domain.Quote, error) {
+	if id <= 0 {
+		return nil, errors.New("quote id required")
+	}
+	draft, err := s.draft(ctx, req, s.now())
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.repo.Update(ctx, id, *draft)
+	if err != nil {
+		return nil, fmt.Errorf("update quote: %w", err)
+	}
+	return updated, nil
+}
+
+// draft prices req with the sale pricing rules.
+func (s *Service) draft(ctx context.Context, req Request, now time.Time) (*domain.Draft, error) {
+	validUntil, err := domain.ValidUntil(now, req.ValidDays)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]saleservice.CreateRequestLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, saleservice.CreateRequestLine{
+			ProductID:     line.ProductID,
+			Quantity:      line.Quantity,
+			DiscountCents: line.DiscountCents,
+		})
+	}
+	priced, err := s.sales.Price(ctx, req.CustomerID, lines, req.DiscountCents)
+	if err != nil {
+		return nil, err
+	}
+
+	draft := &domain.Draft{
+		CustomerID:    req.CustomerID,
+		CustomerName:  req.CustomerName,
+		ValidUntil:    validUntil,
+		SubtotalCents: priced.SubtotalCents,
+		DiscountCents: priced.DiscountCents,
+		TaxCents:      priced.TaxCents,
+		TotalCents:    priced.TotalCents,
+		Note:          req.Note,
+	}
+	for _, line := range priced.Lines {
+		draft.Lines = append(draft.Lines, domain.Line{
+			ProductID:          line.ProductID,
+			ProductName:        line.ProductName,
+			SKU:                line.SKU,
+			Quantity:           line.Quantity,
+			UnitPriceCents:     line.UnitPriceCents,
+			TaxRateBasisPoints: line.TaxRateBasisPoints,
+			LineSubtotalCents:  line.LineSubtotalCents,
+			LineDiscountCents:  line.LineDiscountCents,
+			LineTaxCents:       line.LineTaxCents,
+			LineTotalCents:     line.LineTotalCents,
+		})
+	}
+	return draft, nil
+}
+
+// List returns quotes newest first, optionally only those with status. Lapsed quotes are
+// expired first.
+func (s *Service) List(ctx context.Context, status string) ([]domain.Quote, error) {
+	if _, err := s.ExpireStale(ctx); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, status)
+}
+
+// Get retrieves a quote by id.
+func (s *Service) Get(ctx context.Context, id int64) (*domain.Quote, error) {
+	if id <= 0 {
+		return nil, errors.New("quote id required")
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// MarkSent records that a quote was sent to the customer, after which it can no longer
+// be edited.
+func (s *Service) MarkSent(ctx context.Context, id int64) (*domain.Quote, error) {
+	if id <= 0 {
+		return nil, errors.New("quote id required")
+	}
+	return s.repo.MarkSent(ctx, id, s.now())
+}
+
+// ConvertRequest turns a quote into a sale. With HonourPrices the quoted unit prices and
+// discounts are kept; otherwise the lines are re-priced at current prices. Tax is charged
+// at current rates either way. SerialNumbers lists the units sold for serialised lines,
+// keyed by quote line id. Payment works as for a new sale.
+type ConvertRequest struct {
+	QuoteID       int64
+	HonourPrices  bool
+	PaymentMethod string
+	Payments      []saleservice.CreatePaymentRequest
+	LocationID    int64
+	SerialNumbers map[int64][]string
+	Note          string
+}
+
+// Convert sells an open quote to its customer and marks it Accepted.
+func (s *Service) Convert(ctx context.Context, req ConvertRequest) (*domainsale.Sale, error) {
+	if req.QuoteID <= 0 {
+		return nil, errors.New("quote id required")
+	}
+	q, err := s.repo.Get(ctx, req.QuoteID)
+	if err != nil {
+		return nil, err
+	}
+	if !q.Open() {
+		return nil, fmt.Errorf("quote %s is %s and can no longer be converted", q.QuoteNumber, q.Status)
+	}
+
+	saleReq := saleservice.CreateRequest{
+		CustomerID:    q.CustomerID,
+		CustomerName:  q.CustomerName,
+		PaymentMethod: req.PaymentMethod,
+		Payments:      req.Payments,
+		LocationID:    req.LocationID,
+		DiscountCents: q.OrderDiscountCents(),
+		Note:          req.Note,
+	}
+	if saleReq.Note == "" {
+		saleReq.Note = "Quote " + q.QuoteNumber
+	}
+	for _, line := range q.Lines {
+		saleLine := saleservice.CreateRequestLine{
+			ProductID:     line.ProductID,
+			Quantity:      line.Quantity,
+			DiscountCents: line.LineDiscountCents,
+			SerialNumbers: req.SerialNumbers[line.ID],
+		}
+		if req.HonourPrices {
+			unitPrice := line.UnitPriceCents
+			saleLine.UnitPriceCents = &unitPrice
+		}
+		saleReq.Lines = append(saleReq.Lines, saleLine)
+	}
+
+	draft, err := s.sales.Prepare(ctx, saleReq)
+	if err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Convert(ctx, q.ID, *draft, s.now())
+	if err != nil {
+		return nil, err
+	}
+	s.notifyStock(ctx, created)
+	return created, nil
+}
+
+// ExpireStale marks open quotes past their validity date as expired.
+func (s *Service) ExpireStale(ctx context.Context) (int, error) {
+	expired, err := s.repo.ExpireDue(ctx, s.now())
+	if err != nil {
+		return 0, fmt.Errorf("expire quotes: %w", err)
+	}
+	return expired, nil
+}
+
+func (s *Service) notifyStock(ctx context.Context, sale *domainsale.Sale) {
+	if s.observer == nil {
+		return
+	}
+	ids := make([]int64, 0, len(sale.Lines))
+	for _, line := range sale.Lines {
+		ids = append(ids, line.ProductID)
+	}
+	s.observer(ctx, ids)
+}
@@ -0,0 +1,234 @@
+package quote_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domaincustomer "shopmate/internal/domain/customer"
+	productdomain "shopmate/internal/domain/product"
+	domain "shopmate/internal/domain/quote"
+	domaintax "shopmate/internal/domain/tax"
+	"shopmate/internal/services/invoice"
+	quoteservice "shopmate/internal/services/quote"
+	saleservice "shopmate/internal/services/sale"
+)
+
+func TestQuoteConvertsIntoSaleAtQuotedOrCurrentPrices(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "quotes.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	saleRepo := sqlite.NewSaleRepository(store.DB())
+	quoteRepo := sqlite.NewQuoteRepository(store.DB())
+	sales := saleservice.NewService(productRepo, saleRepo, settingsRepo)
+	service := quoteservice.NewService(quoteRepo, sales)
+
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, TaxRateBasisPoints: 1000, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	quoted, err := service.Create(ctx, quoteservice.Request{
+		CustomerName: "Trade Co",
+		Lines:        []quoteservice.RequestLine{{ProductID: mug.ID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create quote: %v", err)
+	}
+	if !strings.HasPrefix(quoted.QuoteNumber, "QT-") || quoted.Status != domain.StatusDraft || quoted.TotalCents != 2200 {
+		t.Fatalf("unexpected quote %+v", quoted)
+	}
+	if days := quoted.ValidUntil.Sub(quoted.CreatedAt).Hours() / 24; days != domain.DefaultValidityDays {
+		t.Fatalf("expected default validity, got %.1f days", days)
+	}
+
+	sent, err := service.MarkSent(ctx, quoted.ID)
+	if err != nil || sent.Status != domain.StatusSent || sent.SentAt == nil {
+		t.Fatalf("mark sent: %+v (%v)", sent, err)
+	}
+	if _, err := service.Update(ctx, quoted.ID, quoteservice.Request{Lines: []quoteservice.RequestLine{{ProductID: mug.ID, Quantity: 5}}}); err == nil {
+		t.Fatal("expected a sent quote to be read-only")
+	}
+
+	if _, err := productRepo.Update(ctx, mug.ID, productdomain.UpdateInput{Name: "Mug", UnitPriceCents: 1200, TaxRateBasisPoints: 1000, Version: mug.Version}); err != nil {
+		t.Fatalf("raise price: %v", err)
+	}
+
+	honoured, err := service.Convert(ctx, quoteservice.ConvertRequest{QuoteID: quoted.ID, HonourPrices: true, PaymentMethod: "Card"})
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if honoured.TotalCents != 2200 || honoured.CustomerName != "Trade Co" || honoured.Lines[0].UnitPriceCents != 1000 {
+		t.Fatalf("expected quoted prices honoured, got %+v", honoured)
+	}
+	accepted, _ := service.Get(ctx, quoted.ID)
+	if accepted.Status != domain.StatusAccepted || accepted.SaleID != honoured.ID {
+		t.Fatalf("expected quote accepted and linked, got %+v", accepted)
+	}
+	if _, err := service.Convert(ctx, quoteservice.ConvertRequest{QuoteID: quoted.ID, PaymentMethod: "Card"}); err == nil {
+		t.Fatal("expected a quote to convert only once")
+	}
+	if stocked, _ := productRepo.GetByID(ctx, mug.ID); stocked.CurrentQty != 8 {
+		t.Fatalf("expected conversion to take stock, have %d", stocked.CurrentQty)
+	}
+
+	requote, err := service.Create(ctx, quoteservice.Request{Lines: []quoteservice.RequestLine{{ProductID: mug.ID, Quantity: 1}}, ValidDays: 7})
+	if err != nil {
+		t.Fatalf("create quote: %v", err)
+	}
+	repriced, err := service.Convert(ctx, quoteservice.ConvertRequest{QuoteID: requote.ID, PaymentMethod: "Cash"})
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if repriced.TotalCents != 1320 {
+		t.Fatalf("expected re-pricing at the current price, got %d", repriced.TotalCents)
+	}
+
+	lapsing, err := service.Create(ctx, quoteservice.Request{Lines: []quoteservice.RequestLine{{ProductID: mug.ID, Quantity: 1}}, ValidDays: 1})
+	if err != nil {
+		t.Fatalf("create quote: %v", err)
+	}
+	if expired, err := quoteRepo.ExpireDue(ctx, time.Now().Add(48*time.Hour)); err != nil || expired != 1 {
+		t.Fatalf("expected one quote expired, got %d (%v)", expired, err)
+	}
+	if _, err := service.Convert(ctx, quoteservice.ConvertRequest{QuoteID: lapsing.ID, PaymentMethod: "Cash"}); err == nil {
+		t.Fatal("expected an expired quote not to convert")
+	}
+
+	invoices, err := invoice.NewService(saleRepo, quoteRepo, settingsRepo)
+	if err != nil {
+		t.Fatalf("invoice service: %v", err)
+	}
+	html, err := invoices.QuoteHTML(ctx, quoted.ID)
+	if err != nil {
+		t.Fatalf("quote html: %v", err)
+	}
+	if !strings.Contains(html, quoted.QuoteNumber) || !strings.Contains(html, "Valid until") {
+		t.Fatalf("expected quote number and validity in html")
+	}
+	if pdf, err := invoices.QuotePDF(ctx, quoted.ID); err != nil || !strings.HasPrefix(string(pdf), "%PDF") {
+		t.Fatalf("quote pdf: %v", err)
+	}
+}
+
+func TestQuotePricesDiscountsAndExemptionsLikeTheSale(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "quotes.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+	sales.SetTaxes(sqlite.NewTaxRepository(store.DB()))
+	service := quoteservice.NewService(sqlite.NewQuoteRepository(store.DB()), sales)
+
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, TaxRateBasisPoints: 1000, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	charity, err := sqlite.NewCustomerRepository(store.DB()).Create(ctx, domaincustomer.Input{Name: "Charity Shop", TaxExempt: true}, time.Now())
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	request := func(customerID int64) quoteservice.Request {
+		return quoteservice.Request{
+			CustomerID:    customerID,
+			DiscountCents: 300,
+			Lines:         []quoteservice.RequestLine{{ProductID: mug.ID, Quantity: 2, DiscountCents: 200}},
+		}
+	}
+
+	// 2000 less 200 off the line and 300 off the order leaves 1500 to tax.
+	taxed, err := service.Create(ctx, request(0))
+	if err != nil {
+		t.Fatalf("create quote: %v", err)
+	}
+	if taxed.DiscountCents != 500 || taxed.TaxCents != 150 || taxed.TotalCents != 1650 || taxed.OrderDiscountCents() != 300 {
+		t.Fatalf("unexpected quote %+v", taxed)
+	}
+	exempt, err := service.Create(ctx, request(charity.ID))
+	if err != nil {
+		t.Fatalf("create exempt quote: %v", err)
+	}
+	if exempt.TaxCents != 0 || exempt.TotalCents != 1500 {
+		t.Fatalf("expected the exemption applied to the quote, got %+v", exempt)
+	}
+
+	for _, q := range []*domain.Quote{taxed, exempt} {
+		sold, err := service.Convert(ctx, quoteservice.ConvertRequest{QuoteID: q.ID, PaymentMethod: "Card"})
+		if err != nil {
+			t.Fatalf("convert: %v", err)
+		}
+		if sold.TotalCents != q.TotalCents || sold.DiscountCents != q.DiscountCents || sold.TaxCents != q.TaxCents {
+			t.Fatalf("expected the sale to match quote %+v, got %+v", q, sold)
+		}
+	}
+}
+
+func TestHonouredQuoteKeepsItsPricesForExemptCustomers(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "quotes.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), settingsRepo)
+	sales.SetTaxes(sqlite.NewTaxRepository(store.DB()))
+	service := quoteservice.NewService(sqlite.NewQuoteRepository(store.DB()), sales)
+
+	if err := settingsRepo.SaveTaxPolicy(ctx, domaintax.Policy{PricesIncludeTax: true}); err != nil {
+		t.Fatalf("save tax policy: %v", err)
+	}
+	lamp, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Lamp", SKU: "LAMP", UnitPriceCents: 1100, TaxRateBasisPoints: 1000, CurrentQty: 5})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	sample, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Sample", SKU: "SAMPLE", TaxRateBasisPoints: 1000, CurrentQty: 5})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	charity, err := sqlite.NewCustomerRepository(store.DB()).Create(ctx, domaincustomer.Input{Name: "Charity Shop", TaxExempt: true}, time.Now())
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+
+	// The exempt customer is quoted the shelf price less the tax it includes, and the
+	// sample free.
+	quoted, err := service.Create(ctx, quoteservice.Request{
+		CustomerID: charity.ID,
+		Lines:      []quoteservice.RequestLine{{ProductID: lamp.ID, Quantity: 1}, {ProductID: sample.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create quote: %v", err)
+	}
+	if quoted.TotalCents != 1000 || quoted.TaxCents != 0 || quoted.Lines[0].UnitPriceCents != 1000 {
+		t.Fatalf("unexpected quote %+v", quoted)
+	}
+
+	for _, p := range []*productdomain.Product{lamp, sample} {
+		if _, err := productRepo.Update(ctx, p.ID, productdomain.UpdateInput{Name: p.Name, UnitPriceCents: 1320, TaxRateBasisPoints: 1000, Version: p.Version}); err != nil {
+			t.Fatalf("raise price: %v", err)
+		}
+	}
+	honoured, err := service.Convert(ctx, quoteservice.ConvertRequest{QuoteID: quoted.ID, HonourPrices: true, PaymentMethod: "Card"})
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if honoured.TotalCents != 1000 || honoured.TaxCents != 0 || honoured.Lines[0].UnitPriceCents != 1000 || honoured.Lines[1].UnitPriceCents != 0 {
+		t.Fatalf("expected the quoted prices kept as quoted, got %+v", honoured)
+	}
+}
@@ -0,0 +1,164 @@
+package replenishment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/purchasing"
+)
+
+const defaultOrderLimit = 100
+
+// Service suggests reorders from sales velocity and drafts purchase orders.
+type Service struct {
+	repo     *sqlite.PurchasingRepository
+	settings *sqlite.SettingsRepository
+	now      func() time.Time
+}
+
+// NewService constructs a replenishment service.
+func NewService(repo *sqlite.PurchasingRepository, settings *sqlite.SettingsRepository) *Service {
+	return &Service{repo: repo, settings: settings, now: time.Now}
+}
+
+// Suppliers lists suppliers.
+func (s *Service) Suppliers(ctx context.Context) ([]domain.Supplier, error) {
+	suppliers, err := s.repo.ListSuppliers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list suppliers: %w", err)
+	}
+	return suppliers, nil
+}
+
+// CreateSupplier adds a supplier.
+func (s *Service) CreateSupplier(ctx context.Context, input domain.SupplierInput) (*domain.Supplier, error) {
+	if err := input.Validate(); err != nil {
+		return nil, fmt.Errorf("validate supplier: %w", err)
+	}
+	supplier, err := s.repo.CreateSupplier(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("create supplier: %w", err)
+	}
+	return supplier, nil
+}
+
+// UpdateSupplier modifies a supplier.
+func (s *Service) UpdateSupplier(ctx context.Context, id int64, input domain.SupplierInput) (*domain.Supplier, error) {
+	if id <= 0 {
+		return nil, errors.New("supplier id required")
+	}
+	supplier, err := s.repo.UpdateSupplier(ctx, id, input)
+	if err != nil {
+		return nil, fmt.Errorf("update supplier: %w", err)
+	}
+	return supplier, nil
+}
+
+// AssignSupplier links a product to its supplier with an optional lead-time override.
+func (s *Service) AssignSupplier(ctx context.Context, productID, supplierID, leadTimeDays int64) error {
+	if productID <= 0 {
+		return errors.New("product id required")
+	}
+	if err := s.repo.AssignSupplier(ctx, productID, supplierID, leadTimeDays); err != nil {
+		return fmt.Errorf("assign supplier: %w", err)
+	}
+	return nil
+}
+
+// Policy returns the suggestion policy.
+func (s *Service) Policy(ctx context.Context) (domain.Policy, error) {
+	return s.settings.LoadReplenishmentPolicy(ctx)
+}
+
+// SavePolicy stores the suggestion policy.
+func (s *Service) SavePolicy(ctx context.Context, policy domain.Policy) (domain.Policy, error) {
+	if err := s.settings.SaveReplenishmentPolicy(ctx, policy); err != nil {
+		return domain.Policy{}, err
+	}
+	return s.settings.LoadReplenishmentPolicy(ctx)
+}
+
+// Suggestions returns reorder suggestions grouped by supplier. A non-zero supplierID
+// limits the result to that supplier.
+func (s *Service) Suggestions(ctx context.Context, supplierID int64) ([]domain.SupplierSuggestions, error) {
+	policy, err := s.settings.LoadReplenishmentPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load policy: %w", err)
+	}
+
+	since := s.now().Add(-time.Duration(policy.WindowDays) * 24 * time.Hour)
+	demand, err := s.repo.Demand(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("load demand: %w", err)
+	}
+
+	groups := make([]domain.SupplierSuggestions, 0)
+	index := make(map[int64]int)
+	for _, d := range demand {
+		if supplierID > 0 && d.SupplierID != supplierID {
+			continue
+		}
+		suggestion, ok := domain.Suggest(d, policy)
+		if !ok {
+			continue
+		}
+		i, seen := index[d.SupplierID]
+		if !seen {
+			i = len(groups)
+			index[d.SupplierID] = i
+			groups = append(groups, domain.SupplierSuggestions{SupplierID: d.SupplierID, SupplierName: d.SupplierName})
+		}
+		groups[i].Suggestions = append(groups[i].Suggestions, suggestion)
+	}
+	return groups, nil
+}
+
+// CreateDraftOrders drafts purchase orders for explicit lines, one per supplier.
+func (s *Service) CreateDraftOrders(ctx context.Context, lines []domain.DraftLine, note string) ([]domain.PurchaseOrder, error) {
+	orders, err := s.repo.CreateDraftOrders(ctx, lines, note)
+	if err != nil {
+		return nil, fmt.Errorf("create draft orders: %w", err)
+	}
+	return orders, nil
+}
+
+// DraftFromSuggestions turns the current suggestions into draft purchase orders.
+func (s *Service) DraftFromSuggestions(ctx context.Context, supplierID int64) ([]domain.PurchaseOrder, error) {
+	groups, err := s.Suggestions(ctx, supplierID)
+	if err != nil {
+		return nil, err
+	}
+	var lines []domain.DraftLine
+	for _, group := range groups {
+		for _, suggestion := range group.Suggestions {
+			lines = append(lines, domain.DraftLine{ProductID: suggestion.ProductID, Quantity: suggestion.SuggestedQty})
+		}
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("no reorder suggestions")
+	}
+	return s.CreateDraftOrders(ctx, lines, "Generated from reorder suggestions")
+}
+
+// Orders lists purchase orders filtered by status.
+func (s *Service) Orders(ctx context.Context, status string, limit int) ([]domain.PurchaseOrder, error) {
+	if limit <= 0 {
+		limit = defaultOrderLimit
+	}
+	orders, err := s.repo.ListOrders(ctx, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list purchase orders: %w", err)
+	}
+	return orders, nil
+}
+
+// Order loads a purchase order with its lines.
+func (s *Service) Order(ctx context.Context, id int64) (*domain.PurchaseOrder, error) {
+	if id <= 0 {
+		return nil, errors.New("purchase order id required")
+	}
+	return s.repo.GetOrder(ctx, id)
+}
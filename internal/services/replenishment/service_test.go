@@ -0,0 +1,173 @@
+package replenishment_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	productdomain "shopmate/internal/domain/product"
+	domain "shopmate/internal/domain/purchasing"
+	domainsale "shopmate/internal/domain/sale"
+	"shopmate/internal/services/replenishment"
+	saleservice "shopmate/internal/services/sale"
+)
+
+func TestDemandAndDraftOrdersBySupplier(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "replenishment.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	purchasingRepo := sqlite.NewPurchasingRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), settingsRepo)
+	service := replenishment.NewService(purchasingRepo, settingsRepo)
+
+	acme, err := service.CreateSupplier(ctx, domain.SupplierInput{Name: "Acme", LeadTimeDays: 5})
+	if err != nil {
+		t.Fatalf("create supplier: %v", err)
+	}
+	beta, err := service.CreateSupplier(ctx, domain.SupplierInput{Name: "Beta"})
+	if err != nil {
+		t.Fatalf("create supplier: %v", err)
+	}
+	create := func(input productdomain.CreateInput) *productdomain.Product {
+		t.Helper()
+		p, err := productRepo.Create(ctx, input)
+		if err != nil {
+			t.Fatalf("create %s: %v", input.SKU, err)
+		}
+		return p
+	}
+	cup := create(productdomain.CreateInput{Name: "Cup", SKU: "CUP", UnitPriceCents: 300, CurrentQty: 50})
+	mug := create(productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, CurrentQty: 8})
+	tea := create(productdomain.CreateInput{Name: "Tea", SKU: "TEA", UnitPriceCents: 300, CurrentQty: 4})
+	scarf := create(productdomain.CreateInput{Name: "Scarf", SKU: "SCARF", UnitPriceCents: 2500, CurrentQty: 2, ReorderLevel: 5})
+	gift := create(productdomain.CreateInput{Name: "Gift Set", SKU: "GIFT", UnitPriceCents: 2500})
+	if _, err := productRepo.SetKitComponents(ctx, gift.ID, []productdomain.KitComponent{{ComponentID: mug.ID, Quantity: 2}}); err != nil {
+		t.Fatalf("set kit components: %v", err)
+	}
+	for _, assign := range []struct{ product, supplier, lead int64 }{
+		{cup.ID, acme.ID, 0},
+		{mug.ID, acme.ID, 10},
+		{tea.ID, beta.ID, 0},
+	} {
+		if err := service.AssignSupplier(ctx, assign.product, assign.supplier, assign.lead); err != nil {
+			t.Fatalf("assign supplier: %v", err)
+		}
+	}
+
+	sell := func(lines ...saleservice.CreateRequestLine) *domainsale.Sale {
+		t.Helper()
+		created, err := sales.Create(ctx, saleservice.CreateRequest{PaymentMethod: "Cash", Lines: lines})
+		if err != nil {
+			t.Fatalf("sell: %v", err)
+		}
+		return created
+	}
+	refunded := sell(saleservice.CreateRequestLine{ProductID: mug.ID, Quantity: 6}, saleservice.CreateRequestLine{ProductID: tea.ID, Quantity: 3})
+	if _, err := sales.CreateRefund(ctx, saleservice.RefundRequest{
+		SaleID: refunded.ID,
+		Lines:  []saleservice.RefundRequestLine{{SaleLineID: refunded.Lines[0].ID, Quantity: 2}},
+	}); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	sell(saleservice.CreateRequestLine{ProductID: gift.ID, Quantity: 1})
+	voided := sell(saleservice.CreateRequestLine{ProductID: mug.ID, Quantity: 1})
+	if err := sales.Void(ctx, voided.ID, "till error"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+
+	// Mugs: six sold less two refunded, plus two inside the gift set; the voided sale
+	// does not count. Kits are left out, and the mug's own lead time beats Acme's.
+	demand, err := purchasingRepo.Demand(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("demand: %v", err)
+	}
+	want := []domain.Demand{
+		{ProductID: scarf.ID, ProductName: "Scarf", SKU: "SCARF", CurrentQty: 2, ReorderLevel: 5},
+		{ProductID: cup.ID, ProductName: "Cup", SKU: "CUP", SupplierID: acme.ID, SupplierName: "Acme", CurrentQty: 50, LeadTimeDays: 5},
+		{ProductID: mug.ID, ProductName: "Mug", SKU: "MUG", SupplierID: acme.ID, SupplierName: "Acme", CurrentQty: 2, LeadTimeDays: 10, UnitsSold: 6},
+		{ProductID: tea.ID, ProductName: "Tea", SKU: "TEA", SupplierID: beta.ID, SupplierName: "Beta", CurrentQty: 1, UnitsSold: 3},
+	}
+	if len(demand) != len(want) {
+		t.Fatalf("expected %d products in demand, got %+v", len(want), demand)
+	}
+	for i := range want {
+		if demand[i] != want[i] {
+			t.Fatalf("demand %d = %+v want %+v", i, demand[i], want[i])
+		}
+	}
+	if later, err := purchasingRepo.Demand(ctx, time.Now().Add(time.Hour)); err != nil || later[2].UnitsSold != 0 {
+		t.Fatalf("expected sales before the window ignored, got %+v (%v)", later, err)
+	}
+
+	// Over ten days the mug sells 0.6 a day: 6 cover its 10-day lead time and 6 more its
+	// cover days. Tea falls back to the default lead time; the scarf to its reorder level.
+	if policy, err := service.Policy(ctx); err != nil || policy != domain.DefaultPolicy() {
+		t.Fatalf("expected the default policy before one is saved, got %+v (%v)", policy, err)
+	}
+	if policy, err := service.SavePolicy(ctx, domain.Policy{WindowDays: 10, DefaultLeadTimeDays: 0}); err != nil || policy.CoverDays != 0 || policy.DefaultLeadTimeDays != 0 {
+		t.Fatalf("expected zero cover and lead time days kept, got %+v (%v)", policy, err)
+	}
+	if _, err := service.SavePolicy(ctx, domain.Policy{WindowDays: 10, CoverDays: 10, DefaultLeadTimeDays: 7}); err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+	orders, err := service.DraftFromSuggestions(ctx, 0)
+	if err != nil {
+		t.Fatalf("draft from suggestions: %v", err)
+	}
+	type wantOrder struct {
+		supplierID int64
+		lines      []domain.OrderLine
+	}
+	checkOrders := func(orders []domain.PurchaseOrder, want []wantOrder) {
+		t.Helper()
+		if len(orders) != len(want) {
+			t.Fatalf("expected %d orders, got %+v", len(want), orders)
+		}
+		for i, order := range orders {
+			if order.Status != domain.StatusDraft || order.SupplierID != want[i].supplierID || len(order.Lines) != len(want[i].lines) {
+				t.Fatalf("order %d = %+v want supplier %d with %d lines", i, order, want[i].supplierID, len(want[i].lines))
+			}
+			for j, line := range order.Lines {
+				if line != want[i].lines[j] {
+					t.Fatalf("order %d line %d = %+v want %+v", i, j, line, want[i].lines[j])
+				}
+			}
+		}
+	}
+	checkOrders(orders, []wantOrder{
+		{0, []domain.OrderLine{{ProductID: scarf.ID, ProductName: "Scarf", SKU: "SCARF", Quantity: 3}}},
+		{acme.ID, []domain.OrderLine{{ProductID: mug.ID, ProductName: "Mug", SKU: "MUG", Quantity: 10}}},
+		{beta.ID, []domain.OrderLine{{ProductID: tea.ID, ProductName: "Tea", SKU: "TEA", Quantity: 5}}},
+	})
+
+	// Explicit lines are gathered onto one order per supplier, in the order first seen.
+	orders, err = service.CreateDraftOrders(ctx, []domain.DraftLine{
+		{ProductID: tea.ID, Quantity: 2},
+		{ProductID: cup.ID, Quantity: 4},
+		{ProductID: mug.ID, Quantity: 1},
+	}, "Top up")
+	if err != nil {
+		t.Fatalf("create draft orders: %v", err)
+	}
+	checkOrders(orders, []wantOrder{
+		{beta.ID, []domain.OrderLine{{ProductID: tea.ID, ProductName: "Tea", SKU: "TEA", Quantity: 2}}},
+		{acme.ID, []domain.OrderLine{
+			{ProductID: cup.ID, ProductName: "Cup", SKU: "CUP", Quantity: 4},
+			{ProductID: mug.ID, ProductName: "Mug", SKU: "MUG", Quantity: 1},
+		}},
+	})
+	if orders[0].Note != "Top up" || orders[1].SupplierName != "Acme" {
+		t.Fatalf("unexpected order headers %+v", orders)
+	}
+	if _, err := service.CreateDraftOrders(ctx, []domain.DraftLine{{ProductID: mug.ID, Quantity: 0}}, ""); err == nil {
+		t.Fatal("expected a zero quantity line to be rejected")
+	}
+}
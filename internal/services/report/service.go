@@ -94,3 +94,200 @@ func (s *Service) TopProductsCSV(ctx context.Context, from, to time.Time, limit
 	}
 	return buf.Bytes(), nil
 }
+
+// CategorySales returns sales rolled up by category subtree beneath parentID.
+func (s *Service) CategorySales(ctx context.Context, from, to time.Time, parentID int64) ([]report.CategorySales, error) {
+	return s.repo.CategorySales(ctx, from, to, parentID)
+}
+
+// CategorySalesCSV renders the category roll-up as CSV.
+func (s *Service) CategorySalesCSV(ctx context.Context, from, to time.Time, parentID int64) ([]byte, error) {
+	categories, err := s.CategorySales(ctx, from, to, parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"category_id", "category_path", "quantity_sold", "revenue_cents"}); err != nil {
+		return nil, fmt.Errorf("write header: %w", err)
+	}
+
+	for _, c := range categories {
+		record := []string{
+			strconv.FormatInt(c.CategoryID, 10),
+			c.CategoryPath,
+			strconv.FormatInt(c.QuantitySold, 10),
+			strconv.FormatInt(c.RevenueCents, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("write row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("flush csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Valuation values stock on hand as of the given time.
+func (s *Service) Valuation(ctx context.Context, asOf time.Time) (*report.Valuation, error) {
+	return s.repo.Valuation(ctx, asOf)
+}
+
+// ValuationCSV renders the inventory valuation as CSV with a closing total row.
+func (s *Service) ValuationCSV(ctx context.Context, asOf time.Time) ([]byte, error) {
+	valuation, err := s.Valuation(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"product_id", "sku", "product_name", "category", "qty", "unit_cost_cents", "value_cents"}); err != nil {
+		return nil, fmt.Errorf("write header: %w", err)
+	}
+
+	for _, line := range valuation.Lines {
+		record := []string{
+			strconv.FormatInt(line.ProductID, 10),
+			line.SKU,
+			line.ProductName,
+			line.Category,
+			strconv.FormatInt(line.Qty, 10),
+			strconv.FormatInt(line.UnitCostCents, 10),
+			strconv.FormatInt(line.ValueCents, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("write row: %w", err)
+		}
+	}
+	total := []string{"", "", "Total (" + valuation.Method + " as of " + valuation.AsOf.Format(time.RFC3339) + ")", "",
+		strconv.FormatInt(valuation.TotalQty, 10), "", strconv.FormatInt(valuation.TotalValueCents, 10)}
+	if err := writer.Write(total); err != nil {
+		return nil, fmt.Errorf("write total: %w", err)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("flush csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// NegativeStock lists stock positions below zero, most negative first.
+func (s *Service) NegativeStock(ctx context.Context) ([]report.NegativeStockLine, error) {
+	return s.repo.NegativeStock(ctx)
+}
+
+// NegativeStockCSV renders the negative-stock report as CSV.
+func (s *Service) NegativeStockCSV(ctx context.Context) ([]byte, error) {
+	lines, err := s.NegativeStock(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"product_id", "sku", "product_name", "location", "qty", "policy", "backordered_qty"}); err != nil {
+		return nil, fmt.Errorf("write header: %w", err)
+	}
+
+	for _, line := range lines {
+		record := []string{
+			strconv.FormatInt(line.ProductID, 10),
+			line.SKU,
+			line.ProductName,
+			line.LocationName,
+			strconv.FormatInt(line.Qty, 10),
+			line.Policy,
+			strconv.FormatInt(line.BackorderedQty, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("write row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("flush csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// PaymentBreakdown totals money taken and refunded by payment method within a range.
+func (s *Service) PaymentBreakdown(ctx context.Context, from, to time.Time) ([]report.PaymentTotal, error) {
+	return s.repo.PaymentBreakdown(ctx, from, to)
+}
+
+// PaymentBreakdownCSV renders the payment breakdown as CSV.
+func (s *Service) PaymentBreakdownCSV(ctx context.Context, from, to time.Time) ([]byte, error) {
+	totals, err := s.PaymentBreakdown(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"method", "sale_count", "tendered_cents", "change_cents", "received_cents", "refunded_cents", "net_cents"}); err != nil {
+		return nil, fmt.Errorf("write header: %w", err)
+	}
+
+	for _, t := range totals {
+		record := []string{
+			t.Method,
+			strconv.FormatInt(t.SaleCount, 10),
+			strconv.FormatInt(t.TenderedCents, 10),
+			strconv.FormatInt(t.ChangeCents, 10),
+			strconv.FormatInt(t.ReceivedCents, 10),
+			strconv.FormatInt(t.RefundedCents, 10),
+			strconv.FormatInt(t.NetCents, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("write row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("flush csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Promotions totals the discounts given by each promotion within a range.
+func (s *Service) Promotions(ctx context.Context, from, to time.Time) ([]report.PromotionTotal, error) {
+	return s.repo.Promotions(ctx, from, to)
+}
+
+// PromotionsCSV renders the promotion totals as CSV.
+func (s *Service) PromotionsCSV(ctx context.Context, from, to time.Time) ([]byte, error) {
+	totals, err := s.Promotions(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"promotion_id", "name", "coupon_code", "sale_count", "line_count", "units", "discount_cents"}); err != nil {
+		return nil, fmt.Errorf("write header: %w", err)
+	}
+
+	for _, t := range totals {
+		record := []string{
+			strconv.FormatInt(t.PromotionID, 10),
+			t.Name,
+			t.CouponCode,
+			strconv.FormatInt(t.SaleCount, 10),
+			strconv.FormatInt(t.LineCount, 10),
+			strconv.FormatInt(t.Units, 10),
+			strconv.FormatInt(t.DiscountCents, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("write row: %w", err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("flush csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,109 @@
+package report_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	productdomain "shopmate/internal/domain/product"
+	domainsale "shopmate/internal/domain/sale"
+	"shopmate/internal/domain/settings"
+	reportservice "shopmate/internal/services/report"
+	saleservice "shopmate/internal/services/sale"
+)
+
+func TestValuationAndCostOfGoodsSold(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "valuation.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), settingsRepo)
+	reports := reportservice.NewService(sqlite.NewReportRepository(store.DB()))
+
+	before := time.Now().Add(-time.Second)
+	beans, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Beans", SKU: "BEAN", UnitPriceCents: 500, CostCents: 100})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	for _, cost := range []int64{100, 200} {
+		if _, err := productRepo.AdjustStock(ctx, productdomain.AdjustmentInput{ProductID: beans.ID, Delta: 10, Reason: "Receipt", UnitCostCents: cost}); err != nil {
+			t.Fatalf("receive stock: %v", err)
+		}
+	}
+
+	sell := func(qty int64) *domainsale.Sale {
+		t.Helper()
+		created, err := sales.Create(ctx, saleservice.CreateRequest{
+			PaymentMethod: "Cash",
+			Lines:         []saleservice.CreateRequestLine{{ProductID: beans.ID, Quantity: qty}},
+		})
+		if err != nil {
+			t.Fatalf("create sale: %v", err)
+		}
+		return created
+	}
+
+	first := sell(15)
+	if cogs := first.Lines[0].CostCents; cogs != 10*100+5*200 {
+		t.Fatalf("FIFO cost of goods sold = %d, want 2000", cogs)
+	}
+	assertValuation(t, reports, time.Now(), 5, 1000)
+
+	if err := settingsRepo.SaveCosting(ctx, settings.Costing{Method: settings.CostingWeightedAverage}); err != nil {
+		t.Fatalf("save costing: %v", err)
+	}
+	if cogs := sell(2).Lines[0].CostCents; cogs != 400 {
+		t.Fatalf("weighted average cost of goods sold = %d, want 400", cogs)
+	}
+	assertValuation(t, reports, time.Now(), 3, 600)
+
+	if err := sales.Refund(ctx, first.ID); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	// Refunded units return at exactly the cost they left with, though 2000 does not split
+	// evenly over 15 units.
+	assertValuation(t, reports, time.Now(), 18, 600+2000)
+	layers, err := productRepo.CostLayers(ctx, beans.ID)
+	if err != nil {
+		t.Fatalf("cost layers: %v", err)
+	}
+	var layered int64
+	for _, l := range layers {
+		layered += l.RemainingQty * l.UnitCostCents
+	}
+	if layered != 600+2000 {
+		t.Fatalf("expected the cost layers to hold 2600, got %d in %+v", layered, layers)
+	}
+
+	empty, err := reports.Valuation(ctx, before)
+	if err != nil || len(empty.Lines) != 0 {
+		t.Fatalf("expected no stock before the product existed, got %+v (%v)", empty, err)
+	}
+
+	data, err := reports.ValuationCSV(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("valuation csv: %v", err)
+	}
+	if !strings.Contains(string(data), "BEAN,Beans,,18,") || !strings.Contains(string(data), "Total (WeightedAverage") {
+		t.Fatalf("unexpected csv:\n%s", data)
+	}
+}
+
+func assertValuation(t *testing.T, reports *reportservice.Service, asOf time.Time, qty, value int64) {
+	t.Helper()
+	valuation, err := reports.Valuation(context.Background(), asOf)
+	if err != nil {
+		t.Fatalf("valuation: %v", err)
+	}
+	if valuation.TotalQty != qty || valuation.TotalValueCents != value {
+		t.Fatalf("valuation = %d units worth %d, want %d worth %d", valuation.TotalQty, valuation.TotalValueCents, qty, value)
+	}
+}
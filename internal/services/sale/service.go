@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
 	"time"
 
 	"shopmate/internal/adapters/storage/sqlite"
+	domainproduct "shopmate/internal/domain/product"
+	"shopmate/internal/domain/promotion"
 	domainsale "shopmate/internal/domain/sale"
+	"shopmate/internal/domain/tax"
 )
 
 // Repository abstraction enables testing.
@@ -16,103 +18,417 @@ type repository interface {
 	Create(ctx context.Context, draft domainsale.Sale) (*domainsale.Sale, error)
 	GetByID(ctx context.Context, saleID int64) (*domainsale.Sale, error)
 	List(ctx context.Context, filter domainsale.Filter) ([]domainsale.Sale, error)
-	Refund(ctx context.Context, saleID int64) error
+	CreateRefund(ctx context.Context, draft domainsale.RefundDraft) (*domainsale.Refund, error)
+	Refunds(ctx context.Context, saleID int64) ([]domainsale.Refund, error)
+	CreateExchange(ctx context.Context, draft domainsale.ExchangeDraft) (*domainsale.Exchange, error)
+	GetExchange(ctx context.Context, id int64) (*domainsale.Exchange, error)
 	Void(ctx context.Context, saleID int64, note string) error
+	Backorders(ctx context.Context, includeFulfilled bool) ([]domainsale.Backorder, error)
+	FulfilBackorder(ctx context.Context, id int64, now time.Time) error
 }
 
 // Service orchestrates sale workflows.
 type Service struct {
-	products *sqlite.ProductRepository
-	repo     repository
+	products   *sqlite.ProductRepository
+	repo       repository
+	settings   *sqlite.SettingsRepository
+	promotions *sqlite.PromotionRepository
+	taxes      *sqlite.TaxRepository
+	observer   func(ctx context.Context, productIDs []int64)
 }
 
 // NewService builds a sale service.
-func NewService(products *sqlite.ProductRepository, repo repository) *Service {
-	return &Service{products: products, repo: repo}
+func NewService(products *sqlite.ProductRepository, repo repository, settings *sqlite.SettingsRepository) *Service {
+	return &Service{products: products, repo: repo, settings: settings}
 }
 
-// CreateRequestLine describes input from POS.
+// SetStockObserver registers a callback told which products' stock a completed sale,
+// refund or void changed.
+func (s *Service) SetStockObserver(fn func(ctx context.Context, productIDs []int64)) {
+	s.observer = fn
+}
+
+// SetPromotions makes sales apply the promotions running when they are priced and accept
+// coupon codes.
+func (s *Service) SetPromotions(promotions *sqlite.PromotionRepository) {
+	s.promotions = promotions
+}
+
+// SetTaxes makes sales tax products by their tax group and honour customer tax
+// exemptions. Without it every product is taxed at its own single rate.
+func (s *Service) SetTaxes(taxes *sqlite.TaxRepository) {
+	s.taxes = taxes
+}
+
+// CreateRequestLine describes input from POS. Serialised products require one
+// serial number per unit sold. A set UnitPriceCents replaces the product's current price,
+// as when a quoted price is honoured; it is the price this customer pays, so it is taken as
+// given even for a tax-exempt customer.
 type CreateRequestLine struct {
-	ProductID     int64
-	Quantity      int64
-	DiscountCents int64
+	ProductID      int64
+	Quantity       int64
+	DiscountCents  int64
+	UnitPriceCents *int64
+	SerialNumbers  []string
+}
+
+// CreatePaymentRequest is one tender handed over by the customer. Reference is the code
+// of the gift card or store credit a stored-value tender spends.
+type CreatePaymentRequest struct {
+	Method      string
+	AmountCents int64
+	Reference   string
 }
 
-// CreateRequest is the payload for creating a sale.
+// CreateRequest is the payload for creating a sale. A zero LocationID sells from
+// the till's configured location. The sale number is allocated when the sale is saved.
+// Payments lists the tenders taken; without them the total is paid exactly in
+// PaymentMethod. A sale to a customer record sets CustomerID, and is sold under the
+// customer's name; such sales earn loyalty points and may be paid with them using the
+// domainsale.PaymentPoints method. Gift cards and store credit are spent by naming their
+// code in PaymentReference or the payment's Reference. CouponCodes unlocks coupon
+// promotions; every code given must discount the sale.
 type CreateRequest struct {
-	SaleNumber    string
-	CustomerName  string
-	PaymentMethod string
-	Lines         []CreateRequestLine
-	DiscountCents int64
-	Note          string
+	CustomerID       int64
+	CustomerName     string
+	PaymentMethod    string
+	PaymentReference string
+	Payments         []CreatePaymentRequest
+	LocationID       int64
+	Lines            []CreateRequestLine
+	DiscountCents    int64
+	CouponCodes      []string
+	Note             string
 }
 
-// Create registers a sale and decrements inventory.
+// Create registers a sale and decrements inventory. The tenders must cover the total;
+// only cash may exceed it, and the excess is returned as change.
 func (s *Service) Create(ctx context.Context, req CreateRequest) (*domainsale.Sale, error) {
+	draft, err := s.Prepare(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, *draft)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyStock(ctx, created)
+	return created, nil
+}
+
+// Prepare validates req and prices it into a sale ready to be saved, settling its
+// payments. Nothing is stored.
+func (s *Service) Prepare(ctx context.Context, req CreateRequest) (*domainsale.Sale, error) {
 	if err := s.validateCreateRequest(req); err != nil {
 		return nil, err
 	}
+	now := time.Now()
+	offers, err := s.offers(ctx, req.CouponCodes, now)
+	if err != nil {
+		return nil, err
+	}
+	priced, err := s.priceLines(ctx, req.Lines, req.DiscountCents, offers, req.CustomerID, true)
+	if err != nil {
+		return nil, err
+	}
+	if err := couponsApplied(req.CouponCodes, priced.Lines); err != nil {
+		return nil, err
+	}
+
+	locationID := req.LocationID
+	if locationID == 0 {
+		till, err := s.settings.LoadTill(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("load till: %w", err)
+		}
+		locationID = till.LocationID
+	}
+
+	total := priced.TotalCents
+	tenders := []domainsale.Tender{{Method: req.PaymentMethod, AmountCents: total, Reference: req.PaymentReference}}
+	if len(req.Payments) > 0 {
+		tenders = tenders[:0]
+		for _, p := range req.Payments {
+			tenders = append(tenders, domainsale.Tender{Method: p.Method, AmountCents: p.AmountCents, Reference: p.Reference})
+		}
+	}
+	payments, change, err := domainsale.SettlePayments(total, tenders)
+	if err != nil {
+		return nil, err
+	}
+	if priced.PointsRedeemed, err = s.pointsRedeemed(ctx, req.CustomerID, payments); err != nil {
+		return nil, err
+	}
 
-	lines := make([]domainsale.Line, 0, len(req.Lines))
+	priced.Timestamp = now
+	priced.CustomerID = req.CustomerID
+	priced.CustomerName = req.CustomerName
+	priced.PaymentMethod = domainsale.PaymentMethodOf(payments)
+	priced.Payments = payments
+	priced.ChangeCents = change
+	priced.Status = "Completed"
+	priced.Note = req.Note
+	priced.LocationID = locationID
+	return priced, nil
+}
+
+// pointsRedeemed returns the loyalty points spent by the Points payments, which only a
+// customer record can make.
+func (s *Service) pointsRedeemed(ctx context.Context, customerID int64, payments []domainsale.Payment) (int64, error) {
+	var paid int64
+	for _, p := range payments {
+		if domainsale.IsPoints(p.Method) {
+			paid += p.AppliedCents
+		}
+	}
+	if paid == 0 {
+		return 0, nil
+	}
+	if customerID <= 0 {
+		return 0, errors.New("points can only be redeemed on a sale to a customer")
+	}
+	policy, err := s.settings.LoadLoyaltyPolicy(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("load loyalty policy: %w", err)
+	}
+	if !policy.Enabled {
+		return 0, errors.New("loyalty points are not enabled")
+	}
+	return policy.PointsFor(paid)
+}
+
+// Price prices lines and an order discount the way a sale to customerID would be priced,
+// without selling anything or checking serial numbers, for documents such as quotes.
+// Promotions are left for the sale to apply. Only the lines, totals and tax of the
+// returned sale are set.
+func (s *Service) Price(ctx context.Context, customerID int64, lines []CreateRequestLine, discountCents int64) (*domainsale.Sale, error) {
+	if err := validateLines(lines, discountCents); err != nil {
+		return nil, err
+	}
+	return s.priceLines(ctx, lines, discountCents, nil, customerID, false)
+}
+
+// offers returns the promotions running at now that a sale may receive: those without a
+// coupon, and those whose coupon is among codes.
+func (s *Service) offers(ctx context.Context, codes []string, now time.Time) ([]promotion.Promotion, error) {
+	wanted := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		if code = promotion.NormalizeCode(code); code != "" {
+			wanted[code] = true
+		}
+	}
+	if s.promotions == nil {
+		if len(wanted) > 0 {
+			return nil, errors.New("coupons are not available")
+		}
+		return nil, nil
+	}
+
+	active, err := s.promotions.Active(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load promotions: %w", err)
+	}
+	offers := make([]promotion.Promotion, 0, len(active))
+	found := make(map[string]bool, len(wanted))
+	for _, p := range active {
+		if p.CouponCode == "" {
+			if p.ActiveAt(now) {
+				offers = append(offers, p)
+			}
+			continue
+		}
+		if !wanted[p.CouponCode] {
+			continue
+		}
+		found[p.CouponCode] = true
+		if !p.ActiveAt(now) {
+			return nil, fmt.Errorf("coupon %s is not valid at this time", p.CouponCode)
+		}
+		if p.Exhausted() {
+			return nil, fmt.Errorf("coupon %s has been used up", p.CouponCode)
+		}
+		offers = append(offers, p)
+	}
+	for code := range wanted {
+		if !found[code] {
+			return nil, fmt.Errorf("coupon %s not found", code)
+		}
+	}
+	return offers, nil
+}
+
+// couponsApplied checks that every coupon code given discounted one of the lines.
+func couponsApplied(codes []string, lines []domainsale.Line) error {
+	used := make(map[string]bool)
+	for _, line := range lines {
+		for _, p := range line.Promotions {
+			used[p.CouponCode] = true
+		}
+	}
+	for _, code := range codes {
+		if code = promotion.NormalizeCode(code); code != "" && !used[code] {
+			return fmt.Errorf("coupon %s does not apply to this sale", code)
+		}
+	}
+	return nil
+}
+
+// priceLines prices reqLines and the order discount at current product prices, or at a
+// line's own UnitPriceCents when set, applying offers to the lines without a manual
+// discount or agreed price. Promotion discounts come off before tax, which is charged
+// under the shop's tax policy unless customerID is exempt, as do line discounts and the
+// order discount spread over the lines. The sale's discount sums all three. Serial
+// numbers are checked only when selling.
+func (s *Service) priceLines(ctx context.Context, reqLines []CreateRequestLine, discountCents int64, offers []promotion.Promotion, customerID int64, selling bool) (*domainsale.Sale, error) {
+	policy, err := s.settings.LoadTaxPolicy(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load tax policy: %w", err)
+	}
+	var (
+		exempt          bool
+		exemptionNumber string
+	)
+	if s.taxes != nil && customerID > 0 {
+		if exempt, exemptionNumber, err = s.taxes.Exemption(ctx, customerID); err != nil {
+			return nil, err
+		}
+	}
+
+	lines := make([]domainsale.Line, 0, len(reqLines))
+	items := make([]promotion.Item, 0, len(reqLines))
+	taxLines := make([]tax.Line, 0, len(reqLines))
+	groups := make(map[int64][]tax.Component)
 	var subtotal int64
 	var taxTotal int64
+	var lineDiscountTotal int64
+	var promotionTotal int64
 
-	for _, reqLine := range req.Lines {
+	for _, reqLine := range reqLines {
 		product, err := s.products.GetByID(ctx, reqLine.ProductID)
 		if err != nil {
 			return nil, fmt.Errorf("load product %d: %w", reqLine.ProductID, err)
 		}
+		if product.Archived() {
+			return nil, fmt.Errorf("product %s is archived and cannot be sold", product.SKU)
+		}
+
+		var serials []string
+		if selling {
+			if serials, err = lineSerials(product, reqLine); err != nil {
+				return nil, err
+			}
+		}
+
+		components := tax.Single(product.TaxRateBasisPoints)
+		if s.taxes != nil && product.TaxGroupID > 0 {
+			var ok bool
+			if components, ok = groups[product.TaxGroupID]; !ok {
+				if components, err = s.taxes.Components(ctx, product.TaxGroupID); err != nil {
+					return nil, fmt.Errorf("load tax group of %s: %w", product.SKU, err)
+				}
+				groups[product.TaxGroupID] = components
+			}
+		}
 
-		lineSubtotal := product.UnitPriceCents * reqLine.Quantity
+		unitPrice := product.UnitPriceCents
+		if exempt {
+			// Exempt customers pay shelf prices less the tax they include.
+			if policy.PricesIncludeTax {
+				unitPrice = tax.Exclude(unitPrice, components)
+			}
+			components = nil
+		}
+		if reqLine.UnitPriceCents != nil {
+			unitPrice = *reqLine.UnitPriceCents
+		}
+		taxLines = append(taxLines, tax.Line{Components: components})
+		lineSubtotal := unitPrice * reqLine.Quantity
 		if reqLine.DiscountCents > lineSubtotal {
 			return nil, errors.New("line discount exceeds subtotal")
 		}
 
-		taxableBase := lineSubtotal - reqLine.DiscountCents
-		lineTax := computeTax(taxableBase, product.TaxRateBasisPoints)
-		lineTotal := taxableBase + lineTax
+		item := promotion.Item{
+			ProductID:      product.ID,
+			UnitPriceCents: unitPrice,
+			Quantity:       reqLine.Quantity,
+			Eligible:       reqLine.DiscountCents == 0 && reqLine.UnitPriceCents == nil,
+		}
+		if len(offers) > 0 && product.CategoryID > 0 {
+			if item.CategoryIDs, err = s.promotions.Ancestry(ctx, product.CategoryID); err != nil {
+				return nil, fmt.Errorf("load category of %s: %w", product.SKU, err)
+			}
+		}
+		items = append(items, item)
 
 		lines = append(lines, domainsale.Line{
 			ProductID:          product.ID,
 			ProductName:        product.Name,
 			SKU:                product.SKU,
 			Quantity:           reqLine.Quantity,
-			UnitPriceCents:     product.UnitPriceCents,
-			TaxRateBasisPoints: product.TaxRateBasisPoints,
+			UnitPriceCents:     unitPrice,
+			TaxRateBasisPoints: tax.EffectiveRate(components),
 			LineSubtotalCents:  lineSubtotal,
 			LineDiscountCents:  reqLine.DiscountCents,
-			LineTaxCents:       lineTax,
-			LineTotalCents:     lineTotal,
+			SerialNumbers:      serials,
 		})
-
 		subtotal += lineSubtotal
-		taxTotal += lineTax
 	}
 
-	if req.DiscountCents > subtotal {
+	var applied [][]domainsale.LinePromotion
+	if len(offers) > 0 {
+		applied = promotion.Apply(offers, items)
+	}
+	for i := range lines {
+		line := &lines[i]
+		if applied != nil {
+			line.Promotions = applied[i]
+		}
+		for _, p := range line.Promotions {
+			line.PromotionDiscountCents += p.DiscountCents
+		}
+		lineDiscountTotal += line.LineDiscountCents
+		promotionTotal += line.PromotionDiscountCents
+	}
+
+	if discountCents > subtotal-lineDiscountTotal-promotionTotal {
 		return nil, errors.New("order discount exceeds subtotal")
 	}
+	// The order discount comes off the lines before tax, like their own discounts.
+	discountCents += lineDiscountTotal + promotionTotal
+	shares := domainsale.AllocateOrderDiscount(discountCents, lines)
+	for i, line := range lines {
+		taxLines[i].AmountCents = line.LineSubtotalCents - line.LineDiscountCents - shares[i]
+	}
 
-	draft := domainsale.Sale{
-		SaleNumber:    req.SaleNumber,
-		Timestamp:     time.Now(),
-		CustomerName:  req.CustomerName,
-		SubtotalCents: subtotal,
-		DiscountCents: req.DiscountCents,
-		TaxCents:      taxTotal,
-		TotalCents:    subtotal - req.DiscountCents + taxTotal,
-		PaymentMethod: req.PaymentMethod,
-		Status:        "Completed",
-		Note:          req.Note,
-		Lines:         lines,
+	// An exempt sale has no tax left in its prices to include.
+	policy.PricesIncludeTax = policy.PricesIncludeTax && !exempt
+	lineTaxes, breakdown := policy.Compute(taxLines)
+	for i := range lines {
+		line := &lines[i]
+		line.LineTaxCents = lineTaxes[i].TaxCents
+		line.LineTotalCents = taxLines[i].AmountCents
+		if !policy.PricesIncludeTax {
+			line.LineTotalCents += line.LineTaxCents
+		}
+		taxTotal += line.LineTaxCents
 	}
 
-	created, err := s.repo.Create(ctx, draft)
-	if err != nil {
-		return nil, err
+	total := subtotal - discountCents
+	if !policy.PricesIncludeTax {
+		total += taxTotal
 	}
-	return created, nil
+	return &domainsale.Sale{
+		SubtotalCents:      subtotal,
+		DiscountCents:      discountCents,
+		TaxCents:           taxTotal,
+		TotalCents:         total,
+		TaxIncluded:        policy.PricesIncludeTax,
+		TaxExempt:          exempt,
+		TaxExemptionNumber: exemptionNumber,
+		Taxes:              breakdown,
+		Lines:              lines,
+	}, nil
 }
 
 // List returns sales matching the provided filter.
@@ -129,12 +445,164 @@ func (s *Service) Get(ctx context.Context, saleID int64) (*domainsale.Sale, erro
 	return s.repo.GetByID(ctx, saleID)
 }
 
-// Refund reverts a sale and restores stock.
+// RefundRequestLine selects units of a sale line to return. Serialised lines list the
+// serial numbers returned unless every remaining unit comes back.
+type RefundRequestLine struct {
+	SaleLineID    int64
+	Quantity      int64
+	SerialNumbers []string
+}
+
+// RefundRequest returns some of a sale's lines. An empty PaymentMethod refunds to the
+// sale's payment method; split-tender sales must name one. Reference names the gift card
+// or store credit to credit; without it the account the sale was paid from is used, or
+// new store credit is issued.
+type RefundRequest struct {
+	SaleID        int64
+	PaymentMethod string
+	Reference     string
+	Reason        string
+	Lines         []RefundRequestLine
+}
+
+// CreateRefund records a credit note for the chosen lines, pro-rating discounts and tax,
+// and restores stock for the returned items only.
+func (s *Service) CreateRefund(ctx context.Context, req RefundRequest) (*domainsale.Refund, error) {
+	draft := domainsale.RefundDraft{
+		SaleID:        req.SaleID,
+		PaymentMethod: req.PaymentMethod,
+		Reference:     req.Reference,
+		Reason:        req.Reason,
+		Timestamp:     time.Now(),
+	}
+	for _, line := range req.Lines {
+		draft.Lines = append(draft.Lines, domainsale.ReturnLine{
+			SaleLineID:    line.SaleLineID,
+			Quantity:      line.Quantity,
+			SerialNumbers: line.SerialNumbers,
+		})
+	}
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+
+	refund, err := s.repo.CreateRefund(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyRefund(ctx, refund)
+	return refund, nil
+}
+
+// Refund returns every unit of a sale not yet refunded to the sale's payment method.
+// Refunding a sale that is already fully refunded does nothing.
 func (s *Service) Refund(ctx context.Context, saleID int64) error {
 	if saleID <= 0 {
 		return errors.New("sale id required")
 	}
-	return s.repo.Refund(ctx, saleID)
+	sale, err := s.repo.GetByID(ctx, saleID)
+	if err != nil {
+		return err
+	}
+	if sale.Status == "Refunded" {
+		return nil
+	}
+
+	req := RefundRequest{SaleID: saleID}
+	for _, line := range sale.Lines {
+		if remaining := line.Quantity - line.RefundedQty; remaining > 0 {
+			req.Lines = append(req.Lines, RefundRequestLine{SaleLineID: line.ID, Quantity: remaining})
+		}
+	}
+	_, err = s.CreateRefund(ctx, req)
+	return err
+}
+
+// Refunds lists a sale's credit notes, oldest first.
+func (s *Service) Refunds(ctx context.Context, saleID int64) ([]domainsale.Refund, error) {
+	if saleID <= 0 {
+		return nil, errors.New("sale id required")
+	}
+	return s.repo.Refunds(ctx, saleID)
+}
+
+// ExchangeRequest returns lines of a sale and sells replacements in their place. The
+// replacements are sold to the original customer at current prices; an empty
+// PaymentMethod settles the difference with the original sale's payment method.
+type ExchangeRequest struct {
+	SaleID        int64
+	PaymentMethod string
+	Reason        string
+	Returns       []RefundRequestLine
+	Lines         []CreateRequestLine
+	DiscountCents int64
+	LocationID    int64
+	Note          string
+}
+
+// Exchange credits the returned lines and sells the replacements in one transaction,
+// netting the two into a single payment or refund.
+func (s *Service) Exchange(ctx context.Context, req ExchangeRequest) (*domainsale.Exchange, error) {
+	if req.SaleID <= 0 {
+		return nil, errors.New("sale id required")
+	}
+	if len(req.Returns) == 0 {
+		return nil, errors.New("at least one line to return required")
+	}
+	original, err := s.repo.GetByID(ctx, req.SaleID)
+	if err != nil {
+		return nil, err
+	}
+	if req.PaymentMethod == "" {
+		if original.PaymentMethod == domainsale.PaymentSplit {
+			return nil, fmt.Errorf("sale %s was paid with several methods; choose a payment method for the exchange", original.SaleNumber)
+		}
+		req.PaymentMethod = original.PaymentMethod
+	}
+
+	replacement, err := s.Prepare(ctx, CreateRequest{
+		CustomerID:    original.CustomerID,
+		CustomerName:  original.CustomerName,
+		PaymentMethod: req.PaymentMethod,
+		LocationID:    req.LocationID,
+		Lines:         req.Lines,
+		DiscountCents: req.DiscountCents,
+		Note:          req.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	draft := domainsale.ExchangeDraft{
+		Return: domainsale.RefundDraft{SaleID: req.SaleID, Reason: req.Reason},
+		Sale:   *replacement,
+	}
+	for _, line := range req.Returns {
+		draft.Return.Lines = append(draft.Return.Lines, domainsale.ReturnLine{
+			SaleLineID:    line.SaleLineID,
+			Quantity:      line.Quantity,
+			SerialNumbers: line.SerialNumbers,
+		})
+	}
+	if err := draft.Validate(); err != nil {
+		return nil, err
+	}
+
+	exchange, err := s.repo.CreateExchange(ctx, draft)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyRefund(ctx, &exchange.Return)
+	s.notifyStock(ctx, &exchange.Sale)
+	return exchange, nil
+}
+
+// GetExchange retrieves an exchange with both of its sides.
+func (s *Service) GetExchange(ctx context.Context, id int64) (*domainsale.Exchange, error) {
+	if id <= 0 {
+		return nil, errors.New("exchange id required")
+	}
+	return s.repo.GetExchange(ctx, id)
 }
 
 // Void cancels a sale prior to completion.
@@ -142,23 +610,74 @@ func (s *Service) Void(ctx context.Context, saleID int64, note string) error {
 	if saleID <= 0 {
 		return errors.New("sale id required")
 	}
-	return s.repo.Void(ctx, saleID, note)
+	if err := s.repo.Void(ctx, saleID, note); err != nil {
+		return err
+	}
+	s.notifyStockOf(ctx, saleID)
+	return nil
 }
 
-func (s *Service) validateCreateRequest(req CreateRequest) error {
-	if req.SaleNumber == "" {
-		return errors.New("sale number required")
+// Backorders lists stock owed to customers; fulfilled backorders only on request.
+func (s *Service) Backorders(ctx context.Context, includeFulfilled bool) ([]domainsale.Backorder, error) {
+	return s.repo.Backorders(ctx, includeFulfilled)
+}
+
+// FulfilBackorder records that backordered stock was handed to the customer.
+func (s *Service) FulfilBackorder(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("backorder id required")
 	}
-	if req.PaymentMethod == "" {
+	return s.repo.FulfilBackorder(ctx, id, time.Now())
+}
+
+func (s *Service) notifyStockOf(ctx context.Context, saleID int64) {
+	if s.observer == nil {
+		return
+	}
+	sale, err := s.repo.GetByID(ctx, saleID)
+	if err != nil {
+		return
+	}
+	s.notifyStock(ctx, sale)
+}
+
+func (s *Service) notifyRefund(ctx context.Context, refund *domainsale.Refund) {
+	if s.observer == nil {
+		return
+	}
+	ids := make([]int64, 0, len(refund.Lines))
+	for _, line := range refund.Lines {
+		ids = append(ids, line.ProductID)
+	}
+	s.observer(ctx, ids)
+}
+
+func (s *Service) notifyStock(ctx context.Context, sale *domainsale.Sale) {
+	if s.observer == nil || sale == nil {
+		return
+	}
+	ids := make([]int64, 0, len(sale.Lines))
+	for _, line := range sale.Lines {
+		ids = append(ids, line.ProductID)
+	}
+	s.observer(ctx, ids)
+}
+
+func (s *Service) validateCreateRequest(req CreateRequest) error {
+	if req.PaymentMethod == "" && len(req.Payments) == 0 {
 		return errors.New("payment method required")
 	}
-	if len(req.Lines) == 0 {
+	return validateLines(req.Lines, req.DiscountCents)
+}
+
+func validateLines(lines []CreateRequestLine, discountCents int64) error {
+	if len(lines) == 0 {
 		return errors.New("at least one line item required")
 	}
-	if req.DiscountCents < 0 {
+	if discountCents < 0 {
 		return errors.New("discount must be >= 0")
 	}
-	for _, line := range req.Lines {
+	for _, line := range lines {
 		if line.ProductID == 0 {
 			return errors.New("product id required")
 		}
@@ -168,15 +687,28 @@ func (s *Service) validateCreateRequest(req CreateRequest) error {
 		if line.DiscountCents < 0 {
 			return errors.New("discount must be >= 0")
 		}
+		if line.UnitPriceCents != nil && *line.UnitPriceCents < 0 {
+			return errors.New("unit price must be >= 0")
+		}
 	}
 	return nil
 }
 
-func computeTax(amountCents, rateBasisPoints int64) int64 {
-	if rateBasisPoints <= 0 || amountCents <= 0 {
-		return 0
+func lineSerials(product *domainproduct.Product, line CreateRequestLine) ([]string, error) {
+	if !product.Serialised {
+		if len(line.SerialNumbers) > 0 {
+			return nil, fmt.Errorf("%s is not serialised", product.Name)
+		}
+		return nil, nil
+	}
+	serials, err := domainproduct.NormalizeSerials(line.SerialNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", product.Name, err)
+	}
+	if err := domainproduct.CheckSerialCount(serials, line.Quantity); err != nil {
+		return nil, fmt.Errorf("%s: %w", product.Name, err)
 	}
-	return int64(math.Round(float64(amountCents) * float64(rateBasisPoints) / 10000.0))
+	return serials, nil
 }
 
 var _ repository = (*sqlite.SaleRepository)(nil)
@@ -2,11 +2,18 @@ package sale_test
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"shopmate/internal/adapters/storage/sqlite"
 	productdomain "shopmate/internal/domain/product"
+	saledomain "shopmate/internal/domain/sale"
+	"shopmate/internal/domain/sequence"
+	settingsdomain "shopmate/internal/domain/settings"
+	"shopmate/internal/services/invoice"
 	"shopmate/internal/services/sale"
 )
 
@@ -35,10 +42,9 @@ func TestSaleCreateAndRefund(t *testing.T) {
 		t.Fatalf("create product: %v", err)
 	}
 
-	service := sale.NewService(productRepo, saleRepo)
+	service := sale.NewService(productRepo, saleRepo, sqlite.NewSettingsRepository(store.DB()))
 
 	created, err := service.Create(context.Background(), sale.CreateRequest{
-		SaleNumber:    "INV-001",
 		CustomerName:  "Alice",
 		PaymentMethod: "Cash",
 		Lines:         []sale.CreateRequestLine{{ProductID: product.ID, Quantity: 2}},
@@ -79,3 +85,718 @@ func TestSaleCreateAndRefund(t *testing.T) {
 		t.Fatalf("expected stock restored to %d got %d", product.CurrentQty, restoredProduct.CurrentQty)
 	}
 }
+
+func TestSaleSerialisedUnitsAndWarrantyLookup(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "serials.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	saleRepo := sqlite.NewSaleRepository(store.DB())
+
+	phone, err := productRepo.Create(ctx, productdomain.CreateInput{
+		Name:           "Phone",
+		SKU:            "PH-1",
+		UnitPriceCents: 30000,
+		Serialised:     true,
+	})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	if _, err := productRepo.AdjustStock(ctx, productdomain.AdjustmentInput{
+		ProductID: phone.ID, Delta: 2, Reason: "Receive", SerialNumbers: []string{"SN-1"},
+	}); err == nil {
+		t.Fatalf("expected serial count mismatch to fail")
+	}
+	if _, err := productRepo.AdjustStock(ctx, productdomain.AdjustmentInput{
+		ProductID: phone.ID, Delta: 2, Reason: "Receive", SerialNumbers: []string{"SN-1", "SN-2"},
+	}); err != nil {
+		t.Fatalf("receive serials: %v", err)
+	}
+
+	service := sale.NewService(productRepo, saleRepo, sqlite.NewSettingsRepository(store.DB()))
+
+	if _, err := service.Create(ctx, sale.CreateRequest{
+		PaymentMethod: "Card",
+		Lines:         []sale.CreateRequestLine{{ProductID: phone.ID, Quantity: 1}},
+	}); err == nil {
+		t.Fatalf("expected missing serial to fail")
+	}
+
+	created, err := service.Create(ctx, sale.CreateRequest{
+		CustomerName:  "Bob",
+		PaymentMethod: "Card",
+		Lines:         []sale.CreateRequestLine{{ProductID: phone.ID, Quantity: 1, SerialNumbers: []string{"SN-2"}}},
+	})
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+
+	loaded, err := service.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("load sale: %v", err)
+	}
+	if got := loaded.Lines[0].SerialNumbers; len(got) != 1 || got[0] != "SN-2" {
+		t.Fatalf("expected serial stored on line, got %v", got)
+	}
+
+	lookup, err := productRepo.LookupSerial(ctx, "SN-2")
+	if err != nil {
+		t.Fatalf("lookup serial: %v", err)
+	}
+	if len(lookup) != 1 || len(lookup[0].Sales) != 1 || lookup[0].Sales[0].CustomerName != "Bob" {
+		t.Fatalf("unexpected lookup result %+v", lookup)
+	}
+	if lookup[0].Serial.Status != productdomain.SerialStatusSold {
+		t.Fatalf("expected serial sold, got %s", lookup[0].Serial.Status)
+	}
+
+	if err := service.Refund(ctx, created.ID); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	inStock, err := productRepo.ListSerials(ctx, phone.ID, productdomain.SerialStatusInStock)
+	if err != nil {
+		t.Fatalf("list serials: %v", err)
+	}
+	if len(inStock) != 2 {
+		t.Fatalf("expected refunded serial back in stock, got %d in stock", len(inStock))
+	}
+}
+
+func TestArchivedProductKeepsHistory(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "archive.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	service := sale.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+
+	item, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Scone", SKU: "SCONE", UnitPriceCents: 250, CurrentQty: 5})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	created, err := service.Create(ctx, sale.CreateRequest{
+		PaymentMethod: "Cash",
+		Lines:         []sale.CreateRequestLine{{ProductID: item.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+
+	if _, err := productRepo.Update(ctx, item.ID, productdomain.UpdateInput{Name: "Fruit Scone", UnitPriceCents: 250, Version: item.Version}); err != nil {
+		t.Fatalf("rename product: %v", err)
+	}
+	if err := productRepo.Archive(ctx, item.ID); err != nil {
+		t.Fatalf("archive product: %v", err)
+	}
+
+	active, err := productRepo.List(ctx, false)
+	if err != nil || len(active) != 0 {
+		t.Fatalf("expected archived product hidden, got %+v (%v)", active, err)
+	}
+	if _, err := service.Create(ctx, sale.CreateRequest{
+		PaymentMethod: "Cash",
+		Lines:         []sale.CreateRequestLine{{ProductID: item.ID, Quantity: 1}},
+	}); err == nil {
+		t.Fatalf("expected sale of archived product to fail")
+	}
+
+	stored, err := service.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get sale: %v", err)
+	}
+	if stored.Lines[0].ProductName != "Scone" || stored.Lines[0].SKU != "SCONE" {
+		t.Fatalf("expected sale line snapshot, got %+v", stored.Lines[0])
+	}
+
+	if err := productRepo.Unarchive(ctx, item.ID); err != nil {
+		t.Fatalf("unarchive product: %v", err)
+	}
+	if active, _ := productRepo.List(ctx, false); len(active) != 1 {
+		t.Fatalf("expected unarchived product listed, got %+v", active)
+	}
+}
+
+func TestKitSaleDecrementsAndRefundRestoresComponents(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "kits.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	service := sale.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), settingsRepo)
+
+	jam, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Jam", SKU: "JAM", UnitPriceCents: 400, CurrentQty: 5})
+	if err != nil {
+		t.Fatalf("create jam: %v", err)
+	}
+	tea, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Tea", SKU: "TEA", UnitPriceCents: 300, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create tea: %v", err)
+	}
+	basket, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Gift Basket", SKU: "BASKET", UnitPriceCents: 1500})
+	if err != nil {
+		t.Fatalf("create basket: %v", err)
+	}
+	if _, err := productRepo.SetKitComponents(ctx, basket.ID, []productdomain.KitComponent{
+		{ComponentID: jam.ID, Quantity: 1},
+		{ComponentID: tea.ID, Quantity: 3},
+	}); err != nil {
+		t.Fatalf("set kit components: %v", err)
+	}
+
+	basket, err = productRepo.GetByID(ctx, basket.ID)
+	if err != nil || !basket.IsKit || basket.AvailableQty != 3 {
+		t.Fatalf("expected kit available from component stock, got %+v (%v)", basket, err)
+	}
+
+	if _, err := service.Create(ctx, sale.CreateRequest{
+		PaymentMethod: "Cash",
+		Lines:         []sale.CreateRequestLine{{ProductID: basket.ID, Quantity: 4}},
+	}); err == nil {
+		t.Fatalf("expected kit sale beyond component stock to fail")
+	}
+
+	created, err := service.Create(ctx, sale.CreateRequest{
+		PaymentMethod: "Cash",
+		Lines:         []sale.CreateRequestLine{{ProductID: basket.ID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create kit sale: %v", err)
+	}
+
+	components, err := productRepo.KitComponents(ctx, basket.ID)
+	if err != nil {
+		t.Fatalf("kit components: %v", err)
+	}
+	onHand := map[int64]int64{}
+	for _, c := range components {
+		onHand[c.ComponentID] = c.OnHand
+	}
+	if onHand[jam.ID] != 3 || onHand[tea.ID] != 4 {
+		t.Fatalf("expected components decremented, got %+v", components)
+	}
+
+	// Kits on hand follow the scarcest component, counting whole kits only, and none while
+	// a component is below zero.
+	available := func(want int64) {
+		t.Helper()
+		listed, err := productRepo.List(ctx, false)
+		if err != nil {
+			t.Fatalf("list products: %v", err)
+		}
+		for _, p := range listed {
+			if p.ID == basket.ID && p.AvailableQty != want {
+				t.Fatalf("expected %d kits available, got %d", want, p.AvailableQty)
+			}
+		}
+	}
+	available(1)
+	if err := settingsRepo.SaveStockPolicy(ctx, settingsdomain.StockPolicy{NegativeStock: productdomain.NegativeStockWarn}); err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+	oversold, err := service.Create(ctx, sale.CreateRequest{
+		PaymentMethod: "Cash",
+		Lines:         []sale.CreateRequestLine{{ProductID: jam.ID, Quantity: 4}},
+	})
+	if err != nil {
+		t.Fatalf("oversell jam: %v", err)
+	}
+	available(0)
+	if err := service.Void(ctx, oversold.ID, "till error"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	available(1)
+
+	var movements int
+	if err := store.DB().QueryRowContext(ctx, `SELECT COUNT(*) FROM stock_movements WHERE ref = ?`, created.SaleNumber).Scan(&movements); err != nil || movements != 2 {
+		t.Fatalf("expected one movement per component, got %d (%v)", movements, err)
+	}
+
+	if err := service.Refund(ctx, created.ID); err != nil {
+		t.Fatalf("refund kit sale: %v", err)
+	}
+	restoredTea, err := productRepo.GetByID(ctx, tea.ID)
+	if err != nil || restoredTea.CurrentQty != 10 {
+		t.Fatalf("expected tea restored, got %+v (%v)", restoredTea, err)
+	}
+	basket, _ = productRepo.GetByID(ctx, basket.ID)
+	if basket.CurrentQty != 0 {
+		t.Fatalf("expected kit to hold no stock, got %d", basket.CurrentQty)
+	}
+}
+
+func TestSaleNegativeStockPolicies(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "negative.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	saleRepo := sqlite.NewSaleRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	service := sale.NewService(productRepo, saleRepo, settingsRepo)
+
+	tea, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Tea", SKU: "TEA", UnitPriceCents: 300, CurrentQty: 2})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	cups, err := productRepo.Create(ctx, productdomain.CreateInput{
+		Name: "Cups", SKU: "CUP", UnitPriceCents: 100, CurrentQty: 1,
+		NegativeStockPolicy: productdomain.NegativeStockBackorder,
+	})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	sell := func(productID, qty int64) (*saledomain.Sale, error) {
+		return service.Create(ctx, sale.CreateRequest{
+			PaymentMethod: "Cash",
+			Lines:         []sale.CreateRequestLine{{ProductID: productID, Quantity: qty}},
+		})
+	}
+
+	// The shop default blocks the sale and names the product.
+	_, err = sell(tea.ID, 3)
+	var shortage *productdomain.StockShortageError
+	if !errors.As(err, &shortage) || shortage.SKU != "TEA" || shortage.OnHand != 2 || shortage.Requested != 3 {
+		t.Fatalf("expected shortage error for TEA, got %v", err)
+	}
+
+	// Warn lets the sale through and reports the shortage.
+	if err := settingsRepo.SaveStockPolicy(ctx, settingsdomain.StockPolicy{NegativeStock: productdomain.NegativeStockWarn}); err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+	created, err := sell(tea.ID, 3)
+	if err != nil {
+		t.Fatalf("create warned sale: %v", err)
+	}
+	if len(created.Shortages) != 1 || created.Shortages[0].Policy != productdomain.NegativeStockWarn || created.Shortages[0].BackorderedQty != 0 {
+		t.Fatalf("unexpected shortages %+v", created.Shortages)
+	}
+
+	// The product's own Backorder policy overrides the shop setting.
+	backordered, err := sell(cups.ID, 4)
+	if err != nil {
+		t.Fatalf("create backordered sale: %v", err)
+	}
+	backorders, err := service.Backorders(ctx, false)
+	if err != nil {
+		t.Fatalf("list backorders: %v", err)
+	}
+	if len(backorders) != 1 || backorders[0].ProductID != cups.ID || backorders[0].Qty != 3 || backorders[0].SaleNumber != backordered.SaleNumber {
+		t.Fatalf("unexpected backorders %+v", backorders)
+	}
+
+	report, err := sqlite.NewReportRepository(store.DB()).NegativeStock(ctx)
+	if err != nil {
+		t.Fatalf("negative stock report: %v", err)
+	}
+	if len(report) != 2 || report[0].SKU != "CUP" || report[0].Qty != -3 || report[0].BackorderedQty != 3 ||
+		report[1].SKU != "TEA" || report[1].Qty != -1 || report[1].Policy != productdomain.NegativeStockWarn {
+		t.Fatalf("unexpected negative stock report %+v", report)
+	}
+
+	if err := service.FulfilBackorder(ctx, backorders[0].ID); err != nil {
+		t.Fatalf("fulfil backorder: %v", err)
+	}
+	if open, _ := service.Backorders(ctx, false); len(open) != 0 {
+		t.Fatalf("expected no open backorders, got %+v", open)
+	}
+
+	// Refunds take units still owed off the backorder; voids cancel what is left.
+	refunded, err := sell(cups.ID, 3)
+	if err != nil {
+		t.Fatalf("create backordered sale: %v", err)
+	}
+	voided, err := sell(cups.ID, 2)
+	if err != nil {
+		t.Fatalf("create backordered sale: %v", err)
+	}
+	if _, err := service.CreateRefund(ctx, sale.RefundRequest{
+		SaleID: refunded.ID,
+		Lines:  []sale.RefundRequestLine{{SaleLineID: refunded.Lines[0].ID, Quantity: 1}},
+	}); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if err := service.Void(ctx, voided.ID, "customer left"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	backorders, err = service.Backorders(ctx, false)
+	if err != nil {
+		t.Fatalf("list backorders: %v", err)
+	}
+	if len(backorders) != 1 || backorders[0].SaleID != refunded.ID || backorders[0].Qty != 2 {
+		t.Fatalf("expected two cups still owed on %s, got %+v", refunded.SaleNumber, backorders)
+	}
+	if all, _ := service.Backorders(ctx, true); len(all) != 2 || all[0].FulfilledAt == nil {
+		t.Fatalf("expected the voided sale's backorder left out, got %+v", all)
+	}
+	report, err = sqlite.NewReportRepository(store.DB()).NegativeStock(ctx)
+	if err != nil {
+		t.Fatalf("negative stock report: %v", err)
+	}
+	if report[0].SKU != "CUP" || report[0].Qty != -5 || report[0].BackorderedQty != 2 {
+		t.Fatalf("unexpected negative stock report %+v", report)
+	}
+	if _, err := service.CreateRefund(ctx, sale.RefundRequest{
+		SaleID: refunded.ID,
+		Lines:  []sale.RefundRequestLine{{SaleLineID: refunded.Lines[0].ID, Quantity: 2}},
+	}); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if open, _ := service.Backorders(ctx, false); len(open) != 0 {
+		t.Fatalf("expected the refunded backorder cancelled, got %+v", open)
+	}
+}
+
+func TestSaleNumbersAreSequentialAndGapFree(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "numbers.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	sequences := sqlite.NewSequenceRepository(store.DB())
+	service := sale.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+
+	if err := sequences.SaveFormat(ctx, sequence.Format{Name: sequence.Sale, Pattern: "T{YY}-{SEQ:4}", ResetYearly: true}); err != nil {
+		t.Fatalf("save format: %v", err)
+	}
+	tea, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Tea", SKU: "TEA", UnitPriceCents: 300, CurrentQty: 5})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	sell := func(qty int64) (*saledomain.Sale, error) {
+		return service.Create(ctx, sale.CreateRequest{
+			PaymentMethod: "Cash",
+			Lines:         []sale.CreateRequestLine{{ProductID: tea.ID, Quantity: qty}},
+		})
+	}
+
+	prefix := "T" + time.Now().Format("06") + "-"
+	first, err := sell(1)
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+	// A failed sale rolls back its number, so the next sale reuses it.
+	if _, err := sell(100); err == nil {
+		t.Fatalf("expected oversell to fail")
+	}
+	second, err := sell(1)
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+	if first.SaleNumber != prefix+"0001" || second.SaleNumber != prefix+"0002" {
+		t.Fatalf("expected consecutive numbers, got %s and %s", first.SaleNumber, second.SaleNumber)
+	}
+
+	next, err := sequences.Peek(ctx, sequence.Sale, time.Now())
+	if err != nil || next != prefix+"0003" {
+		t.Fatalf("expected next number %s0003, got %s (%v)", prefix, next, err)
+	}
+}
+
+func TestPartialRefundsProrateAndCapQuantities(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "refunds.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	service := sale.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, TaxRateBasisPoints: 500, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	pen, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Pen", SKU: "PEN", UnitPriceCents: 250, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	created, err := service.Create(ctx, sale.CreateRequest{
+		PaymentMethod: "Card",
+		DiscountCents: 100,
+		Lines: []sale.CreateRequestLine{
+			{ProductID: mug.ID, Quantity: 3, DiscountCents: 300},
+			{ProductID: pen.ID, Quantity: 2},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+	mugLine, penLine := created.Lines[0], created.Lines[1]
+	if mugLine.ID == 0 || penLine.ID == 0 {
+		t.Fatalf("expected sale line ids, got %+v", created.Lines)
+	}
+
+	first, err := service.CreateRefund(ctx, sale.RefundRequest{
+		SaleID:        created.ID,
+		PaymentMethod: "Cash",
+		Reason:        "Chipped",
+		Lines:         []sale.RefundRequestLine{{SaleLineID: mugLine.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("partial refund: %v", err)
+	}
+	if !strings.HasPrefix(first.RefundNumber, "CN-") || first.PaymentMethod != "Cash" {
+		t.Fatalf("unexpected refund header %+v", first)
+	}
+	// One of three mugs: a third of the line's 3000 subtotal and 300 discount, of the 85 of
+	// the order discount the mugs carry (2700 of the 3200 discounted subtotal) and of the 131
+	// tax charged on the 2615 left.
+	line := first.Lines[0]
+	if line.SubtotalCents != 1000 || line.LineDiscountCents != 100 || line.TaxCents != 43 || line.OrderDiscountCents != 28 {
+		t.Fatalf("unexpected pro-rated line %+v", line)
+	}
+	if first.TotalCents != 1000-100-28+43 {
+		t.Fatalf("unexpected refund total %d", first.TotalCents)
+	}
+
+	reloaded, err := service.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get sale: %v", err)
+	}
+	if reloaded.Status != "PartiallyRefunded" || reloaded.Lines[0].RefundedQty != 1 {
+		t.Fatalf("expected partially refunded sale, got %s %+v", reloaded.Status, reloaded.Lines[0])
+	}
+	if p, _ := productRepo.GetByID(ctx, mug.ID); p.CurrentQty != 8 {
+		t.Fatalf("expected one mug restocked, got %d", p.CurrentQty)
+	}
+	if p, _ := productRepo.GetByID(ctx, pen.ID); p.CurrentQty != 8 {
+		t.Fatalf("expected pens untouched, got %d", p.CurrentQty)
+	}
+
+	if _, err := service.CreateRefund(ctx, sale.RefundRequest{
+		SaleID: created.ID,
+		Lines:  []sale.RefundRequestLine{{SaleLineID: mugLine.ID, Quantity: 3}},
+	}); err == nil {
+		t.Fatalf("expected refund beyond the quantity sold to fail")
+	}
+	if err := service.Void(ctx, created.ID, ""); err == nil {
+		t.Fatalf("expected void of a partially refunded sale to fail")
+	}
+
+	if err := service.Refund(ctx, created.ID); err != nil {
+		t.Fatalf("refund remainder: %v", err)
+	}
+	refunds, err := service.Refunds(ctx, created.ID)
+	if err != nil || len(refunds) != 2 {
+		t.Fatalf("expected two refunds, got %d (%v)", len(refunds), err)
+	}
+	if refunds[1].PaymentMethod != "Card" {
+		t.Fatalf("expected remainder refunded to the sale's payment method, got %s", refunds[1].PaymentMethod)
+	}
+	if total := refunds[0].TotalCents + refunds[1].TotalCents; total != created.TotalCents {
+		t.Fatalf("refunds total %d, sale total %d", total, created.TotalCents)
+	}
+	if reloaded, _ = service.Get(ctx, created.ID); reloaded.Status != "Refunded" {
+		t.Fatalf("expected refunded sale, got %s", reloaded.Status)
+	}
+}
+
+func TestExchangeNetsReturnAgainstReplacement(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "exchanges.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	saleRepo := sqlite.NewSaleRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	service := sale.NewService(productRepo, saleRepo, settingsRepo)
+
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, TaxRateBasisPoints: 500, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	scarf, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Scarf", SKU: "SCARF", UnitPriceCents: 2500, CurrentQty: 1})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	pen, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Pen", SKU: "PEN", UnitPriceCents: 250, CurrentQty: 5})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	created, err := service.Create(ctx, sale.CreateRequest{
+		CustomerName:  "Alice",
+		PaymentMethod: "Card",
+		Lines:         []sale.CreateRequestLine{{ProductID: mug.ID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+	mugLine := created.Lines[0]
+
+	upgrade, err := service.Exchange(ctx, sale.ExchangeRequest{
+		SaleID:        created.ID,
+		PaymentMethod: "Cash",
+		Reason:        "Wrong colour",
+		Returns:       []sale.RefundRequestLine{{SaleLineID: mugLine.ID, Quantity: 1}},
+		Lines:         []sale.CreateRequestLine{{ProductID: scarf.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	// One mug is credited at 1000 + 50 tax against a 2500 scarf.
+	if upgrade.Return.TotalCents != 1050 || upgrade.Sale.TotalCents != 2500 || upgrade.NetCents != 1450 {
+		t.Fatalf("unexpected exchange totals: credit %d, sale %d, net %d", upgrade.Return.TotalCents, upgrade.Sale.TotalCents, upgrade.NetCents)
+	}
+	if upgrade.Return.PaymentMethod != "Cash" || upgrade.Sale.PaymentMethod != "Cash" || upgrade.Sale.CustomerName != "Alice" {
+		t.Fatalf("expected both sides settled in cash for Alice, got %+v / %+v", upgrade.Return, upgrade.Sale)
+	}
+	if p, _ := productRepo.GetByID(ctx, mug.ID); p.CurrentQty != 9 {
+		t.Fatalf("expected returned mug restocked, got %d", p.CurrentQty)
+	}
+	if p, _ := productRepo.GetByID(ctx, scarf.ID); p.CurrentQty != 0 {
+		t.Fatalf("expected scarf sold, got %d", p.CurrentQty)
+	}
+
+	loaded, err := service.GetExchange(ctx, upgrade.ID)
+	if err != nil {
+		t.Fatalf("get exchange: %v", err)
+	}
+	if loaded.Return.RefundNumber != upgrade.Return.RefundNumber || loaded.Sale.SaleNumber != upgrade.Sale.SaleNumber || loaded.NetCents != 1450 {
+		t.Fatalf("unexpected reloaded exchange %+v", loaded)
+	}
+
+	// The replacement is out of stock, so the return is rolled back with it.
+	if _, err := service.Exchange(ctx, sale.ExchangeRequest{
+		SaleID:  created.ID,
+		Returns: []sale.RefundRequestLine{{SaleLineID: mugLine.ID, Quantity: 1}},
+		Lines:   []sale.CreateRequestLine{{ProductID: scarf.ID, Quantity: 1}},
+	}); err == nil {
+		t.Fatalf("expected exchange for an out-of-stock item to fail")
+	}
+	if refunds, _ := service.Refunds(ctx, created.ID); len(refunds) != 1 {
+		t.Fatalf("expected the failed exchange to leave one refund, got %d", len(refunds))
+	}
+	if p, _ := productRepo.GetByID(ctx, mug.ID); p.CurrentQty != 9 {
+		t.Fatalf("expected failed exchange to leave mug stock alone, got %d", p.CurrentQty)
+	}
+
+	downgrade, err := service.Exchange(ctx, sale.ExchangeRequest{
+		SaleID:  created.ID,
+		Returns: []sale.RefundRequestLine{{SaleLineID: mugLine.ID, Quantity: 1}},
+		Lines:   []sale.CreateRequestLine{{ProductID: pen.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if downgrade.NetCents != 250-1050 || downgrade.PaymentMethod != "Card" {
+		t.Fatalf("expected 800 refunded to the card, got %d to %s", -downgrade.NetCents, downgrade.PaymentMethod)
+	}
+	if reloaded, _ := service.Get(ctx, created.ID); reloaded.Status != "Refunded" {
+		t.Fatalf("expected original sale refunded, got %s", reloaded.Status)
+	}
+
+	invoices, err := invoice.NewService(saleRepo, sqlite.NewQuoteRepository(store.DB()), settingsRepo)
+	if err != nil {
+		t.Fatalf("invoice service: %v", err)
+	}
+	html, err := invoices.ExchangeHTML(ctx, downgrade.ID)
+	if err != nil {
+		t.Fatalf("exchange receipt: %v", err)
+	}
+	for _, want := range []string{downgrade.Return.RefundNumber, downgrade.Sale.SaleNumber, "Refunded to customer", "8.00"} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("expected receipt to contain %q", want)
+		}
+	}
+}
+
+func TestSplitTenderGivesChangeFromCash(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "payments.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	service := sale.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, TaxRateBasisPoints: 500, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	lines := []sale.CreateRequestLine{{ProductID: mug.ID, Quantity: 2}}
+
+	for _, payments := range [][]sale.CreatePaymentRequest{
+		{{Method: "Card", AmountCents: 1500}, {Method: "Cash", AmountCents: 500}},
+		{{Method: "Card", AmountCents: 2500}},
+	} {
+		if _, err := service.Create(ctx, sale.CreateRequest{Payments: payments, Lines: lines}); err == nil {
+			t.Fatalf("expected payments %+v to be rejected", payments)
+		}
+	}
+
+	created, err := service.Create(ctx, sale.CreateRequest{
+		Payments: []sale.CreatePaymentRequest{{Method: "Card", AmountCents: 1500}, {Method: "Cash", AmountCents: 1000}},
+		Lines:    lines,
+	})
+	if err != nil {
+		t.Fatalf("create sale: %v", err)
+	}
+	if created.TotalCents != 2100 || created.ChangeCents != 400 || created.PaymentMethod != saledomain.PaymentSplit {
+		t.Fatalf("unexpected settlement: total %d, change %d, method %s", created.TotalCents, created.ChangeCents, created.PaymentMethod)
+	}
+
+	reloaded, err := service.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get sale: %v", err)
+	}
+	if len(reloaded.Payments) != 2 || reloaded.Payments[1].AppliedCents != 600 || reloaded.ChangeCents != 400 {
+		t.Fatalf("unexpected stored payments %+v change %d", reloaded.Payments, reloaded.ChangeCents)
+	}
+
+	byCard, err := service.List(ctx, saledomain.Filter{PaymentMethods: []string{"Card"}})
+	if err != nil || len(byCard) != 1 || byCard[0].ID != created.ID {
+		t.Fatalf("expected the split sale when filtering by card, got %d (%v)", len(byCard), err)
+	}
+
+	refund := sale.RefundRequest{SaleID: created.ID, Lines: []sale.RefundRequestLine{{SaleLineID: reloaded.Lines[0].ID, Quantity: 1}}}
+	if _, err := service.CreateRefund(ctx, refund); err == nil {
+		t.Fatalf("expected a refund of a split-tender sale to need a payment method")
+	}
+	refund.PaymentMethod = "Cash"
+	if _, err := service.CreateRefund(ctx, refund); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+
+	breakdown, err := sqlite.NewReportRepository(store.DB()).PaymentBreakdown(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("payment breakdown: %v", err)
+	}
+	if len(breakdown) != 2 {
+		t.Fatalf("expected card and cash totals, got %+v", breakdown)
+	}
+	card, cash := breakdown[0], breakdown[1]
+	if card.Method != "Card" || card.ReceivedCents != 1500 || card.NetCents != 1500 {
+		t.Fatalf("unexpected card total %+v", card)
+	}
+	if cash.TenderedCents != 1000 || cash.ChangeCents != 400 || cash.ReceivedCents != 600 || cash.RefundedCents != 1050 || cash.NetCents != -450 {
+		t.Fatalf("unexpected cash total %+v", cash)
+	}
+}
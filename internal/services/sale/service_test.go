@@ -1,8 +1,13 @@
 package sale
 
-import "testing"
+import (
+	"testing"
 
-func TestComputeTax(t *testing.T) {
+	"shopmate/internal/domain/tax"
+)
+
+// A product without a tax group is taxed at its single rate, rounded on the line.
+func TestSingleRateTax(t *testing.T) {
 	tests := []struct {
 		name   string
 		amount int64
@@ -17,9 +22,9 @@ func TestComputeTax(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := computeTax(tc.amount, tc.rateBP)
-			if got != tc.want {
-				t.Fatalf("computeTax(%d,%d) = %d want %d", tc.amount, tc.rateBP, got, tc.want)
+			taxes, _ := tax.Policy{Rounding: tax.RoundPerLine}.Compute([]tax.Line{{AmountCents: tc.amount, Components: tax.Single(tc.rateBP)}})
+			if got := taxes[0].TaxCents; got != tc.want {
+				t.Fatalf("tax on %d at %d = %d want %d", tc.amount, tc.rateBP, got, tc.want)
 			}
 		})
 	}
@@ -27,16 +32,15 @@ func TestComputeTax(t *testing.T) {
 
 func TestValidateCreateRequest(t *testing.T) {
 	base := CreateRequest{
-		SaleNumber:    "INV-1",
 		PaymentMethod: "Cash",
 		Lines:         []CreateRequestLine{{ProductID: 1, Quantity: 1}},
 		DiscountCents: 0,
 	}
 
 	invalids := []CreateRequest{
-		{SaleNumber: "", PaymentMethod: "Cash", Lines: base.Lines},
-		{SaleNumber: "INV", PaymentMethod: "", Lines: base.Lines},
-		{SaleNumber: "INV", PaymentMethod: "Cash", Lines: nil},
+		{PaymentMethod: "", Lines: base.Lines},
+		{PaymentMethod: "Cash", Lines: nil},
+		{PaymentMethod: "Cash", Lines: base.Lines, DiscountCents: -1},
 	}
 
 	for _, req := range invalids {
@@ -0,0 +1,46 @@
+package sequence
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/sequence"
+)
+
+// Service manages the formats of document number sequences. Numbers themselves are
+// allocated by the repositories that save the numbered documents.
+type Service struct {
+	repo *sqlite.SequenceRepository
+	now  func() time.Time
+}
+
+// NewService constructs a sequence service.
+func NewService(repo *sqlite.SequenceRepository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Format returns a sequence's format.
+func (s *Service) Format(ctx context.Context, name string) (domain.Format, error) {
+	if name == "" {
+		return domain.Format{}, errors.New("sequence name is required")
+	}
+	return s.repo.Format(ctx, name)
+}
+
+// SaveFormat stores a sequence's format and returns it.
+func (s *Service) SaveFormat(ctx context.Context, format domain.Format) (domain.Format, error) {
+	if err := s.repo.SaveFormat(ctx, format); err != nil {
+		return domain.Format{}, err
+	}
+	return s.repo.Format(ctx, format.Name)
+}
+
+// Next previews the number a sequence will issue next.
+func (s *Service) Next(ctx context.Context, name string) (string, error) {
+	if name == "" {
+		return "", errors.New("sequence name is required")
+	}
+	return s.repo.Peek(ctx, name, s.now())
+}
@@ -0,0 +1,108 @@
+package sequence_test
+
+import (
+	"context"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	productdomain "shopmate/internal/domain/product"
+	quotedomain "shopmate/internal/domain/quote"
+	domain "shopmate/internal/domain/sequence"
+	saleservice "shopmate/internal/services/sale"
+	sequenceservice "shopmate/internal/services/sequence"
+)
+
+func TestSequenceFormatsNumberDocuments(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "sequences.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	quoteRepo := sqlite.NewQuoteRepository(store.DB())
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), sqlite.NewSettingsRepository(store.DB()))
+	service := sequenceservice.NewService(sqlite.NewSequenceRepository(store.DB()))
+
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, CurrentQty: 20})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	format, err := service.Format(ctx, domain.Sale)
+	if err != nil || format != domain.DefaultFormat(domain.Sale) {
+		t.Fatalf("expected the default sale format, got %+v (%v)", format, err)
+	}
+	next, err := service.Next(ctx, domain.Sale)
+	if want := "INV-" + strconv.Itoa(time.Now().Year()) + "-000001"; err != nil || next != want {
+		t.Fatalf("expected %s next, got %q (%v)", want, next, err)
+	}
+	if _, err := service.SaveFormat(ctx, domain.Format{Name: domain.Sale, Pattern: "INV-{YYYY}"}); err == nil {
+		t.Fatal("expected a pattern without a counter to be rejected")
+	}
+
+	// Every date token is rendered from the time the number is allocated, and the yearly
+	// reset gives each year its own counter.
+	if _, err := service.SaveFormat(ctx, domain.Format{Name: domain.Quote, Pattern: "Q{YY}{MM}{DD}/{SEQ:3}-{YYYY}", ResetYearly: true}); err != nil {
+		t.Fatalf("save quote format: %v", err)
+	}
+	quoteAt := func(at time.Time) string {
+		t.Helper()
+		created, err := quoteRepo.Create(ctx, quotedomain.Draft{
+			ValidUntil: at.AddDate(0, 0, 30),
+			Lines:      []quotedomain.Line{{ProductID: mug.ID, ProductName: "Mug", SKU: "MUG", Quantity: 1}},
+		}, at)
+		if err != nil {
+			t.Fatalf("create quote: %v", err)
+		}
+		return created.QuoteNumber
+	}
+	for i, tc := range []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2025, time.December, 31, 17, 0, 0, 0, time.UTC), "Q251231/001-2025"},
+		{time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC), "Q260101/001-2026"},
+		{time.Date(2026, time.March, 5, 9, 0, 0, 0, time.UTC), "Q260305/002-2026"},
+		{time.Date(2025, time.June, 1, 9, 0, 0, 0, time.UTC), "Q250601/002-2025"},
+	} {
+		if got := quoteAt(tc.at); got != tc.want {
+			t.Fatalf("quote %d numbered %s, want %s", i, got, tc.want)
+		}
+	}
+
+	// Dropping the yearly reset starts a fresh counter; numbers it would repeat are skipped.
+	if _, err := service.SaveFormat(ctx, domain.Format{Name: domain.Sale, Pattern: "S{YY}-{SEQ:3}", ResetYearly: true}); err != nil {
+		t.Fatalf("save sale format: %v", err)
+	}
+	sell := func() string {
+		t.Helper()
+		created, err := sales.Create(ctx, saleservice.CreateRequest{
+			PaymentMethod: "Cash",
+			Lines:         []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: 1}},
+		})
+		if err != nil {
+			t.Fatalf("sell: %v", err)
+		}
+		return created.SaleNumber
+	}
+	first, second := sell(), sell()
+	prefix := first[:4]
+	if first != prefix+"001" || second != prefix+"002" {
+		t.Fatalf("unexpected sale numbers %s, %s", first, second)
+	}
+	saved, err := service.SaveFormat(ctx, domain.Format{Name: domain.Sale, Pattern: "S{YY}-{SEQ:3}"})
+	if err != nil || saved.ResetYearly {
+		t.Fatalf("save sale format: %+v (%v)", saved, err)
+	}
+	if third := sell(); third != prefix+"003" {
+		t.Fatalf("expected the numbers already issued skipped, got %s", third)
+	}
+	if next, err := service.Next(ctx, domain.Sale); err != nil || next != prefix+"004" {
+		t.Fatalf("expected %s004 next, got %q (%v)", prefix, next, err)
+	}
+}
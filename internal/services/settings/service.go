@@ -59,6 +59,45 @@ func (s *Service) SavePreferences(ctx context.Context, prefs domain.Preferences)
 	return s.repo.LoadPreferences(ctx)
 }
 
+// Till returns the register configuration.
+func (s *Service) Till(ctx context.Context) (domain.Till, error) {
+	return s.repo.LoadTill(ctx)
+}
+
+// SaveTill stores the register configuration.
+func (s *Service) SaveTill(ctx context.Context, till domain.Till) (domain.Till, error) {
+	if err := s.repo.SaveTill(ctx, till); err != nil {
+		return domain.Till{}, err
+	}
+	return s.repo.LoadTill(ctx)
+}
+
+// Costing returns the inventory costing method.
+func (s *Service) Costing(ctx context.Context) (domain.Costing, error) {
+	return s.repo.LoadCosting(ctx)
+}
+
+// SaveCosting stores the inventory costing method.
+func (s *Service) SaveCosting(ctx context.Context, costing domain.Costing) (domain.Costing, error) {
+	if err := s.repo.SaveCosting(ctx, costing); err != nil {
+		return domain.Costing{}, err
+	}
+	return s.repo.LoadCosting(ctx)
+}
+
+// StockPolicy returns the shop-wide stock rules.
+func (s *Service) StockPolicy(ctx context.Context) (domain.StockPolicy, error) {
+	return s.repo.LoadStockPolicy(ctx)
+}
+
+// SaveStockPolicy stores the shop-wide stock rules.
+func (s *Service) SaveStockPolicy(ctx context.Context, policy domain.StockPolicy) (domain.StockPolicy, error) {
+	if err := s.repo.SaveStockPolicy(ctx, policy); err != nil {
+		return domain.StockPolicy{}, err
+	}
+	return s.repo.LoadStockPolicy(ctx)
+}
+
 // SetOwnerPIN validates and stores the owner pin.
 func (s *Service) SetOwnerPIN(ctx context.Context, pin string) error {
 	if !pinPattern.MatchString(pin) {
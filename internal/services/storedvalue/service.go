@@ -0,0 +1,72 @@
+package storedvalue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/storedvalue"
+)
+
+// Service sells gift cards and manages gift card and store credit balances. Sales spend
+// them as a tender; refunds and voids put value back.
+//
+// Selling a gift card is not recorded as a sale: the money taken is owed to the holder
+// until the card is spent, so the issue is kept on the account's ledger with the payment
+// method it was paid in.
+type Service struct {
+	repo *sqlite.StoredValueRepository
+	now  func() time.Time
+}
+
+// NewService constructs a stored-value service.
+func NewService(repo *sqlite.StoredValueRepository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Issue sells a gift card or opens store credit, generating a code unless issue has one.
+func (s *Service) Issue(ctx context.Context, issue domain.Issue) (*domain.Account, error) {
+	account, err := s.repo.Issue(ctx, issue, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("issue account: %w", err)
+	}
+	return account, nil
+}
+
+// TopUp loads more value onto an account, paid in paymentMethod.
+func (s *Service) TopUp(ctx context.Context, code string, amountCents int64, paymentMethod, note string) (*domain.Account, error) {
+	account, err := s.repo.TopUp(ctx, code, amountCents, paymentMethod, note, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("top up account: %w", err)
+	}
+	return account, nil
+}
+
+// Void closes an account; whatever is left on it is forfeited.
+func (s *Service) Void(ctx context.Context, code, note string) (*domain.Account, error) {
+	account, err := s.repo.Void(ctx, code, note, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("void account: %w", err)
+	}
+	return account, nil
+}
+
+// Balance looks an account up by code for a balance enquiry.
+func (s *Service) Balance(ctx context.Context, code string) (*domain.Account, error) {
+	return s.repo.Get(ctx, code)
+}
+
+// Ledger lists an account's movements, newest first.
+func (s *Service) Ledger(ctx context.Context, code string) ([]domain.Entry, error) {
+	return s.repo.Ledger(ctx, code)
+}
+
+// List returns all accounts, or a customer's when customerID is set.
+func (s *Service) List(ctx context.Context, customerID int64) ([]domain.Account, error) {
+	if customerID < 0 {
+		return nil, errors.New("invalid customer id")
+	}
+	return s.repo.List(ctx, customerID)
+}
@@ -0,0 +1,173 @@
+package storedvalue_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	customerdomain "shopmate/internal/domain/customer"
+	productdomain "shopmate/internal/domain/product"
+	domainsale "shopmate/internal/domain/sale"
+	domain "shopmate/internal/domain/storedvalue"
+	saleservice "shopmate/internal/services/sale"
+	storedvalueservice "shopmate/internal/services/storedvalue"
+)
+
+func TestGiftCardsAndStoreCreditFollowSalesRefundsAndVoids(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "storedvalue.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	saleRepo := sqlite.NewSaleRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	sales := saleservice.NewService(productRepo, saleRepo, settingsRepo)
+	service := storedvalueservice.NewService(sqlite.NewStoredValueRepository(store.DB()))
+
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 1000, CurrentQty: 10})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	jane, err := sqlite.NewCustomerRepository(store.DB()).Create(ctx, customerdomain.Input{Name: "Jane"}, time.Now())
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	balance := func(code string, want int64) {
+		t.Helper()
+		account, err := service.Balance(ctx, code)
+		if err != nil {
+			t.Fatalf("balance: %v", err)
+		}
+		if account.BalanceCents != want {
+			t.Fatalf("expected %s to hold %d, got %d", code, want, account.BalanceCents)
+		}
+	}
+
+	card, err := service.Issue(ctx, domain.Issue{AmountCents: 1500, PaymentMethod: "Cash"})
+	if err != nil {
+		t.Fatalf("issue gift card: %v", err)
+	}
+	if card.Kind != domain.KindGiftCard || !strings.HasPrefix(card.Code, "GC-") {
+		t.Fatalf("unexpected gift card %+v", card)
+	}
+	if _, err := service.Issue(ctx, domain.Issue{Code: strings.ToLower(card.Code), AmountCents: 100, PaymentMethod: "Cash"}); err == nil {
+		t.Fatal("expected a duplicate code to be rejected")
+	}
+
+	// Lowercase, spaced codes match the card.
+	first, err := sales.Create(ctx, saleservice.CreateRequest{
+		Payments: []saleservice.CreatePaymentRequest{{Method: domainsale.PaymentGiftCard, AmountCents: 1000, Reference: " " + strings.ToLower(card.Code)}},
+		Lines:    []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("pay with gift card: %v", err)
+	}
+	if first.Payments[0].Reference != card.Code {
+		t.Fatalf("expected the payment to carry the code, got %q", first.Payments[0].Reference)
+	}
+	balance(card.Code, 500)
+
+	if _, err := sales.Create(ctx, saleservice.CreateRequest{
+		Payments: []saleservice.CreatePaymentRequest{{Method: domainsale.PaymentGiftCard, AmountCents: 1000, Reference: card.Code}},
+		Lines:    []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: 1}},
+	}); err == nil {
+		t.Fatal("expected spending more than the balance to fail")
+	}
+	if _, err := sales.Create(ctx, saleservice.CreateRequest{
+		Payments: []saleservice.CreatePaymentRequest{{Method: domainsale.PaymentStoreCredit, AmountCents: 1000, Reference: card.Code}},
+		Lines:    []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: 1}},
+	}); err == nil {
+		t.Fatal("expected a gift card not to be taken as store credit")
+	}
+	balance(card.Code, 500)
+
+	// The refund goes back onto the card the sale was paid with.
+	refund, err := sales.CreateRefund(ctx, saleservice.RefundRequest{
+		SaleID: first.ID,
+		Lines:  []saleservice.RefundRequestLine{{SaleLineID: first.Lines[0].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("refund to gift card: %v", err)
+	}
+	if refund.Reference != card.Code {
+		t.Fatalf("expected the refund to name the card, got %q", refund.Reference)
+	}
+	balance(card.Code, 1500)
+
+	second, err := sales.Create(ctx, saleservice.CreateRequest{
+		CustomerID: jane.ID,
+		Payments: []saleservice.CreatePaymentRequest{
+			{Method: domainsale.PaymentGiftCard, AmountCents: 600, Reference: card.Code},
+			{Method: "Cash", AmountCents: 1400},
+		},
+		Lines: []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("split gift card payment: %v", err)
+	}
+	balance(card.Code, 900)
+
+	// Store credit without an account opens one for the customer.
+	credit, err := sales.CreateRefund(ctx, saleservice.RefundRequest{
+		SaleID:        second.ID,
+		PaymentMethod: domainsale.PaymentStoreCredit,
+		Lines:         []saleservice.RefundRequestLine{{SaleLineID: second.Lines[0].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("refund to store credit: %v", err)
+	}
+	if !strings.HasPrefix(credit.Reference, "SC-") {
+		t.Fatalf("expected new store credit, got %q", credit.Reference)
+	}
+	balance(credit.Reference, 1000)
+	accounts, err := service.List(ctx, jane.ID)
+	if err != nil || len(accounts) != 1 || accounts[0].Code != credit.Reference || accounts[0].CustomerName != "Jane" {
+		t.Fatalf("expected Jane's store credit, got %+v (%v)", accounts, err)
+	}
+
+	// Voiding gives back the gift card's share; the card payment's authorisation code is
+	// not an account.
+	third, err := sales.Create(ctx, saleservice.CreateRequest{
+		Payments: []saleservice.CreatePaymentRequest{{Method: domainsale.PaymentGiftCard, AmountCents: 400, Reference: card.Code}, {Method: "Card", AmountCents: 600, Reference: "AUTH-1"}},
+		Lines:    []saleservice.CreateRequestLine{{ProductID: mug.ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("pay with gift card: %v", err)
+	}
+	balance(card.Code, 500)
+	if third.Payments[1].Reference != "AUTH-1" {
+		t.Fatalf("expected the card payment to keep its reference, got %+v", third.Payments)
+	}
+	if err := sales.Void(ctx, third.ID, "till error"); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+	balance(card.Code, 900)
+
+	if _, err := service.TopUp(ctx, card.Code, 500, "Card", ""); err != nil {
+		t.Fatalf("top up: %v", err)
+	}
+	voided, err := service.Void(ctx, card.Code, "lost")
+	if err != nil || voided.Status != domain.StatusVoided || voided.BalanceCents != 0 {
+		t.Fatalf("expected the card voided and emptied, got %+v (%v)", voided, err)
+	}
+	if _, err := service.TopUp(ctx, card.Code, 500, "Card", ""); err == nil {
+		t.Fatal("expected a voided card not to be topped up")
+	}
+
+	ledger, err := service.Ledger(ctx, card.Code)
+	if err != nil || len(ledger) != 8 {
+		t.Fatalf("expected eight ledger entries, got %d (%v)", len(ledger), err)
+	}
+	if ledger[0].Kind != domain.EntryVoid || ledger[0].AmountCents != -1400 || ledger[len(ledger)-1].Kind != domain.EntryIssue {
+		t.Fatalf("unexpected ledger %+v", ledger)
+	}
+	if ledger[len(ledger)-2].SaleNumber != first.SaleNumber {
+		t.Fatalf("expected the redemption to name its sale, got %+v", ledger[len(ledger)-2])
+	}
+}
@@ -0,0 +1,78 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domain "shopmate/internal/domain/tax"
+)
+
+// Service manages the shop's tax policy and the tax groups products are taxed by.
+type Service struct {
+	repo     *sqlite.TaxRepository
+	settings *sqlite.SettingsRepository
+	now      func() time.Time
+}
+
+// NewService constructs a tax service.
+func NewService(repo *sqlite.TaxRepository, settings *sqlite.SettingsRepository) *Service {
+	return &Service{repo: repo, settings: settings, now: time.Now}
+}
+
+// Policy returns whether prices include tax and how tax is rounded.
+func (s *Service) Policy(ctx context.Context) (domain.Policy, error) {
+	return s.settings.LoadTaxPolicy(ctx)
+}
+
+// SavePolicy stores the tax policy. Sales already recorded keep the tax they were charged.
+func (s *Service) SavePolicy(ctx context.Context, policy domain.Policy) (domain.Policy, error) {
+	if err := s.settings.SaveTaxPolicy(ctx, policy); err != nil {
+		return domain.Policy{}, fmt.Errorf("save tax policy: %w", err)
+	}
+	return s.settings.LoadTaxPolicy(ctx)
+}
+
+// CreateGroup adds a tax group.
+func (s *Service) CreateGroup(ctx context.Context, input domain.Input) (*domain.Group, error) {
+	created, err := s.repo.Create(ctx, input, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("create tax group: %w", err)
+	}
+	return created, nil
+}
+
+// UpdateGroup replaces a tax group's components; sales already recorded are not retaxed.
+func (s *Service) UpdateGroup(ctx context.Context, id int64, input domain.Input) (*domain.Group, error) {
+	if id <= 0 {
+		return nil, errors.New("tax group id required")
+	}
+	updated, err := s.repo.Update(ctx, id, input, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("update tax group: %w", err)
+	}
+	return updated, nil
+}
+
+// GetGroup retrieves a tax group by id.
+func (s *Service) GetGroup(ctx context.Context, id int64) (*domain.Group, error) {
+	if id <= 0 {
+		return nil, errors.New("tax group id required")
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// ListGroups returns every tax group by name.
+func (s *Service) ListGroups(ctx context.Context) ([]domain.Group, error) {
+	return s.repo.List(ctx)
+}
+
+// DeleteGroup removes a tax group once no product is assigned to it.
+func (s *Service) DeleteGroup(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("tax group id required")
+	}
+	return s.repo.Delete(ctx, id)
+}
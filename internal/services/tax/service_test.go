@@ -0,0 +1,255 @@
+package tax_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"shopmate/internal/adapters/storage/sqlite"
+	domaincustomer "shopmate/internal/domain/customer"
+	productdomain "shopmate/internal/domain/product"
+	domain "shopmate/internal/domain/tax"
+	saleservice "shopmate/internal/services/sale"
+	taxservice "shopmate/internal/services/tax"
+)
+
+func TestTaxGroupsPoliciesAndExemptions(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "tax.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	taxRepo := sqlite.NewTaxRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), settingsRepo)
+	sales.SetTaxes(taxRepo)
+	service := taxservice.NewService(taxRepo, settingsRepo)
+
+	group, err := service.CreateGroup(ctx, domain.Input{Name: " State + City ", Components: []domain.Component{
+		{Name: "State", RateBasisPoints: 600},
+		{Name: "City", RateBasisPoints: 225},
+	}})
+	if err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if group.Name != "State + City" || len(group.Components) != 2 || group.Components[1].Name != "City" {
+		t.Fatalf("unexpected group %+v", group)
+	}
+	if _, err := service.CreateGroup(ctx, domain.Input{Name: "state + city", Components: group.Components}); err == nil {
+		t.Fatal("expected a duplicate group name to be rejected")
+	}
+	zero, err := service.CreateGroup(ctx, domain.Input{Name: "Zero", Components: []domain.Component{{Name: "Zero", RateBasisPoints: 0}}})
+	if err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if _, err := service.UpdateGroup(ctx, zero.ID, domain.Input{Name: "STATE + CITY", Components: zero.Components}); err == nil {
+		t.Fatal("expected a rename to another group's name to be rejected")
+	}
+	if err := service.DeleteGroup(ctx, zero.ID); err != nil {
+		t.Fatalf("delete group: %v", err)
+	}
+
+	widget, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Widget", SKU: "WID", UnitPriceCents: 1000, TaxRateBasisPoints: 500, TaxGroupID: group.ID, CurrentQty: 20})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	mug, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Mug", SKU: "MUG", UnitPriceCents: 500, TaxRateBasisPoints: 1000, CurrentQty: 20})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	sell := func(customerID int64, lines ...saleservice.CreateRequestLine) saleservice.CreateRequest {
+		return saleservice.CreateRequest{PaymentMethod: "Cash", CustomerID: customerID, Lines: lines}
+	}
+
+	// By default tax is added to prices and rounded per line; the mug keeps its own rate.
+	first, err := sales.Create(ctx, sell(0, saleservice.CreateRequestLine{ProductID: widget.ID, Quantity: 1}, saleservice.CreateRequestLine{ProductID: mug.ID, Quantity: 1}))
+	if err != nil {
+		t.Fatalf("sell: %v", err)
+	}
+	if first.Lines[0].LineTaxCents != 83 || first.Lines[0].TaxRateBasisPoints != 825 || first.Lines[1].LineTaxCents != 50 {
+		t.Fatalf("unexpected line taxes %+v", first.Lines)
+	}
+	if first.TaxIncluded || first.TaxCents != 133 || first.TotalCents != 1633 {
+		t.Fatalf("unexpected totals %+v", first)
+	}
+	stored, err := sales.Get(ctx, first.ID)
+	if err != nil {
+		t.Fatalf("get sale: %v", err)
+	}
+	if len(stored.Taxes) != 3 || stored.Taxes[0].Name != "State" || stored.Taxes[0].TaxCents != 60 ||
+		stored.Taxes[1].TaxCents != 23 || stored.Taxes[2].Name != "Tax" || stored.Taxes[2].TaxableCents != 500 {
+		t.Fatalf("unexpected stored breakdown %+v", stored.Taxes)
+	}
+
+	// Compound components are charged on the tax before them.
+	if _, err := service.UpdateGroup(ctx, group.ID, domain.Input{Name: "GST + QST", Components: []domain.Component{
+		{Name: "GST", RateBasisPoints: 500},
+		{Name: "QST", RateBasisPoints: 1000, Compound: true},
+	}}); err != nil {
+		t.Fatalf("update group: %v", err)
+	}
+	compound, err := sales.Create(ctx, sell(0, saleservice.CreateRequestLine{ProductID: widget.ID, Quantity: 2}))
+	if err != nil {
+		t.Fatalf("sell compound: %v", err)
+	}
+	if compound.TaxCents != 310 || compound.Taxes[1].TaxableCents != 2100 || compound.Taxes[1].TaxCents != 210 {
+		t.Fatalf("unexpected compound tax %d %+v", compound.TaxCents, compound.Taxes)
+	}
+
+	if err := service.DeleteGroup(ctx, group.ID); err == nil || !strings.Contains(err.Error(), "assigned") {
+		t.Fatalf("expected a group in use to stay, got %v", err)
+	}
+	groups, err := service.ListGroups(ctx)
+	if err != nil || len(groups) != 1 || groups[0].ProductCount != 1 {
+		t.Fatalf("expected one group with one product, got %+v (%v)", groups, err)
+	}
+
+	// Rounded once over the invoice, three 5c lines at 10% carry 2c of tax rather than 3c.
+	if _, err := service.SavePolicy(ctx, domain.Policy{Rounding: domain.RoundPerInvoice}); err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+	sweet, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Sweet", SKU: "SWT", UnitPriceCents: 5, TaxRateBasisPoints: 1000, CurrentQty: 20})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	sweetLine := saleservice.CreateRequestLine{ProductID: sweet.ID, Quantity: 1}
+	perInvoice, err := sales.Create(ctx, sell(0, sweetLine, sweetLine, sweetLine))
+	if err != nil {
+		t.Fatalf("sell per invoice: %v", err)
+	}
+	if perInvoice.TaxCents != 2 || perInvoice.TotalCents != 17 {
+		t.Fatalf("expected 2c of tax per invoice, got %d (total %d)", perInvoice.TaxCents, perInvoice.TotalCents)
+	}
+
+	// Tax-inclusive shelf prices: the total is the shelf price and the tax is inside it.
+	if _, err := service.SavePolicy(ctx, domain.Policy{PricesIncludeTax: true}); err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+	if policy, _ := service.Policy(ctx); policy.Rounding != domain.RoundPerLine {
+		t.Fatalf("expected per-line rounding by default, got %+v", policy)
+	}
+	inclusive, err := sales.Create(ctx, sell(0, saleservice.CreateRequestLine{ProductID: mug.ID, Quantity: 2}))
+	if err != nil {
+		t.Fatalf("sell inclusive: %v", err)
+	}
+	if !inclusive.TaxIncluded || inclusive.TotalCents != 1000 || inclusive.TaxCents != 91 || inclusive.Lines[0].LineTotalCents != 1000 {
+		t.Fatalf("unexpected tax-inclusive sale %+v", inclusive)
+	}
+	refund, err := sales.CreateRefund(ctx, saleservice.RefundRequest{
+		SaleID: inclusive.ID,
+		Lines:  []saleservice.RefundRequestLine{{SaleLineID: inclusive.Lines[0].ID, Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if !refund.TaxIncluded || refund.TotalCents != inclusive.TotalCents || refund.TaxCents != inclusive.TaxCents {
+		t.Fatalf("expected the refund to return the sale's %d, got %+v", inclusive.TotalCents, refund)
+	}
+
+	// Exempt customers pay shelf prices less the tax included in them.
+	customer, err := sqlite.NewCustomerRepository(store.DB()).Create(ctx, domaincustomer.Input{Name: "Charity Shop", TaxExempt: true, TaxExemptionNumber: " EX-42 "}, time.Now())
+	if err != nil {
+		t.Fatalf("create customer: %v", err)
+	}
+	exempt, err := sales.Create(ctx, sell(customer.ID, saleservice.CreateRequestLine{ProductID: mug.ID, Quantity: 1}))
+	if err != nil {
+		t.Fatalf("sell exempt: %v", err)
+	}
+	if !exempt.TaxExempt || exempt.TaxExemptionNumber != "EX-42" || exempt.TaxIncluded || exempt.TaxCents != 0 ||
+		exempt.TotalCents != 455 || len(exempt.Taxes) != 0 {
+		t.Fatalf("unexpected exempt sale %+v", exempt)
+	}
+	kept, err := sales.Get(ctx, exempt.ID)
+	if err != nil {
+		t.Fatalf("get sale: %v", err)
+	}
+	if !kept.TaxExempt || kept.TaxExemptionNumber != "EX-42" {
+		t.Fatalf("expected the exemption stored on the sale, got %+v", kept)
+	}
+}
+
+func TestDiscountsComeOffBeforeTax(t *testing.T) {
+	ctx := context.Background()
+	store, err := sqlite.Open(ctx, filepath.Join(t.TempDir(), "discounts.sqlite"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer store.Close()
+
+	productRepo := sqlite.NewProductRepository(store.DB())
+	settingsRepo := sqlite.NewSettingsRepository(store.DB())
+	sales := saleservice.NewService(productRepo, sqlite.NewSaleRepository(store.DB()), settingsRepo)
+	service := taxservice.NewService(sqlite.NewTaxRepository(store.DB()), settingsRepo)
+
+	lamp, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Lamp", SKU: "LMP", UnitPriceCents: 1200, TaxRateBasisPoints: 2000, CurrentQty: 20})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	book, err := productRepo.Create(ctx, productdomain.CreateInput{Name: "Book", SKU: "BK", UnitPriceCents: 1000, TaxRateBasisPoints: 2000, CurrentQty: 20})
+	if err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+
+	// Tax added to prices is charged on what is left after the order discount.
+	exclusive, err := sales.Create(ctx, saleservice.CreateRequest{PaymentMethod: "Cash", DiscountCents: 600,
+		Lines: []saleservice.CreateRequestLine{{ProductID: lamp.ID, Quantity: 1}}})
+	if err != nil {
+		t.Fatalf("sell exclusive: %v", err)
+	}
+	if exclusive.TaxCents != 120 || exclusive.TotalCents != 720 || exclusive.Lines[0].LineTotalCents != 720 ||
+		exclusive.Taxes[0].TaxableCents != 600 || exclusive.Taxes[0].TaxCents != 120 {
+		t.Fatalf("unexpected order-discounted exclusive sale %+v", exclusive)
+	}
+
+	// Line discounts come off the total as well as the line.
+	lineDiscounted, err := sales.Create(ctx, saleservice.CreateRequest{PaymentMethod: "Cash",
+		Lines: []saleservice.CreateRequestLine{{ProductID: book.ID, Quantity: 1, DiscountCents: 200}}})
+	if err != nil {
+		t.Fatalf("sell line discount: %v", err)
+	}
+	if lineDiscounted.DiscountCents != 200 || lineDiscounted.TaxCents != 160 || lineDiscounted.Lines[0].LineTotalCents != 960 ||
+		lineDiscounted.TotalCents != 960 {
+		t.Fatalf("unexpected line-discounted sale %+v", lineDiscounted)
+	}
+
+	// Tax included in shelf prices is worked out of the discounted amount.
+	if _, err := service.SavePolicy(ctx, domain.Policy{PricesIncludeTax: true}); err != nil {
+		t.Fatalf("save policy: %v", err)
+	}
+	inclusive, err := sales.Create(ctx, saleservice.CreateRequest{PaymentMethod: "Cash", DiscountCents: 600,
+		Lines: []saleservice.CreateRequestLine{{ProductID: lamp.ID, Quantity: 1}}})
+	if err != nil {
+		t.Fatalf("sell inclusive: %v", err)
+	}
+	if inclusive.TaxCents != 100 || inclusive.TotalCents != 600 || inclusive.Lines[0].LineTotalCents != 600 ||
+		inclusive.Taxes[0].TaxCents != 100 {
+		t.Fatalf("unexpected order-discounted inclusive sale %+v", inclusive)
+	}
+	mixed, err := sales.Create(ctx, saleservice.CreateRequest{PaymentMethod: "Cash", DiscountCents: 300, Lines: []saleservice.CreateRequestLine{
+		{ProductID: lamp.ID, Quantity: 1},
+		{ProductID: book.ID, Quantity: 1, DiscountCents: 400},
+	}})
+	if err != nil {
+		t.Fatalf("sell mixed: %v", err)
+	}
+	// The 300 splits 200/100 over the 1200 lamp and the 600 left of the book.
+	if mixed.DiscountCents != 700 || mixed.TotalCents != 1500 || mixed.Lines[0].LineTotalCents != 1000 ||
+		mixed.Lines[1].LineTotalCents != 500 || mixed.TaxCents != 167+83 {
+		t.Fatalf("unexpected mixed discounts %+v", mixed)
+	}
+	refund, err := sales.CreateRefund(ctx, saleservice.RefundRequest{SaleID: mixed.ID, Lines: []saleservice.RefundRequestLine{
+		{SaleLineID: mixed.Lines[0].ID, Quantity: 1},
+		{SaleLineID: mixed.Lines[1].ID, Quantity: 1},
+	}})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if refund.TotalCents != mixed.TotalCents || refund.TaxCents != mixed.TaxCents || refund.DiscountCents != mixed.DiscountCents {
+		t.Fatalf("expected the refund to return the sale's %d, got %+v", mixed.TotalCents, refund)
+	}
+}
@@ -0,0 +1,129 @@
+package cart
+
+import (
+	"context"
+
+	domain "shopmate/internal/domain/cart"
+	cartservice "shopmate/internal/services/cart"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes parked carts to every POS window.
+type API struct {
+	service       *cartservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the held cart API bridge.
+func New(service *cartservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// ParkCartLine is a product in the cart being parked.
+type ParkCartLine struct {
+	ProductID     int64    `json:"productId"`
+	Quantity      int64    `json:"quantity"`
+	DiscountCents int64    `json:"discountCents"`
+	SerialNumbers []string `json:"serialNumbers"`
+}
+
+// ParkCartRequest is the cart to park; a zero location holds it at the till's location.
+type ParkCartRequest struct {
+	CustomerName  string         `json:"customerName"`
+	DiscountCents int64          `json:"discountCents"`
+	Note          string         `json:"note"`
+	ParkedBy      string         `json:"parkedBy"`
+	LocationID    int64          `json:"locationId"`
+	ReserveStock  bool           `json:"reserveStock"`
+	Lines         []ParkCartLine `json:"lines"`
+}
+
+// Park holds a cart to be resumed later.
+func (api *API) Park(req ParkCartRequest) response.Envelope[domain.Held] {
+	ctx := api.contextSource()
+	lines := make([]cartservice.ParkRequestLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, cartservice.ParkRequestLine{
+			ProductID:     line.ProductID,
+			Quantity:      line.Quantity,
+			DiscountCents: line.DiscountCents,
+			SerialNumbers: line.SerialNumbers,
+		})
+	}
+	held, err := api.service.Park(ctx, cartservice.ParkRequest{
+		CustomerName:  req.CustomerName,
+		DiscountCents: req.DiscountCents,
+		Note:          req.Note,
+		ParkedBy:      req.ParkedBy,
+		LocationID:    req.LocationID,
+		ReserveStock:  req.ReserveStock,
+		Lines:         lines,
+	})
+	if err != nil {
+		return response.Failure[domain.Held](err.Error())
+	}
+	return response.Success(*held)
+}
+
+// List returns carts still held.
+func (api *API) List() response.Envelope[[]domain.Held] {
+	ctx := api.contextSource()
+	carts, err := api.service.List(ctx)
+	if err != nil {
+		return response.Failure[[]domain.Held](err.Error())
+	}
+	return response.Success(carts)
+}
+
+// Get returns a held cart by id.
+func (api *API) Get(id int64) response.Envelope[domain.Held] {
+	ctx := api.contextSource()
+	held, err := api.service.Get(ctx, id)
+	if err != nil {
+		return response.Failure[domain.Held](err.Error())
+	}
+	return response.Success(*held)
+}
+
+// Resume takes a held cart back to the till and returns it for checkout.
+func (api *API) Resume(id int64) response.Envelope[domain.Held] {
+	ctx := api.contextSource()
+	held, err := api.service.Resume(ctx, id)
+	if err != nil {
+		return response.Failure[domain.Held](err.Error())
+	}
+	return response.Success(*held)
+}
+
+// Discard abandons a held cart.
+func (api *API) Discard(id int64) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.Discard(ctx, id); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
+
+// Policy returns how long carts are held.
+func (api *API) Policy() response.Envelope[domain.Policy] {
+	ctx := api.contextSource()
+	policy, err := api.service.Policy(ctx)
+	if err != nil {
+		return response.Failure[domain.Policy](err.Error())
+	}
+	return response.Success(policy)
+}
+
+// SavePolicy updates how long new carts are held.
+func (api *API) SavePolicy(policy domain.Policy) response.Envelope[domain.Policy] {
+	ctx := api.contextSource()
+	saved, err := api.service.SavePolicy(ctx, policy)
+	if err != nil {
+		return response.Failure[domain.Policy](err.Error())
+	}
+	return response.Success(saved)
+}
@@ -0,0 +1,102 @@
+package category
+
+import (
+	"context"
+
+	domain "shopmate/internal/domain/category"
+	categoryservice "shopmate/internal/services/category"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes the category hierarchy to the frontend.
+type API struct {
+	service       *categoryservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the category API bridge.
+func New(service *categoryservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// CategoryInput describes editable category fields. Nil defaults inherit from the parent.
+type CategoryInput struct {
+	Name                      string `json:"name"`
+	ParentID                  int64  `json:"parentId"`
+	DefaultTaxRateBasisPoints *int64 `json:"defaultTaxRateBasisPoints"`
+	DefaultReorderLevel       *int64 `json:"defaultReorderLevel"`
+}
+
+// UpdateCategoryRequest targets a category for update.
+type UpdateCategoryRequest struct {
+	ID   int64         `json:"id"`
+	Form CategoryInput `json:"form"`
+}
+
+// MergeCategoriesRequest folds one category into another.
+type MergeCategoriesRequest struct {
+	SourceID int64 `json:"sourceId"`
+	TargetID int64 `json:"targetId"`
+}
+
+// ListCategories returns every category ordered by path.
+func (api *API) ListCategories() response.Envelope[[]domain.Category] {
+	ctx := api.contextSource()
+	categories, err := api.service.List(ctx)
+	if err != nil {
+		return response.Failure[[]domain.Category](err.Error())
+	}
+	return response.Success(categories)
+}
+
+// CreateCategory adds a category.
+func (api *API) CreateCategory(input CategoryInput) response.Envelope[domain.Category] {
+	ctx := api.contextSource()
+	c, err := api.service.Create(ctx, toDomainInput(input))
+	if err != nil {
+		return response.Failure[domain.Category](err.Error())
+	}
+	return response.Success(*c)
+}
+
+// UpdateCategory renames, moves or changes defaults of a category.
+func (api *API) UpdateCategory(req UpdateCategoryRequest) response.Envelope[domain.Category] {
+	ctx := api.contextSource()
+	c, err := api.service.Update(ctx, req.ID, toDomainInput(req.Form))
+	if err != nil {
+		return response.Failure[domain.Category](err.Error())
+	}
+	return response.Success(*c)
+}
+
+// MergeCategories moves products and subcategories from source into target.
+func (api *API) MergeCategories(req MergeCategoriesRequest) response.Envelope[domain.Category] {
+	ctx := api.contextSource()
+	c, err := api.service.Merge(ctx, req.SourceID, req.TargetID)
+	if err != nil {
+		return response.Failure[domain.Category](err.Error())
+	}
+	return response.Success(*c)
+}
+
+// DeleteCategory removes an empty category.
+func (api *API) DeleteCategory(id int64) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.Delete(ctx, id); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
+
+func toDomainInput(input CategoryInput) domain.Input {
+	return domain.Input{
+		Name:                      input.Name,
+		ParentID:                  input.ParentID,
+		DefaultTaxRateBasisPoints: input.DefaultTaxRateBasisPoints,
+		DefaultReorderLevel:       input.DefaultReorderLevel,
+	}
+}
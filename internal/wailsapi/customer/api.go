@@ -0,0 +1,113 @@
+package customer
+
+import (
+	"context"
+	"encoding/base64"
+
+	domain "shopmate/internal/domain/customer"
+	customerservice "shopmate/internal/services/customer"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes customer records to the POS and back office.
+type API struct {
+	service       *customerservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the customer API bridge.
+func New(service *customerservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// CustomerInput holds a customer's editable details.
+type CustomerInput struct {
+	Name    string `json:"name"`
+	Phone   string `json:"phone"`
+	Email   string `json:"email"`
+	Address string `json:"address"`
+	TaxID   string `json:"taxId"`
+	Notes   string `json:"notes"`
+	// TaxExempt sells to the customer without tax.
+	TaxExempt          bool   `json:"taxExempt"`
+	TaxExemptionNumber string `json:"taxExemptionNumber"`
+}
+
+// ImportCustomersRequest carries the CSV text to import.
+type ImportCustomersRequest struct {
+	CSV string `json:"csv"`
+}
+
+// Create adds a customer.
+func (api *API) Create(input CustomerInput) response.Envelope[domain.Customer] {
+	ctx := api.contextSource()
+	created, err := api.service.Create(ctx, domain.Input(input))
+	if err != nil {
+		return response.Failure[domain.Customer](err.Error())
+	}
+	return response.Success(*created)
+}
+
+// Update replaces a customer's details.
+func (api *API) Update(id int64, input CustomerInput) response.Envelope[domain.Customer] {
+	ctx := api.contextSource()
+	updated, err := api.service.Update(ctx, id, domain.Input(input))
+	if err != nil {
+		return response.Failure[domain.Customer](err.Error())
+	}
+	return response.Success(*updated)
+}
+
+// Get returns a customer by id.
+func (api *API) Get(id int64) response.Envelope[domain.Customer] {
+	ctx := api.contextSource()
+	c, err := api.service.Get(ctx, id)
+	if err != nil {
+		return response.Failure[domain.Customer](err.Error())
+	}
+	return response.Success(*c)
+}
+
+// Search looks customers up by name, phone or email.
+func (api *API) Search(query string) response.Envelope[[]domain.Customer] {
+	ctx := api.contextSource()
+	customers, err := api.service.Search(ctx, query)
+	if err != nil {
+		return response.Failure[[]domain.Customer](err.Error())
+	}
+	return response.Success(customers)
+}
+
+// Detail returns a customer's lifetime spend and purchase history.
+func (api *API) Detail(id int64) response.Envelope[domain.Detail] {
+	ctx := api.contextSource()
+	detail, err := api.service.Detail(ctx, id)
+	if err != nil {
+		return response.Failure[domain.Detail](err.Error())
+	}
+	return response.Success(*detail)
+}
+
+// ImportCSV imports customers and returns the counts created and updated.
+func (api *API) ImportCSV(req ImportCustomersRequest) response.Envelope[customerservice.ImportSummary] {
+	ctx := api.contextSource()
+	summary, err := api.service.ImportCSV(ctx, []byte(req.CSV))
+	if err != nil {
+		return response.Failure[customerservice.ImportSummary](err.Error())
+	}
+	return response.Success(summary)
+}
+
+// ExportCSV exports every customer to CSV (base64 encoded).
+func (api *API) ExportCSV() response.Envelope[string] {
+	ctx := api.contextSource()
+	data, err := api.service.ExportCSV(ctx)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(base64.StdEncoding.EncodeToString(data))
+}
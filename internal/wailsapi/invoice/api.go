@@ -45,3 +45,43 @@ func (api *API) GeneratePDF(saleID int64) response.Envelope[string] {
 	}
 	return response.Success(base64.StdEncoding.EncodeToString(bytes))
 }
+
+// ExchangeHTML returns the receipt HTML for an exchange id.
+func (api *API) ExchangeHTML(exchangeID int64) response.Envelope[string] {
+	ctx := api.contextSource()
+	html, err := api.service.ExchangeHTML(ctx, exchangeID)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(html)
+}
+
+// ExchangePDF returns a base64 encoded receipt PDF for the exchange id.
+func (api *API) ExchangePDF(exchangeID int64) response.Envelope[string] {
+	ctx := api.contextSource()
+	bytes, err := api.service.ExchangePDF(ctx, exchangeID)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(base64.StdEncoding.EncodeToString(bytes))
+}
+
+// QuoteHTML returns the HTML for a quote id.
+func (api *API) QuoteHTML(quoteID int64) response.Envelope[string] {
+	ctx := api.contextSource()
+	html, err := api.service.QuoteHTML(ctx, quoteID)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(html)
+}
+
+// QuotePDF returns a base64 encoded PDF for the quote id.
+func (api *API) QuotePDF(quoteID int64) response.Envelope[string] {
+	ctx := api.contextSource()
+	bytes, err := api.service.QuotePDF(ctx, quoteID)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(base64.StdEncoding.EncodeToString(bytes))
+}
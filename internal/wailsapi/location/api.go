@@ -0,0 +1,144 @@
+package location
+
+import (
+	"context"
+
+	domain "shopmate/internal/domain/location"
+	locationservice "shopmate/internal/services/location"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes stock locations and transfers to the frontend.
+type API struct {
+	service       *locationservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the location API bridge.
+func New(service *locationservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// LocationInput describes editable location fields.
+type LocationInput struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+// UpdateLocationRequest targets a location for update.
+type UpdateLocationRequest struct {
+	ID   int64         `json:"id"`
+	Form LocationInput `json:"form"`
+}
+
+// StockLevelsRequest filters stock levels; zero values match everything.
+type StockLevelsRequest struct {
+	ProductID  int64 `json:"productId"`
+	LocationID int64 `json:"locationId"`
+}
+
+// TransferLineInput is one product moved by a transfer.
+type TransferLineInput struct {
+	ProductID int64 `json:"productId"`
+	Quantity  int64 `json:"quantity"`
+}
+
+// CreateTransferRequest moves stock between locations.
+type CreateTransferRequest struct {
+	FromLocationID int64               `json:"fromLocationId"`
+	ToLocationID   int64               `json:"toLocationId"`
+	Note           string              `json:"note"`
+	Lines          []TransferLineInput `json:"lines"`
+}
+
+// ListLocations returns all locations.
+func (api *API) ListLocations() response.Envelope[[]domain.Location] {
+	ctx := api.contextSource()
+	locations, err := api.service.List(ctx)
+	if err != nil {
+		return response.Failure[[]domain.Location](err.Error())
+	}
+	return response.Success(locations)
+}
+
+// CreateLocation adds a location.
+func (api *API) CreateLocation(input LocationInput) response.Envelope[domain.Location] {
+	ctx := api.contextSource()
+	loc, err := api.service.Create(ctx, domain.Input{Name: input.Name, Active: input.Active})
+	if err != nil {
+		return response.Failure[domain.Location](err.Error())
+	}
+	return response.Success(*loc)
+}
+
+// UpdateLocation renames or (de)activates a location.
+func (api *API) UpdateLocation(req UpdateLocationRequest) response.Envelope[domain.Location] {
+	ctx := api.contextSource()
+	loc, err := api.service.Update(ctx, req.ID, domain.Input{Name: req.Form.Name, Active: req.Form.Active})
+	if err != nil {
+		return response.Failure[domain.Location](err.Error())
+	}
+	return response.Success(*loc)
+}
+
+// SetDefaultLocation marks the default location.
+func (api *API) SetDefaultLocation(id int64) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.SetDefault(ctx, id); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
+
+// StockLevels returns per-location quantities.
+func (api *API) StockLevels(req StockLevelsRequest) response.Envelope[[]domain.StockLevel] {
+	ctx := api.contextSource()
+	levels, err := api.service.StockLevels(ctx, req.ProductID, req.LocationID)
+	if err != nil {
+		return response.Failure[[]domain.StockLevel](err.Error())
+	}
+	return response.Success(levels)
+}
+
+// CreateTransfer moves stock between locations.
+func (api *API) CreateTransfer(req CreateTransferRequest) response.Envelope[domain.Transfer] {
+	ctx := api.contextSource()
+	lines := make([]domain.TransferLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, domain.TransferLine{ProductID: line.ProductID, Quantity: line.Quantity})
+	}
+	transfer, err := api.service.Transfer(ctx, domain.TransferDraft{
+		FromLocationID: req.FromLocationID,
+		ToLocationID:   req.ToLocationID,
+		Note:           req.Note,
+		Lines:          lines,
+	})
+	if err != nil {
+		return response.Failure[domain.Transfer](err.Error())
+	}
+	return response.Success(*transfer)
+}
+
+// GetTransfer returns a transfer with its lines.
+func (api *API) GetTransfer(id int64) response.Envelope[domain.Transfer] {
+	ctx := api.contextSource()
+	transfer, err := api.service.GetTransfer(ctx, id)
+	if err != nil {
+		return response.Failure[domain.Transfer](err.Error())
+	}
+	return response.Success(*transfer)
+}
+
+// ListTransfers returns recent transfers.
+func (api *API) ListTransfers(limit int) response.Envelope[[]domain.Transfer] {
+	ctx := api.contextSource()
+	transfers, err := api.service.Transfers(ctx, limit)
+	if err != nil {
+		return response.Failure[[]domain.Transfer](err.Error())
+	}
+	return response.Success(transfers)
+}
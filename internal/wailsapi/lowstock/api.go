@@ -0,0 +1,88 @@
+package lowstock
+
+import (
+	"context"
+
+	domain "shopmate/internal/domain/lowstock"
+	lowstockservice "shopmate/internal/services/lowstock"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes the low-stock list, its alerts and the alert policy.
+type API struct {
+	service       *lowstockservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the low-stock API bridge.
+func New(service *lowstockservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// SnoozeAlertRequest hides an alert for a number of hours (0 for the policy default).
+type SnoozeAlertRequest struct {
+	ID    int64 `json:"id"`
+	Hours int64 `json:"hours"`
+}
+
+// List returns products flagged by the alert policy with shortfall and days of cover.
+func (api *API) List() response.Envelope[[]domain.Item] {
+	ctx := api.contextSource()
+	items, err := api.service.List(ctx)
+	if err != nil {
+		return response.Failure[[]domain.Item](err.Error())
+	}
+	return response.Success(items)
+}
+
+// Alerts returns open, unsnoozed alerts.
+func (api *API) Alerts() response.Envelope[[]domain.Alert] {
+	ctx := api.contextSource()
+	alerts, err := api.service.Alerts(ctx)
+	if err != nil {
+		return response.Failure[[]domain.Alert](err.Error())
+	}
+	return response.Success(alerts)
+}
+
+// AcknowledgeAlert marks an alert as seen.
+func (api *API) AcknowledgeAlert(id int64) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.Acknowledge(ctx, id); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
+
+// SnoozeAlert hides an alert for a while.
+func (api *API) SnoozeAlert(req SnoozeAlertRequest) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.Snooze(ctx, req.ID, req.Hours); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
+
+// Policy returns the alert policy.
+func (api *API) Policy() response.Envelope[domain.Policy] {
+	ctx := api.contextSource()
+	policy, err := api.service.Policy(ctx)
+	if err != nil {
+		return response.Failure[domain.Policy](err.Error())
+	}
+	return response.Success(policy)
+}
+
+// SavePolicy updates the alert policy.
+func (api *API) SavePolicy(policy domain.Policy) response.Envelope[domain.Policy] {
+	ctx := api.contextSource()
+	saved, err := api.service.SavePolicy(ctx, policy)
+	if err != nil {
+		return response.Failure[domain.Policy](err.Error())
+	}
+	return response.Success(saved)
+}
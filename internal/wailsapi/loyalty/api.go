@@ -0,0 +1,81 @@
+package loyalty
+
+import (
+	"context"
+
+	domain "shopmate/internal/domain/loyalty"
+	loyaltyservice "shopmate/internal/services/loyalty"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes loyalty points to the POS and back office.
+type API struct {
+	service       *loyaltyservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the loyalty API bridge.
+func New(service *loyaltyservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// AdjustPointsRequest credits (positive) or debits (negative) a customer's points.
+type AdjustPointsRequest struct {
+	CustomerID int64  `json:"customerId"`
+	Points     int64  `json:"points"`
+	Note       string `json:"note"`
+}
+
+// Policy returns the earn and redemption rules.
+func (api *API) Policy() response.Envelope[domain.Policy] {
+	ctx := api.contextSource()
+	policy, err := api.service.Policy(ctx)
+	if err != nil {
+		return response.Failure[domain.Policy](err.Error())
+	}
+	return response.Success(policy)
+}
+
+// SavePolicy updates the earn and redemption rules.
+func (api *API) SavePolicy(policy domain.Policy) response.Envelope[domain.Policy] {
+	ctx := api.contextSource()
+	saved, err := api.service.SavePolicy(ctx, policy)
+	if err != nil {
+		return response.Failure[domain.Policy](err.Error())
+	}
+	return response.Success(saved)
+}
+
+// Account returns a customer's points balance.
+func (api *API) Account(customerID int64) response.Envelope[domain.Account] {
+	ctx := api.contextSource()
+	account, err := api.service.Account(ctx, customerID)
+	if err != nil {
+		return response.Failure[domain.Account](err.Error())
+	}
+	return response.Success(*account)
+}
+
+// Ledger lists a customer's recent points movements.
+func (api *API) Ledger(customerID int64) response.Envelope[[]domain.Entry] {
+	ctx := api.contextSource()
+	entries, err := api.service.Ledger(ctx, customerID)
+	if err != nil {
+		return response.Failure[[]domain.Entry](err.Error())
+	}
+	return response.Success(entries)
+}
+
+// Adjust corrects a customer's points by hand and returns the new balance.
+func (api *API) Adjust(req AdjustPointsRequest) response.Envelope[domain.Account] {
+	ctx := api.contextSource()
+	account, err := api.service.Adjust(ctx, req.CustomerID, req.Points, req.Note)
+	if err != nil {
+		return response.Failure[domain.Account](err.Error())
+	}
+	return response.Success(*account)
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/base64"
 	"errors"
 	"math"
+	"strings"
+	"time"
 
 	domain "shopmate/internal/domain/product"
 	service "shopmate/internal/services/product"
@@ -32,10 +34,19 @@ type ProductInput struct {
 	SKU            string  `json:"sku"`
 	Category       string  `json:"category"`
 	UnitPriceCents int64   `json:"unitPriceCents"`
+	CostCents      int64   `json:"costCents"`
 	TaxRate        float64 `json:"taxRate"`
 	StockQuantity  int64   `json:"stockQuantity"`
 	ReorderLevel   int64   `json:"reorderLevel"`
 	Notes          string  `json:"notes"`
+	Serialised     bool    `json:"serialised"`
+	ChangedBy      string  `json:"changedBy"`
+	// Attributes sets custom attribute values by code; a blank value clears one.
+	Attributes map[string]string `json:"attributes"`
+	// NegativeStockPolicy is Block, Warn or Backorder; empty follows the shop setting.
+	NegativeStockPolicy string `json:"negativeStockPolicy"`
+	// TaxGroupID assigns a tax group that replaces TaxRate; zero uses TaxRate.
+	TaxGroupID int64 `json:"taxGroupId"`
 }
 
 // ProductView models the product payload returned to the frontend.
@@ -44,27 +55,106 @@ type ProductView struct {
 	Name               string  `json:"name"`
 	SKU                string  `json:"sku"`
 	Category           string  `json:"category"`
+	CategoryID         int64   `json:"categoryId"`
 	UnitPriceCents     int64   `json:"unitPriceCents"`
+	CostCents          int64   `json:"costCents"`
 	TaxRate            float64 `json:"taxRate"`
 	TaxRateBasisPoints int64   `json:"taxRateBasisPoints"`
 	StockQuantity      int64   `json:"stockQuantity"`
 	CurrentQty         int64   `json:"currentQty"`
 	ReorderLevel       int64   `json:"reorderLevel"`
 	Notes              string  `json:"notes"`
+	Serialised         bool    `json:"serialised"`
+	Archived           bool    `json:"archived"`
+	IsKit              bool    `json:"isKit"`
+	AvailableQty       int64   `json:"availableQty"`
+	// Attributes holds custom attribute values keyed by attribute code.
+	Attributes map[string]string `json:"attributes"`
+	// Version must be sent back with UpdateProduct.
+	Version             int64  `json:"version"`
+	NegativeStockPolicy string `json:"negativeStockPolicy"`
+	TaxGroupID          int64  `json:"taxGroupId"`
 }
 
 // CreateProduct persists a product and returns its representation.
 
+// SearchProductsRequest filters the product list. Query matches name, SKU, category, notes and
+// attribute values; Attributes requires exact attribute values keyed by attribute code.
+type SearchProductsRequest struct {
+	Query           string            `json:"query"`
+	IncludeArchived bool              `json:"includeArchived"`
+	Attributes      map[string]string `json:"attributes"`
+}
+
+// AttributeInput describes a custom product attribute definition. Type is one of text,
+// number, enum, date or boolean; Options lists the allowed enum values.
+type AttributeInput struct {
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Options   []string `json:"options"`
+	SortOrder int      `json:"sortOrder"`
+}
+
+// UpdateAttributeRequest targets an attribute definition for update.
+type UpdateAttributeRequest struct {
+	ID   int64          `json:"id"`
+	Form AttributeInput `json:"form"`
+}
+
+// UpdateProductRequest edits a product. Version is the version the form was loaded at.
 type UpdateProductRequest struct {
-	ID   int64        `json:"id"`
-	Form ProductInput `json:"form"`
+	ID      int64        `json:"id"`
+	Version int64        `json:"version"`
+	Form    ProductInput `json:"form"`
 }
 
 type AdjustStockRequest struct {
+	ProductID     int64    `json:"productId"`
+	LocationID    int64    `json:"locationId"`
+	Delta         int64    `json:"delta"`
+	Reason        string   `json:"reason"`
+	Ref           string   `json:"ref"`
+	SerialNumbers []string `json:"serialNumbers"`
+	// UnitCostCents costs received stock; zero uses the product's cost.
+	UnitCostCents int64 `json:"unitCostCents"`
+}
+
+// ListSerialsRequest selects tracked units for a product.
+type ListSerialsRequest struct {
 	ProductID int64  `json:"productId"`
-	Delta     int64  `json:"delta"`
-	Reason    string `json:"reason"`
-	Ref       string `json:"ref"`
+	Status    string `json:"status"`
+}
+
+// SchedulePriceRequest sets a product price from an RFC3339 time; blank applies immediately.
+type SchedulePriceRequest struct {
+	ProductID     int64  `json:"productId"`
+	PriceCents    int64  `json:"priceCents"`
+	EffectiveFrom string `json:"effectiveFrom"`
+	ChangedBy     string `json:"changedBy"`
+	Note          string `json:"note"`
+}
+
+// KitComponentInput is one component consumed per kit sold.
+type KitComponentInput struct {
+	ComponentID int64 `json:"componentId"`
+	Quantity    int64 `json:"quantity"`
+}
+
+// SetKitComponentsRequest replaces a kit's component list.
+type SetKitComponentsRequest struct {
+	KitID      int64               `json:"kitId"`
+	Components []KitComponentInput `json:"components"`
+}
+
+// ImportFileRequest carries an uploaded CSV or XLSX file (base64 encoded), an optional
+// column mapping from product field to source column header and the import mode
+// ("AllOrNothing", the default, or "Partial").
+type ImportFileRequest struct {
+	FileName string            `json:"fileName"`
+	Data     string            `json:"data"`
+	Mapping  map[string]string `json:"mapping"`
+	Mode     string            `json:"mode"`
 }
 
 type ImportRequest struct {
@@ -73,9 +163,11 @@ type ImportRequest struct {
 
 // ImportResponse mirrors the service response for CSV imports.
 type ImportResponse struct {
-	Created int      `json:"created"`
-	Updated int      `json:"updated"`
-	Errors  []string `json:"errors"`
+	JobID     int64    `json:"jobId"`
+	JobNumber string   `json:"jobNumber"`
+	Created   int      `json:"created"`
+	Updated   int      `json:"updated"`
+	Errors    []string `json:"errors"`
 }
 
 // CreateProduct persists a product and returns its representation.
@@ -83,14 +175,20 @@ func (api *API) CreateProduct(input ProductInput) response.Envelope[ProductView]
 	ctx := api.contextSource()
 	taxBasisPoints := amountToBasisPoints(input.TaxRate)
 	product, err := api.service.Create(ctx, domain.CreateInput{
-		Name:               input.Name,
-		SKU:                input.SKU,
-		Category:           input.Category,
-		UnitPriceCents:     input.UnitPriceCents,
-		TaxRateBasisPoints: taxBasisPoints,
-		CurrentQty:         input.StockQuantity,
-		ReorderLevel:       input.ReorderLevel,
-		Notes:              input.Notes,
+		Name:                input.Name,
+		SKU:                 input.SKU,
+		Category:            input.Category,
+		UnitPriceCents:      input.UnitPriceCents,
+		CostCents:           input.CostCents,
+		TaxRateBasisPoints:  taxBasisPoints,
+		CurrentQty:          input.StockQuantity,
+		ReorderLevel:        input.ReorderLevel,
+		Notes:               input.Notes,
+		Serialised:          input.Serialised,
+		ChangedBy:           input.ChangedBy,
+		Attributes:          input.Attributes,
+		NegativeStockPolicy: input.NegativeStockPolicy,
+		TaxGroupID:          input.TaxGroupID,
 	})
 	if err != nil {
 		if errors.Is(err, service.ErrDuplicateSKU) {
@@ -101,10 +199,32 @@ func (api *API) CreateProduct(input ProductInput) response.Envelope[ProductView]
 	return response.Success(*mapProduct(product))
 }
 
-// ListProducts retrieves all products.
+// ListProducts retrieves products available for sale.
 func (api *API) ListProducts() response.Envelope[[]ProductView] {
+	return api.listProducts(false)
+}
+
+// ListAllProducts retrieves every product, including archived ones.
+func (api *API) ListAllProducts() response.Envelope[[]ProductView] {
+	return api.listProducts(true)
+}
+
+// SearchProducts retrieves products matching a text query and attribute values.
+func (api *API) SearchProducts(req SearchProductsRequest) response.Envelope[[]ProductView] {
+	return api.searchProducts(domain.Filter{
+		Query:           req.Query,
+		IncludeArchived: req.IncludeArchived,
+		Attributes:      req.Attributes,
+	})
+}
+
+func (api *API) listProducts(includeArchived bool) response.Envelope[[]ProductView] {
+	return api.searchProducts(domain.Filter{IncludeArchived: includeArchived})
+}
+
+func (api *API) searchProducts(filter domain.Filter) response.Envelope[[]ProductView] {
 	ctx := api.contextSource()
-	products, err := api.service.List(ctx)
+	products, err := api.service.Search(ctx, filter)
 	if err != nil {
 		return response.Failure[[]ProductView](err.Error())
 	}
@@ -117,28 +237,49 @@ func (api *API) ListProducts() response.Envelope[[]ProductView] {
 	return response.Success(views)
 }
 
-// UpdateProduct updates a product by id.
+// UpdateProduct updates a product by id. If the product changed since req.Version the
+// envelope fails with code CONFLICT and carries the current product.
 func (api *API) UpdateProduct(req UpdateProductRequest) response.Envelope[ProductView] {
 	ctx := api.contextSource()
 	input := req.Form
 	product, err := api.service.Update(ctx, req.ID, domain.UpdateInput{
-		Name:               input.Name,
-		Category:           input.Category,
-		UnitPriceCents:     input.UnitPriceCents,
-		TaxRateBasisPoints: amountToBasisPoints(input.TaxRate),
-		ReorderLevel:       input.ReorderLevel,
-		Notes:              input.Notes,
+		Name:                input.Name,
+		Category:            input.Category,
+		UnitPriceCents:      input.UnitPriceCents,
+		CostCents:           input.CostCents,
+		TaxRateBasisPoints:  amountToBasisPoints(input.TaxRate),
+		ReorderLevel:        input.ReorderLevel,
+		Notes:               input.Notes,
+		Serialised:          input.Serialised,
+		ChangedBy:           input.ChangedBy,
+		Attributes:          input.Attributes,
+		Version:             req.Version,
+		NegativeStockPolicy: input.NegativeStockPolicy,
+		TaxGroupID:          input.TaxGroupID,
 	})
 	if err != nil {
+		var conflict *domain.ConflictError
+		if errors.As(err, &conflict) {
+			return response.FailureWithData(response.CodeConflict, conflict.Error(), *mapProduct(conflict.Current))
+		}
 		return response.Failure[ProductView](err.Error())
 	}
 	return response.Success(*mapProduct(product))
 }
 
-// DeleteProduct removes a product.
+// DeleteProduct archives a product. It disappears from the POS but stays in sales history and reports.
 func (api *API) DeleteProduct(id int64) response.Envelope[struct{}] {
 	ctx := api.contextSource()
-	if err := api.service.Delete(ctx, id); err != nil {
+	if err := api.service.Archive(ctx, id); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
+
+// UnarchiveProduct returns an archived product to sale.
+func (api *API) UnarchiveProduct(id int64) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.Unarchive(ctx, id); err != nil {
 		return response.Failure[struct{}](err.Error())
 	}
 	return response.SuccessNoData[struct{}]()
@@ -148,10 +289,13 @@ func (api *API) DeleteProduct(id int64) response.Envelope[struct{}] {
 func (api *API) AdjustStock(req AdjustStockRequest) response.Envelope[ProductView] {
 	ctx := api.contextSource()
 	product, err := api.service.AdjustStock(ctx, domain.AdjustmentInput{
-		ProductID: req.ProductID,
-		Delta:     req.Delta,
-		Reason:    req.Reason,
-		Ref:       req.Ref,
+		ProductID:     req.ProductID,
+		LocationID:    req.LocationID,
+		Delta:         req.Delta,
+		Reason:        req.Reason,
+		Ref:           req.Ref,
+		SerialNumbers: req.SerialNumbers,
+		UnitCostCents: req.UnitCostCents,
 	})
 	if err != nil {
 		return response.Failure[ProductView](err.Error())
@@ -159,6 +303,108 @@ func (api *API) AdjustStock(req AdjustStockRequest) response.Envelope[ProductVie
 	return response.Success(*mapProduct(product))
 }
 
+// CostLayers lists the unsold stock a product holds from each receipt, oldest first.
+func (api *API) CostLayers(productID int64) response.Envelope[[]domain.CostLayer] {
+	ctx := api.contextSource()
+	layers, err := api.service.CostLayers(ctx, productID)
+	if err != nil {
+		return response.Failure[[]domain.CostLayer](err.Error())
+	}
+	return response.Success(layers)
+}
+
+// ListSerials returns tracked units for a serialised product.
+func (api *API) ListSerials(req ListSerialsRequest) response.Envelope[[]domain.Serial] {
+	ctx := api.contextSource()
+	serials, err := api.service.Serials(ctx, req.ProductID, req.Status)
+	if err != nil {
+		return response.Failure[[]domain.Serial](err.Error())
+	}
+	return response.Success(serials)
+}
+
+// LookupSerial finds the sale history for a serial number to support warranty claims.
+func (api *API) LookupSerial(serialNo string) response.Envelope[[]domain.SerialLookup] {
+	ctx := api.contextSource()
+	results, err := api.service.LookupSerial(ctx, serialNo)
+	if err != nil {
+		if errors.Is(err, domain.ErrSerialNotFound) {
+			return response.Failure[[]domain.SerialLookup]("SERIAL_NOT_FOUND")
+		}
+		return response.Failure[[]domain.SerialLookup](err.Error())
+	}
+	return response.Success(results)
+}
+
+// PriceHistory lists applied, scheduled and cancelled prices for a product.
+func (api *API) PriceHistory(productID int64) response.Envelope[[]domain.PriceChange] {
+	ctx := api.contextSource()
+	history, err := api.service.PriceHistory(ctx, productID)
+	if err != nil {
+		return response.Failure[[]domain.PriceChange](err.Error())
+	}
+	return response.Success(history)
+}
+
+// SchedulePrice records a price change, now or at a future effective time.
+func (api *API) SchedulePrice(req SchedulePriceRequest) response.Envelope[domain.PriceChange] {
+	ctx := api.contextSource()
+	input := domain.PriceChangeInput{
+		ProductID:  req.ProductID,
+		PriceCents: req.PriceCents,
+		ChangedBy:  req.ChangedBy,
+		Note:       req.Note,
+	}
+	if strings.TrimSpace(req.EffectiveFrom) != "" {
+		effective, err := time.Parse(time.RFC3339, req.EffectiveFrom)
+		if err != nil {
+			return response.Failure[domain.PriceChange](err.Error())
+		}
+		input.EffectiveFrom = effective
+	}
+	change, err := api.service.SchedulePriceChange(ctx, input)
+	if err != nil {
+		return response.Failure[domain.PriceChange](err.Error())
+	}
+	return response.Success(*change)
+}
+
+// CancelScheduledPrice withdraws a price change that has not applied yet.
+func (api *API) CancelScheduledPrice(id int64) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.CancelPriceChange(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrPriceChangeNotPending) {
+			return response.Failure[struct{}]("PRICE_CHANGE_NOT_PENDING")
+		}
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
+
+// KitComponents lists a kit's components and their stock on hand.
+func (api *API) KitComponents(kitID int64) response.Envelope[[]domain.KitComponent] {
+	ctx := api.contextSource()
+	components, err := api.service.KitComponents(ctx, kitID)
+	if err != nil {
+		return response.Failure[[]domain.KitComponent](err.Error())
+	}
+	return response.Success(components)
+}
+
+// SetKitComponents defines a kit; an empty component list makes it an ordinary product again.
+func (api *API) SetKitComponents(req SetKitComponentsRequest) response.Envelope[[]domain.KitComponent] {
+	ctx := api.contextSource()
+	components := make([]domain.KitComponent, 0, len(req.Components))
+	for _, c := range req.Components {
+		components = append(components, domain.KitComponent{ComponentID: c.ComponentID, Quantity: c.Quantity})
+	}
+	saved, err := api.service.SetKitComponents(ctx, req.KitID, components)
+	if err != nil {
+		return response.Failure[[]domain.KitComponent](err.Error())
+	}
+	return response.Success(saved)
+}
+
 // ImportProductsCSV imports CSV payload and returns summary counts.
 func (api *API) ImportProductsCSV(req ImportRequest) response.Envelope[ImportResponse] {
 	ctx := api.contextSource()
@@ -170,6 +416,136 @@ func (api *API) ImportProductsCSV(req ImportRequest) response.Envelope[ImportRes
 	return response.Success(result)
 }
 
+// InspectImportFile reads an uploaded file's headers and proposes a column mapping.
+func (api *API) InspectImportFile(req ImportFileRequest) response.Envelope[service.ImportInspection] {
+	ctx := api.contextSource()
+	data, err := base64.StdEncoding.DecodeString(req.Data)
+	if err != nil {
+		return response.Failure[service.ImportInspection](err.Error())
+	}
+	inspection, err := api.service.InspectImport(ctx, req.FileName, data)
+	if err != nil {
+		return response.Failure[service.ImportInspection](err.Error())
+	}
+	return response.Success(*inspection)
+}
+
+// PreviewImportFile dry-runs an import, reporting creates, updates and errors per row.
+func (api *API) PreviewImportFile(req ImportFileRequest) response.Envelope[domain.ImportPreview] {
+	ctx := api.contextSource()
+	src, err := importSource(req)
+	if err != nil {
+		return response.Failure[domain.ImportPreview](err.Error())
+	}
+	preview, err := api.service.PreviewImport(ctx, src)
+	if err != nil {
+		return response.Failure[domain.ImportPreview](err.Error())
+	}
+	return response.Success(*preview)
+}
+
+// CommitImportFile applies an import previously previewed with the same file and mapping.
+func (api *API) CommitImportFile(req ImportFileRequest) response.Envelope[ImportResponse] {
+	ctx := api.contextSource()
+	src, err := importSource(req)
+	if err != nil {
+		return response.Failure[ImportResponse](err.Error())
+	}
+	summary, err := api.service.CommitImport(ctx, src)
+	if err != nil {
+		return response.Failure[ImportResponse](err.Error())
+	}
+	return response.Success(ImportResponse(summary))
+}
+
+// ImportMapping returns the saved import column mapping.
+func (api *API) ImportMapping() response.Envelope[map[string]string] {
+	ctx := api.contextSource()
+	mapping, err := api.service.ImportMapping(ctx)
+	if err != nil {
+		return response.Failure[map[string]string](err.Error())
+	}
+	return response.Success(map[string]string(mapping))
+}
+
+// SaveImportMapping remembers a column mapping for future imports.
+func (api *API) SaveImportMapping(mapping map[string]string) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.SaveImportMapping(ctx, domain.ColumnMapping(mapping)); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
+
+// ListImportJobs returns recent product imports, newest first.
+func (api *API) ListImportJobs(limit int) response.Envelope[[]domain.ImportJob] {
+	ctx := api.contextSource()
+	jobs, err := api.service.ImportJobs(ctx, limit)
+	if err != nil {
+		return response.Failure[[]domain.ImportJob](err.Error())
+	}
+	return response.Success(jobs)
+}
+
+// GetImportJob returns one import with the products it created or updated.
+func (api *API) GetImportJob(id int64) response.Envelope[domain.ImportJob] {
+	ctx := api.contextSource()
+	job, err := api.service.ImportJob(ctx, id)
+	if err != nil {
+		return response.Failure[domain.ImportJob](err.Error())
+	}
+	return response.Success(*job)
+}
+
+// RollbackImport undoes a product import.
+func (api *API) RollbackImport(id int64) response.Envelope[domain.ImportJob] {
+	ctx := api.contextSource()
+	job, err := api.service.RollbackImport(ctx, id)
+	if err != nil {
+		return response.Failure[domain.ImportJob](err.Error())
+	}
+	return response.Success(*job)
+}
+
+// ListAttributes returns the custom product attribute definitions.
+func (api *API) ListAttributes() response.Envelope[[]domain.Attribute] {
+	ctx := api.contextSource()
+	attributes, err := api.service.ListAttributes(ctx)
+	if err != nil {
+		return response.Failure[[]domain.Attribute](err.Error())
+	}
+	return response.Success(attributes)
+}
+
+// CreateAttribute defines a custom product attribute.
+func (api *API) CreateAttribute(input AttributeInput) response.Envelope[domain.Attribute] {
+	ctx := api.contextSource()
+	attribute, err := api.service.CreateAttribute(ctx, toAttributeInput(input))
+	if err != nil {
+		return response.Failure[domain.Attribute](err.Error())
+	}
+	return response.Success(*attribute)
+}
+
+// UpdateAttribute changes a custom product attribute definition.
+func (api *API) UpdateAttribute(req UpdateAttributeRequest) response.Envelope[domain.Attribute] {
+	ctx := api.contextSource()
+	attribute, err := api.service.UpdateAttribute(ctx, req.ID, toAttributeInput(req.Form))
+	if err != nil {
+		return response.Failure[domain.Attribute](err.Error())
+	}
+	return response.Success(*attribute)
+}
+
+// DeleteAttribute removes a custom product attribute and its values.
+func (api *API) DeleteAttribute(id int64) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.DeleteAttribute(ctx, id); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
+
 // ExportProductsCSV exports inventory to CSV (base64 encoded).
 func (api *API) ExportProductsCSV() response.Envelope[string] {
 	ctx := api.contextSource()
@@ -191,19 +567,52 @@ func (api *API) LowStockCount() response.Envelope[int] {
 	return response.Success(count)
 }
 
+func importSource(req ImportFileRequest) (service.ImportSource, error) {
+	data, err := base64.StdEncoding.DecodeString(req.Data)
+	if err != nil {
+		return service.ImportSource{}, err
+	}
+	return service.ImportSource{
+		FileName: req.FileName,
+		Data:     data,
+		Mapping:  domain.ColumnMapping(req.Mapping),
+		Mode:     req.Mode,
+	}, nil
+}
+
+func toAttributeInput(input AttributeInput) domain.AttributeInput {
+	return domain.AttributeInput{
+		Code:      input.Code,
+		Name:      input.Name,
+		Type:      input.Type,
+		Options:   input.Options,
+		SortOrder: input.SortOrder,
+	}
+}
+
 func mapProduct(p *domain.Product) *ProductView {
 	return &ProductView{
-		ID:                 p.ID,
-		Name:               p.Name,
-		SKU:                p.SKU,
-		Category:           p.Category,
-		UnitPriceCents:     p.UnitPriceCents,
-		TaxRate:            basisPointsToPercent(p.TaxRateBasisPoints),
-		TaxRateBasisPoints: p.TaxRateBasisPoints,
-		StockQuantity:      p.CurrentQty,
-		CurrentQty:         p.CurrentQty,
-		ReorderLevel:       p.ReorderLevel,
-		Notes:              p.Notes,
+		ID:                  p.ID,
+		Name:                p.Name,
+		SKU:                 p.SKU,
+		Category:            p.Category,
+		UnitPriceCents:      p.UnitPriceCents,
+		CostCents:           p.CostCents,
+		TaxRate:             basisPointsToPercent(p.TaxRateBasisPoints),
+		TaxRateBasisPoints:  p.TaxRateBasisPoints,
+		StockQuantity:       p.CurrentQty,
+		CurrentQty:          p.CurrentQty,
+		ReorderLevel:        p.ReorderLevel,
+		Notes:               p.Notes,
+		Serialised:          p.Serialised,
+		CategoryID:          p.CategoryID,
+		Archived:            p.Archived(),
+		IsKit:               p.IsKit,
+		AvailableQty:        p.AvailableQty,
+		Attributes:          p.Attributes,
+		Version:             p.Version,
+		NegativeStockPolicy: p.NegativeStockPolicy,
+		TaxGroupID:          p.TaxGroupID,
 	}
 }
 
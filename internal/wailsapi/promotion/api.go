@@ -0,0 +1,150 @@
+package promotion
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	domain "shopmate/internal/domain/promotion"
+	promotionservice "shopmate/internal/services/promotion"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes promotions and coupons to the back office.
+type API struct {
+	service       *promotionservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the promotion API bridge.
+func New(service *promotionservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// PromotionInput holds a promotion's editable rule. Dates are RFC3339 and may be empty;
+// days are weekdays from 0 (Sunday) and minutes count from midnight.
+type PromotionInput struct {
+	Name               string  `json:"name"`
+	Kind               string  `json:"kind"`
+	Active             bool    `json:"active"`
+	ProductIDs         []int64 `json:"productIds"`
+	CategoryIDs        []int64 `json:"categoryIds"`
+	PercentBasisPoints int64   `json:"percentBasisPoints"`
+	AmountCents        int64   `json:"amountCents"`
+	BuyQty             int64   `json:"buyQty"`
+	GetQty             int64   `json:"getQty"`
+	MinSpendCents      int64   `json:"minSpendCents"`
+	StartsAt           string  `json:"startsAt"`
+	EndsAt             string  `json:"endsAt"`
+	Days               []int   `json:"days"`
+	StartMinute        int     `json:"startMinute"`
+	EndMinute          int     `json:"endMinute"`
+	CouponCode         string  `json:"couponCode"`
+	UsageLimit         int64   `json:"usageLimit"`
+	PerCustomerLimit   int64   `json:"perCustomerLimit"`
+	Priority           int64   `json:"priority"`
+}
+
+func (in PromotionInput) domain() (domain.Input, error) {
+	startsAt, err := parseTime(in.StartsAt)
+	if err != nil {
+		return domain.Input{}, err
+	}
+	endsAt, err := parseTime(in.EndsAt)
+	if err != nil {
+		return domain.Input{}, err
+	}
+	return domain.Input{
+		Name:               in.Name,
+		Kind:               in.Kind,
+		Active:             in.Active,
+		ProductIDs:         in.ProductIDs,
+		CategoryIDs:        in.CategoryIDs,
+		PercentBasisPoints: in.PercentBasisPoints,
+		AmountCents:        in.AmountCents,
+		BuyQty:             in.BuyQty,
+		GetQty:             in.GetQty,
+		MinSpendCents:      in.MinSpendCents,
+		StartsAt:           startsAt,
+		EndsAt:             endsAt,
+		Days:               in.Days,
+		StartMinute:        in.StartMinute,
+		EndMinute:          in.EndMinute,
+		CouponCode:         in.CouponCode,
+		UsageLimit:         in.UsageLimit,
+		PerCustomerLimit:   in.PerCustomerLimit,
+		Priority:           in.Priority,
+	}, nil
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// Create adds a promotion.
+func (api *API) Create(input PromotionInput) response.Envelope[domain.Promotion] {
+	ctx := api.contextSource()
+	in, err := input.domain()
+	if err != nil {
+		return response.Failure[domain.Promotion](err.Error())
+	}
+	created, err := api.service.Create(ctx, in)
+	if err != nil {
+		return response.Failure[domain.Promotion](err.Error())
+	}
+	return response.Success(*created)
+}
+
+// Update replaces a promotion's rule.
+func (api *API) Update(id int64, input PromotionInput) response.Envelope[domain.Promotion] {
+	ctx := api.contextSource()
+	in, err := input.domain()
+	if err != nil {
+		return response.Failure[domain.Promotion](err.Error())
+	}
+	updated, err := api.service.Update(ctx, id, in)
+	if err != nil {
+		return response.Failure[domain.Promotion](err.Error())
+	}
+	return response.Success(*updated)
+}
+
+// Get returns a promotion by id.
+func (api *API) Get(id int64) response.Envelope[domain.Promotion] {
+	ctx := api.contextSource()
+	p, err := api.service.Get(ctx, id)
+	if err != nil {
+		return response.Failure[domain.Promotion](err.Error())
+	}
+	return response.Success(*p)
+}
+
+// List returns every promotion.
+func (api *API) List() response.Envelope[[]domain.Promotion] {
+	ctx := api.contextSource()
+	promotions, err := api.service.List(ctx)
+	if err != nil {
+		return response.Failure[[]domain.Promotion](err.Error())
+	}
+	return response.Success(promotions)
+}
+
+// Delete removes a promotion.
+func (api *API) Delete(id int64) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.Delete(ctx, id); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
@@ -0,0 +1,163 @@
+package quote
+
+import (
+	"context"
+
+	domain "shopmate/internal/domain/quote"
+	domainsale "shopmate/internal/domain/sale"
+	quoteservice "shopmate/internal/services/quote"
+	saleservice "shopmate/internal/services/sale"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes quotes and their conversion into sales.
+type API struct {
+	service       *quoteservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the quote API bridge.
+func New(service *quoteservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// QuoteLine is a product to quote.
+type QuoteLine struct {
+	ProductID     int64 `json:"productId"`
+	Quantity      int64 `json:"quantity"`
+	DiscountCents int64 `json:"discountCents"`
+}
+
+// QuoteRequest is the content of a quote; a zero validDays uses the default validity.
+type QuoteRequest struct {
+	CustomerID    int64       `json:"customerId"`
+	CustomerName  string      `json:"customerName"`
+	ValidDays     int         `json:"validDays"`
+	DiscountCents int64       `json:"discountCents"`
+	Note          string      `json:"note"`
+	Lines         []QuoteLine `json:"lines"`
+}
+
+// QuoteLineSerials names the units sold for a serialised quote line.
+type QuoteLineSerials struct {
+	LineID        int64    `json:"lineId"`
+	SerialNumbers []string `json:"serialNumbers"`
+}
+
+// QuotePayment is one tender handed over when a quote is converted.
+type QuotePayment struct {
+	Method      string `json:"method"`
+	AmountCents int64  `json:"amountCents"`
+	Reference   string `json:"reference"`
+}
+
+// ConvertQuoteRequest turns a quote into a sale, honouring the quoted prices or
+// re-pricing at current prices.
+type ConvertQuoteRequest struct {
+	QuoteID       int64              `json:"quoteId"`
+	HonourPrices  bool               `json:"honourPrices"`
+	PaymentMethod string             `json:"paymentMethod"`
+	Payments      []QuotePayment     `json:"payments"`
+	LocationID    int64              `json:"locationId"`
+	Serials       []QuoteLineSerials `json:"serials"`
+	Note          string             `json:"note"`
+}
+
+// Create saves a new draft quote.
+func (api *API) Create(req QuoteRequest) response.Envelope[domain.Quote] {
+	ctx := api.contextSource()
+	created, err := api.service.Create(ctx, toRequest(req))
+	if err != nil {
+		return response.Failure[domain.Quote](err.Error())
+	}
+	return response.Success(*created)
+}
+
+// Update replaces the content of a draft quote.
+func (api *API) Update(id int64, req QuoteRequest) response.Envelope[domain.Quote] {
+	ctx := api.contextSource()
+	updated, err := api.service.Update(ctx, id, toRequest(req))
+	if err != nil {
+		return response.Failure[domain.Quote](err.Error())
+	}
+	return response.Success(*updated)
+}
+
+// List returns quotes, optionally only those with the given status.
+func (api *API) List(status string) response.Envelope[[]domain.Quote] {
+	ctx := api.contextSource()
+	quotes, err := api.service.List(ctx, status)
+	if err != nil {
+		return response.Failure[[]domain.Quote](err.Error())
+	}
+	return response.Success(quotes)
+}
+
+// Get returns a quote by id.
+func (api *API) Get(id int64) response.Envelope[domain.Quote] {
+	ctx := api.contextSource()
+	q, err := api.service.Get(ctx, id)
+	if err != nil {
+		return response.Failure[domain.Quote](err.Error())
+	}
+	return response.Success(*q)
+}
+
+// MarkSent records that a quote was sent to the customer.
+func (api *API) MarkSent(id int64) response.Envelope[domain.Quote] {
+	ctx := api.contextSource()
+	q, err := api.service.MarkSent(ctx, id)
+	if err != nil {
+		return response.Failure[domain.Quote](err.Error())
+	}
+	return response.Success(*q)
+}
+
+// Convert sells a quote and returns the sale.
+func (api *API) Convert(req ConvertQuoteRequest) response.Envelope[domainsale.Sale] {
+	ctx := api.contextSource()
+	payments := make([]saleservice.CreatePaymentRequest, 0, len(req.Payments))
+	for _, p := range req.Payments {
+		payments = append(payments, saleservice.CreatePaymentRequest{Method: p.Method, AmountCents: p.AmountCents, Reference: p.Reference})
+	}
+	serials := make(map[int64][]string, len(req.Serials))
+	for _, s := range req.Serials {
+		serials[s.LineID] = s.SerialNumbers
+	}
+	created, err := api.service.Convert(ctx, quoteservice.ConvertRequest{
+		QuoteID:       req.QuoteID,
+		HonourPrices:  req.HonourPrices,
+		PaymentMethod: req.PaymentMethod,
+		Payments:      payments,
+		LocationID:    req.LocationID,
+		SerialNumbers: serials,
+		Note:          req.Note,
+	})
+	if err != nil {
+		return response.Failure[domainsale.Sale](err.Error())
+	}
+	return response.Success(*created)
+}
+
+func toRequest(req QuoteRequest) quoteservice.Request {
+	lines := make([]quoteservice.RequestLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, quoteservice.RequestLine{
+			ProductID:     line.ProductID,
+			Quantity:      line.Quantity,
+			DiscountCents: line.DiscountCents,
+		})
+	}
+	return quoteservice.Request{
+		CustomerID:    req.CustomerID,
+		CustomerName:  req.CustomerName,
+		ValidDays:     req.ValidDays,
+		DiscountCents: req.DiscountCents,
+		Note:          req.Note,
+		Lines:         lines,
+	}
+}
@@ -0,0 +1,178 @@
+package replenishment
+
+import (
+	"context"
+
+	domain "shopmate/internal/domain/purchasing"
+	replenishmentservice "shopmate/internal/services/replenishment"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes suppliers, reorder suggestions and draft purchase orders.
+type API struct {
+	service       *replenishmentservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the replenishment API bridge.
+func New(service *replenishmentservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// SupplierInput describes editable supplier fields.
+type SupplierInput struct {
+	Name         string `json:"name"`
+	Contact      string `json:"contact"`
+	Phone        string `json:"phone"`
+	Email        string `json:"email"`
+	LeadTimeDays int64  `json:"leadTimeDays"`
+	Notes        string `json:"notes"`
+}
+
+// UpdateSupplierRequest targets a supplier for update.
+type UpdateSupplierRequest struct {
+	ID   int64         `json:"id"`
+	Form SupplierInput `json:"form"`
+}
+
+// AssignSupplierRequest links a product to a supplier.
+type AssignSupplierRequest struct {
+	ProductID    int64 `json:"productId"`
+	SupplierID   int64 `json:"supplierId"`
+	LeadTimeDays int64 `json:"leadTimeDays"`
+}
+
+// DraftOrderLine requests a product quantity on a draft order.
+type DraftOrderLine struct {
+	ProductID int64 `json:"productId"`
+	Quantity  int64 `json:"quantity"`
+}
+
+// CreateDraftOrdersRequest drafts purchase orders for explicit lines.
+type CreateDraftOrdersRequest struct {
+	Note  string           `json:"note"`
+	Lines []DraftOrderLine `json:"lines"`
+}
+
+// ListOrdersRequest filters purchase orders.
+type ListOrdersRequest struct {
+	Status string `json:"status"`
+	Limit  int    `json:"limit"`
+}
+
+// ListSuppliers returns all suppliers.
+func (api *API) ListSuppliers() response.Envelope[[]domain.Supplier] {
+	ctx := api.contextSource()
+	suppliers, err := api.service.Suppliers(ctx)
+	if err != nil {
+		return response.Failure[[]domain.Supplier](err.Error())
+	}
+	return response.Success(suppliers)
+}
+
+// CreateSupplier adds a supplier.
+func (api *API) CreateSupplier(input SupplierInput) response.Envelope[domain.Supplier] {
+	ctx := api.contextSource()
+	supplier, err := api.service.CreateSupplier(ctx, domain.SupplierInput(input))
+	if err != nil {
+		return response.Failure[domain.Supplier](err.Error())
+	}
+	return response.Success(*supplier)
+}
+
+// UpdateSupplier modifies a supplier.
+func (api *API) UpdateSupplier(req UpdateSupplierRequest) response.Envelope[domain.Supplier] {
+	ctx := api.contextSource()
+	supplier, err := api.service.UpdateSupplier(ctx, req.ID, domain.SupplierInput(req.Form))
+	if err != nil {
+		return response.Failure[domain.Supplier](err.Error())
+	}
+	return response.Success(*supplier)
+}
+
+// AssignSupplier links a product to a supplier.
+func (api *API) AssignSupplier(req AssignSupplierRequest) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.AssignSupplier(ctx, req.ProductID, req.SupplierID, req.LeadTimeDays); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
+
+// Policy returns the suggestion policy.
+func (api *API) Policy() response.Envelope[domain.Policy] {
+	ctx := api.contextSource()
+	policy, err := api.service.Policy(ctx)
+	if err != nil {
+		return response.Failure[domain.Policy](err.Error())
+	}
+	return response.Success(policy)
+}
+
+// SavePolicy updates the suggestion policy.
+func (api *API) SavePolicy(policy domain.Policy) response.Envelope[domain.Policy] {
+	ctx := api.contextSource()
+	saved, err := api.service.SavePolicy(ctx, policy)
+	if err != nil {
+		return response.Failure[domain.Policy](err.Error())
+	}
+	return response.Success(saved)
+}
+
+// Suggestions returns reorder suggestions grouped by supplier (0 for all suppliers).
+func (api *API) Suggestions(supplierID int64) response.Envelope[[]domain.SupplierSuggestions] {
+	ctx := api.contextSource()
+	groups, err := api.service.Suggestions(ctx, supplierID)
+	if err != nil {
+		return response.Failure[[]domain.SupplierSuggestions](err.Error())
+	}
+	return response.Success(groups)
+}
+
+// DraftFromSuggestions creates draft purchase orders from current suggestions.
+func (api *API) DraftFromSuggestions(supplierID int64) response.Envelope[[]domain.PurchaseOrder] {
+	ctx := api.contextSource()
+	orders, err := api.service.DraftFromSuggestions(ctx, supplierID)
+	if err != nil {
+		return response.Failure[[]domain.PurchaseOrder](err.Error())
+	}
+	return response.Success(orders)
+}
+
+// CreateDraftOrders creates draft purchase orders for explicit lines.
+func (api *API) CreateDraftOrders(req CreateDraftOrdersRequest) response.Envelope[[]domain.PurchaseOrder] {
+	ctx := api.contextSource()
+	lines := make([]domain.DraftLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, domain.DraftLine{ProductID: line.ProductID, Quantity: line.Quantity})
+	}
+	orders, err := api.service.CreateDraftOrders(ctx, lines, req.Note)
+	if err != nil {
+		return response.Failure[[]domain.PurchaseOrder](err.Error())
+	}
+	return response.Success(orders)
+}
+
+// ListOrders returns purchase orders.
+func (api *API) ListOrders(req ListOrdersRequest) response.Envelope[[]domain.PurchaseOrder] {
+	ctx := api.contextSource()
+	orders, err := api.service.Orders(ctx, req.Status, req.Limit)
+	if err != nil {
+		return response.Failure[[]domain.PurchaseOrder](err.Error())
+	}
+	return response.Success(orders)
+}
+
+// GetOrder returns a purchase order with its lines.
+func (api *API) GetOrder(id int64) response.Envelope[domain.PurchaseOrder] {
+	ctx := api.contextSource()
+	order, err := api.service.Order(ctx, id)
+	if err != nil {
+		return response.Failure[domain.PurchaseOrder](err.Error())
+	}
+	return response.Success(*order)
+}
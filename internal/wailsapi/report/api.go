@@ -88,3 +88,159 @@ func (api *API) TopProductsCSV(fromISO, toISO string, limit int) response.Envelo
 	}
 	return response.Success(base64.StdEncoding.EncodeToString(bytes))
 }
+
+// CategorySales returns sales rolled up by the subcategories of parentID (0 for top level).
+func (api *API) CategorySales(fromISO, toISO string, parentID int64) response.Envelope[[]report.CategorySales] {
+	ctx := api.contextSource()
+	from, err := time.Parse(time.RFC3339, fromISO)
+	if err != nil {
+		return response.Failure[[]report.CategorySales](err.Error())
+	}
+	to, err := time.Parse(time.RFC3339, toISO)
+	if err != nil {
+		return response.Failure[[]report.CategorySales](err.Error())
+	}
+	categories, err := api.service.CategorySales(ctx, from, to, parentID)
+	if err != nil {
+		return response.Failure[[]report.CategorySales](err.Error())
+	}
+	return response.Success(categories)
+}
+
+// CategorySalesCSV exports the category roll-up as CSV (base64 encoded).
+func (api *API) CategorySalesCSV(fromISO, toISO string, parentID int64) response.Envelope[string] {
+	ctx := api.contextSource()
+	from, err := time.Parse(time.RFC3339, fromISO)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	to, err := time.Parse(time.RFC3339, toISO)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	bytes, err := api.service.CategorySalesCSV(ctx, from, to, parentID)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(base64.StdEncoding.EncodeToString(bytes))
+}
+
+// Valuation values stock on hand as of the given time (RFC3339).
+func (api *API) Valuation(asOfISO string) response.Envelope[report.Valuation] {
+	ctx := api.contextSource()
+	asOf, err := time.Parse(time.RFC3339, asOfISO)
+	if err != nil {
+		return response.Failure[report.Valuation](err.Error())
+	}
+	valuation, err := api.service.Valuation(ctx, asOf)
+	if err != nil {
+		return response.Failure[report.Valuation](err.Error())
+	}
+	return response.Success(*valuation)
+}
+
+// ValuationCSV exports the inventory valuation as CSV (base64 encoded).
+func (api *API) ValuationCSV(asOfISO string) response.Envelope[string] {
+	ctx := api.contextSource()
+	asOf, err := time.Parse(time.RFC3339, asOfISO)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	bytes, err := api.service.ValuationCSV(ctx, asOf)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(base64.StdEncoding.EncodeToString(bytes))
+}
+
+// NegativeStock lists stock positions below zero.
+func (api *API) NegativeStock() response.Envelope[[]report.NegativeStockLine] {
+	ctx := api.contextSource()
+	lines, err := api.service.NegativeStock(ctx)
+	if err != nil {
+		return response.Failure[[]report.NegativeStockLine](err.Error())
+	}
+	return response.Success(lines)
+}
+
+// NegativeStockCSV exports the negative-stock report as CSV (base64 encoded).
+func (api *API) NegativeStockCSV() response.Envelope[string] {
+	ctx := api.contextSource()
+	bytes, err := api.service.NegativeStockCSV(ctx)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(base64.StdEncoding.EncodeToString(bytes))
+}
+
+// PaymentBreakdown returns money taken and refunded by payment method within range.
+func (api *API) PaymentBreakdown(fromISO, toISO string) response.Envelope[[]report.PaymentTotal] {
+	ctx := api.contextSource()
+	from, err := time.Parse(time.RFC3339, fromISO)
+	if err != nil {
+		return response.Failure[[]report.PaymentTotal](err.Error())
+	}
+	to, err := time.Parse(time.RFC3339, toISO)
+	if err != nil {
+		return response.Failure[[]report.PaymentTotal](err.Error())
+	}
+	totals, err := api.service.PaymentBreakdown(ctx, from, to)
+	if err != nil {
+		return response.Failure[[]report.PaymentTotal](err.Error())
+	}
+	return response.Success(totals)
+}
+
+// PaymentBreakdownCSV exports the payment breakdown as CSV (base64 encoded).
+func (api *API) PaymentBreakdownCSV(fromISO, toISO string) response.Envelope[string] {
+	ctx := api.contextSource()
+	from, err := time.Parse(time.RFC3339, fromISO)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	to, err := time.Parse(time.RFC3339, toISO)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	bytes, err := api.service.PaymentBreakdownCSV(ctx, from, to)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(base64.StdEncoding.EncodeToString(bytes))
+}
+
+// Promotions returns the discounts given by each promotion within range.
+func (api *API) Promotions(fromISO, toISO string) response.Envelope[[]report.PromotionTotal] {
+	ctx := api.contextSource()
+	from, err := time.Parse(time.RFC3339, fromISO)
+	if err != nil {
+		return response.Failure[[]report.PromotionTotal](err.Error())
+	}
+	to, err := time.Parse(time.RFC3339, toISO)
+	if err != nil {
+		return response.Failure[[]report.PromotionTotal](err.Error())
+	}
+	totals, err := api.service.Promotions(ctx, from, to)
+	if err != nil {
+		return response.Failure[[]report.PromotionTotal](err.Error())
+	}
+	return response.Success(totals)
+}
+
+// PromotionsCSV exports the promotion totals as CSV (base64 encoded).
+func (api *API) PromotionsCSV(fromISO, toISO string) response.Envelope[string] {
+	ctx := api.contextSource()
+	from, err := time.Parse(time.RFC3339, fromISO)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	to, err := time.Parse(time.RFC3339, toISO)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	bytes, err := api.service.PromotionsCSV(ctx, from, to)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(base64.StdEncoding.EncodeToString(bytes))
+}
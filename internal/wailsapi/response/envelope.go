@@ -1,10 +1,17 @@
 package response
 
+// Error codes let the frontend react to specific failures without parsing messages.
+const (
+	// CodeConflict means the record changed since it was loaded; Data holds its current values.
+	CodeConflict = "CONFLICT"
+)
+
 // Envelope wraps backend responses for Wails bindings.
 type Envelope[T any] struct {
 	OK    bool   `json:"ok"`
 	Data  *T     `json:"data,omitempty"`
 	Error string `json:"error,omitempty"`
+	Code  string `json:"code,omitempty"`
 }
 
 // Success produces a successful envelope with data.
@@ -27,3 +34,14 @@ func Failure[T any](message string) Envelope[T] {
 		Error: message,
 	}
 }
+
+// FailureWithData produces a coded error envelope that still carries a payload, such as the
+// current record after a conflict.
+func FailureWithData[T any](code, message string, value T) Envelope[T] {
+	return Envelope[T]{
+		OK:    false,
+		Data:  &value,
+		Error: message,
+		Code:  code,
+	}
+}
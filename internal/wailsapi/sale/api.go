@@ -26,19 +26,35 @@ func New(service *saleservice.Service, provider func() context.Context) *API {
 
 // CreateSaleRequestLine represents a line input from frontend.
 type CreateSaleRequestLine struct {
-	ProductID     int64 `json:"productId"`
-	Quantity      int64 `json:"quantity"`
-	DiscountCents int64 `json:"discountCents"`
+	ProductID     int64    `json:"productId"`
+	Quantity      int64    `json:"quantity"`
+	DiscountCents int64    `json:"discountCents"`
+	SerialNumbers []string `json:"serialNumbers"`
 }
 
-// CreateSaleRequest payload.
+// CreateSalePayment is one tender handed over by the customer. Gift card and store
+// credit tenders carry the account's code as their reference.
+type CreateSalePayment struct {
+	Method      string `json:"method"`
+	AmountCents int64  `json:"amountCents"`
+	Reference   string `json:"reference"`
+}
+
+// CreateSaleRequest payload. Payments lists the tenders for a split or over-tendered
+// payment; without them the total is paid exactly in PaymentMethod, with PaymentReference
+// naming the gift card or store credit spent. A customerId sells to a customer record
+// under its name. CouponCodes unlocks coupon promotions.
 type CreateSaleRequest struct {
-	SaleNumber    string                  `json:"saleNumber"`
-	CustomerName  string                  `json:"customerName"`
-	PaymentMethod string                  `json:"paymentMethod"`
-	DiscountCents int64                   `json:"discountCents"`
-	Note          string                  `json:"note"`
-	Lines         []CreateSaleRequestLine `json:"lines"`
+	CustomerID       int64                   `json:"customerId"`
+	CustomerName     string                  `json:"customerName"`
+	PaymentMethod    string                  `json:"paymentMethod"`
+	PaymentReference string                  `json:"paymentReference"`
+	Payments         []CreateSalePayment     `json:"payments"`
+	LocationID       int64                   `json:"locationId"`
+	DiscountCents    int64                   `json:"discountCents"`
+	CouponCodes      []string                `json:"couponCodes"`
+	Note             string                  `json:"note"`
+	Lines            []CreateSaleRequestLine `json:"lines"`
 }
 
 // ListSalesRequest describes filters for history retrieval.
@@ -48,6 +64,7 @@ type ListSalesRequest struct {
 	PaymentMethods []string `json:"paymentMethods"`
 	Statuses       []string `json:"statuses"`
 	CustomerQuery  string   `json:"customerQuery"`
+	CustomerID     int64    `json:"customerId"`
 	Limit          int      `json:"limit"`
 	Offset         int      `json:"offset"`
 }
@@ -61,16 +78,26 @@ func (api *API) CreateSale(req CreateSaleRequest) response.Envelope[domainsale.S
 			ProductID:     line.ProductID,
 			Quantity:      line.Quantity,
 			DiscountCents: line.DiscountCents,
+			SerialNumbers: line.SerialNumbers,
 		})
 	}
 
+	payments := make([]saleservice.CreatePaymentRequest, 0, len(req.Payments))
+	for _, p := range req.Payments {
+		payments = append(payments, saleservice.CreatePaymentRequest{Method: p.Method, AmountCents: p.AmountCents, Reference: p.Reference})
+	}
+
 	sale, err := api.service.Create(ctx, saleservice.CreateRequest{
-		SaleNumber:    req.SaleNumber,
-		CustomerName:  req.CustomerName,
-		PaymentMethod: req.PaymentMethod,
-		DiscountCents: req.DiscountCents,
-		Note:          req.Note,
-		Lines:         lines,
+		CustomerID:       req.CustomerID,
+		CustomerName:     req.CustomerName,
+		PaymentMethod:    req.PaymentMethod,
+		PaymentReference: req.PaymentReference,
+		Payments:         payments,
+		LocationID:       req.LocationID,
+		DiscountCents:    req.DiscountCents,
+		CouponCodes:      req.CouponCodes,
+		Note:             req.Note,
+		Lines:            lines,
 	})
 	if err != nil {
 		return response.Failure[domainsale.Sale](err.Error())
@@ -95,6 +122,7 @@ func (api *API) ListSales(req ListSalesRequest) response.Envelope[[]domainsale.S
 	filter.PaymentMethods = req.PaymentMethods
 	filter.Status = req.Statuses
 	filter.CustomerQuery = req.CustomerQuery
+	filter.CustomerID = req.CustomerID
 	filter.Limit = req.Limit
 	filter.Offset = req.Offset
 
@@ -115,7 +143,7 @@ func (api *API) GetSale(id int64) response.Envelope[domainsale.Sale] {
 	return response.Success(*sale)
 }
 
-// RefundSale reverts a sale and restores inventory.
+// RefundSale refunds every unit of a sale not yet refunded and restores inventory.
 
 func (api *API) RefundSale(saleID int64) response.Envelope[struct{}] {
 	ctx := api.contextSource()
@@ -125,6 +153,116 @@ func (api *API) RefundSale(saleID int64) response.Envelope[struct{}] {
 	return response.SuccessNoData[struct{}]()
 }
 
+// RefundLineRequest selects units of a sale line to return.
+type RefundLineRequest struct {
+	SaleLineID    int64    `json:"saleLineId"`
+	Quantity      int64    `json:"quantity"`
+	SerialNumbers []string `json:"serialNumbers"`
+}
+
+// CreateRefundRequest returns some of a sale's lines; an empty payment method refunds to
+// the sale's. Reference names the gift card or store credit to credit.
+type CreateRefundRequest struct {
+	SaleID        int64               `json:"saleId"`
+	PaymentMethod string              `json:"paymentMethod"`
+	Reference     string              `json:"reference"`
+	Reason        string              `json:"reason"`
+	Lines         []RefundLineRequest `json:"lines"`
+}
+
+// CreateRefund records a credit note for the chosen lines and quantities.
+func (api *API) CreateRefund(req CreateRefundRequest) response.Envelope[domainsale.Refund] {
+	ctx := api.contextSource()
+	lines := make([]saleservice.RefundRequestLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, saleservice.RefundRequestLine{
+			SaleLineID:    line.SaleLineID,
+			Quantity:      line.Quantity,
+			SerialNumbers: line.SerialNumbers,
+		})
+	}
+	refund, err := api.service.CreateRefund(ctx, saleservice.RefundRequest{
+		SaleID:        req.SaleID,
+		PaymentMethod: req.PaymentMethod,
+		Reference:     req.Reference,
+		Reason:        req.Reason,
+		Lines:         lines,
+	})
+	if err != nil {
+		return response.Failure[domainsale.Refund](err.Error())
+	}
+	return response.Success(*refund)
+}
+
+// ListRefunds returns a sale's credit notes.
+func (api *API) ListRefunds(saleID int64) response.Envelope[[]domainsale.Refund] {
+	ctx := api.contextSource()
+	refunds, err := api.service.Refunds(ctx, saleID)
+	if err != nil {
+		return response.Failure[[]domainsale.Refund](err.Error())
+	}
+	return response.Success(refunds)
+}
+
+// ExchangeRequest returns lines of a sale and sells replacements in their place; an empty
+// payment method settles with the original sale's.
+type ExchangeRequest struct {
+	SaleID        int64                   `json:"saleId"`
+	PaymentMethod string                  `json:"paymentMethod"`
+	Reason        string                  `json:"reason"`
+	Returns       []RefundLineRequest     `json:"returns"`
+	Lines         []CreateSaleRequestLine `json:"lines"`
+	DiscountCents int64                   `json:"discountCents"`
+	LocationID    int64                   `json:"locationId"`
+	Note          string                  `json:"note"`
+}
+
+// CreateExchange records an exchange and returns both sides with the net amount due.
+func (api *API) CreateExchange(req ExchangeRequest) response.Envelope[domainsale.Exchange] {
+	ctx := api.contextSource()
+	returns := make([]saleservice.RefundRequestLine, 0, len(req.Returns))
+	for _, line := range req.Returns {
+		returns = append(returns, saleservice.RefundRequestLine{
+			SaleLineID:    line.SaleLineID,
+			Quantity:      line.Quantity,
+			SerialNumbers: line.SerialNumbers,
+		})
+	}
+	lines := make([]saleservice.CreateRequestLine, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, saleservice.CreateRequestLine{
+			ProductID:     line.ProductID,
+			Quantity:      line.Quantity,
+			DiscountCents: line.DiscountCents,
+			SerialNumbers: line.SerialNumbers,
+		})
+	}
+	exchange, err := api.service.Exchange(ctx, saleservice.ExchangeRequest{
+		SaleID:        req.SaleID,
+		PaymentMethod: req.PaymentMethod,
+		Reason:        req.Reason,
+		Returns:       returns,
+		Lines:         lines,
+		DiscountCents: req.DiscountCents,
+		LocationID:    req.LocationID,
+		Note:          req.Note,
+	})
+	if err != nil {
+		return response.Failure[domainsale.Exchange](err.Error())
+	}
+	return response.Success(*exchange)
+}
+
+// GetExchange returns an exchange by id.
+func (api *API) GetExchange(id int64) response.Envelope[domainsale.Exchange] {
+	ctx := api.contextSource()
+	exchange, err := api.service.GetExchange(ctx, id)
+	if err != nil {
+		return response.Failure[domainsale.Exchange](err.Error())
+	}
+	return response.Success(*exchange)
+}
+
 // VoidSale voids a sale and restores inventory.
 func (api *API) VoidSale(saleID int64, note string) response.Envelope[struct{}] {
 	ctx := api.contextSource()
@@ -133,3 +271,22 @@ func (api *API) VoidSale(saleID int64, note string) response.Envelope[struct{}]
 	}
 	return response.SuccessNoData[struct{}]()
 }
+
+// ListBackorders returns stock owed to customers; fulfilled backorders only on request.
+func (api *API) ListBackorders(includeFulfilled bool) response.Envelope[[]domainsale.Backorder] {
+	ctx := api.contextSource()
+	backorders, err := api.service.Backorders(ctx, includeFulfilled)
+	if err != nil {
+		return response.Failure[[]domainsale.Backorder](err.Error())
+	}
+	return response.Success(backorders)
+}
+
+// FulfilBackorder marks a backorder as handed to the customer.
+func (api *API) FulfilBackorder(id int64) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.FulfilBackorder(ctx, id); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
@@ -0,0 +1,54 @@
+package sequence
+
+import (
+	"context"
+
+	domain "shopmate/internal/domain/sequence"
+	sequenceservice "shopmate/internal/services/sequence"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes document number formats.
+type API struct {
+	service       *sequenceservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the sequence API bridge.
+func New(service *sequenceservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// Format returns the format of a sequence such as "sale".
+func (api *API) Format(name string) response.Envelope[domain.Format] {
+	ctx := api.contextSource()
+	format, err := api.service.Format(ctx, name)
+	if err != nil {
+		return response.Failure[domain.Format](err.Error())
+	}
+	return response.Success(format)
+}
+
+// SaveFormat updates a sequence's format.
+func (api *API) SaveFormat(format domain.Format) response.Envelope[domain.Format] {
+	ctx := api.contextSource()
+	saved, err := api.service.SaveFormat(ctx, format)
+	if err != nil {
+		return response.Failure[domain.Format](err.Error())
+	}
+	return response.Success(saved)
+}
+
+// Next previews the number a sequence will issue next.
+func (api *API) Next(name string) response.Envelope[string] {
+	ctx := api.contextSource()
+	next, err := api.service.Next(ctx, name)
+	if err != nil {
+		return response.Failure[string](err.Error())
+	}
+	return response.Success(next)
+}
@@ -69,6 +69,66 @@ func (api *API) SavePreferences(prefs domain.Preferences) response.Envelope[doma
 	return response.Success(saved)
 }
 
+// Till returns the register configuration.
+func (api *API) Till() response.Envelope[domain.Till] {
+	ctx := api.contextSource()
+	till, err := api.service.Till(ctx)
+	if err != nil {
+		return response.Failure[domain.Till](err.Error())
+	}
+	return response.Success(till)
+}
+
+// SaveTill updates the register configuration.
+func (api *API) SaveTill(till domain.Till) response.Envelope[domain.Till] {
+	ctx := api.contextSource()
+	saved, err := api.service.SaveTill(ctx, till)
+	if err != nil {
+		return response.Failure[domain.Till](err.Error())
+	}
+	return response.Success(saved)
+}
+
+// Costing returns the inventory costing method.
+func (api *API) Costing() response.Envelope[domain.Costing] {
+	ctx := api.contextSource()
+	costing, err := api.service.Costing(ctx)
+	if err != nil {
+		return response.Failure[domain.Costing](err.Error())
+	}
+	return response.Success(costing)
+}
+
+// SaveCosting updates the inventory costing method.
+func (api *API) SaveCosting(costing domain.Costing) response.Envelope[domain.Costing] {
+	ctx := api.contextSource()
+	saved, err := api.service.SaveCosting(ctx, costing)
+	if err != nil {
+		return response.Failure[domain.Costing](err.Error())
+	}
+	return response.Success(saved)
+}
+
+// StockPolicy returns the shop-wide stock rules.
+func (api *API) StockPolicy() response.Envelope[domain.StockPolicy] {
+	ctx := api.contextSource()
+	policy, err := api.service.StockPolicy(ctx)
+	if err != nil {
+		return response.Failure[domain.StockPolicy](err.Error())
+	}
+	return response.Success(policy)
+}
+
+// SaveStockPolicy updates the shop-wide stock rules.
+func (api *API) SaveStockPolicy(policy domain.StockPolicy) response.Envelope[domain.StockPolicy] {
+	ctx := api.contextSource()
+	saved, err := api.service.SaveStockPolicy(ctx, policy)
+	if err != nil {
+		return response.Failure[domain.StockPolicy](err.Error())
+	}
+	return response.Success(saved)
+}
+
 // SetOwnerPIN stores the owner pin.
 func (api *API) SetOwnerPIN(pin string) response.Envelope[struct{}] {
 	ctx := api.contextSource()
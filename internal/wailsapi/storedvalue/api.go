@@ -0,0 +1,115 @@
+package storedvalue
+
+import (
+	"context"
+
+	domain "shopmate/internal/domain/storedvalue"
+	storedvalueservice "shopmate/internal/services/storedvalue"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes gift cards and store credit to the POS and back office.
+type API struct {
+	service       *storedvalueservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the stored-value API bridge.
+func New(service *storedvalueservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// IssueRequest sells a gift card or opens store credit. An empty code generates one.
+type IssueRequest struct {
+	Kind          string `json:"kind"`
+	Code          string `json:"code"`
+	CustomerID    int64  `json:"customerId"`
+	AmountCents   int64  `json:"amountCents"`
+	PaymentMethod string `json:"paymentMethod"`
+	Note          string `json:"note"`
+}
+
+// TopUpRequest loads more value onto an account.
+type TopUpRequest struct {
+	Code          string `json:"code"`
+	AmountCents   int64  `json:"amountCents"`
+	PaymentMethod string `json:"paymentMethod"`
+	Note          string `json:"note"`
+}
+
+// VoidRequest closes an account.
+type VoidRequest struct {
+	Code string `json:"code"`
+	Note string `json:"note"`
+}
+
+// Issue opens an account and returns it with its code.
+func (api *API) Issue(req IssueRequest) response.Envelope[domain.Account] {
+	ctx := api.contextSource()
+	account, err := api.service.Issue(ctx, domain.Issue{
+		Kind:          req.Kind,
+		Code:          req.Code,
+		CustomerID:    req.CustomerID,
+		AmountCents:   req.AmountCents,
+		PaymentMethod: req.PaymentMethod,
+		Note:          req.Note,
+	})
+	if err != nil {
+		return response.Failure[domain.Account](err.Error())
+	}
+	return response.Success(*account)
+}
+
+// TopUp loads value onto an account and returns the new balance.
+func (api *API) TopUp(req TopUpRequest) response.Envelope[domain.Account] {
+	ctx := api.contextSource()
+	account, err := api.service.TopUp(ctx, req.Code, req.AmountCents, req.PaymentMethod, req.Note)
+	if err != nil {
+		return response.Failure[domain.Account](err.Error())
+	}
+	return response.Success(*account)
+}
+
+// Void closes an account.
+func (api *API) Void(req VoidRequest) response.Envelope[domain.Account] {
+	ctx := api.contextSource()
+	account, err := api.service.Void(ctx, req.Code, req.Note)
+	if err != nil {
+		return response.Failure[domain.Account](err.Error())
+	}
+	return response.Success(*account)
+}
+
+// Balance returns the account with code, for a balance enquiry.
+func (api *API) Balance(code string) response.Envelope[domain.Account] {
+	ctx := api.contextSource()
+	account, err := api.service.Balance(ctx, code)
+	if err != nil {
+		return response.Failure[domain.Account](err.Error())
+	}
+	return response.Success(*account)
+}
+
+// Ledger lists an account's movements.
+func (api *API) Ledger(code string) response.Envelope[[]domain.Entry] {
+	ctx := api.contextSource()
+	entries, err := api.service.Ledger(ctx, code)
+	if err != nil {
+		return response.Failure[[]domain.Entry](err.Error())
+	}
+	return response.Success(entries)
+}
+
+// List returns accounts, only a customer's when customerId is set.
+func (api *API) List(customerID int64) response.Envelope[[]domain.Account] {
+	ctx := api.contextSource()
+	accounts, err := api.service.List(ctx, customerID)
+	if err != nil {
+		return response.Failure[[]domain.Account](err.Error())
+	}
+	return response.Success(accounts)
+}
@@ -0,0 +1,100 @@
+package tax
+
+import (
+	"context"
+
+	domain "shopmate/internal/domain/tax"
+	taxservice "shopmate/internal/services/tax"
+	"shopmate/internal/wailsapi/response"
+)
+
+// API exposes the tax policy and tax groups to the back office.
+type API struct {
+	service       *taxservice.Service
+	contextSource func() context.Context
+}
+
+// New constructs the tax API bridge.
+func New(service *taxservice.Service, provider func() context.Context) *API {
+	source := provider
+	if source == nil {
+		source = context.Background
+	}
+	return &API{service: service, contextSource: source}
+}
+
+// TaxGroupInput holds a tax group's name and its components, in the order they are
+// charged.
+type TaxGroupInput struct {
+	Name       string             `json:"name"`
+	Components []domain.Component `json:"components"`
+}
+
+// Policy returns whether prices include tax and how tax is rounded.
+func (api *API) Policy() response.Envelope[domain.Policy] {
+	ctx := api.contextSource()
+	policy, err := api.service.Policy(ctx)
+	if err != nil {
+		return response.Failure[domain.Policy](err.Error())
+	}
+	return response.Success(policy)
+}
+
+// SavePolicy updates the tax policy.
+func (api *API) SavePolicy(policy domain.Policy) response.Envelope[domain.Policy] {
+	ctx := api.contextSource()
+	saved, err := api.service.SavePolicy(ctx, policy)
+	if err != nil {
+		return response.Failure[domain.Policy](err.Error())
+	}
+	return response.Success(saved)
+}
+
+// CreateGroup adds a tax group.
+func (api *API) CreateGroup(input TaxGroupInput) response.Envelope[domain.Group] {
+	ctx := api.contextSource()
+	created, err := api.service.CreateGroup(ctx, domain.Input(input))
+	if err != nil {
+		return response.Failure[domain.Group](err.Error())
+	}
+	return response.Success(*created)
+}
+
+// UpdateGroup replaces a tax group's name and components.
+func (api *API) UpdateGroup(id int64, input TaxGroupInput) response.Envelope[domain.Group] {
+	ctx := api.contextSource()
+	updated, err := api.service.UpdateGroup(ctx, id, domain.Input(input))
+	if err != nil {
+		return response.Failure[domain.Group](err.Error())
+	}
+	return response.Success(*updated)
+}
+
+// GetGroup returns a tax group by id.
+func (api *API) GetGroup(id int64) response.Envelope[domain.Group] {
+	ctx := api.contextSource()
+	group, err := api.service.GetGroup(ctx, id)
+	if err != nil {
+		return response.Failure[domain.Group](err.Error())
+	}
+	return response.Success(*group)
+}
+
+// ListGroups returns every tax group.
+func (api *API) ListGroups() response.Envelope[[]domain.Group] {
+	ctx := api.contextSource()
+	groups, err := api.service.ListGroups(ctx)
+	if err != nil {
+		return response.Failure[[]domain.Group](err.Error())
+	}
+	return response.Success(groups)
+}
+
+// DeleteGroup removes a tax group no product is assigned to.
+func (api *API) DeleteGroup(id int64) response.Envelope[struct{}] {
+	ctx := api.contextSource()
+	if err := api.service.DeleteGroup(ctx, id); err != nil {
+		return response.Failure[struct{}](err.Error())
+	}
+	return response.SuccessNoData[struct{}]()
+}
@@ -48,6 +48,18 @@ func main() {
 			application.Backups(),
 			application.Settings(),
 			application.Invoices(),
+			application.Locations(),
+			application.Replenishment(),
+			application.Categories(),
+			application.LowStock(),
+			application.Sequences(),
+			application.Carts(),
+			application.Quotes(),
+			application.Customers(),
+			application.Loyalty(),
+			application.StoredValue(),
+			application.Promotions(),
+			application.Taxes(),
 		},
 	})
 	if err != nil {
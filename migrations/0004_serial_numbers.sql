@@ -0,0 +1,20 @@
+ALTER TABLE products ADD COLUMN serialised INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS product_serials (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    serial_no TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'InStock',
+    received_at INTEGER NOT NULL,
+    ref TEXT,
+    UNIQUE (product_id, serial_no)
+);
+
+CREATE TABLE IF NOT EXISTS sale_item_serials (
+    sale_item_id INTEGER NOT NULL REFERENCES sale_items(id) ON DELETE CASCADE,
+    serial_id INTEGER NOT NULL REFERENCES product_serials(id),
+    PRIMARY KEY (sale_item_id, serial_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_serials_serial_no ON product_serials(serial_no);
+CREATE INDEX IF NOT EXISTS idx_sale_item_serials_serial_id ON sale_item_serials(serial_id);
@@ -0,0 +1,76 @@
+CREATE TABLE IF NOT EXISTS locations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    is_default INTEGER NOT NULL DEFAULT 0,
+    active INTEGER NOT NULL DEFAULT 1,
+    created_at INTEGER NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000)
+);
+
+INSERT INTO locations (id, name, is_default)
+SELECT 1, 'Shop Floor', 1
+WHERE NOT EXISTS (SELECT 1 FROM locations);
+
+CREATE TABLE IF NOT EXISTS product_stock (
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    location_id INTEGER NOT NULL REFERENCES locations(id),
+    qty INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (product_id, location_id)
+);
+
+INSERT INTO product_stock (product_id, location_id, qty)
+SELECT id, 1, current_qty FROM products WHERE current_qty <> 0;
+
+-- products.current_qty is a derived total of product_stock.
+CREATE TRIGGER IF NOT EXISTS trg_product_stock_insert
+AFTER INSERT ON product_stock
+FOR EACH ROW
+BEGIN
+    UPDATE products
+    SET current_qty = (SELECT COALESCE(SUM(qty), 0) FROM product_stock WHERE product_id = NEW.product_id)
+    WHERE id = NEW.product_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_product_stock_update
+AFTER UPDATE OF qty ON product_stock
+FOR EACH ROW
+BEGIN
+    UPDATE products
+    SET current_qty = (SELECT COALESCE(SUM(qty), 0) FROM product_stock WHERE product_id = NEW.product_id)
+    WHERE id = NEW.product_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_product_stock_delete
+AFTER DELETE ON product_stock
+FOR EACH ROW
+BEGIN
+    UPDATE products
+    SET current_qty = (SELECT COALESCE(SUM(qty), 0) FROM product_stock WHERE product_id = OLD.product_id)
+    WHERE id = OLD.product_id;
+END;
+
+ALTER TABLE stock_movements ADD COLUMN location_id INTEGER REFERENCES locations(id);
+UPDATE stock_movements SET location_id = 1 WHERE location_id IS NULL;
+
+ALTER TABLE sales ADD COLUMN location_id INTEGER REFERENCES locations(id);
+UPDATE sales SET location_id = 1 WHERE location_id IS NULL;
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transfer_no TEXT NOT NULL UNIQUE,
+    from_location_id INTEGER NOT NULL REFERENCES locations(id),
+    to_location_id INTEGER NOT NULL REFERENCES locations(id),
+    ts INTEGER NOT NULL,
+    note TEXT
+);
+
+CREATE TABLE IF NOT EXISTS stock_transfer_lines (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    transfer_id INTEGER NOT NULL REFERENCES stock_transfers(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    qty INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_stock_location_id ON product_stock(location_id);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_location_id ON stock_movements(location_id);
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_ts ON stock_transfers(ts);
+CREATE INDEX IF NOT EXISTS idx_stock_transfer_lines_transfer_id ON stock_transfer_lines(transfer_id);
@@ -0,0 +1,99 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    parent_id INTEGER REFERENCES categories(id),
+    default_tax_rate_bp INTEGER,
+    default_reorder_level INTEGER,
+    created_at INTEGER NOT NULL DEFAULT (CAST(strftime('%s', 'now') AS INTEGER) * 1000)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_parent_name ON categories(COALESCE(parent_id, 0), name COLLATE NOCASE);
+
+ALTER TABLE products ADD COLUMN category_id INTEGER REFERENCES categories(id);
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products(category_id);
+
+-- Collapse existing free-text categories case-insensitively into the tree. Text written as a
+-- path, such as "Food > Dairy", becomes a category under its parent rather than one named
+-- with the separator; blank steps are dropped and runs of whitespace inside a name become
+-- one space, as category.SplitPath does.
+CREATE TEMP TABLE legacy_category_steps (
+    product_id INTEGER NOT NULL,
+    depth INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    path_key TEXT NOT NULL
+);
+
+WITH RECURSIVE spaced(product_id, category) AS (
+    SELECT id, REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(category,
+        char(9), ' '), char(10), ' '), char(11), ' '), char(12), ' '), char(13), ' '), char(160), ' ')
+    FROM products
+    WHERE category IS NOT NULL
+    UNION ALL
+    SELECT product_id, REPLACE(category, '  ', ' ')
+    FROM spaced
+    WHERE INSTR(category, '  ') > 0
+),
+steps(product_id, depth, name, path_key, rest) AS (
+    SELECT product_id, 0, '', '', category || '>'
+    FROM spaced
+    WHERE INSTR(category, '  ') = 0 AND TRIM(category) <> ''
+    UNION ALL
+    SELECT product_id,
+        depth + (TRIM(SUBSTR(rest, 1, INSTR(rest, '>') - 1)) <> ''),
+        TRIM(SUBSTR(rest, 1, INSTR(rest, '>') - 1)),
+        CASE
+            WHEN TRIM(SUBSTR(rest, 1, INSTR(rest, '>') - 1)) = '' THEN path_key
+            WHEN path_key = '' THEN LOWER(TRIM(SUBSTR(rest, 1, INSTR(rest, '>') - 1)))
+            ELSE path_key || ' > ' || LOWER(TRIM(SUBSTR(rest, 1, INSTR(rest, '>') - 1)))
+        END,
+        SUBSTR(rest, INSTR(rest, '>') + 1)
+    FROM steps
+    WHERE rest <> ''
+)
+INSERT INTO legacy_category_steps (product_id, depth, name, path_key)
+SELECT product_id, depth, name, path_key FROM steps WHERE name <> '';
+
+-- Parents are numbered before their children so each row can point at its parent's id.
+CREATE TEMP TABLE legacy_categories (
+    id INTEGER PRIMARY KEY,
+    path_key TEXT NOT NULL UNIQUE,
+    parent_key TEXT,
+    name TEXT NOT NULL
+);
+
+INSERT INTO legacy_categories (path_key, parent_key, name)
+SELECT s.path_key, MIN(p.path_key), MIN(s.name)
+FROM legacy_category_steps s
+LEFT JOIN legacy_category_steps p ON p.product_id = s.product_id AND p.depth = s.depth - 1
+GROUP BY s.path_key
+ORDER BY MIN(s.depth), s.path_key;
+
+INSERT INTO categories (id, name, parent_id)
+SELECT c.id, c.name, parent.id
+FROM legacy_categories c
+LEFT JOIN legacy_categories parent ON parent.path_key = c.parent_key
+ORDER BY c.id;
+
+UPDATE products
+SET category_id = (
+        SELECT c.id
+        FROM legacy_category_steps s
+        INNER JOIN legacy_categories c ON c.path_key = s.path_key
+        WHERE s.product_id = products.id
+        ORDER BY s.depth DESC
+        LIMIT 1
+    )
+WHERE TRIM(COALESCE(category, '')) <> '';
+
+WITH RECURSIVE category_tree(id, path) AS (
+    SELECT id, name FROM categories WHERE parent_id IS NULL
+    UNION ALL
+    SELECT c.id, category_tree.path || ' > ' || c.name
+    FROM categories c
+    INNER JOIN category_tree ON c.parent_id = category_tree.id
+)
+UPDATE products
+SET category = COALESCE((SELECT path FROM category_tree WHERE category_tree.id = products.category_id), '');
+
+DROP TABLE legacy_category_steps;
+DROP TABLE legacy_categories;
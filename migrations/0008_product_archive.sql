@@ -0,0 +1,10 @@
+ALTER TABLE products ADD COLUMN archived_at INTEGER;
+CREATE INDEX IF NOT EXISTS idx_products_archived_at ON products(archived_at);
+
+-- Snapshot product identity on each sale line so history survives renames and archiving.
+ALTER TABLE sale_items ADD COLUMN product_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE sale_items ADD COLUMN sku TEXT NOT NULL DEFAULT '';
+
+UPDATE sale_items
+SET product_name = COALESCE((SELECT name FROM products WHERE products.id = sale_items.product_id), ''),
+    sku = COALESCE((SELECT sku FROM products WHERE products.id = sale_items.product_id), '');
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS product_prices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    price_cents INTEGER NOT NULL CHECK(price_cents >= 0),
+    effective_from INTEGER NOT NULL,
+    changed_by TEXT NOT NULL DEFAULT '',
+    note TEXT,
+    created_at INTEGER NOT NULL,
+    applied_at INTEGER,
+    cancelled_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_prices_product ON product_prices(product_id, effective_from);
+CREATE INDEX IF NOT EXISTS idx_product_prices_pending ON product_prices(effective_from) WHERE applied_at IS NULL AND cancelled_at IS NULL;
+
+-- Seed history with the price each existing product carries today.
+INSERT INTO product_prices (product_id, price_cents, effective_from, changed_by, note, created_at, applied_at)
+SELECT id, unit_price_cents, 0, '', 'Opening price', CAST(strftime('%s', 'now') AS INTEGER) * 1000, CAST(strftime('%s', 'now') AS INTEGER) * 1000
+FROM products;
@@ -0,0 +1,19 @@
+ALTER TABLE products ADD COLUMN is_kit INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS kit_components (
+    kit_id INTEGER NOT NULL REFERENCES products(id),
+    component_id INTEGER NOT NULL REFERENCES products(id),
+    qty INTEGER NOT NULL CHECK(qty > 0),
+    PRIMARY KEY (kit_id, component_id)
+);
+
+-- Components actually decremented for a kit sale line, so refunds restore exactly what left the shelf.
+CREATE TABLE IF NOT EXISTS sale_item_components (
+    sale_item_id INTEGER NOT NULL REFERENCES sale_items(id),
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    qty INTEGER NOT NULL,
+    PRIMARY KEY (sale_item_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kit_components_component ON kit_components(component_id);
+CREATE INDEX IF NOT EXISTS idx_sale_item_components_product ON sale_item_components(product_id);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_no TEXT NOT NULL UNIQUE,
+    file_name TEXT,
+    mode TEXT NOT NULL DEFAULT 'AllOrNothing',
+    created_count INTEGER NOT NULL DEFAULT 0,
+    updated_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL,
+    rolled_back_at INTEGER
+);
+
+-- One row per product an import touched. before_json holds the product as it was before the
+-- import (NULL for products the import created) and qty_delta the stock the import moved.
+CREATE TABLE IF NOT EXISTS import_job_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    job_id INTEGER NOT NULL REFERENCES import_jobs(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    line INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    qty_delta INTEGER NOT NULL DEFAULT 0,
+    before_json TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_created_at ON import_jobs(created_at);
+CREATE INDEX IF NOT EXISTS idx_import_job_items_job_id ON import_job_items(job_id);
+CREATE INDEX IF NOT EXISTS idx_import_job_items_product_id ON import_job_items(product_id);
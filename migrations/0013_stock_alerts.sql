@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS stock_alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    current_qty INTEGER NOT NULL,
+    shortfall INTEGER NOT NULL DEFAULT 0,
+    raised_at INTEGER NOT NULL,
+    acknowledged_at INTEGER,
+    snoozed_until INTEGER,
+    resolved_at INTEGER
+);
+
+-- At most one open alert per product and rule.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_alerts_open ON stock_alerts(product_id, rule) WHERE resolved_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_alerts_raised_at ON stock_alerts(raised_at);
@@ -0,0 +1,50 @@
+-- Last known unit cost, used for stock received without an explicit cost.
+ALTER TABLE products ADD COLUMN cost_cents INTEGER NOT NULL DEFAULT 0;
+
+UPDATE products
+SET cost_cents = COALESCE((
+    SELECT pol.unit_cost_cents
+    FROM purchase_order_lines pol
+    WHERE pol.product_id = products.id AND pol.unit_cost_cents > 0
+    ORDER BY pol.id DESC
+    LIMIT 1
+), 0);
+
+-- Signed change in inventory value; summing up to a date values stock as of that date.
+ALTER TABLE stock_movements ADD COLUMN value_cents INTEGER NOT NULL DEFAULT 0;
+
+-- Cost of goods sold per line, and per component for kit lines.
+ALTER TABLE sale_items ADD COLUMN cost_cents INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sale_item_components ADD COLUMN cost_cents INTEGER NOT NULL DEFAULT 0;
+
+-- Stock received at a unit cost, consumed oldest first.
+CREATE TABLE IF NOT EXISTS cost_layers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    movement_id INTEGER REFERENCES stock_movements(id),
+    received_at INTEGER NOT NULL,
+    qty INTEGER NOT NULL,
+    remaining_qty INTEGER NOT NULL,
+    unit_cost_cents INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_layers_open ON cost_layers(product_id, received_at) WHERE remaining_qty > 0;
+
+-- Stock on hand before costing existed opens at the product's cost.
+INSERT INTO stock_movements (product_id, location_id, ts, delta, value_cents, reason, ref)
+SELECT
+    id,
+    (SELECT id FROM locations WHERE is_default = 1 ORDER BY id LIMIT 1),
+    CAST(strftime('%s', 'now') AS INTEGER) * 1000,
+    0,
+    current_qty * cost_cents,
+    'Opening valuation',
+    NULL
+FROM products
+WHERE is_kit = 0 AND current_qty > 0;
+
+INSERT INTO cost_layers (product_id, movement_id, received_at, qty, remaining_qty, unit_cost_cents)
+SELECT m.product_id, m.id, m.ts, p.current_qty, p.current_qty, p.cost_cents
+FROM stock_movements m
+INNER JOIN products p ON p.id = m.product_id
+WHERE m.reason = 'Opening valuation';
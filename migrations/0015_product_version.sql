@@ -0,0 +1,31 @@
+-- Edit token for optimistic concurrency: bumped whenever product details or attribute
+-- values change, but not when stock moves.
+ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TRIGGER IF NOT EXISTS trg_products_version
+AFTER UPDATE OF sku, name, category, category_id, unit_price_cents, cost_cents, tax_rate_bp, reorder_level, notes, serialised, archived_at, is_kit ON products
+FOR EACH ROW
+BEGIN
+    UPDATE products SET version = version + 1 WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_product_attribute_values_insert_version
+AFTER INSERT ON product_attribute_values
+FOR EACH ROW
+BEGIN
+    UPDATE products SET version = version + 1 WHERE id = NEW.product_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_product_attribute_values_update_version
+AFTER UPDATE ON product_attribute_values
+FOR EACH ROW
+BEGIN
+    UPDATE products SET version = version + 1 WHERE id = NEW.product_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_product_attribute_values_delete_version
+AFTER DELETE ON product_attribute_values
+FOR EACH ROW
+BEGIN
+    UPDATE products SET version = version + 1 WHERE id = OLD.product_id;
+END;
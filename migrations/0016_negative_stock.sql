@@ -0,0 +1,26 @@
+-- Per-product override of the shop's negative-stock policy; NULL follows the shop setting.
+ALTER TABLE products ADD COLUMN negative_stock_policy TEXT;
+
+-- The policy is a product detail, so changing it invalidates open edits.
+DROP TRIGGER IF EXISTS trg_products_version;
+
+CREATE TRIGGER IF NOT EXISTS trg_products_version
+AFTER UPDATE OF sku, name, category, category_id, unit_price_cents, cost_cents, tax_rate_bp, reorder_level, notes, serialised, archived_at, is_kit, negative_stock_policy ON products
+FOR EACH ROW
+BEGIN
+    UPDATE products SET version = version + 1 WHERE id = NEW.id;
+END;
+
+-- Units sold beyond the stock on hand under the Backorder policy, owed to the customer.
+CREATE TABLE IF NOT EXISTS backorders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    sale_id INTEGER NOT NULL REFERENCES sales(id),
+    sale_item_id INTEGER NOT NULL REFERENCES sale_items(id),
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    location_id INTEGER REFERENCES locations(id),
+    qty INTEGER NOT NULL,
+    created_at INTEGER NOT NULL,
+    fulfilled_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_backorders_open ON backorders(product_id) WHERE fulfilled_at IS NULL;
@@ -0,0 +1,17 @@
+-- Configured number formats, e.g. for sale invoices. Sequences without a row use the
+-- built-in default.
+CREATE TABLE IF NOT EXISTS number_sequences (
+    name TEXT PRIMARY KEY,
+    pattern TEXT NOT NULL,
+    reset_yearly INTEGER NOT NULL DEFAULT 0
+);
+
+-- Last value issued per sequence and period ('' for sequences that never reset, the year
+-- otherwise). Allocated inside the transaction that uses the number, so numbers from
+-- rolled-back work are reissued and the sequence stays gap-free.
+CREATE TABLE IF NOT EXISTS number_sequence_counters (
+    name TEXT NOT NULL,
+    period TEXT NOT NULL,
+    last_value INTEGER NOT NULL,
+    PRIMARY KEY (name, period)
+);
@@ -0,0 +1,46 @@
+-- Units of each sale line returned so far; refunds may not exceed the quantity sold.
+ALTER TABLE sale_items ADD COLUMN refunded_qty INTEGER NOT NULL DEFAULT 0;
+
+-- Sales refunded in full before refunds were itemised.
+UPDATE sale_items SET refunded_qty = qty
+WHERE sale_id IN (SELECT id FROM sales WHERE status = 'Refunded');
+
+-- Credit notes. Totals follow the sale's arithmetic: subtotal - order discount + tax.
+CREATE TABLE IF NOT EXISTS refunds (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    refund_no TEXT NOT NULL UNIQUE,
+    sale_id INTEGER NOT NULL REFERENCES sales(id),
+    ts INTEGER NOT NULL,
+    payment_method TEXT NOT NULL,
+    reason TEXT,
+    subtotal_cents INTEGER NOT NULL DEFAULT 0,
+    discount_cents INTEGER NOT NULL DEFAULT 0,
+    tax_cents INTEGER NOT NULL DEFAULT 0,
+    total_cents INTEGER NOT NULL DEFAULT 0,
+    location_id INTEGER REFERENCES locations(id)
+);
+
+CREATE TABLE IF NOT EXISTS refund_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    refund_id INTEGER NOT NULL REFERENCES refunds(id) ON DELETE CASCADE,
+    sale_item_id INTEGER NOT NULL REFERENCES sale_items(id),
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    qty INTEGER NOT NULL,
+    subtotal_cents INTEGER NOT NULL,
+    line_discount_cents INTEGER NOT NULL,
+    order_discount_cents INTEGER NOT NULL,
+    tax_cents INTEGER NOT NULL,
+    total_cents INTEGER NOT NULL,
+    cost_cents INTEGER NOT NULL
+);
+
+-- Serialised units returned on a refund line.
+CREATE TABLE IF NOT EXISTS refund_item_serials (
+    refund_item_id INTEGER NOT NULL REFERENCES refund_items(id) ON DELETE CASCADE,
+    serial_id INTEGER NOT NULL REFERENCES product_serials(id),
+    PRIMARY KEY (refund_item_id, serial_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_sale_id ON refunds(sale_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_ts ON refunds(ts);
+CREATE INDEX IF NOT EXISTS idx_refund_items_sale_item_id ON refund_items(sale_item_id);
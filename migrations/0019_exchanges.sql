@@ -0,0 +1,11 @@
+-- Exchanges link the credit note for the items brought back to the sale of their
+-- replacements. net_cents is the sale total less the credit: positive when the customer
+-- paid the difference, negative when it was refunded to them.
+CREATE TABLE IF NOT EXISTS exchanges (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    ts INTEGER NOT NULL,
+    refund_id INTEGER NOT NULL UNIQUE REFERENCES refunds(id),
+    sale_id INTEGER NOT NULL UNIQUE REFERENCES sales(id),
+    payment_method TEXT NOT NULL,
+    net_cents INTEGER NOT NULL
+);
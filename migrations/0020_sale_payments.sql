@@ -0,0 +1,16 @@
+-- Tenders taken for each sale. applied_cents is the part of the tender that paid for the
+-- sale; the rest of tendered_cents was given back as change.
+CREATE TABLE IF NOT EXISTS sale_payments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    sale_id INTEGER NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+    method TEXT NOT NULL,
+    tendered_cents INTEGER NOT NULL,
+    applied_cents INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_payments_sale_id ON sale_payments(sale_id);
+CREATE INDEX IF NOT EXISTS idx_sale_payments_method ON sale_payments(method);
+
+-- Sales recorded before split tender were paid exactly, in their single payment method.
+INSERT INTO sale_payments (sale_id, method, tendered_cents, applied_cents)
+SELECT id, payment_method, total_cents, total_cents FROM sales;
@@ -0,0 +1,40 @@
+-- Carts parked at the till to be resumed later, from any POS window.
+CREATE TABLE IF NOT EXISTS held_carts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    customer_name TEXT,
+    discount_cents INTEGER NOT NULL DEFAULT 0,
+    note TEXT,
+    parked_by TEXT NOT NULL,
+    location_id INTEGER NOT NULL REFERENCES locations(id),
+    reserve_stock INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'Held',
+    parked_at INTEGER NOT NULL,
+    expires_at INTEGER NOT NULL,
+    closed_at INTEGER
+);
+
+CREATE INDEX IF NOT EXISTS idx_held_carts_open ON held_carts(expires_at) WHERE status = 'Held';
+
+-- serial_numbers is a JSON array of the units picked for serialised lines.
+CREATE TABLE IF NOT EXISTS held_cart_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    cart_id INTEGER NOT NULL REFERENCES held_carts(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    qty INTEGER NOT NULL,
+    discount_cents INTEGER NOT NULL DEFAULT 0,
+    serial_numbers TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_held_cart_items_cart_id ON held_cart_items(cart_id);
+
+-- Stock held back from other sales for a parked cart. Kits reserve their components.
+-- Rows are removed when the cart is resumed, discarded or expires.
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    cart_id INTEGER NOT NULL REFERENCES held_carts(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    location_id INTEGER NOT NULL REFERENCES locations(id),
+    qty INTEGER NOT NULL,
+    PRIMARY KEY (cart_id, product_id, location_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_reservations_product ON stock_reservations(product_id, location_id);
@@ -0,0 +1,38 @@
+-- Quotes and estimates numbered from the quote sequence. Open quotes (Draft, Sent) can be
+-- converted into a sale, which links the sale and marks the quote Accepted.
+CREATE TABLE IF NOT EXISTS quotes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    quote_no TEXT NOT NULL UNIQUE,
+    customer_name TEXT,
+    status TEXT NOT NULL DEFAULT 'Draft',
+    created_at INTEGER NOT NULL,
+    valid_until INTEGER NOT NULL,
+    sent_at INTEGER,
+    accepted_at INTEGER,
+    sale_id INTEGER UNIQUE REFERENCES sales(id),
+    subtotal_cents INTEGER NOT NULL,
+    discount_cents INTEGER NOT NULL DEFAULT 0,
+    tax_cents INTEGER NOT NULL,
+    total_cents INTEGER NOT NULL,
+    note TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_quotes_open ON quotes(valid_until) WHERE status IN ('Draft', 'Sent');
+
+-- Lines keep the prices quoted so a conversion can honour them.
+CREATE TABLE IF NOT EXISTS quote_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    quote_id INTEGER NOT NULL REFERENCES quotes(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id),
+    product_name TEXT NOT NULL,
+    sku TEXT NOT NULL,
+    qty INTEGER NOT NULL,
+    unit_price_cents INTEGER NOT NULL,
+    tax_rate_bp INTEGER NOT NULL,
+    line_subtotal_cents INTEGER NOT NULL,
+    line_discount_cents INTEGER NOT NULL DEFAULT 0,
+    line_tax_cents INTEGER NOT NULL,
+    line_total_cents INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_quote_items_quote_id ON quote_items(quote_id);
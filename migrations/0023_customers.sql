@@ -0,0 +1,23 @@
+-- Customer records. Sales and quotes link to a customer by id and keep the name as it was
+-- when they were recorded in customer_name. Sales recorded before customers existed keep
+-- only their free-text name.
+CREATE TABLE IF NOT EXISTS customers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    phone TEXT,
+    email TEXT,
+    address TEXT,
+    tax_id TEXT,
+    notes TEXT,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_customers_name ON customers(name COLLATE NOCASE);
+CREATE INDEX IF NOT EXISTS idx_customers_email ON customers(email) WHERE email IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_customers_phone ON customers(phone) WHERE phone IS NOT NULL;
+
+ALTER TABLE sales ADD COLUMN customer_id INTEGER REFERENCES customers(id);
+CREATE INDEX IF NOT EXISTS idx_sales_customer_id ON sales(customer_id, ts);
+
+ALTER TABLE quotes ADD COLUMN customer_id INTEGER REFERENCES customers(id);
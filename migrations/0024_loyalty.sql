@@ -0,0 +1,22 @@
+-- Loyalty points. Every change to a customer's points is a ledger entry; the balance is the
+-- sum of their entries. Credits open a lot whose remaining points later debits consume,
+-- soonest to expire first, until the lot expires.
+CREATE TABLE IF NOT EXISTS loyalty_ledger (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    customer_id INTEGER NOT NULL REFERENCES customers(id),
+    ts INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    points INTEGER NOT NULL,
+    remaining INTEGER NOT NULL DEFAULT 0,
+    expires_at INTEGER,
+    sale_id INTEGER REFERENCES sales(id),
+    refund_id INTEGER REFERENCES refunds(id),
+    note TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_customer ON loyalty_ledger(customer_id, ts);
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_sale ON loyalty_ledger(sale_id) WHERE sale_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_open_lots ON loyalty_ledger(customer_id, expires_at) WHERE remaining > 0;
+
+ALTER TABLE sales ADD COLUMN points_earned INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sales ADD COLUMN points_redeemed INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,37 @@
+-- Gift cards and store credit. The balance is kept on the account so a sale can spend it
+-- with a single conditional update, and every change is also posted to the ledger with the
+-- balance it left.
+CREATE TABLE IF NOT EXISTS stored_value_accounts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL COLLATE NOCASE,
+    kind TEXT NOT NULL,
+    customer_id INTEGER REFERENCES customers(id),
+    balance_cents INTEGER NOT NULL DEFAULT 0 CHECK (balance_cents >= 0),
+    status TEXT NOT NULL DEFAULT 'Active',
+    issued_at INTEGER NOT NULL,
+    voided_at INTEGER,
+    note TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stored_value_accounts_code ON stored_value_accounts(code);
+CREATE INDEX IF NOT EXISTS idx_stored_value_accounts_customer ON stored_value_accounts(customer_id) WHERE customer_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS stored_value_ledger (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    account_id INTEGER NOT NULL REFERENCES stored_value_accounts(id),
+    ts INTEGER NOT NULL,
+    kind TEXT NOT NULL,
+    amount_cents INTEGER NOT NULL,
+    balance_after_cents INTEGER NOT NULL,
+    payment_method TEXT,
+    sale_id INTEGER REFERENCES sales(id),
+    refund_id INTEGER REFERENCES refunds(id),
+    note TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_stored_value_ledger_account ON stored_value_ledger(account_id, ts);
+CREATE INDEX IF NOT EXISTS idx_stored_value_ledger_sale ON stored_value_ledger(sale_id) WHERE sale_id IS NOT NULL;
+
+-- The stored-value account a payment was taken from or a refund paid into.
+ALTER TABLE sale_payments ADD COLUMN reference TEXT;
+ALTER TABLE refunds ADD COLUMN reference TEXT;
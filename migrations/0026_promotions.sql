@@ -0,0 +1,52 @@
+-- Promotions evaluated when a sale is priced. A promotion with a coupon code only applies
+-- when the code is given; used_count counts the sales that redeemed the coupon.
+CREATE TABLE IF NOT EXISTS promotions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    active INTEGER NOT NULL DEFAULT 1,
+    percent_bp INTEGER NOT NULL DEFAULT 0,
+    amount_cents INTEGER NOT NULL DEFAULT 0,
+    buy_qty INTEGER NOT NULL DEFAULT 0,
+    get_qty INTEGER NOT NULL DEFAULT 0,
+    min_spend_cents INTEGER NOT NULL DEFAULT 0,
+    starts_at INTEGER,
+    ends_at INTEGER,
+    days_mask INTEGER NOT NULL DEFAULT 0,
+    start_minute INTEGER NOT NULL DEFAULT 0,
+    end_minute INTEGER NOT NULL DEFAULT 0,
+    coupon_code TEXT COLLATE NOCASE,
+    usage_limit INTEGER NOT NULL DEFAULT 0,
+    per_customer_limit INTEGER NOT NULL DEFAULT 0,
+    used_count INTEGER NOT NULL DEFAULT 0,
+    priority INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_coupon ON promotions(coupon_code) WHERE coupon_code IS NOT NULL;
+
+-- The products and categories a promotion is limited to; none means every product.
+CREATE TABLE IF NOT EXISTS promotion_targets (
+    promotion_id INTEGER NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    product_id INTEGER REFERENCES products(id) ON DELETE CASCADE,
+    category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_targets_promotion ON promotion_targets(promotion_id);
+
+-- The promotions applied to each sale line. The name and coupon are kept so the line still
+-- reads correctly after the promotion is edited or deleted.
+CREATE TABLE IF NOT EXISTS sale_item_promotions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    sale_item_id INTEGER NOT NULL REFERENCES sale_items(id) ON DELETE CASCADE,
+    promotion_id INTEGER REFERENCES promotions(id) ON DELETE SET NULL,
+    name TEXT NOT NULL,
+    coupon_code TEXT,
+    discount_cents INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_item_promotions_item ON sale_item_promotions(sale_item_id);
+CREATE INDEX IF NOT EXISTS idx_sale_item_promotions_promotion ON sale_item_promotions(promotion_id);
+
+ALTER TABLE sale_items ADD COLUMN promotion_discount_cents INTEGER NOT NULL DEFAULT 0;
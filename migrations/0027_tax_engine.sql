@@ -0,0 +1,54 @@
+-- Tax groups name the components charged on the products assigned to them, in order. A
+-- compound component is charged on the amount plus the components before it.
+CREATE TABLE IF NOT EXISTS tax_groups (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tax_components (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id INTEGER NOT NULL REFERENCES tax_groups(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    rate_bp INTEGER NOT NULL,
+    compound INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_components_group ON tax_components(group_id, position);
+
+-- Products without a tax group are taxed at their single tax_rate_bp.
+ALTER TABLE products ADD COLUMN tax_group_id INTEGER REFERENCES tax_groups(id) ON DELETE SET NULL;
+
+-- The tax group is a product detail, so changing it invalidates open edits.
+DROP TRIGGER IF EXISTS trg_products_version;
+
+CREATE TRIGGER IF NOT EXISTS trg_products_version
+AFTER UPDATE OF sku, name, category, category_id, unit_price_cents, cost_cents, tax_rate_bp, reorder_level, notes, serialised, archived_at, is_kit, negative_stock_policy, tax_group_id ON products
+FOR EACH ROW
+BEGIN
+    UPDATE products SET version = version + 1 WHERE id = NEW.id;
+END;
+
+ALTER TABLE customers ADD COLUMN tax_exempt INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE customers ADD COLUMN tax_exemption_number TEXT;
+
+-- tax_included marks sales priced with tax inside their line amounts, whose total is the
+-- subtotal less discounts. Exempt sales keep the exemption they were sold under.
+ALTER TABLE sales ADD COLUMN tax_included INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sales ADD COLUMN tax_exempt INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sales ADD COLUMN tax_exemption_number TEXT;
+
+-- Each sale's tax by component and rate.
+CREATE TABLE IF NOT EXISTS sale_taxes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    sale_id INTEGER NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    rate_bp INTEGER NOT NULL,
+    compound INTEGER NOT NULL DEFAULT 0,
+    taxable_cents INTEGER NOT NULL,
+    tax_cents INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sale_taxes_sale ON sale_taxes(sale_id);
@@ -0,0 +1,4 @@
+-- The customer's points balance once a sale's points were redeemed and earned, printed on
+-- its invoice. Sales recorded before it was kept, and sales outside the loyalty programme,
+-- leave it empty.
+ALTER TABLE sales ADD COLUMN points_balance INTEGER;
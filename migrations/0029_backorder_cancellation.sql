@@ -0,0 +1,8 @@
+-- Backorders stop being owed when their sale is voided or the units are refunded before
+-- they were handed over. Open backorders of sales already voided are cancelled now.
+ALTER TABLE backorders ADD COLUMN cancelled_at INTEGER;
+
+UPDATE backorders
+SET cancelled_at = CAST(strftime('%s', 'now') AS INTEGER) * 1000
+WHERE fulfilled_at IS NULL
+    AND sale_id IN (SELECT id FROM sales WHERE status = 'Voided');